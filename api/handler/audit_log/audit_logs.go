@@ -0,0 +1,100 @@
+package audit_log_handler
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/audit_log/query"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+type AuditLogQuery struct {
+	Page      int        `query:"page"`
+	PageSize  int        `query:"pageSize"`
+	UserId    *uuid.UUID `query:"userId"`
+	Action    string     `query:"action"`
+	Entity    string     `query:"entity"`
+	EntityId  string     `query:"entityId"`
+	From      string     `query:"from"`
+	To        string     `query:"to"`
+	SortOrder string     `query:"sortOrder"`
+}
+
+// AuditLogs is a function to get audit logs
+//
+//	@Summary		Get Audit Log list
+//	@Description	Get audit logs filtered by user, action, entity and date range
+//	@Tags			AuditLog
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	query.AuditLogsResult
+//	@Failure		400	{object}	api.ErrorResponse
+//	@Failure		500	{object}	api.ErrorResponse
+//	@Router			/audit-logs [get]
+//
+//	@param			page		query	int		false	"Page number"
+//	@param			pageSize	query	int		false	"Number of items per page"
+//	@param			userId		query	string	false	"Filter by User ID"
+//	@param			action		query	string	false	"Filter by action (create, update, delete)"
+//	@param			entity		query	string	false	"Filter by entity (e.g. products, purchase-orders)"
+//	@param			entityId	query	string	false	"Filter by entity ID"
+//	@param			from		query	string	false	"From date (DD-MM-YYYY)"
+//	@param			to			query	string	false	"To date (DD-MM-YYYY)"
+//	@param			sortOrder	query	string	false	"Sort order by created_at (asc or desc)"
+func AuditLogs(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var q AuditLogQuery
+
+		if err := c.QueryParser(&q); err != nil {
+			logger.Error("Failed to parse query parameters", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid query parameters",
+			})
+		}
+
+		request := query.AuditLogsRequest{
+			Page:      q.Page,
+			PageSize:  q.PageSize,
+			UserId:    q.UserId,
+			Action:    q.Action,
+			Entity:    q.Entity,
+			EntityId:  q.EntityId,
+			SortOrder: q.SortOrder,
+		}
+
+		if q.From != "" {
+			fromDate, err := time.Parse("02-01-2006", q.From)
+			if err != nil {
+				logger.Error("Invalid from date", "from", q.From, "error", err)
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Invalid from date format (expected: DD-MM-YYYY)",
+				})
+			}
+			request.FromDate = &fromDate
+		}
+
+		if q.To != "" {
+			toDate, err := time.Parse("02-01-2006", q.To)
+			if err != nil {
+				logger.Error("Invalid to date", "to", q.To, "error", err)
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Invalid to date format (expected: DD-MM-YYYY)",
+				})
+			}
+			toDate = toDate.Add(24*time.Hour - time.Second)
+			request.ToDate = &toDate
+		}
+
+		response, err := mediatr.Send[query.AuditLogsRequest, *query.AuditLogsResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to get audit logs", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get audit logs",
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"errors"
 	"log/slog"
 	"mini-erp-backend/api/service/auth/command"
 	"time"
@@ -31,31 +32,47 @@ func Login(logger *slog.Logger) fiber.Handler {
 		request := command.LoginRequest{
 			Username: req.Username,
 			Password: req.Password,
+			IP:       c.IP(),
 		}
 
 		response, err := mediatr.Send[*command.LoginRequest, *command.LoginResult](c.Context(), &request)
 		if err != nil {
 			logger.Error("login command failed", "error", err)
+			switch {
+			case errors.Is(err, command.ErrInvalidCredentials):
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+			case errors.Is(err, command.ErrUserDisabled):
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+			case errors.Is(err, command.ErrTooManyAttempts), errors.Is(err, command.ErrAccountLocked):
+				return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": err.Error()})
+			}
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 		}
 
-		// Set refresh token in an HttpOnly secure cookie (do not expose it in JSON)
-		if response != nil && response.RefreshToken != "" {
-			c.Cookie(&fiber.Cookie{
-				Name:     "refresh_token",
-				Value:    response.RefreshToken,
-				HTTPOnly: true,
-				Secure:   false,
-				SameSite: "Lax",
-				Path:     "/",
-				Expires:  time.Unix(response.RefreshTokenExp, 0),
-			})
-
-			// prevent returning refresh token in JSON body
-			response.RefreshToken = ""
-			response.RefreshTokenExp = 0
-		}
+		// ถ้าต้องยืนยัน MFA จะยังไม่มี refresh token จนกว่าจะเรียก /auth/mfa/verify
+		setRefreshTokenCookie(c, response)
 
 		return c.Status(fiber.StatusOK).JSON(response)
 	}
 }
+
+// setRefreshTokenCookie sets the refresh token in an HttpOnly secure cookie (do not expose it in JSON)
+func setRefreshTokenCookie(c *fiber.Ctx, response *command.LoginResult) {
+	if response == nil || response.RefreshToken == "" {
+		return
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     "refresh_token",
+		Value:    response.RefreshToken,
+		HTTPOnly: true,
+		Secure:   false,
+		SameSite: "Lax",
+		Path:     "/",
+		Expires:  time.Unix(response.RefreshTokenExp, 0),
+	})
+
+	// prevent returning refresh token in JSON body
+	response.RefreshToken = ""
+	response.RefreshTokenExp = 0
+}
@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/auth/query"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+type LoginAttemptQuery struct {
+	Page      int        `query:"page"`
+	PageSize  int        `query:"pageSize"`
+	Username  string     `query:"username"`
+	UserId    *uuid.UUID `query:"userId"`
+	IP        string     `query:"ip"`
+	Result    string     `query:"result"`
+	From      string     `query:"from"`
+	To        string     `query:"to"`
+	SortOrder string     `query:"sortOrder"`
+}
+
+// LoginAttempts is a function to get login attempts
+//
+//	@Summary		Get Login Attempt list
+//	@Description	Get login attempts filtered by username, user, IP, result and date range (requires login_attempt.read)
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	query.LoginAttemptsResult
+//	@Failure		400	{object}	api.ErrorResponse
+//	@Failure		500	{object}	api.ErrorResponse
+//	@Router			/login-attempts [get]
+//
+//	@param			page		query	int		false	"Page number (default 1)"
+//	@param			pageSize	query	int		false	"Number of items per page (default 50)"
+//	@param			username	query	string	false	"Filter by username"
+//	@param			userId		query	string	false	"Filter by User ID"
+//	@param			ip			query	string	false	"Filter by IP address"
+//	@param			result		query	string	false	"Filter by result (success, invalid_credentials, disabled, locked, throttled)"
+//	@param			from		query	string	false	"From date (DD-MM-YYYY)"
+//	@param			to			query	string	false	"To date (DD-MM-YYYY)"
+//	@param			sortOrder	query	string	false	"Sort order by created_at (asc or desc)"
+func LoginAttempts(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var q LoginAttemptQuery
+
+		if err := c.QueryParser(&q); err != nil {
+			logger.Error("Failed to parse query parameters", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid query parameters",
+			})
+		}
+
+		request := query.LoginAttemptsRequest{
+			Page:      q.Page,
+			PageSize:  q.PageSize,
+			Username:  q.Username,
+			UserId:    q.UserId,
+			IP:        q.IP,
+			Result:    q.Result,
+			SortOrder: q.SortOrder,
+		}
+
+		if q.From != "" {
+			fromDate, err := time.Parse("02-01-2006", q.From)
+			if err != nil {
+				logger.Error("Invalid from date", "from", q.From, "error", err)
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Invalid from date format (expected: DD-MM-YYYY)",
+				})
+			}
+			request.FromDate = &fromDate
+		}
+
+		if q.To != "" {
+			toDate, err := time.Parse("02-01-2006", q.To)
+			if err != nil {
+				logger.Error("Invalid to date", "to", q.To, "error", err)
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Invalid to date format (expected: DD-MM-YYYY)",
+				})
+			}
+			toDate = toDate.Add(24*time.Hour - time.Second)
+			request.ToDate = &toDate
+		}
+
+		response, err := mediatr.Send[query.LoginAttemptsRequest, *query.LoginAttemptsResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to get login attempts", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get login attempts",
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
@@ -1 +1,66 @@
 package auth
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/auth/command"
+	"mini-erp-backend/utils"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// Logout revokes the session of the current access token
+func Logout(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userData := utils.GetUserDataLocal(c)
+
+		request := command.LogoutRequest{
+			SessionId: userData.SessionId,
+		}
+
+		response, err := mediatr.Send[*command.LogoutRequest, *command.LogoutResult](c.Context(), &request)
+		if err != nil {
+			logger.Error("logout command failed", "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to logout"})
+		}
+
+		clearRefreshTokenCookie(c)
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
+
+// LogoutAll revokes every session of the current user
+func LogoutAll(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userData := utils.GetUserDataLocal(c)
+
+		request := command.LogoutAllRequest{
+			UserId: userData.UserId,
+		}
+
+		response, err := mediatr.Send[*command.LogoutAllRequest, *command.LogoutAllResult](c.Context(), &request)
+		if err != nil {
+			logger.Error("logout all command failed", "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to logout"})
+		}
+
+		clearRefreshTokenCookie(c)
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
+
+func clearRefreshTokenCookie(c *fiber.Ctx) {
+	c.Cookie(&fiber.Cookie{
+		Name:     "refresh_token",
+		Value:    "",
+		HTTPOnly: true,
+		Secure:   false,
+		SameSite: "Lax",
+		Path:     "/",
+		Expires:  time.Unix(0, 0),
+		MaxAge:   -1,
+	})
+}
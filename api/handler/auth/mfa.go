@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/service/auth/command"
+	"mini-erp-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+type VerifyMfaRequest struct {
+	MfaToken string `json:"mfa_token" form:"mfa_token"`
+	Code     string `json:"code" form:"code"`
+}
+
+type MfaCodeRequest struct {
+	Code string `json:"code" form:"code"`
+}
+
+type DisableMfaRequest struct {
+	Password string `json:"password" form:"password"`
+	Code     string `json:"code" form:"code"`
+}
+
+// VerifyMfa completes a login with the MFA token from /auth/login and a TOTP or recovery code
+func VerifyMfa(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := &VerifyMfaRequest{}
+
+		if err := c.BodyParser(req); err != nil {
+			logger.Error("invalid request body", "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+		}
+
+		if req.MfaToken == "" || req.Code == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "missing mfa_token or code"})
+		}
+
+		request := command.VerifyMfaRequest{
+			MfaToken: req.MfaToken,
+			Code:     req.Code,
+			IP:       c.IP(),
+		}
+
+		response, err := mediatr.Send[*command.VerifyMfaRequest, *command.LoginResult](c.Context(), &request)
+		if err != nil {
+			logger.Error("verify mfa command failed", "error", err)
+			if errors.Is(err, command.ErrInvalidMfaChallenge) || errors.Is(err, command.ErrInvalidMfaCode) {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		setRefreshTokenCookie(c, response)
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
+
+// EnrollMfa creates a new TOTP secret for the current user, MFA stays off until EnableMfa confirms a code
+func EnrollMfa(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userData := utils.GetUserDataLocal(c)
+
+		request := command.EnrollMfaRequest{
+			UserId: userData.UserId,
+		}
+
+		response, err := mediatr.Send[*command.EnrollMfaRequest, *command.EnrollMfaResult](c.Context(), &request)
+		if err != nil {
+			logger.Error("enroll mfa command failed", "error", err)
+			return c.Status(mfaStatus(err)).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
+
+// EnableMfa turns MFA on after the first TOTP code is confirmed and returns the recovery codes
+func EnableMfa(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := &MfaCodeRequest{}
+
+		if err := c.BodyParser(req); err != nil {
+			logger.Error("invalid request body", "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+		}
+
+		userData := utils.GetUserDataLocal(c)
+
+		request := command.EnableMfaRequest{
+			UserId:    userData.UserId,
+			SessionId: userData.SessionId,
+			Code:      req.Code,
+		}
+
+		response, err := mediatr.Send[*command.EnableMfaRequest, *command.RecoveryCodesResult](c.Context(), &request)
+		if err != nil {
+			logger.Error("enable mfa command failed", "error", err)
+			return c.Status(mfaStatus(err)).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
+
+// DisableMfa turns MFA off for the current user
+func DisableMfa(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := &DisableMfaRequest{}
+
+		if err := c.BodyParser(req); err != nil {
+			logger.Error("invalid request body", "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+		}
+
+		userData := utils.GetUserDataLocal(c)
+
+		request := command.DisableMfaRequest{
+			UserId:   userData.UserId,
+			Password: req.Password,
+			Code:     req.Code,
+		}
+
+		response, err := mediatr.Send[*command.DisableMfaRequest, *command.DisableMfaResult](c.Context(), &request)
+		if err != nil {
+			logger.Error("disable mfa command failed", "error", err)
+			return c.Status(mfaStatus(err)).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the current user
+func RegenerateRecoveryCodes(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := &MfaCodeRequest{}
+
+		if err := c.BodyParser(req); err != nil {
+			logger.Error("invalid request body", "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+		}
+
+		userData := utils.GetUserDataLocal(c)
+
+		request := command.RegenerateRecoveryCodesRequest{
+			UserId: userData.UserId,
+			Code:   req.Code,
+		}
+
+		response, err := mediatr.Send[*command.RegenerateRecoveryCodesRequest, *command.RecoveryCodesResult](c.Context(), &request)
+		if err != nil {
+			logger.Error("regenerate recovery codes command failed", "error", err)
+			return c.Status(mfaStatus(err)).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
+
+// mfaStatus maps MFA errors caused by the input or the MFA state to 4xx, anything else is a server error
+func mfaStatus(err error) int {
+	switch {
+	case errors.Is(err, command.ErrInvalidMfaCode),
+		errors.Is(err, command.ErrMfaAlreadyEnabled),
+		errors.Is(err, command.ErrMfaNotEnrolled),
+		errors.Is(err, command.ErrMfaNotEnabled),
+		errors.Is(err, command.ErrInvalidCurrentPassword):
+		return fiber.StatusBadRequest
+	case errors.Is(err, command.ErrMfaRequired):
+		return fiber.StatusForbidden
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/service/auth/command"
+	"mini-erp-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" form:"current_password"`
+	NewPassword     string `json:"new_password" form:"new_password"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" form:"token"`
+	NewPassword string `json:"new_password" form:"new_password"`
+}
+
+// ChangePassword changes the password of the current user and revokes their other sessions
+func ChangePassword(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := &ChangePasswordRequest{}
+
+		if err := c.BodyParser(req); err != nil {
+			logger.Error("invalid request body", "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+		}
+
+		userData := utils.GetUserDataLocal(c)
+
+		request := command.ChangePasswordRequest{
+			UserId:          userData.UserId,
+			SessionId:       userData.SessionId,
+			CurrentPassword: req.CurrentPassword,
+			NewPassword:     req.NewPassword,
+		}
+
+		response, err := mediatr.Send[*command.ChangePasswordRequest, *command.ChangePasswordResult](c.Context(), &request)
+		if err != nil {
+			logger.Error("change password command failed", "error", err)
+			return c.Status(passwordStatus(err)).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
+
+// ResetPassword sets a new password with a reset token issued by an admin
+func ResetPassword(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := &ResetPasswordRequest{}
+
+		if err := c.BodyParser(req); err != nil {
+			logger.Error("invalid request body", "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+		}
+
+		request := command.ResetPasswordRequest{
+			Token:       req.Token,
+			NewPassword: req.NewPassword,
+		}
+
+		response, err := mediatr.Send[*command.ResetPasswordRequest, *command.ResetPasswordResult](c.Context(), &request)
+		if err != nil {
+			logger.Error("reset password command failed", "error", err)
+			return c.Status(passwordStatus(err)).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
+
+// passwordStatus maps password errors caused by the input to 400, anything else is a server error
+func passwordStatus(err error) int {
+	switch {
+	case errors.Is(err, command.ErrInvalidCurrentPassword),
+		errors.Is(err, command.ErrPasswordUnchanged),
+		errors.Is(err, command.ErrInvalidResetToken),
+		errors.Is(err, utils.ErrWeakPassword):
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
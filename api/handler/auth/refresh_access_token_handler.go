@@ -2,8 +2,10 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"mini-erp-backend/api/service/auth/command"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/mehdihadeli/go-mediatr"
@@ -21,7 +23,7 @@ func RefreshAccessToken(logger *slog.Logger) fiber.Handler {
 		}
 
 		// Pass refresh token only via context (cookie). Command will read it from context.
-		req := &command.RefreshAccessTokenRequest{}
+		req := &command.RefreshAccessTokenRequest{IP: c.IP()}
 		ctx := context.WithValue(c.Context(), command.RefreshTokenContextKey, rt)
 
 		response, err := mediatr.Send[*command.RefreshAccessTokenRequest, *command.RefreshAccessTokenResult](ctx, req)
@@ -29,9 +31,28 @@ func RefreshAccessToken(logger *slog.Logger) fiber.Handler {
 			if logger != nil {
 				logger.Error("refresh token command failed", "error", err)
 			}
+			if errors.Is(err, command.ErrRefreshTokenReused) {
+				clearRefreshTokenCookie(c)
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": command.ErrRefreshTokenReused.Error()})
+			}
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 		}
 
+		// The refresh token is rotated on every call, replace the cookie with the new one
+		c.Cookie(&fiber.Cookie{
+			Name:     "refresh_token",
+			Value:    response.RefreshToken,
+			HTTPOnly: true,
+			Secure:   false,
+			SameSite: "Lax",
+			Path:     "/",
+			Expires:  time.Unix(response.RefreshTokenExp, 0),
+		})
+
+		// prevent returning refresh token in JSON body
+		response.RefreshToken = ""
+		response.RefreshTokenExp = 0
+
 		return c.Status(fiber.StatusOK).JSON(response)
 	}
 }
@@ -0,0 +1,77 @@
+package customer_handler
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/customer/command"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mehdihadeli/go-mediatr"
+	"github.com/shopspring/decimal"
+)
+
+// Create is a function to create a new customer
+//
+//	@Summary		Create Customer
+//	@Description	Create a new customer
+//	@Tags			Customer
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		command.CreateRequest	true	"Create Request"
+//	@Success		201		{object}	command.CreateResult
+//	@Failure		409		{object}	api.ErrorResponse	"Conflict: Customer tax id already exists"
+//	@Failure		400		{object}	api.ErrorResponse	"Bad Request: Invalid input"
+//	@Failure		500		{object}	api.ErrorResponse	"Internal Server Error"
+//	@Router			/customers [post]
+func Create(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		request := command.CreateRequest{}
+
+		if err := c.BodyParser(&request); err != nil {
+			logger.Error("Failed to parse create customer request", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+
+		if msg := validateCustomer(request.Name, request.CreditLimit, request.PaymentTermDays); msg != "" {
+			logger.Error("Invalid create customer request", slog.String("error", msg))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": msg,
+			})
+		}
+
+		response, err := mediatr.Send[command.CreateRequest, *command.CreateResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to create customer", slog.String("error", err.Error()))
+
+			if strings.Contains(err.Error(), "already exists") {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to create customer",
+			})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(response)
+	}
+}
+
+func validateCustomer(name string, creditLimit decimal.Decimal, paymentTermDays int) string {
+	if strings.TrimSpace(name) == "" {
+		return "Customer name is required"
+	}
+
+	if creditLimit.IsNegative() {
+		return "Credit limit must not be negative"
+	}
+
+	if paymentTermDays < 0 {
+		return "Payment term days must not be negative"
+	}
+
+	return ""
+}
@@ -0,0 +1,55 @@
+package customer_handler
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/customer/query"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// CustomerById is a function to get customer by id
+//
+//	@Summary		Get Customer by ID
+//	@Description	Get customer by ID
+//	@Tags			Customer
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	query.CustomerByIdResult
+//	@Failure		404	{object}	api.ErrorResponse	"Not Found: Customer does not exist"
+//	@Router			/customers/{id} [get]
+//
+//	@param			id	path	string	true	"Customer ID"
+func CustomerById(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		customerId, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			logger.Error("Invalid customer ID", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid customer ID",
+			})
+		}
+
+		request := query.CustomerByIdRequest{
+			CustomerId: customerId,
+		}
+
+		response, err := mediatr.Send[query.CustomerByIdRequest, *query.CustomerByIdResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to get customer by id", slog.String("error", err.Error()))
+
+			if strings.Contains(err.Error(), "not found") {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": "Customer not found",
+				})
+			}
+
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get customer by id",
+			})
+		}
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
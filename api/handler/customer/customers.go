@@ -0,0 +1,63 @@
+package customer_handler
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/customer/query"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+type CustomerQuery struct {
+	Page      int    `query:"page"`
+	PageSize  int    `query:"pageSize"`
+	Search    string `query:"search"`
+	SortBy    string `query:"sortBy"`
+	SortOrder string `query:"sortOrder"`
+}
+
+// Customers is a function to get all customers
+//
+//	@Summary		Get Customer list
+//	@Description	Get customer list
+//	@Tags			Customer
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	query.CustomersResult
+//	@Router			/customers [get]
+//
+//	@param			page		query	int		false	"Page number"
+//	@param			pageSize	query	int		false	"Number of items per page"
+//	@param			search		query	string	false	"Search term for name, contact name, email, phone and tax id"
+//	@param			sortBy		query	string	false	"Field to sort by (name, credit_limit, created_at, updated_at)"
+//	@param			sortOrder	query	string	false	"Sort order (asc or desc)"
+func Customers(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var q CustomerQuery
+
+		if err := c.QueryParser(&q); err != nil {
+			logger.Error("Failed to parse query parameters", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid query parameters",
+			})
+		}
+
+		request := query.CustomersRequest{
+			Page:      q.Page,
+			PageSize:  q.PageSize,
+			Search:    q.Search,
+			SortBy:    q.SortBy,
+			SortOrder: q.SortOrder,
+		}
+
+		response, err := mediatr.Send[query.CustomersRequest, *query.CustomersResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to get customers", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get customers",
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
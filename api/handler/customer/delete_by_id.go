@@ -0,0 +1,64 @@
+package customer_handler
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/customer/command"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// DeleteById is a function to delete customer by id
+//
+//	@Summary		Delete Customer by ID
+//	@Description	Delete customer by ID. Customers that already have sales orders cannot be deleted.
+//	@Tags			Customer
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	command.DeleteByIdResult
+//	@Failure		404	{object}	map[string]string	"Not Found: Customer does not exist"
+//	@Failure		409	{object}	map[string]string	"Conflict: Customer has sales orders"
+//	@Failure		500	{object}	map[string]string	"Internal Server Error"
+//	@Router			/customers/{id} [delete]
+//
+//	@param			id	path	string	true	"Customer ID"
+func DeleteById(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		customerId, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			logger.Error("Invalid customer ID", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid customer ID",
+			})
+		}
+
+		request := command.DeleteByIdRequest{
+			CustomerId: customerId,
+		}
+
+		response, err := mediatr.Send[command.DeleteByIdRequest, *command.DeleteByIdResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to delete customer", slog.String("error", err.Error()))
+
+			if strings.Contains(err.Error(), "not found") {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			if strings.Contains(err.Error(), "referenced by") {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to delete customer",
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
@@ -0,0 +1,79 @@
+package customer_handler
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/customer/command"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// Update is a function to update customer by id
+//
+//	@Summary		Update Customer by ID
+//	@Description	Update customer by ID
+//	@Tags			Customer
+//	@Accept			json
+//	@Produce		json
+//	@param			id		path		string					true	"Customer ID"
+//	@Param			request	body		command.UpdateRequest	true	"Update Request"
+//	@Success		200		{object}	command.UpdateResult
+//	@Failure		409		{object}	api.ErrorResponse		"Conflict: Customer tax id already exists"
+//	@Failure		400		{object}	api.ErrorResponse		"Bad Request: Invalid input"
+//	@Failure		404		{object}	api.ErrorResponse		"Not Found: Customer does not exist"
+//	@Failure		500		{object}	api.ErrorResponse		"Internal Server Error"
+//	@Router			/customers/{id} [patch]
+func Update(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		customerId, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			logger.Error("Invalid customer ID", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid customer ID",
+			})
+		}
+
+		request := command.UpdateRequest{}
+
+		if err := c.BodyParser(&request); err != nil {
+			logger.Error("Failed to parse update customer request", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+
+		request.CustomerId = customerId
+
+		if msg := validateCustomer(request.Name, request.CreditLimit, request.PaymentTermDays); msg != "" {
+			logger.Error("Invalid update customer request", slog.String("error", msg))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": msg,
+			})
+		}
+
+		response, err := mediatr.Send[command.UpdateRequest, *command.UpdateResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to update customer", slog.String("error", err.Error()))
+
+			if strings.Contains(err.Error(), "already exists") {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			if strings.Contains(err.Error(), "not found") {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to update customer",
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
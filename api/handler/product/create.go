@@ -1,6 +1,7 @@
 package product_handler
 
 import (
+	"errors"
 	"log/slog"
 	"mini-erp-backend/api/service/product/command"
 	"strings"
@@ -45,6 +46,12 @@ func Create(logger *slog.Logger) fiber.Handler {
 				})
 			}
 
+			if errors.Is(err, command.ErrSerialBackorder) || errors.Is(err, command.ErrInvalidCostingMethod) || errors.Is(err, command.ErrInvalidReplenishment) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": "Failed to create category",
 			})
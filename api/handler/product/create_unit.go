@@ -0,0 +1,77 @@
+package product_handler
+
+import (
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/service/product/command"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// CreateUnit is a function to add a unit of measure to a product
+//
+//	@Summary		Create Product Unit
+//	@Description	Add a unit of measure to a product, e.g. box = 12 pcs. Quantities entered in this unit are converted to the base unit.
+//	@Tags			Product
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string						true	"Product ID"
+//	@Param			request	body		command.CreateUnitRequest	true	"Create Unit Request"
+//	@Success		201		{object}	command.CreateUnitResult
+//	@Failure		400		{object}	api.ErrorResponse	"Bad Request: Invalid input"
+//	@Failure		404		{object}	api.ErrorResponse	"Not Found: Product does not exist"
+//	@Failure		409		{object}	api.ErrorResponse	"Conflict: Unit already exists"
+//	@Failure		500		{object}	api.ErrorResponse	"Internal Server Error"
+//	@Router			/products/{id}/units [post]
+func CreateUnit(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		productId, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			logger.Error("Invalid product ID", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid product ID",
+			})
+		}
+
+		request := command.CreateUnitRequest{}
+		if err := c.BodyParser(&request); err != nil {
+			logger.Error("Failed to parse create unit request", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+		request.ProductId = productId
+
+		response, err := mediatr.Send[command.CreateUnitRequest, *command.CreateUnitResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to create unit", slog.String("error", err.Error()))
+
+			if errors.Is(err, command.ErrInvalidUnit) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			if strings.Contains(err.Error(), "already exists") {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			if strings.Contains(err.Error(), "not found") {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to create unit",
+			})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(response)
+	}
+}
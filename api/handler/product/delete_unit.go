@@ -0,0 +1,67 @@
+package product_handler
+
+import (
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/api/service/product/command"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// DeleteUnit is a function to remove a unit of measure from a product
+//
+//	@Summary		Delete Product Unit
+//	@Description	Remove a unit of measure from a product. Stock already recorded stays in the base unit.
+//	@Tags			Product
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string	true	"Product ID"
+//	@Param			unitId	path		string	true	"Unit of Measure ID"
+//	@Success		200		{object}	command.DeleteUnitResult
+//	@Failure		400		{object}	api.ErrorResponse
+//	@Failure		404		{object}	api.ErrorResponse
+//	@Router			/products/{id}/units/{unitId} [delete]
+func DeleteUnit(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		productId, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			logger.Error("Invalid product ID", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid product ID",
+			})
+		}
+
+		unitId, err := uuid.Parse(c.Params("unitId"))
+		if err != nil {
+			logger.Error("Invalid unit ID", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid unit ID",
+			})
+		}
+
+		request := command.DeleteUnitRequest{
+			ProductId:       productId,
+			UnitOfMeasureId: unitId,
+		}
+
+		response, err := mediatr.Send[command.DeleteUnitRequest, *command.DeleteUnitResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to delete unit", slog.String("error", err.Error()))
+
+			if errors.Is(err, repository.ErrUnitNotFound) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to delete unit",
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
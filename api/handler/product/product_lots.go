@@ -0,0 +1,68 @@
+package product_handler
+
+import (
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/service/product/query"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+	"gorm.io/gorm"
+)
+
+// ProductLots is a function to get the stock on hand per lot of a product
+//
+//	@Summary		Get Product Lots
+//	@Description	Get stock on hand per lot and warehouse of a product, earliest expiry first
+//	@Tags			Product
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string	true	"Product ID"
+//	@Param			warehouseId	query		string	false	"Filter by Warehouse ID"
+//	@Success		200			{object}	query.ProductLotsResult
+//	@Failure		400			{object}	api.ErrorResponse
+//	@Failure		404			{object}	api.ErrorResponse
+//	@Router			/products/{id}/lots [get]
+func ProductLots(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		productId, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			logger.Error("Invalid product ID", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid product ID",
+			})
+		}
+
+		request := query.ProductLotsRequest{
+			ProductId: productId,
+		}
+
+		if warehouseIdParam := c.Query("warehouseId"); warehouseIdParam != "" {
+			warehouseId, err := uuid.Parse(warehouseIdParam)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Invalid warehouse ID",
+				})
+			}
+			request.WarehouseId = &warehouseId
+		}
+
+		response, err := mediatr.Send[query.ProductLotsRequest, *query.ProductLotsResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to get product lots", slog.String("error", err.Error()))
+
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": "Product not found",
+				})
+			}
+
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get product lots",
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
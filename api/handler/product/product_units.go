@@ -0,0 +1,57 @@
+package product_handler
+
+import (
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/service/product/query"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+	"gorm.io/gorm"
+)
+
+// ProductUnits is a function to get the units of measure of a product
+//
+//	@Summary		Get Product Units
+//	@Description	Get the base unit of a product and its other units with their conversion factor
+//	@Tags			Product
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Product ID"
+//	@Success		200	{object}	query.ProductUnitsResult
+//	@Failure		400	{object}	api.ErrorResponse
+//	@Failure		404	{object}	api.ErrorResponse
+//	@Router			/products/{id}/units [get]
+func ProductUnits(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		productId, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			logger.Error("Invalid product ID", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid product ID",
+			})
+		}
+
+		request := query.ProductUnitsRequest{
+			ProductId: productId,
+		}
+
+		response, err := mediatr.Send[query.ProductUnitsRequest, *query.ProductUnitsResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to get product units", slog.String("error", err.Error()))
+
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": "Product not found",
+				})
+			}
+
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get product units",
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
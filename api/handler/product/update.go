@@ -1,6 +1,7 @@
 package product_handler
 
 import (
+	"errors"
 	"log/slog"
 	"mini-erp-backend/api/service/product/command"
 	"strings"
@@ -60,6 +61,18 @@ func Update(logger *slog.Logger) fiber.Handler {
 				})
 			}
 
+			if errors.Is(err, command.ErrSerialBackorder) || errors.Is(err, command.ErrInvalidCostingMethod) || errors.Is(err, command.ErrInvalidReplenishment) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			if errors.Is(err, command.ErrTrackSerialWithStock) {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
 			// ตรวจสอบว่าเป็น error ไม่พบข้อมูลหรือไม่
 			if strings.Contains(err.Error(), "not found") {
 				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
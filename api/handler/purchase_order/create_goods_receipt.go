@@ -0,0 +1,83 @@
+package purchase_order
+
+import (
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/service/purchase_order/command"
+	"mini-erp-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// CreateGoodsReceipt
+//
+//	@Summary		Receive goods for a purchase order
+//	@Description	Book a (partial) delivery against a CONFIRMED or PARTIALLY_RECEIVED purchase order.
+//	@Description	Each line creates a stock IN transaction into warehouse_id (default warehouse when omitted). The order becomes RECEIVED once every item is fully delivered.
+//	@Description	A line's quantity is in its unit (a unit of measure of the product, base unit when omitted) and is converted to base units; serial tracked items need one serial per base unit.
+//	@Tags			PurchaseOrder
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path	string								true	"Purchase Order ID (UUID)"
+//	@Param			receipt	body	command.CreateGoodsReceiptRequest	true	"Received quantities per purchase order item"
+//	@Success		201	{object}	command.CreateGoodsReceiptResult
+//	@Failure		400	{object}	api.ErrorResponse	"Bad Request: Invalid input, unknown unit or serial numbers do not match the quantity"
+//	@Failure		404	{object}	api.ErrorResponse
+//	@Failure		409	{object}	api.ErrorResponse	"Conflict: Order cannot be received or quantity exceeds outstanding"
+//	@Failure		500	{object}	api.ErrorResponse
+//	@Router			/purchase-orders/{id}/receipts [post]
+func CreateGoodsReceipt(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		poIdStr := c.Params("id")
+		poId, err := uuid.Parse(poIdStr)
+		if err != nil {
+			logger.Error("Invalid purchase order ID", "id", poIdStr, "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid purchase order ID",
+			})
+		}
+
+		var req command.CreateGoodsReceiptRequest
+		if err := c.BodyParser(&req); err != nil {
+			logger.Error("Failed to parse request body", "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+
+		if len(req.Lines) == 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Goods receipt must have at least one line",
+			})
+		}
+
+		for _, line := range req.Lines {
+			if line.Quantity == 0 {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Quantity must be greater than 0",
+				})
+			}
+		}
+
+		req.PurchaseOrderId = poId
+		req.ReceivedBy = utils.GetUserDataLocal(c).UserId
+
+		result, err := mediatr.Send[*command.CreateGoodsReceiptRequest, *command.CreateGoodsReceiptResult](c.Context(), &req)
+		if err != nil {
+			logger.Error("Failed to create goods receipt", "po_id", poId, "error", err)
+
+			status := statusFromError(err)
+			if errors.Is(err, command.ErrOverReceipt) {
+				status = fiber.StatusConflict
+			}
+
+			return c.Status(status).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(result)
+	}
+}
@@ -3,6 +3,7 @@ package purchase_order
 import (
 	"log/slog"
 	"mini-erp-backend/api/service/purchase_order/command"
+	"mini-erp-backend/utils"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/mehdihadeli/go-mediatr"
@@ -11,13 +12,14 @@ import (
 // CreatePurchaseOrder
 //
 //	@Summary		Create a new purchase order
-//	@Description	Create a new purchase order with items
+//	@Description	Create a new purchase order with items. created_by is deprecated and ignored, the creator is the authenticated user.
 //	@Tags			PurchaseOrder
 //	@Accept			json
 //	@Produce		json
 //	@Param			purchaseOrder	body	command.CreatePurchaseOrderRequest	true	"Purchase Order information"
 //	@Success		201	{object}	model.PurchaseOrder
-//	@Failure		400	{object}	api.ErrorResponse
+//	@Failure		400	{object}	api.ErrorResponse	"Invalid body, discount or tax rate"
+//	@Failure		404	{object}	api.ErrorResponse
 //	@Failure		500	{object}	api.ErrorResponse
 //	@Router			/purchase-orders [post]
 func CreatePurchaseOrder(logger *slog.Logger) fiber.Handler {
@@ -32,10 +34,15 @@ func CreatePurchaseOrder(logger *slog.Logger) fiber.Handler {
 			})
 		}
 
+		req.CreatedBy = utils.GetUserDataLocal(c).UserId
+		if req.LegacyCreatedBy != nil && *req.LegacyCreatedBy != req.CreatedBy {
+			logger.Warn("Ignoring deprecated created_by, using the authenticated user", "created_by", *req.LegacyCreatedBy, "user_id", req.CreatedBy)
+		}
+
 		result, err := mediatr.Send[*command.CreatePurchaseOrderRequest, interface{}](c.Context(), &req)
 		if err != nil {
 			logger.Error("Failed to create purchase order", "error", err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			return c.Status(statusFromError(err)).JSON(fiber.Map{
 				"error": err.Error(),
 			})
 		}
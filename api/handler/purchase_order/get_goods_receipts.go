@@ -0,0 +1,50 @@
+package purchase_order
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/purchase_order/query"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// GoodsReceipts
+//
+//	@Summary		Get goods receipts of a purchase order
+//	@Description	List every delivery booked against the purchase order
+//	@Tags			PurchaseOrder
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path	string	true	"Purchase Order ID (UUID)"
+//	@Success		200	{object}	query.GoodsReceiptsResult
+//	@Failure		400	{object}	api.ErrorResponse
+//	@Failure		404	{object}	api.ErrorResponse
+//	@Failure		500	{object}	api.ErrorResponse
+//	@Router			/purchase-orders/{id}/receipts [get]
+func GoodsReceipts(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		poIdStr := c.Params("id")
+		poId, err := uuid.Parse(poIdStr)
+		if err != nil {
+			logger.Error("Invalid purchase order ID", "id", poIdStr, "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid purchase order ID",
+			})
+		}
+
+		req := &query.GoodsReceiptsRequest{
+			PurchaseOrderId: poId,
+		}
+
+		result, err := mediatr.Send[*query.GoodsReceiptsRequest, *query.GoodsReceiptsResult](c.Context(), req)
+		if err != nil {
+			logger.Error("Failed to get goods receipts", "po_id", poId, "error", err)
+			return c.Status(statusFromError(err)).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(result)
+	}
+}
@@ -20,6 +20,8 @@ import (
 //	@Param			purchaseOrder	body	command.UpdatePurchaseOrderRequest	true	"Updated purchase order information"
 //	@Success		200	{object}	model.PurchaseOrder
 //	@Failure		400	{object}	api.ErrorResponse
+//	@Failure		404	{object}	api.ErrorResponse
+//	@Failure		409	{object}	api.ErrorResponse	"Conflict: Purchase order is not DRAFT"
 //	@Failure		500	{object}	api.ErrorResponse
 //	@Router			/purchase-orders/{id} [put]
 func UpdatePurchaseOrder(logger *slog.Logger) fiber.Handler {
@@ -47,7 +49,7 @@ func UpdatePurchaseOrder(logger *slog.Logger) fiber.Handler {
 		result, err := mediatr.Send[*command.UpdatePurchaseOrderRequest, interface{}](c.Context(), &req)
 		if err != nil {
 			logger.Error("Failed to update purchase order", "po_id", poId, "error", err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			return c.Status(statusFromError(err)).JSON(fiber.Map{
 				"error": err.Error(),
 			})
 		}
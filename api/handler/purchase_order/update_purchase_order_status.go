@@ -1,9 +1,13 @@
 package purchase_order
 
 import (
+	"errors"
 	"log/slog"
+	"mini-erp-backend/api/repository"
 	"mini-erp-backend/api/service/purchase_order/command"
 	"mini-erp-backend/model"
+	"mini-erp-backend/utils"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -13,14 +17,23 @@ import (
 // UpdatePurchaseOrderStatus
 //
 //	@Summary		Update purchase order status
-//	@Description	Update the status of a purchase order
+//	@Description	Update the status of a purchase order. Allowed: DRAFT -> CONFIRMED/CANCELLED, CONFIRMED -> RECEIVED/CANCELLED,
+//	@Description	PARTIALLY_RECEIVED -> RECEIVED. RECEIVED and CANCELLED are final.
+//	@Description	PARTIALLY_RECEIVED cannot be requested, it is set by goods receipts (POST /purchase-orders/{id}/receipts) while items are outstanding,
+//	@Description	and a partially received order can no longer be cancelled.
+//	@Description	Sending the current status again is a no-op returning 200, e.g. RECEIVED twice does not receive the goods twice.
+//	@Description	RECEIVED puts everything still outstanding (all items from CONFIRMED, the rest from PARTIALLY_RECEIVED) into warehouse_id,
+//	@Description	or into the default warehouse when it is omitted. Serial tracked items must be received through goods receipts.
+//	@Description	created_by is deprecated and ignored, the authenticated user is recorded.
 //	@Tags			PurchaseOrder
 //	@Accept			json
 //	@Produce		json
-//	@Param			id		status	path	string	true	"Purchase Order ID (UUID)"
+//	@Param			id		path	string	true	"Purchase Order ID (UUID)"
 //	@Param			status	body	object	true	"Status update"
 //	@Success		200	{object}	model.PurchaseOrder
-//	@Failure		400	{object}	api.ErrorResponse
+//	@Failure		400	{object}	api.ErrorResponse	"Bad Request: Invalid status, or serial numbers required for an outstanding item"
+//	@Failure		404	{object}	api.ErrorResponse	"Not Found: Purchase order or warehouse does not exist"
+//	@Failure		409	{object}	api.ErrorResponse	"Conflict: Transition not in the table above (requesting PARTIALLY_RECEIVED, cancelling a partially received order, leaving RECEIVED or CANCELLED)"
 //	@Failure		500	{object}	api.ErrorResponse
 //	@Router			/purchase-orders/{id}/status [put]
 func UpdatePurchaseOrderStatus(logger *slog.Logger) fiber.Handler {
@@ -35,7 +48,10 @@ func UpdatePurchaseOrderStatus(logger *slog.Logger) fiber.Handler {
 		}
 
 		var body struct {
-			Status model.PurchaseOrderStatus `json:"status"`
+			Status      model.PurchaseOrderStatus `json:"status"`
+			WarehouseId *uuid.UUID                `json:"warehouse_id"`
+			// CreatedBy is deprecated, still accepted from older clients but ignored
+			CreatedBy *uuid.UUID `json:"created_by"`
 		}
 		err = c.BodyParser(&body)
 		if err != nil {
@@ -45,15 +61,27 @@ func UpdatePurchaseOrderStatus(logger *slog.Logger) fiber.Handler {
 			})
 		}
 
+		if !body.Status.IsValid() {
+			logger.Error("Invalid purchase order status", "status", body.Status)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid purchase order status",
+			})
+		}
+
 		req := &command.UpdatePOStatusRequest{
 			PurchaseOrderId: poId,
 			Status:          body.Status,
+			WarehouseId:     body.WarehouseId,
+			CreatedBy:       utils.GetUserDataLocal(c).UserId,
+		}
+		if body.CreatedBy != nil && *body.CreatedBy != req.CreatedBy {
+			logger.Warn("Ignoring deprecated created_by, using the authenticated user", "created_by", *body.CreatedBy, "user_id", req.CreatedBy)
 		}
 
 		result, err := mediatr.Send[*command.UpdatePOStatusRequest, interface{}](c.Context(), req)
 		if err != nil {
 			logger.Error("Failed to update purchase order status", "po_id", poId, "error", err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			return c.Status(statusFromError(err)).JSON(fiber.Map{
 				"error": err.Error(),
 			})
 		}
@@ -61,3 +89,21 @@ func UpdatePurchaseOrderStatus(logger *slog.Logger) fiber.Handler {
 		return c.Status(fiber.StatusOK).JSON(result)
 	}
 }
+
+// statusFromError maps a purchase order command error to the HTTP status returned to the client
+func statusFromError(err error) int {
+	switch {
+	case errors.Is(err, command.ErrInvalidStatusTransition):
+		return fiber.StatusConflict
+	case errors.Is(err, command.ErrInvalidAmount), errors.Is(err, command.ErrInvalidOrderQuantity), errors.Is(err, repository.ErrUnitNotFound), errors.Is(err, repository.ErrLotNumberRequired),
+		errors.Is(err, repository.ErrSerialRequired), errors.Is(err, repository.ErrSerialNotTracked),
+		errors.Is(err, repository.ErrSerialDuplicate):
+		return fiber.StatusBadRequest
+	case errors.Is(err, repository.ErrLotExpiryMismatch), errors.Is(err, repository.ErrSerialInStock):
+		return fiber.StatusConflict
+	case strings.Contains(err.Error(), "not found"):
+		return fiber.StatusNotFound
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
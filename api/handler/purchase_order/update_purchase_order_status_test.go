@@ -0,0 +1,29 @@
+package purchase_order
+
+import (
+	"errors"
+	"fmt"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/api/service/purchase_order/command"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestStatusFromError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{fmt.Errorf("%w: RECEIVED -> CANCELLED", command.ErrInvalidStatusTransition), fiber.StatusConflict},
+		{fmt.Errorf("%w: CONFIRMED -> PARTIALLY_RECEIVED", command.ErrInvalidStatusTransition), fiber.StatusConflict},
+		{fmt.Errorf("%w: PCS", repository.ErrUnitNotFound), fiber.StatusBadRequest},
+		{errors.New("purchase order not found"), fiber.StatusNotFound},
+		{errors.New("connection reset"), fiber.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		if got := statusFromError(tt.err); got != tt.want {
+			t.Errorf("statusFromError(%q) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
@@ -1,8 +1,10 @@
 package register
 
 import (
+	"errors"
 	"log/slog"
 	"mini-erp-backend/api/service/register/command"
+	"mini-erp-backend/utils"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/mehdihadeli/go-mediatr"
@@ -26,16 +28,20 @@ func Register(logger *slog.Logger) fiber.Handler {
 		}
 
 		request := command.RegisterRequest{
-			Username:  req.Username,
-			FirstName: req.FirstName,
-			LastName:  req.LastName,
-			Password:  req.Password,
-			Role:      req.Role,
+			Username:         req.Username,
+			FirstName:        req.FirstName,
+			LastName:         req.LastName,
+			Password:         req.Password,
+			Role:             req.Role,
+			ActorPermissions: utils.GetUserDataLocal(c).Permissions,
 		}
 
 		response, err := mediatr.Send[*command.RegisterRequest, *command.RegisterResult](c.Context(), &request)
 		if err != nil {
 			logger.Error("login command failed", "error", err)
+			if errors.Is(err, command.ErrPrivilegeEscalation) {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+			}
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 		}
 
@@ -0,0 +1,48 @@
+package replenishment_handler
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/replenishment/command"
+	"mini-erp-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// Generate is a function to create draft purchase orders from the replenishment suggestions
+//
+//	@Summary		Generate Replenishment Purchase Orders
+//	@Description	Create one DRAFT purchase order per supplier from the current suggestions. Products without a preferred supplier are skipped.
+//	@Tags			Replenishment
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		command.GenerateRequest	false	"Generate Request"
+//	@Success		201		{object}	command.GenerateResult
+//	@Failure		400		{object}	api.ErrorResponse	"Bad Request: Invalid input"
+//	@Failure		500		{object}	api.ErrorResponse	"Internal Server Error"
+//	@Router			/replenishment/generate [post]
+func Generate(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		request := command.GenerateRequest{}
+
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&request); err != nil {
+				logger.Error("Failed to parse generate replenishment request", slog.String("error", err.Error()))
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Invalid request body",
+				})
+			}
+		}
+		request.CreatedBy = utils.GetUserDataLocal(c).UserId
+
+		response, err := mediatr.Send[command.GenerateRequest, *command.GenerateResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to generate replenishment purchase orders", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(response)
+	}
+}
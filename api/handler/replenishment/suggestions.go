@@ -0,0 +1,52 @@
+package replenishment_handler
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/replenishment/query"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+type SuggestionsQuery struct {
+	SupplierId *uuid.UUID `query:"supplierId"`
+}
+
+// Suggestions is a function to get the suggested purchase orders
+//
+//	@Summary		Get Replenishment Suggestions
+//	@Description	Get products whose stock on hand plus open orders is below the reorder point (min stock + usage during the lead time), grouped by preferred supplier
+//	@Tags			Replenishment
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	query.SuggestionsResult
+//	@Failure		500	{object}	api.ErrorResponse	"Internal Server Error"
+//	@Router			/replenishment/suggestions [get]
+//
+//	@param			supplierId	query	string	false	"Filter by preferred Supplier ID"
+func Suggestions(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var q SuggestionsQuery
+
+		if err := c.QueryParser(&q); err != nil {
+			logger.Error("Failed to parse query parameters", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid query parameters",
+			})
+		}
+
+		request := query.SuggestionsRequest{
+			SupplierId: q.SupplierId,
+		}
+
+		response, err := mediatr.Send[query.SuggestionsRequest, *query.SuggestionsResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to get replenishment suggestions", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get replenishment suggestions",
+			})
+		}
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
@@ -0,0 +1,55 @@
+package report
+
+import (
+	"log/slog"
+	reportCommand "mini-erp-backend/api/service/report/command"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// ExportExpiringLotsExcel
+//
+//	@Summary		Export expiring lots to Excel
+//	@Description	Export lots with stock that expire within the given number of days to Excel file
+//	@Tags			Report
+//	@Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+//	@Param			days		query	int		false	"Expiring within N days (default 30)"
+//	@Param			warehouseId	query	string	false	"Only lots in this warehouse"
+//	@Success		200	{file}	file
+//	@Failure		400	{object}	api.ErrorResponse
+//	@Failure		500	{object}	api.ErrorResponse
+//	@Router			/reports/expiring-lots/export [get]
+func ExportExpiringLotsExcel(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		days, err := daysFromQuery(c)
+		if err != nil {
+			logger.Error("Invalid days", "days", c.Query("days"), "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		warehouseId, err := warehouseIdFromQuery(c)
+		if err != nil {
+			logger.Error("Invalid warehouse ID", "warehouseId", c.Query("warehouseId"), "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid warehouse ID",
+			})
+		}
+
+		req := &reportCommand.ExportExpiringLotsExcelRequest{Days: days, WarehouseId: warehouseId}
+
+		result, err := mediatr.Send[*reportCommand.ExportExpiringLotsExcelRequest, *reportCommand.ExportExpiringLotsExcelResult](c.Context(), req)
+		if err != nil {
+			logger.Error("Failed to export expiring lots Excel", "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to export expiring lots",
+			})
+		}
+
+		c.Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		c.Set("Content-Disposition", "attachment; filename="+result.Filename)
+		return c.Send(result.Data)
+	}
+}
@@ -3,6 +3,7 @@ package report
 import (
 	"log/slog"
 	reportCommand "mini-erp-backend/api/service/report/command"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/mehdihadeli/go-mediatr"
@@ -11,15 +12,35 @@ import (
 // ExportStockSummaryCSV
 //
 //	@Summary		Export stock summary to CSV
-//	@Description	Export current stock summary to CSV file
+//	@Description	Export stock summary to CSV file, now or at the end of a past date
 //	@Tags			Report
 //	@Produce		text/csv
+//	@Param			warehouseId	query	string	false	"Only count stock in this warehouse"
+//	@Param			as_of		query	string	false	"Stock at the end of this date (DD-MM-YYYY, default now)"
+//	@Param			unit		query	string	false	"Also show stock in this unit of measure for products that have it, e.g. box"
 //	@Success		200	{file}	file
+//	@Failure		400	{object}	api.ErrorResponse
 //	@Failure		500	{object}	api.ErrorResponse
 //	@Router			/reports/stock-summary/export [get]
-func ExportStockSummaryCSV(logger *slog.Logger) fiber.Handler {
+func ExportStockSummaryCSV(logger *slog.Logger, location *time.Location) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		req := &reportCommand.ExportStockSummaryCSVRequest{}
+		warehouseId, err := warehouseIdFromQuery(c)
+		if err != nil {
+			logger.Error("Invalid warehouse ID", "warehouseId", c.Query("warehouseId"), "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid warehouse ID",
+			})
+		}
+
+		asOf, err := asOfFromQuery(c, location)
+		if err != nil {
+			logger.Error("Invalid as_of date", "as_of", c.Query("as_of"), "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid as_of date format (expected: DD-MM-YYYY)",
+			})
+		}
+
+		req := &reportCommand.ExportStockSummaryCSVRequest{WarehouseId: warehouseId, AsOf: asOf, Unit: unitFromQuery(c)}
 
 		result, err := mediatr.Send[*reportCommand.ExportStockSummaryCSVRequest, *reportCommand.ExportStockSummaryCSVResult](c.Context(), req)
 		if err != nil {
@@ -0,0 +1,73 @@
+package report
+
+import (
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/service/report/query"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// defaultExpiringDays is used when the days query parameter is not given
+const defaultExpiringDays = 30
+
+// ExpiringLots
+//
+//	@Summary		Get expiring lots
+//	@Description	Get lots with stock that expire within the given number of days, including lots already expired
+//	@Tags			Report
+//	@Accept			json
+//	@Produce		json
+//	@Param			days		query	int		false	"Expiring within N days (default 30)"
+//	@Param			warehouseId	query	string	false	"Only lots in this warehouse"
+//	@Success		200	{object}	query.ExpiringLotsResult
+//	@Failure		400	{object}	api.ErrorResponse
+//	@Failure		500	{object}	api.ErrorResponse
+//	@Router			/reports/expiring-lots [get]
+func ExpiringLots(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		days, err := daysFromQuery(c)
+		if err != nil {
+			logger.Error("Invalid days", "days", c.Query("days"), "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		warehouseId, err := warehouseIdFromQuery(c)
+		if err != nil {
+			logger.Error("Invalid warehouse ID", "warehouseId", c.Query("warehouseId"), "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid warehouse ID",
+			})
+		}
+
+		req := &query.ExpiringLotsRequest{Days: days, WarehouseId: warehouseId}
+
+		result, err := mediatr.Send[*query.ExpiringLotsRequest, *query.ExpiringLotsResult](c.Context(), req)
+		if err != nil {
+			logger.Error("Failed to get expiring lots", "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to retrieve expiring lots",
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(result)
+	}
+}
+
+// daysFromQuery reads the optional days query parameter, defaulting to defaultExpiringDays
+func daysFromQuery(c *fiber.Ctx) (int, error) {
+	raw := c.Query("days")
+	if raw == "" {
+		return defaultExpiringDays, nil
+	}
+
+	days, err := strconv.Atoi(raw)
+	if err != nil || days < 0 {
+		return 0, errors.New("days must be a non-negative number")
+	}
+	return days, nil
+}
@@ -0,0 +1,60 @@
+package report
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/report/query"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// InventoryValuation
+//
+//	@Summary		Get inventory valuation
+//	@Description	Get quantity and value of stock per product at the end of a date, valued by cost layers (FIFO or moving-average). Quantity and value include stock in transit between warehouses, counted at the destination warehouse
+//	@Tags			Report
+//	@Accept			json
+//	@Produce		json
+//	@Param			as_of		query	string	false	"Value at the end of this date (DD-MM-YYYY, default now)"
+//	@Param			asOf		query	string	false	"Deprecated, same as as_of"
+//	@Param			warehouseId	query	string	false	"Only stock in this warehouse"
+//	@Success		200	{object}	query.InventoryValuationResult
+//	@Failure		400	{object}	api.ErrorResponse
+//	@Failure		500	{object}	api.ErrorResponse
+//	@Router			/reports/inventory-valuation [get]
+func InventoryValuation(logger *slog.Logger, location *time.Location) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		asOf, err := asOfFromQuery(c, location)
+		if err != nil {
+			logger.Error("Invalid as_of date", "as_of", c.Query("as_of"), "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid as_of date format (expected: DD-MM-YYYY)",
+			})
+		}
+		if asOf == nil {
+			now := time.Now()
+			asOf = &now
+		}
+
+		warehouseId, err := warehouseIdFromQuery(c)
+		if err != nil {
+			logger.Error("Invalid warehouse ID", "warehouseId", c.Query("warehouseId"), "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid warehouse ID",
+			})
+		}
+
+		req := &query.InventoryValuationRequest{AsOf: *asOf, WarehouseId: warehouseId}
+
+		result, err := mediatr.Send[*query.InventoryValuationRequest, *query.InventoryValuationResult](c.Context(), req)
+		if err != nil {
+			logger.Error("Failed to get inventory valuation", "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to retrieve inventory valuation",
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(result)
+	}
+}
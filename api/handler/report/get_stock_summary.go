@@ -3,24 +3,46 @@ package report
 import (
 	"log/slog"
 	"mini-erp-backend/api/service/report/query"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	"github.com/mehdihadeli/go-mediatr"
 )
 
 // StockSummary
 //
 //	@Summary		Get stock summary
-//	@Description	Get current stock summary including aggregates and low stock products
+//	@Description	Get stock summary including aggregates, per-warehouse totals and low stock products, now or at the end of a past date. Stock shipped by a transfer that has not arrived is reported as in_transit at its destination warehouse, beside stock on hand
 //	@Tags			Report
 //	@Accept			json
 //	@Produce		json
+//	@Param			warehouseId	query	string	false	"Only count stock in this warehouse"
+//	@Param			as_of		query	string	false	"Stock at the end of this date (DD-MM-YYYY, default now)"
+//	@Param			unit		query	string	false	"Also show stock in this unit of measure for products that have it, e.g. box"
 //	@Success		200	{object}	query.StockSummaryResult
+//	@Failure		400	{object}	api.ErrorResponse
 //	@Failure		500	{object}	api.ErrorResponse
 //	@Router			/reports/stock-summary [get]
-func StockSummary(logger *slog.Logger) fiber.Handler {
+func StockSummary(logger *slog.Logger, location *time.Location) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		req := &query.StockSummaryRequest{}
+		warehouseId, err := warehouseIdFromQuery(c)
+		if err != nil {
+			logger.Error("Invalid warehouse ID", "warehouseId", c.Query("warehouseId"), "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid warehouse ID",
+			})
+		}
+
+		asOf, err := asOfFromQuery(c, location)
+		if err != nil {
+			logger.Error("Invalid as_of date", "as_of", c.Query("as_of"), "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid as_of date format (expected: DD-MM-YYYY)",
+			})
+		}
+
+		req := &query.StockSummaryRequest{WarehouseId: warehouseId, AsOf: asOf, Unit: unitFromQuery(c)}
 
 		result, err := mediatr.Send[*query.StockSummaryRequest, *query.StockSummaryResult](c.Context(), req)
 		if err != nil {
@@ -33,3 +55,45 @@ func StockSummary(logger *slog.Logger) fiber.Handler {
 		return c.Status(fiber.StatusOK).JSON(result)
 	}
 }
+
+// warehouseIdFromQuery reads the optional warehouseId query parameter
+func warehouseIdFromQuery(c *fiber.Ctx) (*uuid.UUID, error) {
+	raw := c.Query("warehouseId")
+	if raw == "" {
+		return nil, nil
+	}
+
+	warehouseId, err := uuid.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &warehouseId, nil
+}
+
+// asOfFromQuery reads the optional as_of query parameter (DD-MM-YYYY) as the last second of that day in the business timezone.
+// asOf is the name inventory valuation used first and is still accepted
+func asOfFromQuery(c *fiber.Ctx, location *time.Location) (*time.Time, error) {
+	raw := c.Query("as_of")
+	if raw == "" {
+		raw = c.Query("asOf")
+	}
+	if raw == "" {
+		return nil, nil
+	}
+
+	date, err := time.ParseInLocation("02-01-2006", raw, location)
+	if err != nil {
+		return nil, err
+	}
+	asOf := date.AddDate(0, 0, 1).Add(-time.Second)
+	return &asOf, nil
+}
+
+// unitFromQuery reads the optional unit query parameter, the unit of measure quantities are displayed in
+func unitFromQuery(c *fiber.Ctx) *string {
+	raw := c.Query("unit")
+	if raw == "" {
+		return nil
+	}
+	return &raw
+}
@@ -0,0 +1,65 @@
+package role_handler
+
+import (
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/service/role/command"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// CreateRole is a function to create a role
+//
+//	@Summary		Create Role
+//	@Description	Create a role with a set of permissions (requires role.manage)
+//	@Tags			Role
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		command.CreateRoleRequest	true	"Create Role Request"
+//	@Success		201		{object}	command.CreateRoleResult
+//	@Failure		400		{object}	api.ErrorResponse	"Bad Request: Invalid name or unknown permission"
+//	@Failure		409		{object}	api.ErrorResponse	"Conflict: Role already exists"
+//	@Failure		500		{object}	api.ErrorResponse	"Internal Server Error"
+//	@Router			/roles [post]
+func CreateRole(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		request := command.CreateRoleRequest{}
+
+		if err := c.BodyParser(&request); err != nil {
+			logger.Error("Failed to parse create role request", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+
+		response, err := mediatr.Send[command.CreateRoleRequest, *command.CreateRoleResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to create role", slog.String("error", err.Error()))
+			return c.Status(roleStatus(err)).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(response)
+	}
+}
+
+// roleStatus maps the errors of the role commands to HTTP status codes
+func roleStatus(err error) int {
+	switch {
+	case errors.Is(err, command.ErrInvalidRoleName),
+		errors.Is(err, command.ErrUnknownPermission),
+		errors.Is(err, command.ErrRoleLockout):
+		return fiber.StatusBadRequest
+	case errors.Is(err, command.ErrRoleExists),
+		errors.Is(err, command.ErrSystemRole),
+		errors.Is(err, command.ErrRoleInUse):
+		return fiber.StatusConflict
+	case strings.Contains(err.Error(), "not found"):
+		return fiber.StatusNotFound
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
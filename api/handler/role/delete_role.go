@@ -0,0 +1,40 @@
+package role_handler
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/role/command"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// DeleteRole is a function to delete a role that no user is assigned to
+//
+//	@Summary		Delete Role
+//	@Description	Delete a custom role, built-in roles and roles still assigned to users cannot be deleted (requires role.manage)
+//	@Tags			Role
+//	@Accept			json
+//	@Produce		json
+//	@param			name	path		string	true	"Role name"
+//	@Success		200		{object}	command.DeleteRoleResult
+//	@Failure		404		{object}	api.ErrorResponse	"Not Found: Role does not exist"
+//	@Failure		409		{object}	api.ErrorResponse	"Conflict: Built-in role or role in use"
+//	@Failure		500		{object}	api.ErrorResponse	"Internal Server Error"
+//	@Router			/roles/{name} [delete]
+func DeleteRole(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		request := command.DeleteRoleRequest{
+			Name: c.Params("name"),
+		}
+
+		response, err := mediatr.Send[command.DeleteRoleRequest, *command.DeleteRoleResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to delete role", slog.String("error", err.Error()))
+			return c.Status(roleStatus(err)).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
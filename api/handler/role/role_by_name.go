@@ -0,0 +1,45 @@
+package role_handler
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/role/query"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// RoleByName is a function to get a role by name
+//
+//	@Summary		Get Role by name
+//	@Description	Get a role with the permissions it grants (requires role.manage)
+//	@Tags			Role
+//	@Accept			json
+//	@Produce		json
+//	@param			name	path		string	true	"Role name"
+//	@Success		200		{object}	query.RoleByNameResult
+//	@Failure		404		{object}	api.ErrorResponse	"Not Found: Role does not exist"
+//	@Router			/roles/{name} [get]
+func RoleByName(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		request := query.RoleByNameRequest{
+			Name: c.Params("name"),
+		}
+
+		response, err := mediatr.Send[query.RoleByNameRequest, *query.RoleByNameResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to get role by name", slog.String("error", err.Error()))
+
+			if strings.Contains(err.Error(), "not found") {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": "Role not found",
+				})
+			}
+
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get role by name",
+			})
+		}
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
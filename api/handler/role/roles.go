@@ -0,0 +1,56 @@
+package role_handler
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/role/query"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// Roles is a function to get every role with its permissions
+//
+//	@Summary		Get all Roles
+//	@Description	Get every role with the permissions it grants (requires role.manage)
+//	@Tags			Role
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	query.RolesResult
+//	@Failure		500	{object}	api.ErrorResponse	"Internal Server Error"
+//	@Router			/roles [get]
+func Roles(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		response, err := mediatr.Send[query.RolesRequest, *query.RolesResult](c.Context(), query.RolesRequest{})
+		if err != nil {
+			logger.Error("Failed to get roles", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get roles",
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
+
+// Permissions is a function to get every permission a role can be granted
+//
+//	@Summary		Get all Permissions
+//	@Description	Get every permission name a role can be granted (requires role.manage)
+//	@Tags			Role
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	query.PermissionsResult
+//	@Router			/roles/permissions [get]
+func Permissions(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		response, err := mediatr.Send[query.PermissionsRequest, *query.PermissionsResult](c.Context(), query.PermissionsRequest{})
+		if err != nil {
+			logger.Error("Failed to get permissions", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get permissions",
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
@@ -0,0 +1,48 @@
+package role_handler
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/role/command"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// UpdateRole is a function to replace the description and permissions of a role
+//
+//	@Summary		Update Role
+//	@Description	Replace the permissions of a role, removing a permission revokes the active sessions of its users (requires role.manage)
+//	@Tags			Role
+//	@Accept			json
+//	@Produce		json
+//	@param			name	path		string						true	"Role name"
+//	@Param			request	body		command.UpdateRoleRequest	true	"Update Role Request"
+//	@Success		200		{object}	command.UpdateRoleResult
+//	@Failure		400		{object}	api.ErrorResponse	"Bad Request: Unknown permission"
+//	@Failure		404		{object}	api.ErrorResponse	"Not Found: Role does not exist"
+//	@Failure		500		{object}	api.ErrorResponse	"Internal Server Error"
+//	@Router			/roles/{name} [put]
+func UpdateRole(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		request := command.UpdateRoleRequest{}
+
+		if err := c.BodyParser(&request); err != nil {
+			logger.Error("Failed to parse update role request", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+
+		request.Name = c.Params("name")
+
+		response, err := mediatr.Send[command.UpdateRoleRequest, *command.UpdateRoleResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to update role", slog.String("error", err.Error()))
+			return c.Status(roleStatus(err)).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
@@ -0,0 +1,55 @@
+package sales_order
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/sales_order/command"
+	"mini-erp-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// CreateSalesOrder
+//
+//	@Summary		Create a new sales order
+//	@Description	Create a new DRAFT sales order, item prices are taken from the product selling price
+//	@Tags			SalesOrder
+//	@Accept			json
+//	@Produce		json
+//	@Param			salesOrder	body	command.CreateSalesOrderRequest	true	"Sales Order information"
+//	@Success		201	{object}	model.SalesOrder
+//	@Failure		400	{object}	api.ErrorResponse
+//	@Failure		404	{object}	api.ErrorResponse
+//	@Failure		500	{object}	api.ErrorResponse
+//	@Router			/sales-orders [post]
+func CreateSalesOrder(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req command.CreateSalesOrderRequest
+
+		err := c.BodyParser(&req)
+		if err != nil {
+			logger.Error("Failed to parse request body", "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+
+		if msg := validateItems(req.Items); msg != "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": msg,
+			})
+		}
+
+		req.CreatedBy = utils.GetUserDataLocal(c).UserId
+
+		result, err := mediatr.Send[*command.CreateSalesOrderRequest, interface{}](c.Context(), &req)
+		if err != nil {
+			logger.Error("Failed to create sales order", "error", err)
+			return c.Status(statusFromError(err)).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(result)
+	}
+}
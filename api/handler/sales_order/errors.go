@@ -0,0 +1,41 @@
+package sales_order
+
+import (
+	"errors"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/api/service/sales_order/command"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// statusFromError maps a sales order command error to the HTTP status returned to the client
+func statusFromError(err error) int {
+	switch {
+	case errors.Is(err, command.ErrInvalidStatusTransition), errors.Is(err, repository.ErrInsufficientStock),
+		errors.Is(err, repository.ErrLotExpired):
+		return fiber.StatusConflict
+	case errors.Is(err, repository.ErrSerialRequired), errors.Is(err, repository.ErrSerialNotTracked),
+		errors.Is(err, repository.ErrSerialDuplicate), errors.Is(err, repository.ErrSerialNotAvailable),
+		errors.Is(err, repository.ErrSerialLotMismatch):
+		return fiber.StatusBadRequest
+	case strings.Contains(err.Error(), "not found"):
+		return fiber.StatusNotFound
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+func validateItems(items []command.CreateSalesOrderItem) string {
+	if len(items) == 0 {
+		return "Sales order must have at least one item"
+	}
+
+	for _, it := range items {
+		if it.Quantity == 0 {
+			return "Quantity must be greater than 0"
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,55 @@
+package sales_order
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/sales_order/query"
+	"mini-erp-backend/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// AllSalesOrders
+//
+//	@Summary		Get all sales orders
+//	@Description	Retrieve a list of sales orders
+//	@Tags			SalesOrder
+//	@Accept			json
+//	@Produce		json
+//	@Param			status		query	string	false	"Filter by status"
+//	@Param			customerId	query	string	false	"Filter by customer ID"
+//	@Success		200	{object}	query.AllSalesOrdersResult
+//	@Failure		400	{object}	api.ErrorResponse
+//	@Failure		500	{object}	api.ErrorResponse
+//	@Router			/sales-orders [get]
+func AllSalesOrders(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req query.AllSalesOrdersRequest
+
+		if statusStr := c.Query("status"); statusStr != "" {
+			status := model.SalesOrderStatus(statusStr)
+			req.Status = &status
+		}
+
+		if customerIdStr := c.Query("customerId"); customerIdStr != "" {
+			customerId, err := uuid.Parse(customerIdStr)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Invalid customer ID",
+				})
+			}
+			req.CustomerId = &customerId
+		}
+
+		result, err := mediatr.Send[*query.AllSalesOrdersRequest, *query.AllSalesOrdersResult](c.Context(), &req)
+		if err != nil {
+			logger.Error("Failed to get all sales orders", "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to retrieve sales orders",
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(result)
+	}
+}
@@ -0,0 +1,49 @@
+package sales_order
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/sales_order/query"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// SalesOrder
+//
+//	@Summary		Get a sales order by ID
+//	@Description	Get sales order details by ID
+//	@Tags			SalesOrder
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path	string	true	"Sales Order ID (UUID)"
+//	@Success		200	{object}	query.SalesOrderResult
+//	@Failure		400	{object}	api.ErrorResponse
+//	@Failure		404	{object}	api.ErrorResponse
+//	@Router			/sales-orders/{id} [get]
+func SalesOrder(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		soIdStr := c.Params("id")
+		soId, err := uuid.Parse(soIdStr)
+		if err != nil {
+			logger.Error("Invalid sales order ID", "id", soIdStr, "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid sales order ID",
+			})
+		}
+
+		req := &query.SalesOrderRequest{
+			SalesOrderId: soId,
+		}
+
+		result, err := mediatr.Send[*query.SalesOrderRequest, *query.SalesOrderResult](c.Context(), req)
+		if err != nil {
+			logger.Error("Failed to get sales order", "so_id", soId, "error", err)
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Sales order not found",
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(result)
+	}
+}
@@ -0,0 +1,65 @@
+package sales_order
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/sales_order/command"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// UpdateSalesOrder
+//
+//	@Summary		Update a sales order
+//	@Description	Replace the customer and items of a DRAFT sales order
+//	@Tags			SalesOrder
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path	string	true	"Sales Order ID (UUID)"
+//	@Param			salesOrder	body	command.UpdateSalesOrderRequest	true	"Updated sales order information"
+//	@Success		200	{object}	model.SalesOrder
+//	@Failure		400	{object}	api.ErrorResponse
+//	@Failure		404	{object}	api.ErrorResponse
+//	@Failure		409	{object}	api.ErrorResponse	"Conflict: Sales order is not DRAFT"
+//	@Failure		500	{object}	api.ErrorResponse
+//	@Router			/sales-orders/{id} [put]
+func UpdateSalesOrder(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		soIdStr := c.Params("id")
+		soId, err := uuid.Parse(soIdStr)
+		if err != nil {
+			logger.Error("Invalid sales order ID", "id", soIdStr, "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid sales order ID",
+			})
+		}
+
+		var req command.UpdateSalesOrderRequest
+		err = c.BodyParser(&req)
+		if err != nil {
+			logger.Error("Failed to parse request body", "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+
+		if msg := validateItems(req.Items); msg != "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": msg,
+			})
+		}
+
+		req.SalesOrderId = soId
+
+		result, err := mediatr.Send[*command.UpdateSalesOrderRequest, interface{}](c.Context(), &req)
+		if err != nil {
+			logger.Error("Failed to update sales order", "so_id", soId, "error", err)
+			return c.Status(statusFromError(err)).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(result)
+	}
+}
@@ -0,0 +1,72 @@
+package sales_order
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/sales_order/command"
+	"mini-erp-backend/model"
+	"mini-erp-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// UpdateSalesOrderStatus
+//
+//	@Summary		Update sales order status
+//	@Description	Move a sales order through DRAFT -> CONFIRMED -> SHIPPED, or to CANCELLED. Shipping creates stock OUT transactions
+//	@Description	from warehouse_id, or from the default warehouse when it is omitted.
+//	@Tags			SalesOrder
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path	string	true	"Sales Order ID (UUID)"
+//	@Param			status	body	object	true	"Status update"
+//	@Success		200	{object}	map[string]interface{}
+//	@Failure		400	{object}	api.ErrorResponse
+//	@Failure		404	{object}	api.ErrorResponse
+//	@Failure		409	{object}	api.ErrorResponse	"Conflict: Invalid status transition or insufficient stock"
+//	@Failure		500	{object}	api.ErrorResponse
+//	@Router			/sales-orders/{id}/status [put]
+func UpdateSalesOrderStatus(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		soIdStr := c.Params("id")
+		soId, err := uuid.Parse(soIdStr)
+		if err != nil {
+			logger.Error("Invalid sales order ID", "id", soIdStr, "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid sales order ID",
+			})
+		}
+
+		var body struct {
+			Status      model.SalesOrderStatus `json:"status"`
+			WarehouseId *uuid.UUID             `json:"warehouse_id"`
+			Serials     map[uuid.UUID][]string `json:"serials"`
+		}
+		err = c.BodyParser(&body)
+		if err != nil {
+			logger.Error("Failed to parse request body", "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+
+		req := &command.UpdateSOStatusRequest{
+			SalesOrderId: soId,
+			Status:       body.Status,
+			WarehouseId:  body.WarehouseId,
+			Serials:      body.Serials,
+			CreatedBy:    utils.GetUserDataLocal(c).UserId,
+		}
+
+		result, err := mediatr.Send[*command.UpdateSOStatusRequest, interface{}](c.Context(), req)
+		if err != nil {
+			logger.Error("Failed to update sales order status", "so_id", soId, "error", err)
+			return c.Status(statusFromError(err)).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(result)
+	}
+}
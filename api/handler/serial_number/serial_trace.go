@@ -0,0 +1,52 @@
+package serial_handler
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/serial_number/query"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// SerialTrace is a function to trace a serial number through its movements
+//
+//	@Summary		Trace Serial Number
+//	@Description	Get the status and movement history of a serial number, the purchase order it was received on and its outbound movement
+//	@Tags			SerialNumber
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	query.SerialTraceResult
+//	@Failure		404	{object}	api.ErrorResponse	"Not Found: Serial number does not exist"
+//	@Router			/serials/{serial} [get]
+//
+//	@param			serial	path	string	true	"Serial number"
+func SerialTrace(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		request := query.SerialTraceRequest{
+			Serial: c.Params("serial"),
+		}
+
+		if strings.TrimSpace(request.Serial) == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Serial number is required",
+			})
+		}
+
+		response, err := mediatr.Send[query.SerialTraceRequest, *query.SerialTraceResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to trace serial number", slog.String("error", err.Error()))
+
+			if strings.Contains(err.Error(), "not found") {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to trace serial number",
+			})
+		}
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
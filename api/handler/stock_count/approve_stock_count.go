@@ -0,0 +1,73 @@
+package stockcount_handler
+
+import (
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/api/service/stock_count/command"
+	"mini-erp-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// ApproveStockCount is a function to approve a stock count and post its variances
+//
+//	@Summary		Approve Stock Count
+//	@Description	Approve an open stock count: post ADJUSTs for every counted product with a difference, all in one transaction referencing the count. A shortage is taken from lots first expiry first (one ADJUST per lot); serial tracked products scrap the units in stock that were not counted and receive the counted units that were not in stock
+//	@Tags			StockCount
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Stock Count ID"
+//	@Success		200	{object}	command.ApproveStockCountResult
+//	@Failure		400	{object}	api.ErrorResponse	"Bad Request: Counted serials missing or held elsewhere, submit the count again"
+//	@Failure		404	{object}	api.ErrorResponse	"Not Found: Stock count does not exist"
+//	@Failure		409	{object}	api.ErrorResponse	"Conflict: Stock count is not open or insufficient stock"
+//	@Failure		500	{object}	api.ErrorResponse	"Internal Server Error"
+//	@Router			/stock-counts/{id}/approve [post]
+func ApproveStockCount(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		stockCountId, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			logger.Error("Invalid stock count ID", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid stock count ID",
+			})
+		}
+
+		request := command.ApproveStockCountRequest{
+			StockCountId: stockCountId,
+			ApprovedBy:   utils.GetUserDataLocal(c).UserId,
+		}
+
+		response, err := mediatr.Send[command.ApproveStockCountRequest, *command.ApproveStockCountResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to approve stock count", slog.String("error", err.Error()))
+
+			if errors.Is(err, repository.ErrStockCountNotFound) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			if isSerialError(err) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			if errors.Is(err, repository.ErrStockCountNotOpen) || errors.Is(err, repository.ErrInsufficientStock) {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to approve stock count",
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
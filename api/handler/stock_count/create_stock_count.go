@@ -0,0 +1,63 @@
+package stockcount_handler
+
+import (
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/api/service/stock_count/command"
+	"mini-erp-backend/utils"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// CreateStockCount is a function to start a physical stock count
+//
+//	@Summary		Create Stock Count
+//	@Description	Start a stock count of a warehouse, optionally for one category. Expected quantities are frozen from the current balances.
+//	@Tags			StockCount
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		command.CreateStockCountRequest	true	"Create Stock Count Request"
+//	@Success		201		{object}	command.CreateStockCountResult
+//	@Failure		400		{object}	api.ErrorResponse	"Bad Request: No products to count"
+//	@Failure		404		{object}	api.ErrorResponse	"Not Found: Warehouse does not exist"
+//	@Failure		500		{object}	api.ErrorResponse	"Internal Server Error"
+//	@Router			/stock-counts [post]
+func CreateStockCount(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		request := command.CreateStockCountRequest{}
+
+		if err := c.BodyParser(&request); err != nil {
+			logger.Error("Failed to parse create stock count request", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+		request.CreatedBy = utils.GetUserDataLocal(c).UserId
+
+		response, err := mediatr.Send[command.CreateStockCountRequest, *command.CreateStockCountResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to create stock count", slog.String("error", err.Error()))
+
+			if errors.Is(err, repository.ErrStockCountNoProducts) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			if strings.Contains(err.Error(), "not found") {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to create stock count",
+			})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(response)
+	}
+}
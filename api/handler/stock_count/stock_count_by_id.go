@@ -0,0 +1,55 @@
+package stockcount_handler
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/stock_count/query"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// StockCountById is a function to get a stock count with its lines and variances
+//
+//	@Summary		Get Stock Count by ID
+//	@Description	Get a stock count with expected and counted quantity per product and the variances found so far
+//	@Tags			StockCount
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	query.StockCountByIdResult
+//	@Failure		404	{object}	api.ErrorResponse	"Not Found: Stock count does not exist"
+//	@Router			/stock-counts/{id} [get]
+//
+//	@param			id	path	string	true	"Stock Count ID"
+func StockCountById(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		stockCountId, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			logger.Error("Invalid stock count ID", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid stock count ID",
+			})
+		}
+
+		request := query.StockCountByIdRequest{
+			StockCountId: stockCountId,
+		}
+
+		response, err := mediatr.Send[query.StockCountByIdRequest, *query.StockCountByIdResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to get stock count by id", slog.String("error", err.Error()))
+
+			if strings.Contains(err.Error(), "not found") {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": "Stock count not found",
+				})
+			}
+
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get stock count by id",
+			})
+		}
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
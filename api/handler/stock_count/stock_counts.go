@@ -0,0 +1,70 @@
+package stockcount_handler
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/stock_count/query"
+	"mini-erp-backend/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+type StockCountQuery struct {
+	Page        int        `query:"page"`
+	PageSize    int        `query:"pageSize"`
+	Status      string     `query:"status"`
+	WarehouseId *uuid.UUID `query:"warehouseId"`
+}
+
+// StockCounts is a function to get stock counts
+//
+//	@Summary		Get Stock Count list
+//	@Description	Get stock counts, latest first
+//	@Tags			StockCount
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	query.StockCountsResult
+//	@Router			/stock-counts [get]
+//
+//	@param			page		query	int		false	"Page number"
+//	@param			pageSize	query	int		false	"Number of items per page"
+//	@param			status		query	string	false	"Filter by status (OPEN or APPROVED)"
+//	@param			warehouseId	query	string	false	"Filter by Warehouse ID"
+func StockCounts(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var q StockCountQuery
+
+		if err := c.QueryParser(&q); err != nil {
+			logger.Error("Failed to parse query parameters", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid query parameters",
+			})
+		}
+
+		request := query.StockCountsRequest{
+			Page:        q.Page,
+			PageSize:    q.PageSize,
+			WarehouseId: q.WarehouseId,
+		}
+
+		if q.Status != "" {
+			status := model.StockCountStatus(q.Status)
+			if status != model.StockCountOpen && status != model.StockCountApproved {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Invalid status",
+				})
+			}
+			request.Status = &status
+		}
+
+		response, err := mediatr.Send[query.StockCountsRequest, *query.StockCountsResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to get stock counts", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get stock counts",
+			})
+		}
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
@@ -0,0 +1,80 @@
+package stockcount_handler
+
+import (
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/api/service/stock_count/command"
+	"mini-erp-backend/utils"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// SubmitStockCount is a function to submit counted quantities
+//
+//	@Summary		Submit Counted Quantities
+//	@Description	Submit counted quantities for products of an open stock count. Can be called many times, the latest quantity of a product wins. Serial tracked products need the serials found, one per counted unit.
+//	@Tags			StockCount
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string								true	"Stock Count ID"
+//	@Param			request	body		command.SubmitStockCountRequest	true	"Submit Stock Count Request"
+//	@Success		200		{object}	command.SubmitStockCountResult
+//	@Failure		400		{object}	api.ErrorResponse	"Bad Request: Invalid input, product not in the count or serials do not match the counted quantity"
+//	@Failure		404		{object}	api.ErrorResponse	"Not Found: Stock count does not exist"
+//	@Failure		409		{object}	api.ErrorResponse	"Conflict: Stock count is not open"
+//	@Failure		500		{object}	api.ErrorResponse	"Internal Server Error"
+//	@Router			/stock-counts/{id}/counts [post]
+func SubmitStockCount(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		stockCountId, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			logger.Error("Invalid stock count ID", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid stock count ID",
+			})
+		}
+
+		request := command.SubmitStockCountRequest{}
+		if err := c.BodyParser(&request); err != nil {
+			logger.Error("Failed to parse submit stock count request", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+		request.StockCountId = stockCountId
+		request.CountedBy = utils.GetUserDataLocal(c).UserId
+
+		response, err := mediatr.Send[command.SubmitStockCountRequest, *command.SubmitStockCountResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to submit stock count", slog.String("error", err.Error()))
+
+			if errors.Is(err, repository.ErrStockCountNotFound) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			if errors.Is(err, repository.ErrStockCountNotOpen) {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			if errors.Is(err, command.ErrProductNotInCount) || isSerialError(err) || strings.Contains(err.Error(), "counted") {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to submit stock count",
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
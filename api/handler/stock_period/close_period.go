@@ -0,0 +1,63 @@
+package stockperiod_handler
+
+import (
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/api/service/stock_period/command"
+	"mini-erp-backend/utils"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// ClosePeriod is a function to close a month of stock
+//
+//	@Summary		Close Stock Period
+//	@Description	Close a past month: write an immutable snapshot of quantity and value per product and warehouse, and reject stock transactions dated on or before its end from then on
+//	@Tags			StockPeriod
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		command.ClosePeriodRequest	true	"Close Period Request"
+//	@Success		201		{object}	command.ClosePeriodResult
+//	@Failure		400		{object}	api.ErrorResponse	"Bad Request: Invalid month or month has not ended"
+//	@Failure		409		{object}	api.ErrorResponse	"Conflict: Period already closed"
+//	@Failure		500		{object}	api.ErrorResponse	"Internal Server Error"
+//	@Router			/stock-periods/close [post]
+func ClosePeriod(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		request := command.ClosePeriodRequest{}
+
+		if err := c.BodyParser(&request); err != nil {
+			logger.Error("Failed to parse close period request", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+		request.ClosedBy = utils.GetUserDataLocal(c).UserId
+
+		response, err := mediatr.Send[command.ClosePeriodRequest, *command.ClosePeriodResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to close stock period", slog.String("error", err.Error()))
+
+			if errors.Is(err, repository.ErrPeriodAlreadyClosed) {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			if errors.Is(err, repository.ErrPeriodNotEnded) || strings.Contains(err.Error(), "month") {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to close stock period",
+			})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(response)
+	}
+}
@@ -0,0 +1,55 @@
+package stockperiod_handler
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/stock_period/query"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// PeriodById is a function to get a closed stock period with its snapshot
+//
+//	@Summary		Get Stock Period by ID
+//	@Description	Get a closed stock period with the quantity and value of every product per warehouse at its end
+//	@Tags			StockPeriod
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	query.PeriodByIdResult
+//	@Failure		404	{object}	api.ErrorResponse	"Not Found: Stock period does not exist"
+//	@Router			/stock-periods/{id} [get]
+//
+//	@param			id	path	string	true	"Stock Period ID"
+func PeriodById(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		stockPeriodId, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			logger.Error("Invalid stock period ID", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid stock period ID",
+			})
+		}
+
+		request := query.PeriodByIdRequest{
+			StockPeriodId: stockPeriodId,
+		}
+
+		response, err := mediatr.Send[query.PeriodByIdRequest, *query.PeriodByIdResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to get stock period by id", slog.String("error", err.Error()))
+
+			if strings.Contains(err.Error(), "not found") {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": "Stock period not found",
+				})
+			}
+
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get stock period by id",
+			})
+		}
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
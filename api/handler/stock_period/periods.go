@@ -0,0 +1,32 @@
+package stockperiod_handler
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/stock_period/query"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// Periods is a function to get all closed stock periods
+//
+//	@Summary		Get Stock Period list
+//	@Description	Get closed stock periods, latest first
+//	@Tags			StockPeriod
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	query.PeriodsResult
+//	@Router			/stock-periods [get]
+func Periods(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		response, err := mediatr.Send[query.PeriodsRequest, *query.PeriodsResult](c.Context(), query.PeriodsRequest{})
+		if err != nil {
+			logger.Error("Failed to get stock periods", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get stock periods",
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
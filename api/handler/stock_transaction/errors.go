@@ -0,0 +1,15 @@
+package stocktransaction_handler
+
+import (
+	"errors"
+	"mini-erp-backend/api/repository"
+)
+
+// isSerialError reports whether err is a rejected serial number list, which is a client error
+func isSerialError(err error) bool {
+	return errors.Is(err, repository.ErrSerialRequired) ||
+		errors.Is(err, repository.ErrSerialNotTracked) ||
+		errors.Is(err, repository.ErrSerialDuplicate) ||
+		errors.Is(err, repository.ErrSerialNotAvailable) ||
+		errors.Is(err, repository.ErrSerialLotMismatch)
+}
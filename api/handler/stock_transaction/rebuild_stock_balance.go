@@ -0,0 +1,35 @@
+package stocktransaction_handler
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/stock_transaction/command"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// RebuildStockBalance is a function to recompute stock balances from the stock transaction ledger
+//
+//	@Summary		Rebuild Stock Balances
+//	@Description	Recompute every product stock balance from the stock transaction ledger
+//	@Tags			StockTransaction
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	command.RebuildStockBalanceResult
+//	@Failure		500	{object}	api.ErrorResponse	"Internal Server Error"
+//	@Router			/stocks/balances/rebuild [post]
+func RebuildStockBalance(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		request := command.RebuildStockBalanceRequest{}
+
+		response, err := mediatr.Send[command.RebuildStockBalanceRequest, *command.RebuildStockBalanceResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to rebuild stock balances", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to rebuild stock balances",
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
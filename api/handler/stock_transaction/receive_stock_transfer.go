@@ -0,0 +1,81 @@
+package stocktransaction_handler
+
+import (
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/service/stock_transaction/command"
+	"mini-erp-backend/utils"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// ReceiveStockTransfer is a function to receive an in-transit transfer into its destination warehouse
+//
+//	@Summary		Receive Stock Transfer
+//	@Description	Credit the destination warehouse of an in-transit stock transfer
+//	@Tags			StockTransaction
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string								true	"Stock Transfer ID"
+//	@Param			request	body		command.ReceiveStockTransferRequest	false	"Receive Stock Transfer Request"
+//	@Success		200		{object}	command.ReceiveStockTransferResult
+//	@Failure		400		{object}	api.ErrorResponse	"Bad Request: Invalid input"
+//	@Failure		404		{object}	api.ErrorResponse	"Not Found: Transfer or location does not exist"
+//	@Failure		409		{object}	api.ErrorResponse	"Conflict: Transfer is not in transit"
+//	@Failure		500		{object}	api.ErrorResponse	"Internal Server Error"
+//	@Router			/stocks/transfers/{id}/receive [post]
+func ReceiveStockTransfer(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		transferId, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid stock transfer ID",
+			})
+		}
+
+		request := command.ReceiveStockTransferRequest{}
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&request); err != nil {
+				logger.Error("Failed to parse receive stock transfer request", slog.String("error", err.Error()))
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Invalid request body",
+				})
+			}
+		}
+
+		request.StockTransferId = transferId
+		request.ReceivedBy = utils.GetUserDataLocal(c).UserId.String()
+
+		response, err := mediatr.Send[command.ReceiveStockTransferRequest, *command.ReceiveStockTransferResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to receive stock transfer", slog.String("error", err.Error()))
+
+			if errors.Is(err, command.ErrTransferNotInTransit) {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			if isSerialError(err) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			if strings.Contains(err.Error(), "not found") {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to receive stock transfer",
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
@@ -1,7 +1,9 @@
 package stocktransaction_handler
 
 import (
+	"errors"
 	"log/slog"
+	"mini-erp-backend/api/repository"
 	"mini-erp-backend/api/service/stock_transaction/command"
 	"strings"
 
@@ -20,6 +22,8 @@ import (
 //	@Success		201		{object}	command.StockInResult
 //	@Failure		400		{object}	api.ErrorResponse	"Bad Request: Invalid input or insufficient stock"
 //	@Failure		404		{object}	api.ErrorResponse	"Not Found: Product does not exist"
+//	@Failure		409		{object}	api.ErrorResponse	"Conflict: Lot exists with a different expiry date"
+//	@Failure		409		{object}	api.ErrorResponse	"Conflict: Transaction date falls in a closed period"
 //	@Failure		500		{object}	api.ErrorResponse	"Internal Server Error"s
 //	@Router			/stock/in [post]
 func StockIn(logger *slog.Logger) fiber.Handler {
@@ -53,13 +57,49 @@ func StockIn(logger *slog.Logger) fiber.Handler {
 		if err != nil {
 			logger.Error("Failed to process stock in", slog.String("error", err.Error()))
 
+			if errors.Is(err, repository.ErrLotNumberRequired) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			if errors.Is(err, repository.ErrLotExpiryMismatch) {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			if isSerialError(err) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			if errors.Is(err, command.ErrFutureTransactionDate) || errors.Is(err, repository.ErrUnitNotFound) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			if errors.Is(err, repository.ErrPeriodClosed) {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			if errors.Is(err, repository.ErrSerialInStock) {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
 			if strings.Contains(err.Error(), "not found") {
 				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 					"error": err.Error(),
 				})
 			}
 
-			if strings.Contains(err.Error(), "quantity") {
+			if strings.Contains(err.Error(), "quantity") || strings.Contains(err.Error(), "unit cost") {
 				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 					"error": err.Error(),
 				})
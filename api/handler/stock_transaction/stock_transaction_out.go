@@ -1,7 +1,9 @@
 package stocktransaction_handler
 
 import (
+	"errors"
 	"log/slog"
+	"mini-erp-backend/api/repository"
 	"mini-erp-backend/api/service/stock_transaction/command"
 	"strings"
 
@@ -12,14 +14,17 @@ import (
 // StockOut is a function to handle stock out transactions
 //
 //	@Summary		Stock Out
-//	@Description	Handle stock out for a product
+//	@Description	Handle stock out for a product. Lots past their expiry date are never issued (FEFO over sellable lots).
 //	@Tags			StockTransaction
 //	@Accept			json
 //	@Produce		json
 //	@Param			request	body		command.StockOutRequest	true	"Stock Out Request"
 //	@Success		201		{object}	command.StockOutResult
-//	@Failure		400		{object}	api.ErrorResponse	"Bad Request: Invalid input or insufficient stock"
+//	@Failure		400		{object}	api.ErrorResponse	"Bad Request: Invalid input"
 //	@Failure		404		{object}	api.ErrorResponse	"Not Found: Product does not exist"
+//	@Failure		409		{object}	api.ErrorResponse	"Conflict: Insufficient stock"
+//	@Failure		409		{object}	api.ErrorResponse	"Conflict: Only expired lots left"
+//	@Failure		409		{object}	api.ErrorResponse	"Conflict: Transaction date falls in a closed period"
 //	@Failure		500		{object}	api.ErrorResponse	"Internal Server Error"
 //	@Router			/stock/out [post]
 func StockOut(logger *slog.Logger) fiber.Handler {
@@ -46,18 +51,36 @@ func StockOut(logger *slog.Logger) fiber.Handler {
 		if err != nil {
 			logger.Error("Failed to process stock out", slog.String("error", err.Error()))
 
-			if strings.Contains(err.Error(), "not found") {
-				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			if errors.Is(err, repository.ErrInsufficientStock) || errors.Is(err, repository.ErrLotExpired) {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
 					"error": err.Error(),
 				})
 			}
 
-			if strings.Contains(err.Error(), "insufficient") {
+			if isSerialError(err) {
 				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 					"error": err.Error(),
 				})
 			}
 
+			if errors.Is(err, command.ErrFutureTransactionDate) || errors.Is(err, repository.ErrUnitNotFound) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			if errors.Is(err, repository.ErrPeriodClosed) {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			if strings.Contains(err.Error(), "not found") {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
 			if strings.Contains(err.Error(), "quantity") {
 				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 					"error": err.Error(),
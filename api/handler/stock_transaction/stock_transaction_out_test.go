@@ -0,0 +1,67 @@
+package stocktransaction_handler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/api/service/stock_transaction/command"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// fakeStockOut answers the stock out request with a fixed error
+type fakeStockOut struct {
+	err error
+}
+
+func (f *fakeStockOut) Handle(_ context.Context, _ command.StockOutRequest) (*command.StockOutResult, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &command.StockOutResult{}, nil
+}
+
+func TestStockOutStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"insufficient stock", fmt.Errorf("%w: available 3, requested 5", repository.ErrInsufficientStock), fiber.StatusConflict},
+		{"closed period", fmt.Errorf("%w: 2026-09", repository.ErrPeriodClosed), fiber.StatusConflict},
+		{"future date", command.ErrFutureTransactionDate, fiber.StatusBadRequest},
+		{"success", nil, fiber.StatusCreated},
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mediatr.ClearRequestRegistrations()
+			t.Cleanup(mediatr.ClearRequestRegistrations)
+			if err := mediatr.RegisterRequestHandler[command.StockOutRequest, *command.StockOutResult](&fakeStockOut{err: tt.err}); err != nil {
+				t.Fatalf("RegisterRequestHandler error: %v", err)
+			}
+
+			app := fiber.New()
+			app.Post("/stock/out", StockOut(logger))
+
+			body := `{"product_id":"6f1c2b8e-1d0a-4c55-9a43-2f7a0e9b1c11","quantity":5}`
+			req := httptest.NewRequest(fiber.MethodPost, "/stock/out", strings.NewReader(body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("app.Test error: %v", err)
+			}
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
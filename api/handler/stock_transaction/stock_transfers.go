@@ -0,0 +1,79 @@
+package stocktransaction_handler
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/stock_transaction/query"
+	"mini-erp-backend/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+type StockTransferQuery struct {
+	Page        int        `query:"page"`
+	PageSize    int        `query:"pageSize"`
+	Status      string     `query:"status"`
+	ProductId   *uuid.UUID `query:"productId"`
+	WarehouseId *uuid.UUID `query:"warehouseId"`
+	SortBy      string     `query:"sortBy"`
+	SortOrder   string     `query:"sortOrder"`
+}
+
+// StockTransfers is a function to get stock transfers between warehouses
+//
+//	@Summary		Get Stock Transfers list
+//	@Description	Get stock transfers, optionally only those still in transit
+//	@Tags			StockTransaction
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	query.StockTransfersResult
+//	@Router			/stocks/transfers [get]
+//
+//	@param			page		query	int		false	"Page number"
+//	@param			pageSize	query	int		false	"Number of items per page"
+//	@param			status		query	string	false	"Filter by status (IN_TRANSIT or RECEIVED)"
+//	@param			productId	query	string	false	"Filter by Product ID"
+//	@param			warehouseId	query	string	false	"Filter by source or destination Warehouse ID"
+//	@param			sortBy		query	string	false	"Field to sort by"
+//	@param			sortOrder	query	string	false	"Sort order (asc or desc)"
+func StockTransfers(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var q StockTransferQuery
+
+		if err := c.QueryParser(&q); err != nil {
+			logger.Error("Failed to parse query parameters", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid query parameters",
+			})
+		}
+
+		request := query.StockTransfersRequest{
+			Page:        q.Page,
+			PageSize:    q.PageSize,
+			ProductId:   q.ProductId,
+			WarehouseId: q.WarehouseId,
+			SortBy:      q.SortBy,
+			SortOrder:   q.SortOrder,
+		}
+
+		if q.Status != "" {
+			status := model.StockTransferStatus(q.Status)
+			if status != model.StockTransferInTransit && status != model.StockTransferReceived {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Invalid status",
+				})
+			}
+			request.Status = &status
+		}
+
+		response, err := mediatr.Send[query.StockTransfersRequest, *query.StockTransfersResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to get stock transfers", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get stock transfers",
+			})
+		}
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
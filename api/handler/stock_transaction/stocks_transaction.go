@@ -10,12 +10,13 @@ import (
 )
 
 type StockTransactionQuery struct {
-	Page      int        `query:"page"`
-	PageSize  int        `query:"pageSize"`
-	Search    string     `query:"search"`
-	ProductId *uuid.UUID `query:"productId"`
-	SortBy    string     `query:"sortBy"`
-	SortOrder string     `query:"sortOrder"`
+	Page        int        `query:"page"`
+	PageSize    int        `query:"pageSize"`
+	Search      string     `query:"search"`
+	ProductId   *uuid.UUID `query:"productId"`
+	WarehouseId *uuid.UUID `query:"warehouseId"`
+	SortBy      string     `query:"sortBy"`
+	SortOrder   string     `query:"sortOrder"`
 }
 
 // StockTransactions is a function to get all stock transactions
@@ -32,6 +33,7 @@ type StockTransactionQuery struct {
 //	@param			pageSize	query	int		false	"Number of items per page"
 //	@param			search		query	string	false	"Search term for quantity, type, and reason"
 //	@param			productId	query	string	false	"Filter by Product ID"
+//	@param			warehouseId	query	string	false	"Filter by Warehouse ID"
 //	@param			sortBy		query	string	false	"Field to sort by"
 //	@param			sortOrder	query	string	false	"Sort order (asc or desc)"
 func StockTransactions(logger *slog.Logger) fiber.Handler {
@@ -46,12 +48,13 @@ func StockTransactions(logger *slog.Logger) fiber.Handler {
 		}
 
 		request := query.StocksRequest{
-			Page:      q.Page,
-			PageSize:  q.PageSize,
-			Search:    q.Search,
-			ProductId: q.ProductId,
-			SortBy:    q.SortBy,
-			SortOrder: q.SortOrder,
+			Page:        q.Page,
+			PageSize:    q.PageSize,
+			Search:      q.Search,
+			ProductId:   q.ProductId,
+			WarehouseId: q.WarehouseId,
+			SortBy:      q.SortBy,
+			SortOrder:   q.SortOrder,
 		}
 
 		response, err := mediatr.Send[query.StocksRequest, *query.StocksResult](c.Context(), request)
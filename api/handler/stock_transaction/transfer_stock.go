@@ -0,0 +1,86 @@
+package stocktransaction_handler
+
+import (
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/api/service/stock_transaction/command"
+	"mini-erp-backend/utils"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// TransferStock is a function to move stock between warehouses
+//
+//	@Summary		Transfer Stock
+//	@Description	Move stock from one warehouse to another. With in_transit the destination is credited only when the transfer is received.
+//	@Tags			StockTransaction
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		command.TransferStockRequest	true	"Transfer Stock Request"
+//	@Success		201		{object}	command.TransferStockResult
+//	@Failure		400		{object}	api.ErrorResponse	"Bad Request: Invalid input or insufficient stock"
+//	@Failure		404		{object}	api.ErrorResponse	"Not Found: Product, warehouse or location does not exist"
+//	@Failure		500		{object}	api.ErrorResponse	"Internal Server Error"
+//	@Router			/stocks/transfer [post]
+func TransferStock(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		request := command.TransferStockRequest{}
+
+		if err := c.BodyParser(&request); err != nil {
+			logger.Error("Failed to parse transfer stock request", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+
+		if request.ProductId == uuid.Nil || request.FromWarehouseId == uuid.Nil || request.ToWarehouseId == uuid.Nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "product_id, from_warehouse_id and to_warehouse_id are required",
+			})
+		}
+
+		if request.Quantity <= 0 {
+			logger.Error("Invalid quantity for stock transfer", slog.Int64("quantity", request.Quantity))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Quantity must be greater than 0 for stock transfer",
+			})
+		}
+
+		request.CreatedBy = utils.GetUserDataLocal(c).UserId.String()
+
+		response, err := mediatr.Send[command.TransferStockRequest, *command.TransferStockResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to transfer stock", slog.String("error", err.Error()))
+
+			if errors.Is(err, command.ErrSameWarehouse) ||
+				errors.Is(err, repository.ErrInsufficientStock) ||
+				strings.Contains(err.Error(), "quantity") {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			if isSerialError(err) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			if strings.Contains(err.Error(), "not found") {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to transfer stock",
+			})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(response)
+	}
+}
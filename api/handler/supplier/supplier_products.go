@@ -0,0 +1,186 @@
+package supplier
+
+import (
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/service/supplier/command"
+	"mini-erp-backend/api/service/supplier/query"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// SupplierProducts
+//
+//	@Summary		Get the product catalog of a supplier
+//	@Description	Get the products a supplier sells with its SKU, price, minimum order quantity, pack size and lead time
+//	@Tags			Supplier
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path	string	true	"Supplier ID (UUID)"
+//	@Success		200	{object}	query.SupplierProductsResult
+//	@Failure		400	{object}	api.ErrorResponse
+//	@Failure		404	{object}	api.ErrorResponse
+//	@Failure		500	{object}	api.ErrorResponse
+//	@Router			/suppliers/{id}/products [get]
+func SupplierProducts(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		supplierId, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			logger.Error("Invalid supplier ID", "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid supplier ID"})
+		}
+
+		req := query.SupplierProductsRequest{SupplierId: supplierId}
+
+		result, err := mediatr.Send[*query.SupplierProductsRequest, *query.SupplierProductsResult](c.Context(), &req)
+		if err != nil {
+			logger.Error("Failed to get supplier products", "error", err)
+			return c.Status(supplierProductStatus(err)).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(result)
+	}
+}
+
+// CreateSupplierProduct
+//
+//	@Summary		Add a product to a supplier catalog
+//	@Description	Add a product with supplier specific SKU, price, minimum order quantity, pack size and lead time
+//	@Tags			Supplier
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path	string									true	"Supplier ID (UUID)"
+//	@Param			supplierProduct	body	command.CreateSupplierProductRequest	true	"Supplier product information"
+//	@Success		201	{object}	model.SupplierProduct
+//	@Failure		400	{object}	api.ErrorResponse
+//	@Failure		404	{object}	api.ErrorResponse
+//	@Failure		409	{object}	api.ErrorResponse
+//	@Failure		500	{object}	api.ErrorResponse
+//	@Router			/suppliers/{id}/products [post]
+func CreateSupplierProduct(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		supplierId, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			logger.Error("Invalid supplier ID", "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid supplier ID"})
+		}
+
+		var req command.CreateSupplierProductRequest
+		if err := c.BodyParser(&req); err != nil {
+			logger.Error("Failed to parse request body", "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		req.SupplierId = supplierId
+
+		result, err := mediatr.Send[*command.CreateSupplierProductRequest, interface{}](c.Context(), &req)
+		if err != nil {
+			logger.Error("Failed to create supplier product", "error", err)
+			return c.Status(supplierProductStatus(err)).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(result)
+	}
+}
+
+// UpdateSupplierProduct
+//
+//	@Summary		Update a product in a supplier catalog
+//	@Description	Update the supplier specific terms of a product
+//	@Tags			Supplier
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path	string									true	"Supplier ID (UUID)"
+//	@Param			productId		path	string									true	"Product ID (UUID)"
+//	@Param			supplierProduct	body	command.UpdateSupplierProductRequest	true	"Supplier product information"
+//	@Success		200	{object}	model.SupplierProduct
+//	@Failure		400	{object}	api.ErrorResponse
+//	@Failure		404	{object}	api.ErrorResponse
+//	@Failure		500	{object}	api.ErrorResponse
+//	@Router			/suppliers/{id}/products/{productId} [put]
+func UpdateSupplierProduct(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		supplierId, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			logger.Error("Invalid supplier ID", "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid supplier ID"})
+		}
+
+		productId, err := uuid.Parse(c.Params("productId"))
+		if err != nil {
+			logger.Error("Invalid product ID", "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid product ID"})
+		}
+
+		var req command.UpdateSupplierProductRequest
+		if err := c.BodyParser(&req); err != nil {
+			logger.Error("Failed to parse request body", "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		req.SupplierId = supplierId
+		req.ProductId = productId
+
+		result, err := mediatr.Send[*command.UpdateSupplierProductRequest, interface{}](c.Context(), &req)
+		if err != nil {
+			logger.Error("Failed to update supplier product", "error", err)
+			return c.Status(supplierProductStatus(err)).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(result)
+	}
+}
+
+// DeleteSupplierProduct
+//
+//	@Summary		Remove a product from a supplier catalog
+//	@Description	Remove a product from a supplier catalog, purchase orders to the supplier go back to the product cost price
+//	@Tags			Supplier
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path	string	true	"Supplier ID (UUID)"
+//	@Param			productId	path	string	true	"Product ID (UUID)"
+//	@Success		200
+//	@Failure		400	{object}	api.ErrorResponse
+//	@Failure		404	{object}	api.ErrorResponse
+//	@Failure		500	{object}	api.ErrorResponse
+//	@Router			/suppliers/{id}/products/{productId} [delete]
+func DeleteSupplierProduct(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		supplierId, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			logger.Error("Invalid supplier ID", "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid supplier ID"})
+		}
+
+		productId, err := uuid.Parse(c.Params("productId"))
+		if err != nil {
+			logger.Error("Invalid product ID", "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid product ID"})
+		}
+
+		req := command.DeleteSupplierProductRequest{SupplierId: supplierId, ProductId: productId}
+
+		_, err = mediatr.Send[*command.DeleteSupplierProductRequest, interface{}](c.Context(), &req)
+		if err != nil {
+			logger.Error("Failed to delete supplier product", "error", err)
+			return c.Status(supplierProductStatus(err)).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		return c.SendStatus(fiber.StatusOK)
+	}
+}
+
+func supplierProductStatus(err error) int {
+	switch {
+	case errors.Is(err, command.ErrInvalidSupplierProduct):
+		return fiber.StatusBadRequest
+	case errors.Is(err, command.ErrSupplierProductExists):
+		return fiber.StatusConflict
+	case strings.Contains(err.Error(), "not found"):
+		return fiber.StatusNotFound
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
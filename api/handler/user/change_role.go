@@ -0,0 +1,79 @@
+package user_handler
+
+import (
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/service/user/command"
+	"mini-erp-backend/utils"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// ChangeRole is a function to change the role of a user
+//
+//	@Summary		Change User role
+//	@Description	Change the role of a user, a change that removes permissions revokes the active sessions of the user (requires user.manage)
+//	@Tags			User
+//	@Accept			json
+//	@Produce		json
+//	@param			id		path		string						true	"User ID"
+//	@Param			request	body		command.ChangeRoleRequest	true	"Change Role Request"
+//	@Success		200		{object}	command.ChangeRoleResult
+//	@Failure		400		{object}	api.ErrorResponse	"Bad Request: Invalid role"
+//	@Failure		403		{object}	api.ErrorResponse	"Forbidden: Cannot demote your own account or grant permissions you do not have"
+//	@Failure		404		{object}	api.ErrorResponse	"Not Found: User does not exist"
+//	@Failure		500		{object}	api.ErrorResponse	"Internal Server Error"
+//	@Router			/users/{id}/role [put]
+func ChangeRole(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userId, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			logger.Error("Invalid user ID", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid user ID",
+			})
+		}
+
+		request := command.ChangeRoleRequest{}
+
+		if err := c.BodyParser(&request); err != nil {
+			logger.Error("Failed to parse change role request", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+
+		request.UserId = userId
+		actor := utils.GetUserDataLocal(c)
+		request.ActorId = actor.UserId
+		request.ActorPermissions = actor.Permissions
+
+		response, err := mediatr.Send[command.ChangeRoleRequest, *command.ChangeRoleResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to change user role", slog.String("error", err.Error()))
+			return c.Status(userStatus(err)).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
+
+// userStatus maps the errors of the user commands to HTTP status codes
+func userStatus(err error) int {
+	switch {
+	case errors.Is(err, command.ErrInvalidRole):
+		return fiber.StatusBadRequest
+	case errors.Is(err, command.ErrSelfModification),
+		errors.Is(err, command.ErrPrivilegeEscalation):
+		return fiber.StatusForbidden
+	case strings.Contains(err.Error(), "not found"):
+		return fiber.StatusNotFound
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,53 @@
+package user_handler
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/user/command"
+	"mini-erp-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// IssuePasswordReset is a function to issue a password reset token for a user
+//
+//	@Summary		Issue password reset
+//	@Description	Issue a single-use, time-limited reset token, force a password change at next login and revoke the sessions of the user (requires user.manage)
+//	@Tags			User
+//	@Accept			json
+//	@Produce		json
+//	@param			id	path		string	true	"User ID"
+//	@Success		200	{object}	command.IssuePasswordResetResult
+//	@Failure		403	{object}	api.ErrorResponse	"Forbidden: User's role has permissions you do not have"
+//	@Failure		404	{object}	api.ErrorResponse	"Not Found: User does not exist"
+//	@Failure		500	{object}	api.ErrorResponse	"Internal Server Error"
+//	@Router			/users/{id}/password-reset [post]
+func IssuePasswordReset(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userId, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			logger.Error("Invalid user ID", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid user ID",
+			})
+		}
+
+		actor := utils.GetUserDataLocal(c)
+		request := command.IssuePasswordResetRequest{
+			UserId:           userId,
+			ActorId:          actor.UserId,
+			ActorPermissions: actor.Permissions,
+		}
+
+		response, err := mediatr.Send[command.IssuePasswordResetRequest, *command.IssuePasswordResetResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to issue password reset", slog.String("error", err.Error()))
+			return c.Status(userStatus(err)).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
@@ -0,0 +1,72 @@
+package user_handler
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/user/command"
+	"mini-erp-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// Deactivate is a function to disable a user
+//
+//	@Summary		Deactivate User
+//	@Description	Disable a user and revoke all of their active sessions (requires user.manage)
+//	@Tags			User
+//	@Accept			json
+//	@Produce		json
+//	@param			id	path		string	true	"User ID"
+//	@Success		200	{object}	command.SetDisabledResult
+//	@Failure		403	{object}	api.ErrorResponse	"Forbidden: Cannot disable your own account"
+//	@Failure		404	{object}	api.ErrorResponse	"Not Found: User does not exist"
+//	@Failure		500	{object}	api.ErrorResponse	"Internal Server Error"
+//	@Router			/users/{id}/deactivate [post]
+func Deactivate(logger *slog.Logger) fiber.Handler {
+	return setDisabled(logger, true)
+}
+
+// Reactivate is a function to enable a disabled user
+//
+//	@Summary		Reactivate User
+//	@Description	Enable a disabled user so they can log in again (requires user.manage)
+//	@Tags			User
+//	@Accept			json
+//	@Produce		json
+//	@param			id	path		string	true	"User ID"
+//	@Success		200	{object}	command.SetDisabledResult
+//	@Failure		404	{object}	api.ErrorResponse	"Not Found: User does not exist"
+//	@Failure		500	{object}	api.ErrorResponse	"Internal Server Error"
+//	@Router			/users/{id}/reactivate [post]
+func Reactivate(logger *slog.Logger) fiber.Handler {
+	return setDisabled(logger, false)
+}
+
+func setDisabled(logger *slog.Logger, disabled bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userId, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			logger.Error("Invalid user ID", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid user ID",
+			})
+		}
+
+		request := command.SetDisabledRequest{
+			UserId:   userId,
+			ActorId:  utils.GetUserDataLocal(c).UserId,
+			Disabled: disabled,
+		}
+
+		response, err := mediatr.Send[command.SetDisabledRequest, *command.SetDisabledResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to change user status", slog.String("error", err.Error()), slog.Bool("disabled", disabled))
+			return c.Status(userStatus(err)).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
@@ -0,0 +1,71 @@
+package user_handler
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/user/command"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// UpdateProfile is a function to update the profile of a user
+//
+//	@Summary		Update User profile
+//	@Description	Update first name and last name of a user (requires user.manage)
+//	@Tags			User
+//	@Accept			json
+//	@Produce		json
+//	@param			id		path		string							true	"User ID"
+//	@Param			request	body		command.UpdateProfileRequest	true	"Update Profile Request"
+//	@Success		200		{object}	command.UpdateProfileResult
+//	@Failure		400		{object}	api.ErrorResponse	"Bad Request: Invalid input"
+//	@Failure		404		{object}	api.ErrorResponse	"Not Found: User does not exist"
+//	@Failure		500		{object}	api.ErrorResponse	"Internal Server Error"
+//	@Router			/users/{id} [put]
+func UpdateProfile(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userId, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			logger.Error("Invalid user ID", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid user ID",
+			})
+		}
+
+		request := command.UpdateProfileRequest{}
+
+		if err := c.BodyParser(&request); err != nil {
+			logger.Error("Failed to parse update profile request", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+
+		request.UserId = userId
+
+		response, err := mediatr.Send[command.UpdateProfileRequest, *command.UpdateProfileResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to update user profile", slog.String("error", err.Error()))
+
+			if strings.Contains(err.Error(), "not found") {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			if strings.Contains(err.Error(), "required") {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to update user profile",
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
@@ -0,0 +1,55 @@
+package user_handler
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/user/query"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// UserById is a function to get user by id
+//
+//	@Summary		Get User by ID
+//	@Description	Get user by ID (requires user.manage)
+//	@Tags			User
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	query.UserByIdResult
+//	@Failure		404	{object}	api.ErrorResponse	"Not Found: User does not exist"
+//	@Router			/users/{id} [get]
+//
+//	@param			id	path	string	true	"User ID"
+func UserById(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userId, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			logger.Error("Invalid user ID", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid user ID",
+			})
+		}
+
+		request := query.UserByIdRequest{
+			UserId: userId,
+		}
+
+		response, err := mediatr.Send[query.UserByIdRequest, *query.UserByIdResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to get user by id", slog.String("error", err.Error()))
+
+			if strings.Contains(err.Error(), "not found") {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": "User not found",
+				})
+			}
+
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get user by id",
+			})
+		}
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
@@ -0,0 +1,70 @@
+package user_handler
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/user/query"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+type UserQuery struct {
+	Page      int    `query:"page"`
+	PageSize  int    `query:"pageSize"`
+	Search    string `query:"search"`
+	Role      string `query:"role"`
+	Disabled  *bool  `query:"disabled"`
+	SortBy    string `query:"sortBy"`
+	SortOrder string `query:"sortOrder"`
+}
+
+// Users is a function to get users
+//
+//	@Summary		Get User list
+//	@Description	Get user list with search and pagination (requires user.manage)
+//	@Tags			User
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	query.UsersResult
+//	@Failure		400	{object}	api.ErrorResponse	"Bad Request: Invalid query parameters"
+//	@Router			/users [get]
+//
+//	@param			page		query	int		false	"Page number (default 1)"
+//	@param			pageSize	query	int		false	"Number of items per page (default 20)"
+//	@param			search		query	string	false	"Search term for username, first name and last name"
+//	@param			role		query	string	false	"Filter by role (admin, staff, viewer)"
+//	@param			disabled	query	bool	false	"Filter by disabled status"
+//	@param			sortBy		query	string	false	"Field to sort by (username, first_name, last_name, role, created_at, updated_at)"
+//	@param			sortOrder	query	string	false	"Sort order (asc or desc)"
+func Users(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var q UserQuery
+
+		if err := c.QueryParser(&q); err != nil {
+			logger.Error("Failed to parse query parameters", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid query parameters",
+			})
+		}
+
+		request := query.UsersRequest{
+			Page:      q.Page,
+			PageSize:  q.PageSize,
+			Search:    q.Search,
+			Role:      q.Role,
+			Disabled:  q.Disabled,
+			SortBy:    q.SortBy,
+			SortOrder: q.SortOrder,
+		}
+
+		response, err := mediatr.Send[query.UsersRequest, *query.UsersResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to get users", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get users",
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
@@ -0,0 +1,72 @@
+package warehouse_handler
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/warehouse/command"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// Create is a function to create a new warehouse
+//
+//	@Summary		Create Warehouse
+//	@Description	Create a new warehouse. is_default moves the default flag to the new warehouse.
+//	@Tags			Warehouse
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		command.CreateRequest	true	"Create Request"
+//	@Success		201		{object}	command.CreateResult
+//	@Failure		409		{object}	api.ErrorResponse	"Conflict: Warehouse code already exists"
+//	@Failure		400		{object}	api.ErrorResponse	"Bad Request: Invalid input"
+//	@Failure		500		{object}	api.ErrorResponse	"Internal Server Error"
+//	@Router			/warehouses [post]
+func Create(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		request := command.CreateRequest{}
+
+		if err := c.BodyParser(&request); err != nil {
+			logger.Error("Failed to parse create warehouse request", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+
+		if msg := validateWarehouse(request.Code, request.Name); msg != "" {
+			logger.Error("Invalid create warehouse request", slog.String("error", msg))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": msg,
+			})
+		}
+
+		response, err := mediatr.Send[command.CreateRequest, *command.CreateResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to create warehouse", slog.String("error", err.Error()))
+
+			if strings.Contains(err.Error(), "already exists") {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to create warehouse",
+			})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(response)
+	}
+}
+
+func validateWarehouse(code, name string) string {
+	if strings.TrimSpace(code) == "" {
+		return "Warehouse code is required"
+	}
+
+	if strings.TrimSpace(name) == "" {
+		return "Warehouse name is required"
+	}
+
+	return ""
+}
@@ -0,0 +1,78 @@
+package warehouse_handler
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/warehouse/command"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// CreateLocation is a function to add a bin location to a warehouse
+//
+//	@Summary		Create Location
+//	@Description	Add a bin location to a warehouse
+//	@Tags			Warehouse
+//	@Accept			json
+//	@Produce		json
+//	@param			id		path		string							true	"Warehouse ID"
+//	@Param			request	body		command.CreateLocationRequest	true	"Create Location Request"
+//	@Success		201		{object}	command.CreateLocationResult
+//	@Failure		409		{object}	api.ErrorResponse	"Conflict: Location code already exists in this warehouse"
+//	@Failure		400		{object}	api.ErrorResponse	"Bad Request: Invalid input"
+//	@Failure		404		{object}	api.ErrorResponse	"Not Found: Warehouse does not exist"
+//	@Failure		500		{object}	api.ErrorResponse	"Internal Server Error"
+//	@Router			/warehouses/{id}/locations [post]
+func CreateLocation(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		warehouseId, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			logger.Error("Invalid warehouse ID", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid warehouse ID",
+			})
+		}
+
+		request := command.CreateLocationRequest{}
+
+		if err := c.BodyParser(&request); err != nil {
+			logger.Error("Failed to parse create location request", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+
+		request.WarehouseId = warehouseId
+
+		if strings.TrimSpace(request.Code) == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Location code is required",
+			})
+		}
+
+		response, err := mediatr.Send[command.CreateLocationRequest, *command.CreateLocationResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to create location", slog.String("error", err.Error()))
+
+			if strings.Contains(err.Error(), "already exists") {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			if strings.Contains(err.Error(), "not found") {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to create location",
+			})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(response)
+	}
+}
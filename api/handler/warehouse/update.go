@@ -0,0 +1,80 @@
+package warehouse_handler
+
+import (
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/service/warehouse/command"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// Update is a function to update warehouse by id
+//
+//	@Summary		Update Warehouse by ID
+//	@Description	Update warehouse by ID. The default warehouse can only be changed by marking another warehouse as default.
+//	@Tags			Warehouse
+//	@Accept			json
+//	@Produce		json
+//	@param			id		path		string					true	"Warehouse ID"
+//	@Param			request	body		command.UpdateRequest	true	"Update Request"
+//	@Success		200		{object}	command.UpdateResult
+//	@Failure		409		{object}	api.ErrorResponse		"Conflict: Warehouse code already exists or no default warehouse left"
+//	@Failure		400		{object}	api.ErrorResponse		"Bad Request: Invalid input"
+//	@Failure		404		{object}	api.ErrorResponse		"Not Found: Warehouse does not exist"
+//	@Failure		500		{object}	api.ErrorResponse		"Internal Server Error"
+//	@Router			/warehouses/{id} [patch]
+func Update(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		warehouseId, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			logger.Error("Invalid warehouse ID", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid warehouse ID",
+			})
+		}
+
+		request := command.UpdateRequest{}
+
+		if err := c.BodyParser(&request); err != nil {
+			logger.Error("Failed to parse update warehouse request", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+
+		request.WarehouseId = warehouseId
+
+		if msg := validateWarehouse(request.Code, request.Name); msg != "" {
+			logger.Error("Invalid update warehouse request", slog.String("error", msg))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": msg,
+			})
+		}
+
+		response, err := mediatr.Send[command.UpdateRequest, *command.UpdateResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to update warehouse", slog.String("error", err.Error()))
+
+			if strings.Contains(err.Error(), "already exists") || errors.Is(err, command.ErrDefaultWarehouseRequired) {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			if strings.Contains(err.Error(), "not found") {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to update warehouse",
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
@@ -0,0 +1,55 @@
+package warehouse_handler
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/warehouse/query"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// WarehouseById is a function to get a warehouse and its locations by id
+//
+//	@Summary		Get Warehouse by ID
+//	@Description	Get warehouse by ID including its bin locations
+//	@Tags			Warehouse
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	query.WarehouseByIdResult
+//	@Failure		404	{object}	api.ErrorResponse	"Not Found: Warehouse does not exist"
+//	@Router			/warehouses/{id} [get]
+//
+//	@param			id	path	string	true	"Warehouse ID"
+func WarehouseById(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		warehouseId, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			logger.Error("Invalid warehouse ID", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid warehouse ID",
+			})
+		}
+
+		request := query.WarehouseByIdRequest{
+			WarehouseId: warehouseId,
+		}
+
+		response, err := mediatr.Send[query.WarehouseByIdRequest, *query.WarehouseByIdResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to get warehouse by id", slog.String("error", err.Error()))
+
+			if strings.Contains(err.Error(), "not found") {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": "Warehouse not found",
+				})
+			}
+
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get warehouse by id",
+			})
+		}
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
@@ -0,0 +1,51 @@
+package warehouse_handler
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/service/warehouse/query"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+type WarehouseQuery struct {
+	Search string `query:"search"`
+}
+
+// Warehouses is a function to get all warehouses
+//
+//	@Summary		Get Warehouse list
+//	@Description	Get warehouse list, default warehouse first
+//	@Tags			Warehouse
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	query.WarehousesResult
+//	@Router			/warehouses [get]
+//
+//	@param			search	query	string	false	"Search term for code and name"
+func Warehouses(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var q WarehouseQuery
+
+		if err := c.QueryParser(&q); err != nil {
+			logger.Error("Failed to parse query parameters", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid query parameters",
+			})
+		}
+
+		request := query.WarehousesRequest{
+			Search: q.Search,
+		}
+
+		response, err := mediatr.Send[query.WarehousesRequest, *query.WarehousesResult](c.Context(), request)
+		if err != nil {
+			logger.Error("Failed to get warehouses", slog.String("error", err.Error()))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get warehouses",
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+}
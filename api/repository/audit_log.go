@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"log/slog"
+	"mini-erp-backend/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AuditLogSearchFilters struct {
+	UserId   *uuid.UUID
+	Action   string
+	Entity   string
+	EntityId string
+	FromDate *time.Time
+	ToDate   *time.Time
+}
+
+type AuditLog interface {
+	// Get
+	SearchWithFilters(db *gorm.DB, filters AuditLogSearchFilters, orderBy string) ([]model.AuditLog, error)
+	SearchWithFiltersAndPagination(db *gorm.DB, filters AuditLogSearchFilters, orderBy string, page, pageSize int) ([]model.AuditLog, int64, error)
+	// Create
+	Create(tx *gorm.DB, auditLog *model.AuditLog) error
+}
+
+type auditLog struct {
+	logger *slog.Logger
+}
+
+func NewAuditLog(logger *slog.Logger) AuditLog {
+	return &auditLog{
+		logger: logger,
+	}
+}
+
+func (a *auditLog) Create(tx *gorm.DB, auditLog *model.AuditLog) error {
+	if err := tx.Create(auditLog).Error; err != nil {
+		a.logger.Error("Failed to create audit log", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (a *auditLog) applyFilters(query *gorm.DB, filters AuditLogSearchFilters) *gorm.DB {
+	if filters.UserId != nil && *filters.UserId != uuid.Nil {
+		query = query.Where("user_id = ?", *filters.UserId)
+	}
+
+	if filters.Action != "" {
+		query = query.Where("action = ?", filters.Action)
+	}
+
+	if filters.Entity != "" {
+		query = query.Where("entity = ?", filters.Entity)
+	}
+
+	if filters.EntityId != "" {
+		query = query.Where("entity_id = ?", filters.EntityId)
+	}
+
+	if filters.FromDate != nil {
+		query = query.Where("created_at >= ?", *filters.FromDate)
+	}
+
+	if filters.ToDate != nil {
+		query = query.Where("created_at <= ?", *filters.ToDate)
+	}
+
+	return query
+}
+
+func (a *auditLog) SearchWithFilters(db *gorm.DB, filters AuditLogSearchFilters, orderBy string) ([]model.AuditLog, error) {
+	auditLogs := []model.AuditLog{}
+	query := a.applyFilters(db.Model(&model.AuditLog{}), filters)
+
+	if orderBy != "" {
+		query = query.Order(orderBy)
+	}
+
+	if err := query.Find(&auditLogs).Error; err != nil {
+		a.logger.Error("Failed to search audit logs with filters", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return auditLogs, nil
+}
+
+func (a *auditLog) SearchWithFiltersAndPagination(db *gorm.DB, filters AuditLogSearchFilters, orderBy string, page, pageSize int) ([]model.AuditLog, int64, error) {
+	auditLogs := []model.AuditLog{}
+	var total int64
+
+	query := a.applyFilters(db.Model(&model.AuditLog{}), filters)
+
+	// นับจำนวนทั้งหมด
+	if err := query.Count(&total).Error; err != nil {
+		a.logger.Error("Failed to count audit logs with filters", slog.String("error", err.Error()))
+		return nil, 0, err
+	}
+
+	// คำนวณ offset
+	offset := (page - 1) * pageSize
+
+	// เรียงลำดับ
+	if orderBy != "" {
+		query = query.Order(orderBy)
+	}
+
+	// ดึงข้อมูลแบบ pagination
+	if err := query.Offset(offset).Limit(pageSize).Find(&auditLogs).Error; err != nil {
+		a.logger.Error("Failed to search audit logs with filters and pagination", slog.String("error", err.Error()))
+		return nil, 0, err
+	}
+
+	return auditLogs, total, nil
+}
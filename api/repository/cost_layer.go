@@ -0,0 +1,292 @@
+package repository
+
+import (
+	"log/slog"
+	"mini-erp-backend/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SignedCostSQL is the inventory value counterpart of SignedQuantitySQL
+const SignedCostSQL = "CASE WHEN type = 'OUT' THEN -total_cost ELSE total_cost END"
+
+type CostLayer interface {
+	// Update
+	Apply(tx *gorm.DB, transaction *model.StockTransaction) error
+	BackfillFromProductCost(tx *gorm.DB) (int64, error)
+}
+
+type costLayer struct {
+	logger        *slog.Logger
+	defaultMethod model.CostingMethod
+}
+
+// NewCostLayer creates the costing engine. defaultMethod is used for products without their own costing method.
+func NewCostLayer(logger *slog.Logger, defaultMethod model.CostingMethod) CostLayer {
+	if !defaultMethod.IsValid() {
+		logger.Warn("Invalid default costing method, using FIFO", "costing_method", defaultMethod)
+		defaultMethod = model.CostingFIFO
+	}
+
+	return &costLayer{
+		logger:        logger,
+		defaultMethod: defaultMethod,
+	}
+}
+
+// method returns the costing method of the product, falling back to the global one
+func (r *costLayer) method(product *model.Product) model.CostingMethod {
+	if product.CostingMethod != nil && product.CostingMethod.IsValid() {
+		return *product.CostingMethod
+	}
+	return r.defaultMethod
+}
+
+// Apply costs a ledger row that was just inserted and stores UnitCost and TotalCost on it.
+// Inbound rows add a cost layer at transaction.UnitCost; when the caller did not supply one the current
+// average cost of the warehouse is used, or Product.CostPrice when nothing is on hand.
+// Outbound rows consume layers oldest first by FIFO or moving-average. Quantity not covered by layers
+// (backorder) is costed at Product.CostPrice and kept as a negative layer that later receipts fill first.
+func (r *costLayer) Apply(tx *gorm.DB, transaction *model.StockTransaction) error {
+	signed := transaction.SignedQuantity()
+	if signed == 0 {
+		return nil
+	}
+
+	product := model.Product{}
+	if err := tx.Select("product_id", "cost_price", "costing_method").
+		Where("product_id = ?", transaction.ProductId).
+		First(&product).Error; err != nil {
+		r.logger.Error("Failed to get product for costing", "product_id", transaction.ProductId, "error", err)
+		return err
+	}
+
+	var err error
+	if signed > 0 {
+		err = r.receive(tx, &product, transaction, signed)
+	} else {
+		err = r.issue(tx, &product, transaction, -signed)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Model(&model.StockTransaction{}).
+		Where("stock_transaction_id = ?", transaction.StockTransactionId).
+		Updates(map[string]interface{}{
+			"unit_cost":  transaction.UnitCost,
+			"total_cost": transaction.TotalCost,
+		}).Error; err != nil {
+		r.logger.Error("Failed to store stock transaction cost", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (r *costLayer) receive(tx *gorm.DB, product *model.Product, transaction *model.StockTransaction, quantity int64) error {
+	unitCost := transaction.UnitCost
+	if !transaction.UnitCostSet {
+		current, err := r.currentCost(tx, product, transaction.WarehouseId)
+		if err != nil {
+			return err
+		}
+		unitCost = current
+	}
+
+	backorders := []model.CostLayer{}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ? AND warehouse_id = ? AND remaining_quantity < 0", transaction.ProductId, transaction.WarehouseId).
+		Order("created_at ASC, cost_layer_id ASC").
+		Find(&backorders).Error; err != nil {
+		r.logger.Error("Failed to lock backorder cost layers", "error", err)
+		return err
+	}
+
+	// ของที่รับเข้าชดเชย backorder ก่อน ส่วนนั้นมีมูลค่าเท่ากับที่ตัดออกไปแล้ว มูลค่าใน ledger จึงกลับเป็นศูนย์พร้อมกับจำนวน
+	total := decimal.Zero
+	remaining := quantity
+	for i := range backorders {
+		if remaining == 0 {
+			break
+		}
+		take := min(remaining, -backorders[i].RemainingQuantity)
+		total = total.Add(backorders[i].UnitCost.Mul(decimal.NewFromInt(take)))
+		remaining -= take
+
+		if err := tx.Model(&model.CostLayer{}).
+			Where("cost_layer_id = ?", backorders[i].CostLayerId).
+			Update("remaining_quantity", backorders[i].RemainingQuantity+take).Error; err != nil {
+			r.logger.Error("Failed to fill backorder cost layer", "error", err)
+			return err
+		}
+	}
+
+	if remaining > 0 {
+		layer := &model.CostLayer{
+			ProductId:          transaction.ProductId,
+			WarehouseId:        transaction.WarehouseId,
+			StockTransactionId: &transaction.StockTransactionId,
+			Quantity:           quantity,
+			RemainingQuantity:  remaining,
+			UnitCost:           unitCost,
+			CreatedAt:          transaction.CreatedAt,
+		}
+		if err := tx.Omit(clause.Associations).Create(layer).Error; err != nil {
+			r.logger.Error("Failed to create cost layer", "error", err)
+			return err
+		}
+		total = total.Add(unitCost.Mul(decimal.NewFromInt(remaining)))
+	}
+
+	transaction.TotalCost = total.Round(2)
+	transaction.UnitCost = unitCost
+	if remaining < quantity {
+		transaction.UnitCost = total.Div(decimal.NewFromInt(quantity)).Round(4)
+	}
+	return nil
+}
+
+func (r *costLayer) issue(tx *gorm.DB, product *model.Product, transaction *model.StockTransaction, quantity int64) error {
+	layers := []model.CostLayer{}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ? AND warehouse_id = ? AND remaining_quantity > 0", transaction.ProductId, transaction.WarehouseId).
+		Order("created_at ASC, cost_layer_id ASC").
+		Find(&layers).Error; err != nil {
+		r.logger.Error("Failed to lock cost layers", "error", err)
+		return err
+	}
+
+	method := r.method(product)
+
+	// moving-average: ทุกหน่วยที่เบิกออกใช้ต้นทุนเฉลี่ยของของที่มีอยู่ทั้งหมด
+	average := decimal.Zero
+	if method == model.CostingAverage {
+		average = layerAverage(layers)
+	}
+
+	total := decimal.Zero
+	remaining := quantity
+	for i := range layers {
+		if remaining == 0 {
+			break
+		}
+		take := min(remaining, layers[i].RemainingQuantity)
+
+		unitCost := layers[i].UnitCost
+		if method == model.CostingAverage {
+			unitCost = average
+		}
+		total = total.Add(unitCost.Mul(decimal.NewFromInt(take)))
+
+		layers[i].RemainingQuantity -= take
+		remaining -= take
+	}
+
+	// ของไม่พอ (backorder) คิดต้นทุนส่วนที่ขาดจาก cost price ของสินค้า และเก็บเป็น layer ติดลบให้การรับเข้าครั้งต่อไปมาหักล้าง
+	if remaining > 0 {
+		shortfallCost := decimal.NewFromFloat(product.CostPrice)
+		total = total.Add(shortfallCost.Mul(decimal.NewFromInt(remaining)))
+
+		backorder := &model.CostLayer{
+			ProductId:          transaction.ProductId,
+			WarehouseId:        transaction.WarehouseId,
+			StockTransactionId: &transaction.StockTransactionId,
+			Quantity:           -remaining,
+			RemainingQuantity:  -remaining,
+			UnitCost:           shortfallCost,
+			CreatedAt:          transaction.CreatedAt,
+		}
+		if err := tx.Omit(clause.Associations).Create(backorder).Error; err != nil {
+			r.logger.Error("Failed to create backorder cost layer", "error", err)
+			return err
+		}
+	}
+
+	for _, layer := range layers {
+		updates := map[string]interface{}{"remaining_quantity": layer.RemainingQuantity}
+		// ของที่เหลือถูกตีราคาใหม่ที่ต้นทุนเฉลี่ย เพื่อให้มูลค่าคงเหลือ = จำนวน x ต้นทุนเฉลี่ย
+		if method == model.CostingAverage && layer.RemainingQuantity > 0 {
+			updates["unit_cost"] = average
+		}
+		if err := tx.Model(&model.CostLayer{}).
+			Where("cost_layer_id = ?", layer.CostLayerId).
+			Updates(updates).Error; err != nil {
+			r.logger.Error("Failed to consume cost layer", "error", err)
+			return err
+		}
+	}
+
+	total = total.Round(2)
+	transaction.UnitCost = total.Div(decimal.NewFromInt(quantity)).Round(4)
+	transaction.TotalCost = total
+	// ADJUST ติดลบ เก็บมูลค่าติดลบตาม Quantity
+	if transaction.Type == model.TransactionTypeAdjust {
+		transaction.TotalCost = total.Neg()
+	}
+	return nil
+}
+
+// currentCost is the average unit cost of what is on hand in the warehouse, or the product cost price when nothing is
+func (r *costLayer) currentCost(tx *gorm.DB, product *model.Product, warehouseId uuid.UUID) (decimal.Decimal, error) {
+	layers := []model.CostLayer{}
+	if err := tx.Where("product_id = ? AND warehouse_id = ? AND remaining_quantity > 0", product.ProductId, warehouseId).
+		Find(&layers).Error; err != nil {
+		r.logger.Error("Failed to get cost layers", "error", err)
+		return decimal.Zero, err
+	}
+
+	if len(layers) == 0 {
+		return decimal.NewFromFloat(product.CostPrice), nil
+	}
+	return layerAverage(layers), nil
+}
+
+// layerAverage returns the weighted average unit cost of the remaining quantity of layers
+func layerAverage(layers []model.CostLayer) decimal.Decimal {
+	value := decimal.Zero
+	var quantity int64
+	for _, layer := range layers {
+		value = value.Add(layer.UnitCost.Mul(decimal.NewFromInt(layer.RemainingQuantity)))
+		quantity += layer.RemainingQuantity
+	}
+
+	if quantity == 0 {
+		return decimal.Zero
+	}
+	return value.Div(decimal.NewFromInt(quantity)).Round(4)
+}
+
+// BackfillFromProductCost costs the ledger written before the costing engine existed at the current
+// Product.CostPrice and opens one layer per non-zero stock balance, so valuation starts from the old figures.
+// Negative balances open negative layers that later receipts fill first.
+func (r *costLayer) BackfillFromProductCost(tx *gorm.DB) (int64, error) {
+	if err := tx.Exec(`
+		UPDATE stock_transactions
+		SET unit_cost = products.cost_price,
+			total_cost = ROUND(stock_transactions.quantity * products.cost_price::numeric, 2)
+		FROM products
+		WHERE products.product_id = stock_transactions.product_id
+			AND stock_transactions.unit_cost = 0
+			AND stock_transactions.total_cost = 0
+	`).Error; err != nil {
+		r.logger.Error("Failed to backfill stock transaction costs", "error", err)
+		return 0, err
+	}
+
+	result := tx.Exec(`
+		INSERT INTO cost_layers (product_id, warehouse_id, quantity, remaining_quantity, unit_cost, created_at)
+		SELECT stock_balances.product_id, stock_balances.warehouse_id, stock_balances.quantity, stock_balances.quantity, products.cost_price, ?
+		FROM stock_balances
+		JOIN products ON products.product_id = stock_balances.product_id
+		WHERE stock_balances.quantity <> 0
+	`, time.Now())
+	if result.Error != nil {
+		r.logger.Error("Failed to create opening cost layers", "error", result.Error)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"io"
+	"log/slog"
+	"mini-erp-backend/lib/testdb"
+	"mini-erp-backend/model"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var layerColumns = []string{"cost_layer_id", "remaining_quantity", "unit_cost", "created_at"}
+
+// layerRow is a cost layer as the database returns it, layers are listed oldest first like the ORDER BY in issue and receive
+func layerRow(remaining int64, unitCost string, createdAt time.Time) []driver.Value {
+	return []driver.Value{uuid.NewString(), remaining, unitCost, createdAt}
+}
+
+func TestCostLayerApply(t *testing.T) {
+	opened := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	twoLayers := [][]driver.Value{
+		layerRow(10, "2.0000", opened),
+		layerRow(10, "3.0000", opened.Add(time.Hour)),
+	}
+
+	tests := []struct {
+		name        string
+		method      model.CostingMethod
+		open        [][]driver.Value // layers with remaining_quantity > 0
+		backorders  [][]driver.Value // layers with remaining_quantity < 0
+		transaction model.StockTransaction
+		unitCost    string
+		totalCost   string
+	}{
+		{
+			name:        "FIFO issue takes the oldest layer first",
+			method:      model.CostingFIFO,
+			open:        twoLayers,
+			transaction: model.StockTransaction{Type: model.TransactionTypeOut, Quantity: 15},
+			unitCost:    "2.3333",
+			totalCost:   "35",
+		},
+		{
+			name:        "average issue uses the average of all layers",
+			method:      model.CostingAverage,
+			open:        twoLayers,
+			transaction: model.StockTransaction{Type: model.TransactionTypeOut, Quantity: 15},
+			unitCost:    "2.5",
+			totalCost:   "37.5",
+		},
+		{
+			name:        "FIFO issue beyond the layers costs the backorder at cost price",
+			method:      model.CostingFIFO,
+			open:        twoLayers,
+			transaction: model.StockTransaction{Type: model.TransactionTypeOut, Quantity: 25},
+			unitCost:    "2.8",
+			totalCost:   "70",
+		},
+		{
+			name:        "average issue beyond the layers costs the backorder at cost price",
+			method:      model.CostingAverage,
+			open:        twoLayers,
+			transaction: model.StockTransaction{Type: model.TransactionTypeOut, Quantity: 25},
+			unitCost:    "2.8",
+			totalCost:   "70",
+		},
+		{
+			name:        "issue with nothing on hand is all backorder",
+			method:      model.CostingFIFO,
+			transaction: model.StockTransaction{Type: model.TransactionTypeOut, Quantity: 3},
+			unitCost:    "4",
+			totalCost:   "12",
+		},
+		{
+			name:        "negative adjust keeps the sign on the total",
+			method:      model.CostingFIFO,
+			open:        twoLayers,
+			transaction: model.StockTransaction{Type: model.TransactionTypeAdjust, Quantity: -5},
+			unitCost:    "2",
+			totalCost:   "-10",
+		},
+		{
+			name:        "receipt fills the backorder at its cost before adding a layer",
+			method:      model.CostingFIFO,
+			backorders:  [][]driver.Value{layerRow(-4, "4.0000", opened)},
+			transaction: model.StockTransaction{Type: model.TransactionTypeIn, Quantity: 10, UnitCost: decimal.NewFromInt(6), UnitCostSet: true},
+			unitCost:    "5.2",
+			totalCost:   "52",
+		},
+		{
+			name:        "receipt at an explicit zero cost stays free",
+			method:      model.CostingFIFO,
+			open:        twoLayers,
+			transaction: model.StockTransaction{Type: model.TransactionTypeIn, Quantity: 10, UnitCostSet: true},
+			unitCost:    "0",
+			totalCost:   "0",
+		},
+		{
+			name:        "receipt without a cost uses the average on hand",
+			method:      model.CostingFIFO,
+			open:        twoLayers,
+			transaction: model.StockTransaction{Type: model.TransactionTypeIn, Quantity: 10},
+			unitCost:    "2.5",
+			totalCost:   "25",
+		},
+		{
+			name:        "receipt without a cost and nothing on hand uses cost price",
+			method:      model.CostingFIFO,
+			transaction: model.StockTransaction{Type: model.TransactionTypeIn, Quantity: 10},
+			unitCost:    "4",
+			totalCost:   "40",
+		},
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			productId := uuid.New()
+			db := testdb.Open(t,
+				testdb.Result{
+					Contains: `FROM "products"`,
+					Columns:  []string{"product_id", "cost_price", "costing_method"},
+					Rows:     [][]driver.Value{{productId.String(), 4.0, string(tt.method)}},
+				},
+				testdb.Result{Contains: "remaining_quantity > 0", Columns: layerColumns, Rows: tt.open},
+				testdb.Result{Contains: "remaining_quantity < 0", Columns: layerColumns, Rows: tt.backorders},
+			)
+
+			transaction := tt.transaction
+			transaction.StockTransactionId = uuid.New()
+			transaction.ProductId = productId
+			transaction.WarehouseId = uuid.New()
+			transaction.CreatedAt = opened.AddDate(0, 0, 1)
+
+			if err := NewCostLayer(logger, model.CostingFIFO).Apply(db, &transaction); err != nil {
+				t.Fatalf("Apply error: %v", err)
+			}
+			if !transaction.UnitCost.Equal(decimal.RequireFromString(tt.unitCost)) {
+				t.Errorf("UnitCost = %s, want %s", transaction.UnitCost, tt.unitCost)
+			}
+			if !transaction.TotalCost.Equal(decimal.RequireFromString(tt.totalCost)) {
+				t.Errorf("TotalCost = %s, want %s", transaction.TotalCost, tt.totalCost)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"log/slog"
+	"mini-erp-backend/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CustomerSearchFilters struct {
+	Search string // ค้นหาจากชื่อ ชื่อผู้ติดต่อ อีเมล เบอร์โทร และเลขผู้เสียภาษี
+}
+
+type Customer interface {
+	// Get
+	Search(db *gorm.DB, conditions map[string]interface{}, orderBy string) (*model.Customer, error)
+	SearchWithFilters(db *gorm.DB, filters CustomerSearchFilters, orderBy string) ([]model.Customer, error)
+	SearchWithFiltersAndPagination(db *gorm.DB, filters CustomerSearchFilters, orderBy string, page, pageSize int) ([]model.Customer, int64, error)
+	ExitedByTaxId(db *gorm.DB, taxId string, excludeId *uuid.UUID) (bool, error)
+	HasSalesOrders(db *gorm.DB, customerId uuid.UUID) (bool, error)
+	// Create
+	Create(tx *gorm.DB, customer *model.Customer) error
+	// Update
+	Update(tx *gorm.DB, customer *model.Customer) error
+	// Delete
+	DeleteById(tx *gorm.DB, customerId uuid.UUID) error
+}
+
+type customer struct {
+	logger *slog.Logger
+}
+
+func NewCustomer(logger *slog.Logger) Customer {
+	return &customer{
+		logger: logger,
+	}
+}
+
+func (r *customer) Search(db *gorm.DB, conditions map[string]interface{}, orderBy string) (*model.Customer, error) {
+	customers := []model.Customer{}
+
+	if err := db.Where(conditions).Order(orderBy).Limit(1).Find(&customers).Error; err != nil {
+		r.logger.Error("Failed to get customer", "error", err)
+		return nil, err
+	}
+
+	if len(customers) == 0 {
+		err := gorm.ErrRecordNotFound
+		r.logger.Error("Customer not found", "error", err)
+		return nil, err
+	}
+
+	return &customers[0], nil
+}
+
+func (r *customer) ExitedByTaxId(db *gorm.DB, taxId string, excludeId *uuid.UUID) (bool, error) {
+	var count int64
+	query := db.Model(&model.Customer{}).Where("tax_id = ?", taxId)
+	if excludeId != nil {
+		query = query.Where("customer_id != ?", *excludeId)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		r.logger.Error("Failed to check if customer exists by tax id", "error", err)
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *customer) HasSalesOrders(db *gorm.DB, customerId uuid.UUID) (bool, error) {
+	var count int64
+	if err := db.Model(&model.SalesOrder{}).Where("customer_id = ?", customerId).Count(&count).Error; err != nil {
+		r.logger.Error("Failed to count sales orders of customer", "error", err)
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *customer) Create(tx *gorm.DB, customer *model.Customer) error {
+	if err := tx.Create(customer).Error; err != nil {
+		r.logger.Error("Failed to create customer", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (r *customer) Update(tx *gorm.DB, customer *model.Customer) error {
+	if err := tx.Save(customer).Error; err != nil {
+		r.logger.Error("Failed to update customer", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (r *customer) DeleteById(tx *gorm.DB, customerId uuid.UUID) error {
+	if err := tx.Delete(&model.Customer{}, "customer_id = ?", customerId).Error; err != nil {
+		r.logger.Error("Failed to delete customer", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (r *customer) applyFilters(query *gorm.DB, filters CustomerSearchFilters) *gorm.DB {
+	if filters.Search != "" {
+		searchPattern := "%" + filters.Search + "%"
+		query = query.Where(
+			"name ILIKE ? OR contact_name ILIKE ? OR email ILIKE ? OR phone ILIKE ? OR tax_id ILIKE ?",
+			searchPattern, searchPattern, searchPattern, searchPattern, searchPattern,
+		)
+	}
+	return query
+}
+
+func (r *customer) SearchWithFilters(db *gorm.DB, filters CustomerSearchFilters, orderBy string) ([]model.Customer, error) {
+	customers := []model.Customer{}
+	query := r.applyFilters(db.Model(&model.Customer{}), filters)
+
+	// เรียงลำดับ
+	if orderBy != "" {
+		query = query.Order(orderBy)
+	}
+
+	if err := query.Find(&customers).Error; err != nil {
+		r.logger.Error("Failed to search customers with filters", "error", err)
+		return nil, err
+	}
+
+	return customers, nil
+}
+
+func (r *customer) SearchWithFiltersAndPagination(db *gorm.DB, filters CustomerSearchFilters, orderBy string, page, pageSize int) ([]model.Customer, int64, error) {
+	customers := []model.Customer{}
+	var total int64
+
+	query := r.applyFilters(db.Model(&model.Customer{}), filters)
+
+	// นับจำนวนทั้งหมด
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("Failed to count customers with filters", "error", err)
+		return nil, 0, err
+	}
+
+	// คำนวณ offset
+	offset := (page - 1) * pageSize
+
+	// เรียงลำดับ
+	if orderBy != "" {
+		query = query.Order(orderBy)
+	}
+
+	// ดึงข้อมูลแบบ pagination
+	if err := query.Offset(offset).Limit(pageSize).Find(&customers).Error; err != nil {
+		r.logger.Error("Failed to search customers with filters and pagination", "error", err)
+		return nil, 0, err
+	}
+
+	return customers, total, nil
+}
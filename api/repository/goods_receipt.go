@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"log/slog"
+	"mini-erp-backend/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GoodsReceipt interface {
+	Create(tx *gorm.DB, receipt *model.GoodsReceipt) error
+	SearchesByPurchaseOrderId(db *gorm.DB, poId uuid.UUID) ([]*model.GoodsReceipt, error)
+	SearchLineByStockTransactionId(db *gorm.DB, stockTransactionId uuid.UUID) (*model.GoodsReceiptLine, error)
+}
+
+type goodsReceipt struct {
+	logger *slog.Logger
+}
+
+func NewGoodsReceipt(logger *slog.Logger) GoodsReceipt {
+	return &goodsReceipt{
+		logger: logger,
+	}
+}
+
+// Create inserts the receipt header and its lines
+func (r *goodsReceipt) Create(tx *gorm.DB, receipt *model.GoodsReceipt) error {
+	if err := tx.Omit(clause.Associations).Create(receipt).Error; err != nil {
+		r.logger.Error("Failed to create goods receipt", "error", err)
+		return err
+	}
+
+	for i := range receipt.Lines {
+		receipt.Lines[i].GoodsReceiptId = receipt.GoodsReceiptId
+		if err := tx.Omit(clause.Associations).Create(&receipt.Lines[i]).Error; err != nil {
+			r.logger.Error("Failed to create goods receipt line", "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *goodsReceipt) SearchesByPurchaseOrderId(db *gorm.DB, poId uuid.UUID) ([]*model.GoodsReceipt, error) {
+	receipts := []*model.GoodsReceipt{}
+	if err := db.Preload("Lines").
+		Where("purchase_order_id = ?", poId).
+		Order("received_at ASC").
+		Find(&receipts).Error; err != nil {
+		r.logger.Error("Failed to search goods receipts", "po_id", poId, "error", err)
+		return nil, err
+	}
+	return receipts, nil
+}
+
+// SearchLineByStockTransactionId returns the receipt line (with its receipt) that booked the given stock IN
+func (r *goodsReceipt) SearchLineByStockTransactionId(db *gorm.DB, stockTransactionId uuid.UUID) (*model.GoodsReceiptLine, error) {
+	line := model.GoodsReceiptLine{}
+	if err := db.Preload("GoodsReceipt").
+		Where("stock_transaction_id = ?", stockTransactionId).
+		First(&line).Error; err != nil {
+		r.logger.Error("Failed to search goods receipt line", "stock_transaction_id", stockTransactionId, "error", err)
+		return nil, err
+	}
+	return &line, nil
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"database/sql"
+	"log/slog"
+	"mini-erp-backend/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type LoginAttemptSearchFilters struct {
+	Username string
+	UserId   *uuid.UUID
+	IP       string
+	Result   string
+	FromDate *time.Time
+	ToDate   *time.Time
+}
+
+// LoginFailures สรุปจำนวน login ที่ผิดและเวลาที่ผิดล่าสุดของ username หรือ IP หนึ่ง
+type LoginFailures struct {
+	Count       int64
+	LastFailure *time.Time
+}
+
+type LoginAttempt interface {
+	// Get
+	FailuresByUsername(db *gorm.DB, username string, since time.Time) (*LoginFailures, error)
+	FailuresByIP(db *gorm.DB, ip string, since time.Time) (*LoginFailures, error)
+	SearchWithFiltersAndPagination(db *gorm.DB, filters LoginAttemptSearchFilters, orderBy string, page, pageSize int) ([]model.LoginAttempt, int64, error)
+	// Create
+	Create(db *gorm.DB, attempt *model.LoginAttempt) error
+	// Lock
+	LockUsername(tx *gorm.DB, username string) error
+}
+
+type loginAttempt struct {
+	logger *slog.Logger
+}
+
+func NewLoginAttempt(logger *slog.Logger) LoginAttempt {
+	return &loginAttempt{logger: logger}
+}
+
+func (r *loginAttempt) Create(db *gorm.DB, attempt *model.LoginAttempt) error {
+	if err := db.Create(attempt).Error; err != nil {
+		r.logger.Error("Failed to create login attempt", "username", attempt.Username, "error", err)
+		return err
+	}
+	return nil
+}
+
+// LockUsername takes a transaction-level advisory lock on the username, so the failures of concurrent logins
+// for the same username are counted and recorded one after the other
+func (r *loginAttempt) LockUsername(tx *gorm.DB, username string) error {
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "login:"+username).Error; err != nil {
+		r.logger.Error("Failed to lock username for login", "username", username, "error", err)
+		return err
+	}
+	return nil
+}
+
+// FailuresByUsername counts the failed logins of a username since the later of since and its last successful login
+func (r *loginAttempt) FailuresByUsername(db *gorm.DB, username string, since time.Time) (*LoginFailures, error) {
+	var lastSuccess sql.NullTime
+	if err := db.Model(&model.LoginAttempt{}).
+		Select("MAX(created_at)").
+		Where("username = ? AND result = ? AND created_at >= ?", username, model.LoginResultSuccess, since).
+		Row().Scan(&lastSuccess); err != nil {
+		r.logger.Error("Failed to find last successful login", "username", username, "error", err)
+		return nil, err
+	}
+
+	if lastSuccess.Valid {
+		since = lastSuccess.Time
+	}
+
+	return r.failures(db, "username", username, since)
+}
+
+// FailuresByIP counts the failed logins from an IP since since, a success from the same IP does not reset it
+func (r *loginAttempt) FailuresByIP(db *gorm.DB, ip string, since time.Time) (*LoginFailures, error) {
+	return r.failures(db, "ip", ip, since)
+}
+
+func (r *loginAttempt) failures(db *gorm.DB, column string, value string, since time.Time) (*LoginFailures, error) {
+	var row struct {
+		Count       int64
+		LastFailure *time.Time
+	}
+
+	if err := db.Model(&model.LoginAttempt{}).
+		Select("COUNT(*) AS count, MAX(created_at) AS last_failure").
+		Where(column+" = ? AND result = ? AND created_at > ?", value, model.LoginResultInvalidCredentials, since).
+		Scan(&row).Error; err != nil {
+		r.logger.Error("Failed to count failed logins", column, value, "error", err)
+		return nil, err
+	}
+
+	return &LoginFailures{Count: row.Count, LastFailure: row.LastFailure}, nil
+}
+
+func (r *loginAttempt) applyFilters(query *gorm.DB, filters LoginAttemptSearchFilters) *gorm.DB {
+	if filters.Username != "" {
+		query = query.Where("username = ?", filters.Username)
+	}
+
+	if filters.UserId != nil {
+		query = query.Where("user_id = ?", *filters.UserId)
+	}
+
+	if filters.IP != "" {
+		query = query.Where("ip = ?", filters.IP)
+	}
+
+	if filters.Result != "" {
+		query = query.Where("result = ?", filters.Result)
+	}
+
+	if filters.FromDate != nil {
+		query = query.Where("created_at >= ?", *filters.FromDate)
+	}
+
+	if filters.ToDate != nil {
+		query = query.Where("created_at <= ?", *filters.ToDate)
+	}
+
+	return query
+}
+
+func (r *loginAttempt) SearchWithFiltersAndPagination(db *gorm.DB, filters LoginAttemptSearchFilters, orderBy string, page, pageSize int) ([]model.LoginAttempt, int64, error) {
+	attempts := []model.LoginAttempt{}
+	var total int64
+
+	query := r.applyFilters(db.Model(&model.LoginAttempt{}), filters)
+
+	// นับจำนวนทั้งหมด
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("Failed to count login attempts with filters", slog.String("error", err.Error()))
+		return nil, 0, err
+	}
+
+	// คำนวณ offset
+	offset := (page - 1) * pageSize
+
+	// เรียงลำดับ
+	if orderBy != "" {
+		query = query.Order(orderBy)
+	}
+
+	// ดึงข้อมูลแบบ pagination
+	if err := query.Offset(offset).Limit(pageSize).Find(&attempts).Error; err != nil {
+		r.logger.Error("Failed to search login attempts with filters and pagination", slog.String("error", err.Error()))
+		return nil, 0, err
+	}
+
+	return attempts, total, nil
+}
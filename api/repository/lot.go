@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"mini-erp-backend/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrLotExpiryMismatch is returned when a lot number is received again with a different expiry date
+	ErrLotExpiryMismatch = errors.New("lot already exists with a different expiry date")
+	// ErrLotNumberRequired is returned when an expiry date is given without a lot number
+	ErrLotNumberRequired = errors.New("lot_number is required when expiry_date is given")
+	// ErrLotExpired is returned when stock would be sold or shipped from a lot past its expiry date
+	ErrLotExpired = errors.New("lot has expired")
+)
+
+// LotBalance is the stock on hand of one lot in one warehouse
+type LotBalance struct {
+	LotId       uuid.UUID  `json:"lot_id"`
+	LotNumber   string     `json:"lot_number"`
+	ExpiryDate  *time.Time `json:"expiry_date"`
+	WarehouseId uuid.UUID  `json:"warehouse_id"`
+	Quantity    int64      `json:"quantity"`
+}
+
+// LotAllocation is the part of an outgoing quantity taken from one lot.
+// LotId is nil for stock that was received without a lot.
+// Serials are the units moved, set only for serial tracked products (see SerialNumber.AllocateByLot).
+type LotAllocation struct {
+	LotId    *uuid.UUID
+	Quantity int64
+	Serials  []string
+}
+
+type Lot interface {
+	// Get
+	Balances(db *gorm.DB, productId uuid.UUID, warehouseId *uuid.UUID) ([]LotBalance, error)
+	AllocateFEFO(tx *gorm.DB, warehouseId, productId uuid.UUID, quantity int64) ([]LotAllocation, error)
+	AllocateFEFOWithExpired(tx *gorm.DB, warehouseId, productId uuid.UUID, quantity int64) ([]LotAllocation, error)
+	RejectExpired(tx *gorm.DB, allocations []LotAllocation) error
+	// Create
+	Resolve(tx *gorm.DB, productId uuid.UUID, lotNumber *string, expiryDate *time.Time) (*model.Lot, error)
+}
+
+type lot struct {
+	logger *slog.Logger
+}
+
+func NewLot(logger *slog.Logger) Lot {
+	return &lot{
+		logger: logger,
+	}
+}
+
+// Balances returns every lot of a product that still has stock, earliest expiry first
+func (r *lot) Balances(db *gorm.DB, productId uuid.UUID, warehouseId *uuid.UUID) ([]LotBalance, error) {
+	balances := []LotBalance{}
+
+	query := db.Table("stock_transactions").
+		Select("lots.lot_id, lots.lot_number, lots.expiry_date, stock_transactions.warehouse_id, SUM("+SignedQuantitySQL+") as quantity").
+		Joins("JOIN lots ON lots.lot_id = stock_transactions.lot_id").
+		Where("stock_transactions.product_id = ?", productId)
+	if warehouseId != nil {
+		query = query.Where("stock_transactions.warehouse_id = ?", *warehouseId)
+	}
+
+	if err := query.
+		Group("lots.lot_id, lots.lot_number, lots.expiry_date, lots.created_at, stock_transactions.warehouse_id").
+		Having("SUM(" + SignedQuantitySQL + ") <> 0").
+		Order("lots.expiry_date ASC NULLS LAST, lots.created_at ASC").
+		Scan(&balances).Error; err != nil {
+		r.logger.Error("Failed to get lot balances", "product_id", productId, "error", err)
+		return nil, err
+	}
+
+	return balances, nil
+}
+
+// AllocateFEFO splits quantity over the sellable lots in the warehouse, first expiry first out.
+// Lots past their expiry date are never allocated; stock received without a lot is taken after the lots and
+// whatever neither can cover (backorder) is returned as one allocation without a lot. When the quantity can only
+// be covered by dipping into expired lots ErrLotExpired is returned, expired stock leaves through an adjustment.
+// The caller must hold the stock balance lock of the warehouse/product so the lot balances cannot change underneath it.
+func (r *lot) AllocateFEFO(tx *gorm.DB, warehouseId, productId uuid.UUID, quantity int64) ([]LotAllocation, error) {
+	return r.allocate(tx, warehouseId, productId, quantity, false)
+}
+
+// AllocateFEFOWithExpired is AllocateFEFO including expired lots, which come first because they expire first.
+// It is for movements that do not sell the stock: write-offs, count variances and transfers.
+func (r *lot) AllocateFEFOWithExpired(tx *gorm.DB, warehouseId, productId uuid.UUID, quantity int64) ([]LotAllocation, error) {
+	return r.allocate(tx, warehouseId, productId, quantity, true)
+}
+
+func (r *lot) allocate(tx *gorm.DB, warehouseId, productId uuid.UUID, quantity int64, withExpired bool) ([]LotAllocation, error) {
+	balances, err := r.Balances(tx, productId, &warehouseId)
+	if err != nil {
+		return nil, err
+	}
+
+	today := lotToday()
+	allocations := []LotAllocation{}
+	remaining := quantity
+	var expired int64
+	for _, balance := range balances {
+		if balance.Quantity <= 0 {
+			continue
+		}
+		if !withExpired && lotExpired(balance.ExpiryDate, today) {
+			expired += balance.Quantity
+			continue
+		}
+		if remaining == 0 {
+			break
+		}
+
+		take := min(balance.Quantity, remaining)
+		allocations = append(allocations, LotAllocation{LotId: &balance.LotId, Quantity: take})
+		remaining -= take
+	}
+
+	if remaining > 0 && expired > 0 {
+		var unlotted int64
+		if err := tx.Table("stock_transactions").
+			Select("COALESCE(SUM("+SignedQuantitySQL+"), 0)").
+			Where("product_id = ? AND warehouse_id = ? AND lot_id IS NULL", productId, warehouseId).
+			Scan(&unlotted).Error; err != nil {
+			r.logger.Error("Failed to get stock without lot", "product_id", productId, "error", err)
+			return nil, err
+		}
+		if remaining > unlotted {
+			return nil, fmt.Errorf("%w: %d of the requested quantity is only available in expired lots", ErrLotExpired, min(remaining-max(unlotted, 0), expired))
+		}
+	}
+
+	if remaining > 0 {
+		allocations = append(allocations, LotAllocation{Quantity: remaining})
+	}
+
+	return allocations, nil
+}
+
+// RejectExpired returns ErrLotExpired when an allocation takes stock from a lot past its expiry date,
+// for serial tracked products whose lots come from the serials chosen rather than from AllocateFEFO
+func (r *lot) RejectExpired(tx *gorm.DB, allocations []LotAllocation) error {
+	lotIds := []uuid.UUID{}
+	for _, allocation := range allocations {
+		if allocation.LotId != nil {
+			lotIds = append(lotIds, *allocation.LotId)
+		}
+	}
+	if len(lotIds) == 0 {
+		return nil
+	}
+
+	lots := []model.Lot{}
+	if err := tx.Where("lot_id IN ?", lotIds).Find(&lots).Error; err != nil {
+		r.logger.Error("Failed to get lots", "error", err)
+		return err
+	}
+
+	today := lotToday()
+	for _, lot := range lots {
+		if lotExpired(lot.ExpiryDate, today) {
+			return fmt.Errorf("%w: %s", ErrLotExpired, lot.LotNumber)
+		}
+	}
+	return nil
+}
+
+// lotToday is today's date the way expiry dates are stored (a date at midnight UTC)
+func lotToday() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// lotExpired reports whether a lot is past its expiry date, it can still be sold on the expiry date itself
+func lotExpired(expiryDate *time.Time, today time.Time) bool {
+	return expiryDate != nil && expiryDate.Before(today)
+}
+
+// Resolve returns the lot of a product by number, creating it on first receipt.
+// It returns nil when no lot number is given. An existing lot keeps its expiry date; a different one is rejected.
+func (r *lot) Resolve(tx *gorm.DB, productId uuid.UUID, lotNumber *string, expiryDate *time.Time) (*model.Lot, error) {
+	if lotNumber == nil || *lotNumber == "" {
+		if expiryDate != nil {
+			return nil, ErrLotNumberRequired
+		}
+		return nil, nil
+	}
+
+	if expiryDate != nil {
+		date := time.Date(expiryDate.Year(), expiryDate.Month(), expiryDate.Day(), 0, 0, 0, 0, time.UTC)
+		expiryDate = &date
+	}
+
+	lots := []model.Lot{}
+	if err := tx.Where("product_id = ? AND lot_number = ?", productId, *lotNumber).Limit(1).Find(&lots).Error; err != nil {
+		r.logger.Error("Failed to get lot", "lot_number", *lotNumber, "error", err)
+		return nil, err
+	}
+
+	if len(lots) > 0 {
+		existing := lots[0]
+		if expiryDate != nil && (existing.ExpiryDate == nil || !existing.ExpiryDate.Equal(*expiryDate)) {
+			return nil, fmt.Errorf("%w: %s", ErrLotExpiryMismatch, *lotNumber)
+		}
+		return &existing, nil
+	}
+
+	lot := &model.Lot{
+		LotId:      uuid.New(),
+		ProductId:  productId,
+		LotNumber:  *lotNumber,
+		ExpiryDate: expiryDate,
+		CreatedAt:  time.Now(),
+	}
+	if err := tx.Omit("Product").Create(lot).Error; err != nil {
+		r.logger.Error("Failed to create lot", "lot_number", *lotNumber, "error", err)
+		return nil, err
+	}
+
+	return lot, nil
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"log/slog"
+	"mini-erp-backend/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Mfa interface {
+	// Recovery codes
+	ReplaceRecoveryCodes(tx *gorm.DB, userId uuid.UUID, codes []model.MfaRecoveryCode) error
+	DeleteRecoveryCodes(tx *gorm.DB, userId uuid.UUID) error
+	UseRecoveryCode(tx *gorm.DB, userId uuid.UUID, codeHash string) (bool, error)
+	// Challenges
+	CreateChallenge(tx *gorm.DB, challenge *model.MfaChallenge) error
+	LockChallengeByToken(tx *gorm.DB, tokenHash string) (*model.MfaChallenge, error)
+	IncrementChallengeAttempts(tx *gorm.DB, challengeId uuid.UUID) error
+	ConsumeChallenge(tx *gorm.DB, challengeId uuid.UUID) error
+}
+
+type mfa struct {
+	logger *slog.Logger
+}
+
+func NewMfa(logger *slog.Logger) Mfa {
+	return &mfa{logger: logger}
+}
+
+// ReplaceRecoveryCodes drops every recovery code of the user, used or not, and stores the new set
+func (r *mfa) ReplaceRecoveryCodes(tx *gorm.DB, userId uuid.UUID, codes []model.MfaRecoveryCode) error {
+	if err := r.DeleteRecoveryCodes(tx, userId); err != nil {
+		return err
+	}
+
+	if len(codes) == 0 {
+		return nil
+	}
+
+	if err := tx.Create(&codes).Error; err != nil {
+		r.logger.Error("failed to create mfa recovery codes", "user_id", userId, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *mfa) DeleteRecoveryCodes(tx *gorm.DB, userId uuid.UUID) error {
+	if err := tx.Where("user_id = ?", userId).Delete(&model.MfaRecoveryCode{}).Error; err != nil {
+		r.logger.Error("failed to delete mfa recovery codes", "user_id", userId, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// UseRecoveryCode marks an unused recovery code as used, it returns false when no such code exists
+func (r *mfa) UseRecoveryCode(tx *gorm.DB, userId uuid.UUID, codeHash string) (bool, error) {
+	result := tx.Model(&model.MfaRecoveryCode{}).
+		Where("user_id = ? AND code = ? AND used_at IS NULL", userId, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		r.logger.Error("failed to use mfa recovery code", "user_id", userId, "error", result.Error)
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *mfa) CreateChallenge(tx *gorm.DB, challenge *model.MfaChallenge) error {
+	if err := tx.Create(challenge).Error; err != nil {
+		r.logger.Error("failed to create mfa challenge", "user_id", challenge.UserId, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// LockChallengeByToken returns the challenge by its token hash and locks it until the transaction ends
+func (r *mfa) LockChallengeByToken(tx *gorm.DB, tokenHash string) (*model.MfaChallenge, error) {
+	var challenge model.MfaChallenge
+	if err := tx.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token = ?", tokenHash).
+		First(&challenge).Error; err != nil {
+		r.logger.Error("failed to find mfa challenge", "error", err)
+		return nil, err
+	}
+
+	return &challenge, nil
+}
+
+func (r *mfa) IncrementChallengeAttempts(tx *gorm.DB, challengeId uuid.UUID) error {
+	if err := tx.Model(&model.MfaChallenge{}).
+		Where("challenge_id = ?", challengeId).
+		Update("attempts", gorm.Expr("attempts + 1")).Error; err != nil {
+		r.logger.Error("failed to count mfa challenge attempt", "challenge_id", challengeId, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *mfa) ConsumeChallenge(tx *gorm.DB, challengeId uuid.UUID) error {
+	if err := tx.Model(&model.MfaChallenge{}).
+		Where("challenge_id = ?", challengeId).
+		Update("used_at", time.Now()).Error; err != nil {
+		r.logger.Error("failed to consume mfa challenge", "challenge_id", challengeId, "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"log/slog"
+	"mini-erp-backend/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PasswordResetToken interface {
+	Create(tx *gorm.DB, token *model.PasswordResetToken) error
+	LockByToken(tx *gorm.DB, tokenHash string) (*model.PasswordResetToken, error)
+	MarkUsed(tx *gorm.DB, resetId uuid.UUID) error
+	DeleteUnusedByUserId(tx *gorm.DB, userId uuid.UUID) error
+}
+
+type passwordResetToken struct {
+	logger *slog.Logger
+}
+
+func NewPasswordResetToken(logger *slog.Logger) PasswordResetToken {
+	return &passwordResetToken{logger: logger}
+}
+
+func (r *passwordResetToken) Create(tx *gorm.DB, token *model.PasswordResetToken) error {
+	if err := tx.Create(token).Error; err != nil {
+		r.logger.Error("failed to create password reset token", "user_id", token.UserId, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// LockByToken returns the reset token row by its hash and locks it until the transaction ends,
+// so the same token cannot be redeemed twice concurrently
+func (r *passwordResetToken) LockByToken(tx *gorm.DB, tokenHash string) (*model.PasswordResetToken, error) {
+	var token model.PasswordResetToken
+	if err := tx.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token = ?", tokenHash).
+		First(&token).Error; err != nil {
+		r.logger.Error("failed to find password reset token", "error", err)
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (r *passwordResetToken) MarkUsed(tx *gorm.DB, resetId uuid.UUID) error {
+	if err := tx.Model(&model.PasswordResetToken{}).
+		Where("reset_id = ?", resetId).
+		Update("used_at", time.Now()).Error; err != nil {
+		r.logger.Error("failed to mark password reset token as used", "reset_id", resetId, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteUnusedByUserId drops the outstanding tokens of a user, only the latest reset stays valid
+func (r *passwordResetToken) DeleteUnusedByUserId(tx *gorm.DB, userId uuid.UUID) error {
+	if err := tx.Where("user_id = ? AND used_at IS NULL", userId).
+		Delete(&model.PasswordResetToken{}).Error; err != nil {
+		r.logger.Error("failed to delete unused password reset tokens", "user_id", userId, "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -6,18 +6,23 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PurchaseOrder interface {
 	Create(tx *gorm.DB, po *model.PurchaseOrder) error
 	Update(tx *gorm.DB, po *model.PurchaseOrder) error
 	UpdateStatus(tx *gorm.DB, poId uuid.UUID, status model.PurchaseOrderStatus) error
+	LockById(tx *gorm.DB, poId uuid.UUID) (*model.PurchaseOrder, error)
 	Search(db *gorm.DB, conditions map[string]interface{}, orderBy string) (*model.PurchaseOrder, error)
 	Searches(db *gorm.DB, conditions map[string]interface{}, orderBy string) ([]*model.PurchaseOrder, error)
 
 	CreateItem(tx *gorm.DB, item *model.PurchaseOrderItem) error
 	DeleteItemsByPurchaseOrderId(tx *gorm.DB, poId uuid.UUID) error
 	SearchItemsByPurchaseOrderId(db *gorm.DB, poId uuid.UUID) ([]*model.PurchaseOrderItem, error)
+	AddItemReceivedQuantity(tx *gorm.DB, itemId uuid.UUID, quantity uint64) error
+	BackfillReceivedQuantity(tx *gorm.DB) (int64, error)
+	BackfillTotals(tx *gorm.DB) (int64, error)
 }
 
 type purchaseOrder struct {
@@ -61,11 +66,27 @@ func (r *purchaseOrder) UpdateStatus(tx *gorm.DB, poId uuid.UUID, status model.P
 	return nil
 }
 
+// LockById loads the order header with SELECT ... FOR UPDATE so concurrent status changes are serialized
+func (r *purchaseOrder) LockById(tx *gorm.DB, poId uuid.UUID) (*model.PurchaseOrder, error) {
+	po := model.PurchaseOrder{}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("purchase_order_id = ?", poId).
+		First(&po).Error; err != nil {
+		r.logger.Error("Failed to lock purchase order", "po_id", poId, "error", err)
+		return nil, err
+	}
+	return &po, nil
+}
+
 func (r *purchaseOrder) Search(db *gorm.DB, conditions map[string]interface{}, orderBy string) (*model.PurchaseOrder, error) {
 	pos := []model.PurchaseOrder{}
 
 	query := db.Preload("PurchaseOrderItem").
 		Preload("PurchaseOrderItem.Product").
+		Preload("GoodsReceipts", func(db *gorm.DB) *gorm.DB {
+			return db.Order("received_at ASC")
+		}).
+		Preload("GoodsReceipts.Lines").
 		Preload("Supplier").
 		Where(conditions)
 
@@ -119,10 +140,55 @@ func (r *purchaseOrder) DeleteItemsByPurchaseOrderId(tx *gorm.DB, poId uuid.UUID
 
 func (r *purchaseOrder) SearchItemsByPurchaseOrderId(db *gorm.DB, poId uuid.UUID) ([]*model.PurchaseOrderItem, error) {
 	items := []*model.PurchaseOrderItem{}
-	if err := db.Where("purchase_order_id = ?", poId).
+	if err := db.Preload("Product").Where("purchase_order_id = ?", poId).
 		Find(&items).Error; err != nil {
 		r.logger.Error("Failed to search purchase order items", "error", err)
 		return nil, err
 	}
 	return items, nil
 }
+
+func (r *purchaseOrder) AddItemReceivedQuantity(tx *gorm.DB, itemId uuid.UUID, quantity uint64) error {
+	if err := tx.Model(&model.PurchaseOrderItem{}).
+		Where("purchase_order_item_id = ?", itemId).
+		Update("received_quantity", gorm.Expr("received_quantity + ?", quantity)).Error; err != nil {
+		r.logger.Error("Failed to update received quantity", "item_id", itemId, "error", err)
+		return err
+	}
+	return nil
+}
+
+// BackfillReceivedQuantity marks every item of orders received before goods receipts existed as fully received
+func (r *purchaseOrder) BackfillReceivedQuantity(tx *gorm.DB) (int64, error) {
+	result := tx.Model(&model.PurchaseOrderItem{}).
+		Where("received_quantity = 0 AND purchase_order_id IN (?)",
+			tx.Model(&model.PurchaseOrder{}).Select("purchase_order_id").Where("status = ?", model.Received)).
+		Update("received_quantity", gorm.Expr("quantity"))
+	if result.Error != nil {
+		r.logger.Error("Failed to backfill received quantity", "error", result.Error)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// BackfillTotals fills subtotal and total amount of orders created before totals were stored.
+// Old orders carry no discount or tax so both equal the sum of their items.
+func (r *purchaseOrder) BackfillTotals(tx *gorm.DB) (int64, error) {
+	itemsTotal := gorm.Expr(`(
+		SELECT COALESCE(SUM(ROUND(CAST(purchase_order_items.price AS numeric) * purchase_order_items.quantity, 2)), 0)
+		FROM purchase_order_items
+		WHERE purchase_order_items.purchase_order_id = purchase_orders.purchase_order_id
+	)`)
+
+	result := tx.Model(&model.PurchaseOrder{}).
+		Where("total_amount = 0").
+		Updates(map[string]interface{}{
+			"subtotal":     itemsTotal,
+			"total_amount": itemsTotal,
+		})
+	if result.Error != nil {
+		r.logger.Error("Failed to backfill purchase order totals", "error", result.Error)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
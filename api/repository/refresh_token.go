@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"log/slog"
+	"mini-erp-backend/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RefreshToken interface {
+	Create(tx *gorm.DB, refreshToken *model.RefreshToken) error
+	LockByToken(tx *gorm.DB, tokenHash string) (*model.RefreshToken, error)
+	Consume(tx *gorm.DB, refreshId uuid.UUID, replacedBy uuid.UUID) error
+	RevokeBySessionId(tx *gorm.DB, sessionId uuid.UUID) error
+}
+
+type refreshToken struct {
+	logger *slog.Logger
+}
+
+func NewRefreshToken(logger *slog.Logger) RefreshToken {
+	return &refreshToken{logger: logger}
+}
+
+func (r *refreshToken) Create(tx *gorm.DB, refreshToken *model.RefreshToken) error {
+	if err := tx.Create(refreshToken).Error; err != nil {
+		r.logger.Error("failed to create refresh token", "session_id", refreshToken.SessionId, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// LockByToken returns the refresh token row by its hash and locks it until the transaction ends,
+// so two concurrent refreshes with the same token cannot both rotate it
+func (r *refreshToken) LockByToken(tx *gorm.DB, tokenHash string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	if err := tx.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token = ?", tokenHash).
+		First(&token).Error; err != nil {
+		r.logger.Error("failed to find refresh token", "error", err)
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (r *refreshToken) Consume(tx *gorm.DB, refreshId uuid.UUID, replacedBy uuid.UUID) error {
+	if err := tx.Model(&model.RefreshToken{}).
+		Where("refresh_id = ?", refreshId).
+		Updates(map[string]interface{}{
+			"consumed_at": time.Now(),
+			"replaced_by": replacedBy,
+		}).Error; err != nil {
+		r.logger.Error("failed to consume refresh token", "refresh_id", refreshId, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *refreshToken) RevokeBySessionId(tx *gorm.DB, sessionId uuid.UUID) error {
+	if err := tx.Model(&model.RefreshToken{}).
+		Where("session_id = ? AND revoked = ?", sessionId, false).
+		Update("revoked", true).Error; err != nil {
+		r.logger.Error("failed to revoke refresh tokens of session", "session_id", sessionId, "error", err)
+		return err
+	}
+
+	return nil
+}
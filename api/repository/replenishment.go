@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"log/slog"
+	"math"
+	"mini-erp-backend/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReplenishmentUsageDays is the look-back window used to estimate the daily usage of a product
+const ReplenishmentUsageDays = 30
+
+type ReplenishmentSearchFilters struct {
+	SupplierIds []uuid.UUID
+}
+
+// ReplenishmentLine is a product whose projected stock is below its reorder point
+type ReplenishmentLine struct {
+	ProductId           uuid.UUID  `json:"product_id"`
+	ProductCode         string     `json:"product_code"`
+	ProductName         string     `json:"product_name"`
+	Unit                string     `json:"unit"`
+	PreferredSupplierId *uuid.UUID `json:"preferred_supplier_id"`
+	SupplierName        *string    `json:"supplier_name"`
+	MinStock            int64      `json:"min_stock"`
+	ReorderQuantity     int64      `json:"reorder_quantity"`
+	LeadTimeDays        int64      `json:"lead_time_days"`
+	StockOnHand         int64      `json:"stock_on_hand"`
+	// OnOrder is the quantity outstanding on DRAFT, CONFIRMED and PARTIALLY_RECEIVED purchase orders
+	OnOrder int64 `json:"on_order"`
+	// DailyUsage is the average quantity issued per day over the last ReplenishmentUsageDays days
+	DailyUsage float64 `json:"daily_usage"`
+	// ReorderPoint is MinStock plus the usage expected during the lead time
+	ReorderPoint      int64     `json:"reorder_point"`
+	SuggestedQuantity int64     `json:"suggested_quantity"`
+	ExpectedArrival   time.Time `json:"expected_arrival"`
+}
+
+type Replenishment interface {
+	// Get
+	SearchSuggestions(db *gorm.DB, filters ReplenishmentSearchFilters) ([]ReplenishmentLine, error)
+}
+
+type replenishment struct {
+	logger *slog.Logger
+}
+
+func NewReplenishment(logger *slog.Logger) Replenishment {
+	return &replenishment{
+		logger: logger,
+	}
+}
+
+// SearchSuggestions returns every product whose stock on hand plus open orders, summed over all warehouses,
+// is below its reorder point, with the quantity to order rounded to the preferred supplier's minimum order
+// quantity and pack size. The supplier's lead time overrides the product's. Products without a preferred supplier are included
+// so they still show up as alerts, but cannot be ordered automatically.
+func (r *replenishment) SearchSuggestions(db *gorm.DB, filters ReplenishmentSearchFilters) ([]ReplenishmentLine, error) {
+	now := time.Now()
+
+	onHand := db.Model(&model.StockBalance{}).
+		Select("product_id, SUM(quantity) AS quantity").
+		Group("product_id")
+
+	onOrder := db.Model(&model.PurchaseOrderItem{}).
+		Select("purchase_order_items.product_id, SUM(GREATEST(purchase_order_items.quantity - purchase_order_items.received_quantity, 0)) AS quantity").
+		Joins("JOIN purchase_orders ON purchase_orders.purchase_order_id = purchase_order_items.purchase_order_id").
+		Where("purchase_orders.status IN ?", []model.PurchaseOrderStatus{model.Draft, model.Confirmed, model.PartiallyReceived}).
+		Group("purchase_order_items.product_id")
+
+	// การโอนย้ายระหว่างคลังไม่ใช่การใช้ของ จึงไม่นับ OUT ที่อ้างอิง stock transfer
+	usage := db.Model(&model.StockTransaction{}).
+		Select("product_id, SUM(quantity) AS quantity").
+		Where("type = ? AND created_at >= ?", model.TransactionTypeOut, now.AddDate(0, 0, -ReplenishmentUsageDays)).
+		Where("NOT EXISTS (SELECT 1 FROM stock_transfers WHERE stock_transfers.stock_transfer_id = stock_transactions.reference_id)").
+		Group("product_id")
+
+	type row struct {
+		ProductId           uuid.UUID
+		ProductCode         string
+		Name                string
+		Unit                string
+		PreferredSupplierId *uuid.UUID
+		SupplierName        *string
+		MinStock            int64
+		ReorderQuantity     int64
+		LeadTimeDays        int64
+		MinOrderQuantity    uint64
+		PackSize            uint64
+		OnHand              int64
+		OnOrder             int64
+		Issued              int64
+	}
+
+	query := db.Table("products").
+		Select(`products.product_id, products.product_code, products.name, products.unit,
+			products.preferred_supplier_id, suppliers.name AS supplier_name,
+			products.min_stock, products.reorder_quantity,
+			COALESCE(supplier_products.lead_time_days, products.lead_time_days) AS lead_time_days,
+			COALESCE(supplier_products.min_order_quantity, 1) AS min_order_quantity,
+			COALESCE(supplier_products.pack_size, 1) AS pack_size,
+			COALESCE(on_hand.quantity, 0) AS on_hand,
+			COALESCE(on_order.quantity, 0) AS on_order,
+			COALESCE(recent_usage.quantity, 0) AS issued`).
+		Joins("LEFT JOIN suppliers ON suppliers.supplier_id = products.preferred_supplier_id").
+		Joins("LEFT JOIN supplier_products ON supplier_products.supplier_id = products.preferred_supplier_id AND supplier_products.product_id = products.product_id").
+		Joins("LEFT JOIN (?) AS on_hand ON on_hand.product_id = products.product_id", onHand).
+		Joins("LEFT JOIN (?) AS on_order ON on_order.product_id = products.product_id", onOrder).
+		Joins("LEFT JOIN (?) AS recent_usage ON recent_usage.product_id = products.product_id", usage)
+
+	if len(filters.SupplierIds) > 0 {
+		query = query.Where("products.preferred_supplier_id IN ?", filters.SupplierIds)
+	}
+
+	rows := []row{}
+	if err := query.Order("products.product_code ASC").Scan(&rows).Error; err != nil {
+		r.logger.Error("Failed to search replenishment suggestions", "error", err)
+		return nil, err
+	}
+
+	lines := []ReplenishmentLine{}
+	for _, p := range rows {
+		dailyUsage := float64(p.Issued) / ReplenishmentUsageDays
+		reorderPoint := p.MinStock + int64(math.Ceil(dailyUsage*float64(p.LeadTimeDays)))
+
+		projected := p.OnHand + p.OnOrder
+		if projected >= reorderPoint {
+			continue
+		}
+
+		// สั่งอย่างน้อย reorder quantity และต้องพอให้ยอดกลับขึ้นถึง reorder point
+		// แล้วปัดขึ้นตามขั้นต่ำและขนาดแพ็คของ supplier
+		suggested := max(p.ReorderQuantity, reorderPoint-projected)
+		suggested = int64(model.SupplierProduct{MinOrderQuantity: p.MinOrderQuantity, PackSize: p.PackSize}.RoundUpQuantity(uint64(suggested)))
+
+		lines = append(lines, ReplenishmentLine{
+			ProductId:           p.ProductId,
+			ProductCode:         p.ProductCode,
+			ProductName:         p.Name,
+			Unit:                p.Unit,
+			PreferredSupplierId: p.PreferredSupplierId,
+			SupplierName:        p.SupplierName,
+			MinStock:            p.MinStock,
+			ReorderQuantity:     p.ReorderQuantity,
+			LeadTimeDays:        p.LeadTimeDays,
+			StockOnHand:         p.OnHand,
+			OnOrder:             p.OnOrder,
+			DailyUsage:          math.Round(dailyUsage*100) / 100,
+			ReorderPoint:        reorderPoint,
+			SuggestedQuantity:   suggested,
+			ExpectedArrival:     now.AddDate(0, 0, int(p.LeadTimeDays)),
+		})
+	}
+
+	return lines, nil
+}
+
+// ReplenishmentGroup is the suggested purchase order for one supplier
+type ReplenishmentGroup struct {
+	SupplierId   uuid.UUID           `json:"supplier_id"`
+	SupplierName string              `json:"supplier_name"`
+	Lines        []ReplenishmentLine `json:"lines"`
+}
+
+// GroupReplenishmentBySupplier groups suggestions by preferred supplier in the order the suppliers first appear.
+// Lines without a preferred supplier are returned separately.
+func GroupReplenishmentBySupplier(lines []ReplenishmentLine) ([]ReplenishmentGroup, []ReplenishmentLine) {
+	groups := []ReplenishmentGroup{}
+	unassigned := []ReplenishmentLine{}
+	index := map[uuid.UUID]int{}
+
+	for _, line := range lines {
+		if line.PreferredSupplierId == nil {
+			unassigned = append(unassigned, line)
+			continue
+		}
+
+		i, ok := index[*line.PreferredSupplierId]
+		if !ok {
+			group := ReplenishmentGroup{SupplierId: *line.PreferredSupplierId}
+			if line.SupplierName != nil {
+				group.SupplierName = *line.SupplierName
+			}
+			groups = append(groups, group)
+			i = len(groups) - 1
+			index[*line.PreferredSupplierId] = i
+		}
+		groups[i].Lines = append(groups[i].Lines, line)
+	}
+
+	return groups, unassigned
+}
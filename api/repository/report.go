@@ -6,13 +6,17 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
 type Report interface {
-	GetStockSummary(db *gorm.DB) ([]StockSummaryResult, error)
+	GetStockSummary(db *gorm.DB, warehouseId *uuid.UUID, asOf *time.Time, unit *string) ([]StockSummaryResult, error)
+	GetWarehouseStockSummary(db *gorm.DB, warehouseId *uuid.UUID, asOf *time.Time) ([]WarehouseStockSummaryResult, error)
 	GetStockMovements(db *gorm.DB, fromDate, toDate time.Time) ([]StockMovementResult, error)
 	GetPurchaseSummary(db *gorm.DB, year int, month int) ([]PurchaseSummaryResult, error)
+	GetExpiringLots(db *gorm.DB, expiresBefore time.Time, warehouseId *uuid.UUID) ([]ExpiringLotResult, error)
+	GetInventoryValuation(db *gorm.DB, asOf time.Time, warehouseId *uuid.UUID) ([]InventoryValuationResult, error)
 }
 
 type report struct {
@@ -27,17 +31,37 @@ func NewReport(logger *slog.Logger) Report {
 
 // Result structs
 type StockSummaryResult struct {
-	ProductId         uuid.UUID `json:"product_id"`
-	ProductCode       string    `json:"product_code"`
-	Name              string    `json:"name"`
+	ProductId   uuid.UUID `json:"product_id"`
+	ProductCode string    `json:"product_code"`
+	Name        string    `json:"name"`
+	StockOnHand int64     `json:"stock_on_hand"` // หน่วยฐาน
+	Unit        string    `json:"unit"`
+	// DisplayQuantity is StockOnHand in DisplayUnit, which is the requested unit when the product has it
+	// and the base unit otherwise
+	DisplayUnit       string          `json:"display_unit"`
+	DisplayQuantity   decimal.Decimal `json:"display_quantity"`
+	CostPrice         float64         `json:"cost_price"`
+	SellingPrice      float64         `json:"selling_price"`
+	TotalCostValue    float64         `json:"total_cost_value"` // มูลค่าตาม cost layer ไม่ใช่ cost price ปัจจุบัน
+	TotalSellingValue float64         `json:"total_selling_value"`
+	// InTransit is stock shipped by a transfer towards the warehouse that has not arrived yet, not part of StockOnHand
+	InTransit          int64   `json:"in_transit"`
+	InTransitCostValue float64 `json:"in_transit_cost_value"`
+	MinStock           int64   `json:"min_stock"`
+	CategoryName       string  `json:"category_name"`
+	IsLowStock         bool    `json:"is_low_stock"`
+}
+
+type WarehouseStockSummaryResult struct {
+	WarehouseId       uuid.UUID `json:"warehouse_id"`
+	WarehouseCode     string    `json:"warehouse_code"`
+	WarehouseName     string    `json:"warehouse_name"`
 	StockOnHand       int64     `json:"stock_on_hand"`
-	CostPrice         float64   `json:"cost_price"`
-	SellingPrice      float64   `json:"selling_price"`
 	TotalCostValue    float64   `json:"total_cost_value"`
 	TotalSellingValue float64   `json:"total_selling_value"`
-	MinStock          int64     `json:"min_stock"`
-	CategoryName      string    `json:"category_name"`
-	IsLowStock        bool      `json:"is_low_stock"`
+	// InTransit is stock shipped towards this warehouse that has not arrived yet
+	InTransit          int64   `json:"in_transit"`
+	InTransitCostValue float64 `json:"in_transit_cost_value"`
 }
 
 type StockMovementResult struct {
@@ -55,43 +79,175 @@ type StockMovementResult struct {
 }
 
 type PurchaseSummaryResult struct {
-	Status        string  `json:"status"`
-	TotalOrders   int64   `json:"total_orders"`
-	TotalAmount   uint64  `json:"total_amount"`
-	AverageAmount float64 `json:"average_amount"`
+	Status        string          `json:"status"`
+	TotalOrders   int64           `json:"total_orders"`
+	TotalAmount   decimal.Decimal `json:"total_amount"`
+	AverageAmount decimal.Decimal `json:"average_amount"`
+}
+
+type InventoryValuationResult struct {
+	ProductId     uuid.UUID            `json:"product_id"`
+	ProductCode   string               `json:"product_code"`
+	ProductName   string               `json:"product_name"`
+	CategoryName  string               `json:"category_name"`
+	CostingMethod *model.CostingMethod `json:"costing_method"` // nil = ใช้ค่า COSTING_METHOD ของระบบ
+	// Quantity and TotalValue include stock in transit between warehouses, which is still owned
+	Quantity       int64           `json:"quantity"`
+	TotalValue     decimal.Decimal `json:"total_value"`
+	AverageCost    decimal.Decimal `json:"average_cost"`
+	InTransit      int64           `json:"in_transit"`
+	InTransitValue decimal.Decimal `json:"in_transit_value"`
 }
 
-// GetStockSummary returns stock summary with cost and selling values
-func (r *report) GetStockSummary(db *gorm.DB) ([]StockSummaryResult, error) {
+type ExpiringLotResult struct {
+	LotId         uuid.UUID `json:"lot_id"`
+	LotNumber     string    `json:"lot_number"`
+	ExpiryDate    time.Time `json:"expiry_date"`
+	DaysToExpiry  int       `json:"days_to_expiry"` // ติดลบ = หมดอายุแล้ว
+	ProductId     uuid.UUID `json:"product_id"`
+	ProductCode   string    `json:"product_code"`
+	ProductName   string    `json:"product_name"`
+	WarehouseId   uuid.UUID `json:"warehouse_id"`
+	WarehouseCode string    `json:"warehouse_code"`
+	Quantity      int64     `json:"quantity"`
+}
+
+// GetStockSummary returns stock summary with cost and selling values.
+// Stock on hand is the sum over all warehouses, or of one warehouse when warehouseId is given.
+// When asOf is given quantities and values are replayed from the ledger up to that time instead of the current balances.
+// When unit is given the stock of products that have that unit of measure is also shown in it.
+// Stock in transit is shown beside stock on hand, counted at its destination warehouse.
+func (r *report) GetStockSummary(db *gorm.DB, warehouseId *uuid.UUID, asOf *time.Time, unit *string) ([]StockSummaryResult, error) {
 	var results []StockSummaryResult
 
+	balances := db.Table("(?) as balances", r.balances(db, asOf)).
+		Select("product_id, SUM(quantity) as quantity").
+		Group("product_id")
+	values := r.ledger(db, asOf).
+		Select("product_id, SUM(" + SignedCostSQL + ") as value").
+		Group("product_id")
+	transit := db.Table("(?) as in_transit", r.inTransit(db, asOf)).
+		Select("product_id, SUM(quantity) as quantity, SUM(value) as value").
+		Group("product_id")
+	if warehouseId != nil {
+		balances = balances.Where("warehouse_id = ?", *warehouseId)
+		values = values.Where("warehouse_id = ?", *warehouseId)
+		transit = transit.Where("warehouse_id = ?", *warehouseId)
+	}
+
+	// ไม่ระบุหน่วย = แสดงเป็นหน่วยฐาน (ไม่มี unit ชื่อว่าง จึง join ไม่เจอ)
+	displayUnit := ""
+	if unit != nil {
+		displayUnit = *unit
+	}
+
 	err := db.Table("products").
 		Select(`
 			products.product_id,
 			products.product_code,
 			products.name,
-			COALESCE(latest_stock.quantity, 0) as stock_on_hand,
+			COALESCE(stock_balances.quantity, 0) as stock_on_hand,
+			products.unit,
+			COALESCE(display_units.name, products.unit) as display_unit,
+			ROUND(COALESCE(stock_balances.quantity, 0)::numeric / COALESCE(display_units.factor, 1), 4) as display_quantity,
 			products.cost_price,
 			products.selling_price,
-			(COALESCE(latest_stock.quantity, 0) * products.cost_price) as total_cost_value,
-			(COALESCE(latest_stock.quantity, 0) * products.selling_price) as total_selling_value,
+			COALESCE(stock_values.value, 0) as total_cost_value,
+			(COALESCE(stock_balances.quantity, 0) * products.selling_price) as total_selling_value,
+			COALESCE(in_transit.quantity, 0) as in_transit,
+			COALESCE(in_transit.value, 0) as in_transit_cost_value,
 			products.min_stock,
 			categories.name as category_name
 		`).
 		Joins("LEFT JOIN categories ON products.category_id = categories.category_id").
-		Joins(`LEFT JOIN LATERAL (
-			SELECT quantity 
-			FROM stock_transactions 
-			WHERE stock_transactions.product_id = products.product_id 
-			ORDER BY created_at DESC 
-			LIMIT 1
-		) latest_stock ON true`).
+		Joins("LEFT JOIN (?) as stock_balances ON stock_balances.product_id = products.product_id", balances).
+		Joins("LEFT JOIN (?) as stock_values ON stock_values.product_id = products.product_id", values).
+		Joins("LEFT JOIN (?) as in_transit ON in_transit.product_id = products.product_id", transit).
+		Joins("LEFT JOIN unit_of_measures as display_units ON display_units.product_id = products.product_id AND LOWER(display_units.name) = LOWER(?)", displayUnit).
 		Order("products.name ASC").
 		Scan(&results).Error
 
 	return results, err
 }
 
+// GetWarehouseStockSummary returns stock on hand and its value per warehouse, now or as of asOf,
+// with the stock in transit towards each warehouse
+func (r *report) GetWarehouseStockSummary(db *gorm.DB, warehouseId *uuid.UUID, asOf *time.Time) ([]WarehouseStockSummaryResult, error) {
+	var results []WarehouseStockSummaryResult
+
+	values := r.ledger(db, asOf).
+		Select("warehouse_id, SUM(" + SignedCostSQL + ") as value").
+		Group("warehouse_id")
+	transit := db.Table("(?) as in_transit", r.inTransit(db, asOf)).
+		Select("warehouse_id, SUM(quantity) as quantity, SUM(value) as value").
+		Group("warehouse_id")
+
+	query := db.Table("warehouses").
+		Select(`
+			warehouses.warehouse_id,
+			warehouses.code as warehouse_code,
+			warehouses.name as warehouse_name,
+			COALESCE(SUM(stock_balances.quantity), 0) as stock_on_hand,
+			COALESCE(MAX(stock_values.value), 0) as total_cost_value,
+			COALESCE(SUM(stock_balances.quantity * products.selling_price), 0) as total_selling_value,
+			COALESCE(MAX(in_transit.quantity), 0) as in_transit,
+			COALESCE(MAX(in_transit.value), 0) as in_transit_cost_value
+		`).
+		Joins("LEFT JOIN (?) as stock_values ON stock_values.warehouse_id = warehouses.warehouse_id", values).
+		Joins("LEFT JOIN (?) as in_transit ON in_transit.warehouse_id = warehouses.warehouse_id", transit).
+		Joins("LEFT JOIN (?) as stock_balances ON stock_balances.warehouse_id = warehouses.warehouse_id", r.balances(db, asOf)).
+		Joins("LEFT JOIN products ON products.product_id = stock_balances.product_id")
+	if warehouseId != nil {
+		query = query.Where("warehouses.warehouse_id = ?", *warehouseId)
+	}
+
+	err := query.
+		Group("warehouses.warehouse_id, warehouses.code, warehouses.name").
+		Order("warehouses.code ASC").
+		Scan(&results).Error
+
+	return results, err
+}
+
+// ledger returns the stock transactions up to asOf, or all of them when asOf is nil
+func (r *report) ledger(db *gorm.DB, asOf *time.Time) *gorm.DB {
+	query := db.Model(&model.StockTransaction{})
+	if asOf != nil {
+		query = query.Where("created_at <= ?", *asOf)
+	}
+	return query
+}
+
+// balances returns (warehouse_id, product_id, quantity) rows: the stock_balances projection for now,
+// or the ledger summed up to asOf
+func (r *report) balances(db *gorm.DB, asOf *time.Time) *gorm.DB {
+	if asOf == nil {
+		return db.Model(&model.StockBalance{}).Select("warehouse_id, product_id, quantity")
+	}
+	return r.ledger(db, asOf).
+		Select("warehouse_id, product_id, SUM(" + SignedQuantitySQL + ") as quantity").
+		Group("warehouse_id, product_id")
+}
+
+// inTransit returns (warehouse_id, product_id, quantity, value) rows of the transfers that had shipped but not arrived
+// at asOf (now when nil). The stock is out of the source balance and not yet in the destination one, so it is
+// counted at the destination warehouse and valued at the cost its OUT took from the source's cost layers.
+func (r *report) inTransit(db *gorm.DB, asOf *time.Time) *gorm.DB {
+	shipped := db.Model(&model.StockTransaction{}).
+		Select("reference_id, SUM(total_cost) as total_cost").
+		Where("type = ?", model.TransactionTypeOut).
+		Group("reference_id")
+
+	query := db.Model(&model.StockTransfer{}).
+		Select("stock_transfers.to_warehouse_id as warehouse_id, stock_transfers.product_id, SUM(stock_transfers.quantity) as quantity, COALESCE(SUM(shipped.total_cost), 0) as value").
+		Joins("LEFT JOIN (?) as shipped ON shipped.reference_id = stock_transfers.stock_transfer_id", shipped).
+		Group("stock_transfers.to_warehouse_id, stock_transfers.product_id")
+	if asOf == nil {
+		return query.Where("stock_transfers.status = ?", model.StockTransferInTransit)
+	}
+	return query.Where("stock_transfers.shipped_at <= ? AND (stock_transfers.received_at IS NULL OR stock_transfers.received_at > ?)", *asOf, *asOf)
+}
+
 // GetStockMovements returns stock transactions within a date range
 func (r *report) GetStockMovements(db *gorm.DB, fromDate, toDate time.Time) ([]StockMovementResult, error) {
 	var results []StockMovementResult
@@ -131,8 +287,8 @@ func (r *report) GetPurchaseSummary(db *gorm.DB, year int, month int) ([]Purchas
 		Select(`
 			status,
 			COUNT(*) as total_orders,
-			SUM(total_amount) as total_amount,
-			AVG(total_amount) as average_amount
+			COALESCE(SUM(total_amount), 0) as total_amount,
+			COALESCE(ROUND(AVG(total_amount), 2), 0) as average_amount
 		`).
 		Where("created_at >= ? AND created_at <= ?", firstDay, lastDay).
 		Group("status").
@@ -141,3 +297,80 @@ func (r *report) GetPurchaseSummary(db *gorm.DB, year int, month int) ([]Purchas
 
 	return results, err
 }
+
+// GetExpiringLots returns lots that still have stock and expire on or before expiresBefore, including lots already expired
+func (r *report) GetExpiringLots(db *gorm.DB, expiresBefore time.Time, warehouseId *uuid.UUID) ([]ExpiringLotResult, error) {
+	var results []ExpiringLotResult
+
+	query := db.Table("stock_transactions").
+		Select(`
+			lots.lot_id,
+			lots.lot_number,
+			lots.expiry_date,
+			(lots.expiry_date - CURRENT_DATE) as days_to_expiry,
+			products.product_id,
+			products.product_code,
+			products.name as product_name,
+			warehouses.warehouse_id,
+			warehouses.code as warehouse_code,
+			SUM(`+SignedQuantitySQL+`) as quantity
+		`).
+		Joins("JOIN lots ON lots.lot_id = stock_transactions.lot_id").
+		Joins("JOIN products ON products.product_id = lots.product_id").
+		Joins("JOIN warehouses ON warehouses.warehouse_id = stock_transactions.warehouse_id").
+		Where("lots.expiry_date IS NOT NULL AND lots.expiry_date <= ?", expiresBefore.Format("2006-01-02"))
+	if warehouseId != nil {
+		query = query.Where("stock_transactions.warehouse_id = ?", *warehouseId)
+	}
+
+	err := query.
+		Group("lots.lot_id, lots.lot_number, lots.expiry_date, products.product_id, products.product_code, products.name, warehouses.warehouse_id, warehouses.code").
+		Having("SUM(" + SignedQuantitySQL + ") > 0").
+		Order("lots.expiry_date ASC, products.name ASC, warehouses.code ASC").
+		Scan(&results).Error
+
+	return results, err
+}
+
+// GetInventoryValuation returns quantity and value of every product at the end of asOf, replayed from the costed ledger
+// plus the stock in transit between warehouses at that time (counted at its destination warehouse).
+// Products with neither quantity nor value at that time are left out.
+func (r *report) GetInventoryValuation(db *gorm.DB, asOf time.Time, warehouseId *uuid.UUID) ([]InventoryValuationResult, error) {
+	var results []InventoryValuationResult
+
+	onHand := r.ledger(db, &asOf).
+		Select("product_id, SUM(" + SignedQuantitySQL + ") as quantity, SUM(" + SignedCostSQL + ") as value").
+		Group("product_id")
+	transit := db.Table("(?) as in_transit", r.inTransit(db, &asOf)).
+		Select("product_id, SUM(quantity) as quantity, SUM(value) as value").
+		Group("product_id")
+	if warehouseId != nil {
+		onHand = onHand.Where("warehouse_id = ?", *warehouseId)
+		transit = transit.Where("warehouse_id = ?", *warehouseId)
+	}
+
+	quantity := "(COALESCE(on_hand.quantity, 0) + COALESCE(in_transit.quantity, 0))"
+	value := "(COALESCE(on_hand.value, 0) + COALESCE(in_transit.value, 0))"
+	err := db.Table("products").
+		Select(`
+			products.product_id,
+			products.product_code,
+			products.name as product_name,
+			categories.name as category_name,
+			products.costing_method,
+			`+quantity+` as quantity,
+			`+value+` as total_value,
+			CASE WHEN `+quantity+` = 0 THEN 0
+				ELSE ROUND(`+value+` / `+quantity+`, 4) END as average_cost,
+			COALESCE(in_transit.quantity, 0) as in_transit,
+			COALESCE(in_transit.value, 0) as in_transit_value
+		`).
+		Joins("LEFT JOIN categories ON categories.category_id = products.category_id").
+		Joins("LEFT JOIN (?) as on_hand ON on_hand.product_id = products.product_id", onHand).
+		Joins("LEFT JOIN (?) as in_transit ON in_transit.product_id = products.product_id", transit).
+		Where(quantity + " <> 0 OR " + value + " <> 0").
+		Order("products.name ASC").
+		Scan(&results).Error
+
+	return results, err
+}
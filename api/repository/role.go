@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+	"mini-erp-backend/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrRoleNotFound = errors.New("role not found")
+
+type Role interface {
+	SearchAll(db *gorm.DB) ([]model.RoleDefinition, error)
+	SearchByName(db *gorm.DB, name model.Role) (*model.RoleDefinition, error)
+	PermissionsByRole(db *gorm.DB, name model.Role) ([]string, error)
+	Create(tx *gorm.DB, role *model.RoleDefinition) error
+	UpdateDescription(tx *gorm.DB, name model.Role, description string) error
+	ReplacePermissions(tx *gorm.DB, name model.Role, permissions []model.Permission) error
+	Delete(tx *gorm.DB, name model.Role) error
+	SeedDefaults(db *gorm.DB) error
+}
+
+type role struct {
+	logger *slog.Logger
+}
+
+func NewRole(logger *slog.Logger) Role {
+	return &role{logger: logger}
+}
+
+func (r *role) SearchAll(db *gorm.DB) ([]model.RoleDefinition, error) {
+	var roles []model.RoleDefinition
+	if err := db.Preload("Grants", orderGrants).Order("name").Find(&roles).Error; err != nil {
+		r.logger.Error("failed to search roles", "error", err)
+		return nil, err
+	}
+
+	for i := range roles {
+		fillPermissions(&roles[i])
+	}
+
+	return roles, nil
+}
+
+func (r *role) SearchByName(db *gorm.DB, name model.Role) (*model.RoleDefinition, error) {
+	var result model.RoleDefinition
+	if err := db.Preload("Grants", orderGrants).Where("name = ?", name).First(&result).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		r.logger.Error("failed to search role", "name", name, "error", err)
+		return nil, err
+	}
+
+	fillPermissions(&result)
+	return &result, nil
+}
+
+// PermissionsByRole returns the permission names granted to the role, an unknown role has none
+func (r *role) PermissionsByRole(db *gorm.DB, name model.Role) ([]string, error) {
+	permissions := []string{}
+	if err := db.Model(&model.RolePermission{}).
+		Where("role = ?", name).
+		Order("permission").
+		Pluck("permission", &permissions).Error; err != nil {
+		r.logger.Error("failed to search role permissions", "role", name, "error", err)
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+func (r *role) Create(tx *gorm.DB, role *model.RoleDefinition) error {
+	if err := tx.Omit("Grants").Create(role).Error; err != nil {
+		r.logger.Error("failed to create role", "name", role.Name, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *role) UpdateDescription(tx *gorm.DB, name model.Role, description string) error {
+	if err := tx.Model(&model.RoleDefinition{}).
+		Where("name = ?", name).
+		Update("description", description).Error; err != nil {
+		r.logger.Error("failed to update role", "name", name, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// ReplacePermissions sets the permissions of the role to exactly the given list
+func (r *role) ReplacePermissions(tx *gorm.DB, name model.Role, permissions []model.Permission) error {
+	if err := tx.Where("role = ?", name).Delete(&model.RolePermission{}).Error; err != nil {
+		r.logger.Error("failed to clear role permissions", "role", name, "error", err)
+		return err
+	}
+
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	grants := make([]model.RolePermission, 0, len(permissions))
+	for _, p := range permissions {
+		grants = append(grants, model.RolePermission{Role: name, Permission: p})
+	}
+
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&grants).Error; err != nil {
+		r.logger.Error("failed to create role permissions", "role", name, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *role) Delete(tx *gorm.DB, name model.Role) error {
+	if err := tx.Where("role = ?", name).Delete(&model.RolePermission{}).Error; err != nil {
+		r.logger.Error("failed to delete role permissions", "role", name, "error", err)
+		return err
+	}
+
+	if err := tx.Where("name = ?", name).Delete(&model.RoleDefinition{}).Error; err != nil {
+		r.logger.Error("failed to delete role", "name", name, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// SeedDefaults creates the built-in roles with their default permissions. A role that already exists
+// is left as it is, so permissions edited through the API survive a restart.
+func (r *role) SeedDefaults(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for name, permissions := range model.DefaultRolePermissions {
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Omit("Grants").
+				Create(&model.RoleDefinition{Name: name, System: true})
+			if result.Error != nil {
+				r.logger.Error("failed to seed role", "name", name, "error", result.Error)
+				return result.Error
+			}
+
+			if result.RowsAffected == 0 {
+				continue
+			}
+
+			if err := r.ReplacePermissions(tx, name, permissions); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func orderGrants(db *gorm.DB) *gorm.DB {
+	return db.Order("permission")
+}
+
+func fillPermissions(role *model.RoleDefinition) {
+	role.Permissions = make([]model.Permission, 0, len(role.Grants))
+	for _, g := range role.Grants {
+		role.Permissions = append(role.Permissions, g.Permission)
+	}
+}
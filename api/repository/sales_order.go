@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"log/slog"
+	"mini-erp-backend/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SalesOrder interface {
+	Create(tx *gorm.DB, so *model.SalesOrder) error
+	Update(tx *gorm.DB, so *model.SalesOrder) error
+	UpdateStatus(tx *gorm.DB, so *model.SalesOrder) error
+	LockById(tx *gorm.DB, soId uuid.UUID) (*model.SalesOrder, error)
+	Search(db *gorm.DB, conditions map[string]interface{}, orderBy string) (*model.SalesOrder, error)
+	Searches(db *gorm.DB, conditions map[string]interface{}, orderBy string) ([]*model.SalesOrder, error)
+
+	CreateItem(tx *gorm.DB, item *model.SalesOrderItem) error
+	DeleteItemsBySalesOrderId(tx *gorm.DB, soId uuid.UUID) error
+	SearchItemsBySalesOrderId(db *gorm.DB, soId uuid.UUID) ([]*model.SalesOrderItem, error)
+}
+
+type salesOrder struct {
+	logger *slog.Logger
+}
+
+func NewSalesOrder(logger *slog.Logger) SalesOrder {
+	return &salesOrder{
+		logger: logger,
+	}
+}
+
+func (r *salesOrder) Create(tx *gorm.DB, so *model.SalesOrder) error {
+	if err := tx.Omit("Customer", "SalesOrderItem", "StockTransaction").Create(so).Error; err != nil {
+		r.logger.Error("Failed to create sales order", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (r *salesOrder) Update(tx *gorm.DB, so *model.SalesOrder) error {
+	if err := tx.Model(&model.SalesOrder{}).
+		Where("sales_order_id = ?", so.SalesOrderId).
+		Update("customer_id", so.CustomerId).Error; err != nil {
+		r.logger.Error("Failed to update sales order", "error", err)
+		return err
+	}
+	return nil
+}
+
+// UpdateStatus writes the status together with the ship time of the order
+func (r *salesOrder) UpdateStatus(tx *gorm.DB, so *model.SalesOrder) error {
+	if err := tx.Model(&model.SalesOrder{}).
+		Where("sales_order_id = ?", so.SalesOrderId).
+		Updates(map[string]interface{}{
+			"status":     so.Status,
+			"shipped_at": so.ShippedAt,
+		}).Error; err != nil {
+		r.logger.Error("Failed to update sales order status", "error", err)
+		return err
+	}
+	return nil
+}
+
+// LockById loads the order header with SELECT ... FOR UPDATE so concurrent status changes are serialized
+func (r *salesOrder) LockById(tx *gorm.DB, soId uuid.UUID) (*model.SalesOrder, error) {
+	so := model.SalesOrder{}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("sales_order_id = ?", soId).
+		First(&so).Error; err != nil {
+		r.logger.Error("Failed to lock sales order", "so_id", soId, "error", err)
+		return nil, err
+	}
+	return &so, nil
+}
+
+func (r *salesOrder) Search(db *gorm.DB, conditions map[string]interface{}, orderBy string) (*model.SalesOrder, error) {
+	sos := []model.SalesOrder{}
+
+	query := db.Preload("SalesOrderItem").
+		Preload("SalesOrderItem.Product").
+		Preload("StockTransaction").
+		Preload("Customer").
+		Where(conditions)
+
+	if orderBy != "" {
+		query = query.Order(orderBy)
+	}
+
+	if err := query.Find(&sos).Error; err != nil {
+		r.logger.Error("Failed to search sales order", "error", err)
+		return nil, err
+	}
+
+	if len(sos) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &sos[0], nil
+}
+
+func (r *salesOrder) Searches(db *gorm.DB, conditions map[string]interface{}, orderBy string) ([]*model.SalesOrder, error) {
+	sos := []*model.SalesOrder{}
+
+	query := db.Preload("Customer").Preload("SalesOrderItem").Where(conditions)
+
+	if orderBy != "" {
+		query = query.Order(orderBy)
+	}
+
+	if err := query.Find(&sos).Error; err != nil {
+		r.logger.Error("Failed to search sales orders", "error", err)
+		return nil, err
+	}
+	return sos, nil
+}
+
+func (r *salesOrder) CreateItem(tx *gorm.DB, item *model.SalesOrderItem) error {
+	if err := tx.Omit("SalesOrder", "Product").Create(item).Error; err != nil {
+		r.logger.Error("Failed to create sales order item", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (r *salesOrder) DeleteItemsBySalesOrderId(tx *gorm.DB, soId uuid.UUID) error {
+	if err := tx.Where("sales_order_id = ?", soId).Delete(&model.SalesOrderItem{}).Error; err != nil {
+		r.logger.Error("Failed to delete sales order items", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (r *salesOrder) SearchItemsBySalesOrderId(db *gorm.DB, soId uuid.UUID) ([]*model.SalesOrderItem, error) {
+	items := []*model.SalesOrderItem{}
+	if err := db.Where("sales_order_id = ?", soId).
+		Find(&items).Error; err != nil {
+		r.logger.Error("Failed to search sales order items", "error", err)
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,334 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"mini-erp-backend/model"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrSerialRequired     = errors.New("serial numbers are required for serial tracked products")
+	ErrSerialNotTracked   = errors.New("serial numbers given for a product without serial tracking")
+	ErrSerialDuplicate    = errors.New("duplicate serial number")
+	ErrSerialInStock      = errors.New("serial number already in stock")
+	ErrSerialNotAvailable = errors.New("serial number is not available in this warehouse")
+	ErrSerialLotMismatch  = errors.New("serial number is not in the lot being issued")
+)
+
+// CheckSerials validates the serial list of a movement before anything is written:
+// serial tracked products need exactly one unique serial per unit, other products none.
+func CheckSerials(product *model.Product, quantity int64, serials []string) error {
+	if !product.TrackSerial {
+		if len(serials) > 0 {
+			return fmt.Errorf("%w: %s", ErrSerialNotTracked, product.ProductCode)
+		}
+		return nil
+	}
+
+	if int64(len(serials)) != quantity {
+		return fmt.Errorf("%w: product %s quantity %d, serials %d", ErrSerialRequired, product.ProductCode, quantity, len(serials))
+	}
+
+	seen := make(map[string]struct{}, len(serials))
+	for _, serial := range serials {
+		if strings.TrimSpace(serial) == "" {
+			return fmt.Errorf("%w: product %s has an empty serial", ErrSerialRequired, product.ProductCode)
+		}
+		if _, ok := seen[serial]; ok {
+			return fmt.Errorf("%w: %s", ErrSerialDuplicate, serial)
+		}
+		seen[serial] = struct{}{}
+	}
+	return nil
+}
+
+type SerialNumber interface {
+	// Get
+	SearchBySerial(db *gorm.DB, serial string) ([]model.SerialNumber, error)
+	SerialsByTransaction(db *gorm.DB, stockTransactionId uuid.UUID) ([]string, error)
+	InStock(tx *gorm.DB, warehouseId, productId uuid.UUID) ([]string, error)
+	AllocateByLot(tx *gorm.DB, warehouseId, productId uuid.UUID, serials []string) ([]LotAllocation, error)
+	// Update
+	Receive(tx *gorm.DB, transaction *model.StockTransaction, serials []string) error
+	Issue(tx *gorm.DB, transaction *model.StockTransaction, serials []string, status model.SerialStatus) error
+	Arrive(tx *gorm.DB, transaction *model.StockTransaction, serials []string) error
+	BackfillLots(tx *gorm.DB) (int64, error)
+}
+
+type serialNumber struct {
+	logger *slog.Logger
+}
+
+func NewSerialNumber(logger *slog.Logger) SerialNumber {
+	return &serialNumber{
+		logger: logger,
+	}
+}
+
+// SearchBySerial returns every unit with this serial (one per product) with its movement history, oldest first
+func (r *serialNumber) SearchBySerial(db *gorm.DB, serial string) ([]model.SerialNumber, error) {
+	serialNumbers := []model.SerialNumber{}
+	if err := db.Preload("Product").
+		Preload("Warehouse").
+		Preload("Movements", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Preload("Movements.StockTransaction").
+		Preload("Movements.StockTransaction.Warehouse").
+		Where("serial = ?", serial).
+		Find(&serialNumbers).Error; err != nil {
+		r.logger.Error("Failed to search serial number", "serial", serial, "error", err)
+		return nil, err
+	}
+
+	if len(serialNumbers) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return serialNumbers, nil
+}
+
+// SerialsByTransaction returns the serials moved by one stock transaction
+func (r *serialNumber) SerialsByTransaction(db *gorm.DB, stockTransactionId uuid.UUID) ([]string, error) {
+	serials := []string{}
+	if err := db.Model(&model.SerialMovement{}).
+		Joins("JOIN serial_numbers ON serial_numbers.serial_number_id = serial_movements.serial_number_id").
+		Where("serial_movements.stock_transaction_id = ?", stockTransactionId).
+		Order("serial_numbers.serial ASC").
+		Pluck("serial_numbers.serial", &serials).Error; err != nil {
+		r.logger.Error("Failed to get serials by transaction", "stock_transaction_id", stockTransactionId, "error", err)
+		return nil, err
+	}
+	return serials, nil
+}
+
+// InStock returns the serials of the product in stock at the warehouse, locked (SELECT ... FOR UPDATE)
+func (r *serialNumber) InStock(tx *gorm.DB, warehouseId, productId uuid.UUID) ([]string, error) {
+	serials := []string{}
+	if err := tx.Model(&model.SerialNumber{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("warehouse_id = ? AND product_id = ? AND status = ?", warehouseId, productId, model.SerialInStock).
+		Order("serial ASC").
+		Pluck("serial", &serials).Error; err != nil {
+		r.logger.Error("Failed to get serials in stock", "warehouse_id", warehouseId, "product_id", productId, "error", err)
+		return nil, err
+	}
+	return serials, nil
+}
+
+// AllocateByLot splits the serials of an outgoing movement by the lot each unit was received in,
+// one allocation per lot in the order the lots first appear. Every unit must be in stock in the warehouse.
+func (r *serialNumber) AllocateByLot(tx *gorm.DB, warehouseId, productId uuid.UUID, serials []string) ([]LotAllocation, error) {
+	bySerial, err := r.lock(tx, productId, serials)
+	if err != nil {
+		return nil, err
+	}
+
+	allocations := []LotAllocation{}
+	// uuid.Nil คือหน่วยที่รับเข้าโดยไม่มี lot
+	indexByLot := make(map[uuid.UUID]int)
+	for _, serial := range serials {
+		unit, ok := bySerial[serial]
+		if !ok || unit.Status != model.SerialInStock || unit.WarehouseId != warehouseId {
+			return nil, fmt.Errorf("%w: %s", ErrSerialNotAvailable, serial)
+		}
+
+		lotKey := uuid.Nil
+		if unit.LotId != nil {
+			lotKey = *unit.LotId
+		}
+		index, ok := indexByLot[lotKey]
+		if !ok {
+			index = len(allocations)
+			indexByLot[lotKey] = index
+			allocations = append(allocations, LotAllocation{LotId: unit.LotId})
+		}
+
+		allocations[index].Quantity++
+		allocations[index].Serials = append(allocations[index].Serials, serial)
+	}
+
+	return allocations, nil
+}
+
+// lock loads the units of the product with these serials (SELECT ... FOR UPDATE), keyed by serial
+func (r *serialNumber) lock(tx *gorm.DB, productId uuid.UUID, serials []string) (map[string]*model.SerialNumber, error) {
+	existing := []model.SerialNumber{}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ? AND serial IN ?", productId, serials).
+		Find(&existing).Error; err != nil {
+		r.logger.Error("Failed to lock serial numbers", "error", err)
+		return nil, err
+	}
+
+	bySerial := make(map[string]*model.SerialNumber, len(existing))
+	for i := range existing {
+		bySerial[existing[i].Serial] = &existing[i]
+	}
+	return bySerial, nil
+}
+
+// Receive puts units into stock at the warehouse of an incoming transaction.
+// New serials are created; units that were shipped or scrapped before (e.g. returns) come back into stock.
+func (r *serialNumber) Receive(tx *gorm.DB, transaction *model.StockTransaction, serials []string) error {
+	if int64(len(serials)) != unitCount(transaction) {
+		return ErrSerialRequired
+	}
+
+	bySerial, err := r.lock(tx, transaction.ProductId, serials)
+	if err != nil {
+		return err
+	}
+
+	for _, serial := range serials {
+		unit, ok := bySerial[serial]
+		if ok && (unit.Status == model.SerialInStock || unit.Status == model.SerialInTransit) {
+			return fmt.Errorf("%w: %s", ErrSerialInStock, serial)
+		}
+
+		if !ok {
+			unit = &model.SerialNumber{
+				SerialNumberId: uuid.New(),
+				ProductId:      transaction.ProductId,
+				Serial:         serial,
+				Status:         model.SerialInStock,
+				WarehouseId:    transaction.WarehouseId,
+				LotId:          transaction.LotId,
+				CreatedAt:      time.Now(),
+				UpdatedAt:      time.Now(),
+			}
+			if err := tx.Omit(clause.Associations).Create(unit).Error; err != nil {
+				r.logger.Error("Failed to create serial number", "serial", serial, "error", err)
+				return err
+			}
+		}
+
+		if err := r.move(tx, unit, transaction, model.SerialInStock); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Issue takes units out of stock at the warehouse of an outgoing transaction and sets their new status.
+// When the transaction is for a lot, every unit must have been received in that lot.
+func (r *serialNumber) Issue(tx *gorm.DB, transaction *model.StockTransaction, serials []string, status model.SerialStatus) error {
+	if int64(len(serials)) != unitCount(transaction) {
+		return ErrSerialRequired
+	}
+
+	bySerial, err := r.lock(tx, transaction.ProductId, serials)
+	if err != nil {
+		return err
+	}
+
+	for _, serial := range serials {
+		unit, ok := bySerial[serial]
+		if !ok || unit.Status != model.SerialInStock || unit.WarehouseId != transaction.WarehouseId {
+			return fmt.Errorf("%w: %s", ErrSerialNotAvailable, serial)
+		}
+		if transaction.LotId != nil && (unit.LotId == nil || *unit.LotId != *transaction.LotId) {
+			return fmt.Errorf("%w: %s", ErrSerialLotMismatch, serial)
+		}
+
+		if err := r.move(tx, unit, transaction, status); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Arrive puts in-transit units into stock at the warehouse of the receiving transaction
+func (r *serialNumber) Arrive(tx *gorm.DB, transaction *model.StockTransaction, serials []string) error {
+	bySerial, err := r.lock(tx, transaction.ProductId, serials)
+	if err != nil {
+		return err
+	}
+
+	for _, serial := range serials {
+		unit, ok := bySerial[serial]
+		if !ok || unit.Status != model.SerialInTransit {
+			return fmt.Errorf("%w: %s is not in transit", ErrSerialNotAvailable, serial)
+		}
+
+		if err := r.move(tx, unit, transaction, model.SerialInStock); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// move updates the unit to status at the transaction's warehouse and records the movement in its history
+func (r *serialNumber) move(tx *gorm.DB, unit *model.SerialNumber, transaction *model.StockTransaction, status model.SerialStatus) error {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":       status,
+		"warehouse_id": transaction.WarehouseId,
+		"updated_at":   now,
+	}
+	// ของที่รับเข้าอยู่ใน lot ของรายการรับ (ของคืนอาจกลับมาใน lot ใหม่)
+	if status == model.SerialInStock {
+		updates["lot_id"] = transaction.LotId
+	}
+	if err := tx.Model(&model.SerialNumber{}).
+		Where("serial_number_id = ?", unit.SerialNumberId).
+		Updates(updates).Error; err != nil {
+		r.logger.Error("Failed to update serial number", "serial", unit.Serial, "error", err)
+		return err
+	}
+
+	movement := &model.SerialMovement{
+		SerialMovementId:   uuid.New(),
+		SerialNumberId:     unit.SerialNumberId,
+		StockTransactionId: transaction.StockTransactionId,
+		Status:             status,
+		CreatedAt:          now,
+	}
+	if err := tx.Omit(clause.Associations).Create(movement).Error; err != nil {
+		r.logger.Error("Failed to create serial movement", "serial", unit.Serial, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// BackfillLots sets the lot of units recorded before serial numbers kept one,
+// taken from the stock transaction of their latest movement
+func (r *serialNumber) BackfillLots(tx *gorm.DB) (int64, error) {
+	result := tx.Exec(`
+		UPDATE serial_numbers
+		SET lot_id = latest.lot_id
+		FROM (
+			SELECT DISTINCT ON (serial_movements.serial_number_id) serial_movements.serial_number_id, stock_transactions.lot_id
+			FROM serial_movements
+			JOIN stock_transactions ON stock_transactions.stock_transaction_id = serial_movements.stock_transaction_id
+			ORDER BY serial_movements.serial_number_id, serial_movements.created_at DESC
+		) latest
+		WHERE latest.serial_number_id = serial_numbers.serial_number_id
+			AND serial_numbers.lot_id IS NULL
+			AND latest.lot_id IS NOT NULL
+	`)
+	if result.Error != nil {
+		r.logger.Error("Failed to backfill serial number lots", "error", result.Error)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// unitCount is the number of units a transaction moves; a negative ADJUST moves -quantity units
+func unitCount(transaction *model.StockTransaction) int64 {
+	if transaction.Quantity < 0 {
+		return -transaction.Quantity
+	}
+	return transaction.Quantity
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+	"mini-erp-backend/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInsufficientStock is returned when a movement would drive a product without backorder below zero.
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+type StockBalance interface {
+	// Get
+	SearchByProductId(db *gorm.DB, warehouseId, productId uuid.UUID) (*model.StockBalance, error)
+	SearchesByProductId(db *gorm.DB, productId uuid.UUID) ([]model.StockBalance, error)
+	LockByProductId(tx *gorm.DB, warehouseId, productId uuid.UUID) (*model.StockBalance, error)
+	// Update
+	ApplyTransaction(tx *gorm.DB, transaction *model.StockTransaction) (*model.StockBalance, error)
+	RebuildFromLedger(tx *gorm.DB) (int64, error)
+}
+
+type stockBalance struct {
+	logger *slog.Logger
+}
+
+func NewStockBalance(logger *slog.Logger) StockBalance {
+	return &stockBalance{
+		logger: logger,
+	}
+}
+
+// SearchByProductId returns the balance in one warehouse without locking.
+// A product that has never moved there has a zero balance.
+func (s *stockBalance) SearchByProductId(db *gorm.DB, warehouseId, productId uuid.UUID) (*model.StockBalance, error) {
+	balances := []model.StockBalance{}
+	if err := db.Where("warehouse_id = ? AND product_id = ?", warehouseId, productId).Limit(1).Find(&balances).Error; err != nil {
+		s.logger.Error("Failed to search stock balance", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	if len(balances) == 0 {
+		return &model.StockBalance{WarehouseId: warehouseId, ProductId: productId}, nil
+	}
+
+	return &balances[0], nil
+}
+
+// SearchesByProductId returns the balance of a product in every warehouse it has moved through
+func (s *stockBalance) SearchesByProductId(db *gorm.DB, productId uuid.UUID) ([]model.StockBalance, error) {
+	balances := []model.StockBalance{}
+	if err := db.Preload("Warehouse").
+		Joins("JOIN warehouses ON warehouses.warehouse_id = stock_balances.warehouse_id").
+		Where("stock_balances.product_id = ?", productId).
+		Order("warehouses.code ASC").
+		Find(&balances).Error; err != nil {
+		s.logger.Error("Failed to search stock balances", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return balances, nil
+}
+
+// LockByProductId creates the balance row if missing and locks it (SELECT ... FOR UPDATE) until tx ends.
+func (s *stockBalance) LockByProductId(tx *gorm.DB, warehouseId, productId uuid.UUID) (*model.StockBalance, error) {
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.StockBalance{
+		WarehouseId: warehouseId,
+		ProductId:   productId,
+		Quantity:    0,
+		UpdatedAt:   time.Now(),
+	}).Error; err != nil {
+		s.logger.Error("Failed to initialise stock balance", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	var balance model.StockBalance
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("warehouse_id = ? AND product_id = ?", warehouseId, productId).
+		First(&balance).Error; err != nil {
+		s.logger.Error("Failed to lock stock balance", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return &balance, nil
+}
+
+// ApplyTransaction adds the signed quantity of a ledger row to its warehouse/product balance and to the total of its type.
+func (s *stockBalance) ApplyTransaction(tx *gorm.DB, transaction *model.StockTransaction) (*model.StockBalance, error) {
+	balance, err := s.LockByProductId(tx, transaction.WarehouseId, transaction.ProductId)
+	if err != nil {
+		return nil, err
+	}
+
+	balance.Apply(*transaction)
+	balance.UpdatedAt = time.Now()
+
+	if err := tx.Model(&model.StockBalance{}).
+		Where("warehouse_id = ? AND product_id = ?", balance.WarehouseId, balance.ProductId).
+		Updates(map[string]interface{}{
+			"quantity":     balance.Quantity,
+			"total_in":     balance.TotalIn,
+			"total_out":    balance.TotalOut,
+			"total_adjust": balance.TotalAdjust,
+			"updated_at":   balance.UpdatedAt,
+		}).Error; err != nil {
+		s.logger.Error("Failed to update stock balance", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return balance, nil
+}
+
+// RebuildFromLedger recomputes every balance and its totals from stock_transactions and returns the number of rows written.
+// Ledger inserts are blocked for the duration of tx so the projection cannot drift while it is rebuilt.
+func (s *stockBalance) RebuildFromLedger(tx *gorm.DB) (int64, error) {
+	if err := tx.Exec("LOCK TABLE stock_transactions IN SHARE MODE").Error; err != nil {
+		s.logger.Error("Failed to lock stock transactions", slog.String("error", err.Error()))
+		return 0, err
+	}
+
+	if err := tx.Exec("DELETE FROM stock_balances").Error; err != nil {
+		s.logger.Error("Failed to clear stock balances", slog.String("error", err.Error()))
+		return 0, err
+	}
+
+	result := tx.Exec(`
+		INSERT INTO stock_balances (warehouse_id, product_id, quantity, total_in, total_out, total_adjust, updated_at)
+		SELECT warehouse_id, product_id, SUM(` + SignedQuantitySQL + `),
+			COALESCE(SUM(quantity) FILTER (WHERE type = 'IN'), 0),
+			COALESCE(SUM(quantity) FILTER (WHERE type = 'OUT'), 0),
+			COALESCE(SUM(quantity) FILTER (WHERE type = 'ADJUST'), 0),
+			NOW()
+		FROM stock_transactions
+		GROUP BY warehouse_id, product_id
+	`)
+	if result.Error != nil {
+		s.logger.Error("Failed to rebuild stock balances", slog.String("error", result.Error.Error()))
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
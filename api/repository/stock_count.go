@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrStockCountNotFound   = errors.New("stock count not found")
+	ErrStockCountNotOpen    = errors.New("stock count is not open")
+	ErrStockCountNoProducts = errors.New("no products to count")
+)
+
+type StockCountSearchFilters struct {
+	Status      *model.StockCountStatus
+	WarehouseId *uuid.UUID
+}
+
+type StockCount interface {
+	// Get
+	SearchWithLines(db *gorm.DB, stockCountId uuid.UUID) (*model.StockCount, error)
+	LockById(tx *gorm.DB, stockCountId uuid.UUID) (*model.StockCount, error)
+	SearchWithFilters(db *gorm.DB, filters StockCountSearchFilters, orderBy string) ([]model.StockCount, error)
+	SearchWithFiltersAndPagination(db *gorm.DB, filters StockCountSearchFilters, orderBy string, page int, pageSize int) ([]model.StockCount, int64, error)
+	ExpectedLines(db *gorm.DB, warehouseId uuid.UUID, categoryId *uuid.UUID) ([]model.StockCountLine, error)
+	// Create
+	Create(tx *gorm.DB, stockCount *model.StockCount) error
+	// Update
+	UpdateCounted(tx *gorm.DB, stockCountId uuid.UUID, productId uuid.UUID, quantity int64, serials []string, countedBy uuid.UUID, countedAt time.Time) (bool, error)
+	SetLineTransaction(tx *gorm.DB, stockCountLineId uuid.UUID, stockTransactionId uuid.UUID) error
+	MarkApproved(tx *gorm.DB, stockCount *model.StockCount) error
+}
+
+type stockCount struct {
+	logger *slog.Logger
+}
+
+func NewStockCount(logger *slog.Logger) StockCount {
+	return &stockCount{
+		logger: logger,
+	}
+}
+
+// SearchWithLines returns the count with its lines ordered by product code
+func (r *stockCount) SearchWithLines(db *gorm.DB, stockCountId uuid.UUID) (*model.StockCount, error) {
+	count := model.StockCount{}
+	if err := db.Preload("Warehouse").
+		Preload("Category").
+		Preload("Lines", func(db *gorm.DB) *gorm.DB {
+			return db.Joins("JOIN products ON products.product_id = stock_count_lines.product_id").
+				Order("products.product_code ASC")
+		}).
+		Preload("Lines.Product").
+		Where("stock_count_id = ?", stockCountId).
+		First(&count).Error; err != nil {
+		r.logger.Error("Failed to get stock count", "stock_count_id", stockCountId, "error", err)
+		return nil, err
+	}
+	return &count, nil
+}
+
+// LockById loads the count with SELECT ... FOR UPDATE so submissions and approval do not interleave
+func (r *stockCount) LockById(tx *gorm.DB, stockCountId uuid.UUID) (*model.StockCount, error) {
+	count := model.StockCount{}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("stock_count_id = ?", stockCountId).
+		First(&count).Error; err != nil {
+		r.logger.Error("Failed to lock stock count", "stock_count_id", stockCountId, "error", err)
+		return nil, err
+	}
+	return &count, nil
+}
+
+func (r *stockCount) applyFilters(query *gorm.DB, filters StockCountSearchFilters) *gorm.DB {
+	if filters.Status != nil {
+		query = query.Where("status = ?", *filters.Status)
+	}
+	if filters.WarehouseId != nil {
+		query = query.Where("warehouse_id = ?", *filters.WarehouseId)
+	}
+	return query
+}
+
+func (r *stockCount) SearchWithFilters(db *gorm.DB, filters StockCountSearchFilters, orderBy string) ([]model.StockCount, error) {
+	counts := []model.StockCount{}
+	query := r.applyFilters(db.Model(&model.StockCount{}), filters)
+
+	if orderBy != "" {
+		query = query.Order(orderBy)
+	}
+
+	if err := query.Preload("Warehouse").Preload("Category").Find(&counts).Error; err != nil {
+		r.logger.Error("Failed to search stock counts with filters", "error", err)
+		return nil, err
+	}
+	return counts, nil
+}
+
+func (r *stockCount) SearchWithFiltersAndPagination(db *gorm.DB, filters StockCountSearchFilters, orderBy string, page int, pageSize int) ([]model.StockCount, int64, error) {
+	counts := []model.StockCount{}
+	var total int64
+
+	query := r.applyFilters(db.Model(&model.StockCount{}), filters)
+
+	// นับจำนวนทั้งหมดก่อน pagination
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("Failed to count stock counts", "error", err)
+		return nil, 0, err
+	}
+	offset := (page - 1) * pageSize
+
+	if orderBy != "" {
+		query = query.Order(orderBy)
+	}
+
+	if err := query.Preload("Warehouse").Preload("Category").Limit(pageSize).Offset(offset).Find(&counts).Error; err != nil {
+		r.logger.Error("Failed to search stock counts with filters and pagination", "error", err)
+		return nil, 0, err
+	}
+	return counts, total, nil
+}
+
+// ExpectedLines returns one line per product (of the category, when given) with its current balance in the warehouse
+// as the expected quantity. Serial tracked products are left out, they are counted by serial number.
+func (r *stockCount) ExpectedLines(db *gorm.DB, warehouseId uuid.UUID, categoryId *uuid.UUID) ([]model.StockCountLine, error) {
+	lines := []model.StockCountLine{}
+
+	query := db.Table("products").
+		Select("products.product_id, COALESCE(stock_balances.quantity, 0) as expected_quantity").
+		Joins("LEFT JOIN stock_balances ON stock_balances.product_id = products.product_id AND stock_balances.warehouse_id = ?", warehouseId).
+		Where("products.track_serial = ?", false)
+	if categoryId != nil {
+		query = query.Where("products.category_id = ?", *categoryId)
+	}
+
+	if err := query.Order("products.product_code ASC").Scan(&lines).Error; err != nil {
+		r.logger.Error("Failed to get expected stock count lines", "error", err)
+		return nil, err
+	}
+	return lines, nil
+}
+
+func (r *stockCount) Create(tx *gorm.DB, stockCount *model.StockCount) error {
+	if err := tx.Omit("Warehouse", "Category", "Lines.Product").Create(stockCount).Error; err != nil {
+		r.logger.Error("Failed to create stock count", "error", err)
+		return err
+	}
+	return nil
+}
+
+// UpdateCounted records the latest counted quantity (and serials) of a product, a later submission replaces an earlier one.
+// It returns false when the product is not part of the count.
+func (r *stockCount) UpdateCounted(tx *gorm.DB, stockCountId uuid.UUID, productId uuid.UUID, quantity int64, serials []string, countedBy uuid.UUID, countedAt time.Time) (bool, error) {
+	// Updates แบบ map ไม่ผ่าน serializer ของ gorm จึงแปลงเป็น JSON เอง
+	var countedSerials interface{}
+	if len(serials) > 0 {
+		encoded, err := json.Marshal(serials)
+		if err != nil {
+			return false, err
+		}
+		countedSerials = string(encoded)
+	}
+
+	result := tx.Model(&model.StockCountLine{}).
+		Where("stock_count_id = ? AND product_id = ?", stockCountId, productId).
+		Updates(map[string]interface{}{
+			"counted_quantity": quantity,
+			"counted_serials":  countedSerials,
+			"counted_at":       countedAt,
+			"counted_by":       countedBy,
+		})
+	if result.Error != nil {
+		r.logger.Error("Failed to update counted quantity", "error", result.Error)
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *stockCount) SetLineTransaction(tx *gorm.DB, stockCountLineId uuid.UUID, stockTransactionId uuid.UUID) error {
+	if err := tx.Model(&model.StockCountLine{}).
+		Where("stock_count_line_id = ?", stockCountLineId).
+		Update("stock_transaction_id", stockTransactionId).Error; err != nil {
+		r.logger.Error("Failed to link stock count line to adjustment", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (r *stockCount) MarkApproved(tx *gorm.DB, stockCount *model.StockCount) error {
+	if err := tx.Model(&model.StockCount{}).
+		Where("stock_count_id = ?", stockCount.StockCountId).
+		Updates(map[string]interface{}{
+			"status":      stockCount.Status,
+			"approved_at": stockCount.ApprovedAt,
+			"approved_by": stockCount.ApprovedBy,
+		}).Error; err != nil {
+		r.logger.Error("Failed to mark stock count approved", "error", err)
+		return err
+	}
+	return nil
+}
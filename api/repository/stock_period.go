@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"mini-erp-backend/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrPeriodClosed is returned for a stock transaction dated inside a closed period
+	ErrPeriodClosed        = errors.New("stock period is closed")
+	ErrPeriodAlreadyClosed = errors.New("stock period already closed")
+	ErrPeriodNotEnded      = errors.New("stock period has not ended yet")
+	ErrPeriodNotFound      = errors.New("stock period not found")
+)
+
+type StockPeriod interface {
+	// Get
+	SearchAll(db *gorm.DB) ([]model.StockPeriod, error)
+	SearchWithSnapshots(db *gorm.DB, stockPeriodId uuid.UUID) (*model.StockPeriod, error)
+	ExitedByMonth(db *gorm.DB, year int, month int) (bool, error)
+	CheckOpen(db *gorm.DB, at time.Time) error
+	// Create
+	Close(tx *gorm.DB, period *model.StockPeriod) error
+}
+
+type stockPeriod struct {
+	logger *slog.Logger
+}
+
+func NewStockPeriod(logger *slog.Logger) StockPeriod {
+	return &stockPeriod{
+		logger: logger,
+	}
+}
+
+// SearchAll returns every closed period, latest first
+func (r *stockPeriod) SearchAll(db *gorm.DB) ([]model.StockPeriod, error) {
+	periods := []model.StockPeriod{}
+	if err := db.Order("year DESC, month DESC").Find(&periods).Error; err != nil {
+		r.logger.Error("Failed to search stock periods", "error", err)
+		return nil, err
+	}
+	return periods, nil
+}
+
+func (r *stockPeriod) SearchWithSnapshots(db *gorm.DB, stockPeriodId uuid.UUID) (*model.StockPeriod, error) {
+	period := model.StockPeriod{}
+	if err := db.Preload("Snapshots", func(db *gorm.DB) *gorm.DB {
+		return db.Joins("JOIN products ON products.product_id = stock_period_snapshots.product_id").
+			Order("products.product_code ASC")
+	}).
+		Preload("Snapshots.Product").
+		Preload("Snapshots.Warehouse").
+		Where("stock_period_id = ?", stockPeriodId).
+		First(&period).Error; err != nil {
+		r.logger.Error("Failed to get stock period", "stock_period_id", stockPeriodId, "error", err)
+		return nil, err
+	}
+	return &period, nil
+}
+
+func (r *stockPeriod) ExitedByMonth(db *gorm.DB, year int, month int) (bool, error) {
+	var count int64
+	if err := db.Model(&model.StockPeriod{}).
+		Where("year = ? AND month = ?", year, month).
+		Count(&count).Error; err != nil {
+		r.logger.Error("Failed to check if stock period exists", "error", err)
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CheckOpen returns ErrPeriodClosed when at falls on or before the end of the latest closed period
+func (r *stockPeriod) CheckOpen(db *gorm.DB, at time.Time) error {
+	periods := []model.StockPeriod{}
+	if err := db.Order("period_end DESC").Limit(1).Find(&periods).Error; err != nil {
+		r.logger.Error("Failed to get latest stock period", "error", err)
+		return err
+	}
+
+	if len(periods) > 0 && !at.After(periods[0].PeriodEnd) {
+		return fmt.Errorf("%w: %04d-%02d", ErrPeriodClosed, periods[0].Year, periods[0].Month)
+	}
+	return nil
+}
+
+// Close creates the period and writes its snapshot from the ledger up to PeriodEnd.
+// stock_balances is locked in SHARE mode first, which waits for stock movements in flight and holds off new ones
+// until tx ends, so the snapshot is complete and no back-dated movement can slip in behind it.
+func (r *stockPeriod) Close(tx *gorm.DB, period *model.StockPeriod) error {
+	if err := tx.Exec("LOCK TABLE stock_balances IN SHARE MODE").Error; err != nil {
+		r.logger.Error("Failed to lock stock balances", "error", err)
+		return err
+	}
+
+	if err := tx.Omit(clause.Associations).Create(period).Error; err != nil {
+		r.logger.Error("Failed to create stock period", "error", err)
+		return err
+	}
+
+	if err := tx.Exec(`
+		INSERT INTO stock_period_snapshots (stock_period_id, warehouse_id, product_id, quantity, total_value)
+		SELECT ?, warehouse_id, product_id, SUM(`+SignedQuantitySQL+`), SUM(`+SignedCostSQL+`)
+		FROM stock_transactions
+		WHERE created_at <= ?
+		GROUP BY warehouse_id, product_id
+		HAVING SUM(`+SignedQuantitySQL+`) <> 0 OR SUM(`+SignedCostSQL+`) <> 0
+	`, period.StockPeriodId, period.PeriodEnd).Error; err != nil {
+		r.logger.Error("Failed to write stock period snapshot", "error", err)
+		return err
+	}
+	return nil
+}
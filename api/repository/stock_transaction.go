@@ -9,28 +9,37 @@ import (
 	"gorm.io/gorm"
 )
 
+// SignedQuantitySQL is the SQL counterpart of model.StockTransaction.SignedQuantity.
+const SignedQuantitySQL = "CASE WHEN type = 'OUT' THEN -quantity ELSE quantity END"
+
 type StockTransactionSearchFilters struct {
-	Search    string
-	ProductId *uuid.UUID
+	Search      string
+	ProductId   *uuid.UUID
+	WarehouseId *uuid.UUID
 }
 
 type StockTransaction interface {
 	// Get
 	SearchWithFilters(db *gorm.DB, filters StockTransactionSearchFilters, orderBy string) ([]model.StockTransaction, error)
 	SearchWithFiltersAndPagination(db *gorm.DB, filters StockTransactionSearchFilters, orderBy string, page int, pageSize int) ([]model.StockTransaction, int64, error)
-	StockSummary(db *gorm.DB, productId uuid.UUID) (int64, int64, int64, error)
+	TransactionsByReference(db *gorm.DB, referenceId uuid.UUID, transactionType model.TransactionType) ([]model.StockTransaction, error)
 	// Create
 	Create(tx *gorm.DB, transaction *model.StockTransaction) error
-	GetLatestByProduct(db *gorm.DB, productId uuid.UUID) (*model.StockTransaction, error)
 }
 
 type stockTransaction struct {
-	logger *slog.Logger
+	logger           *slog.Logger
+	stockBalanceRepo StockBalance
+	costLayerRepo    CostLayer
+	stockPeriodRepo  StockPeriod
 }
 
-func NewStockTransaction(logger *slog.Logger) StockTransaction {
+func NewStockTransaction(logger *slog.Logger, stockBalanceRepo StockBalance, costLayerRepo CostLayer, stockPeriodRepo StockPeriod) StockTransaction {
 	return &stockTransaction{
-		logger: logger,
+		logger:           logger,
+		stockBalanceRepo: stockBalanceRepo,
+		costLayerRepo:    costLayerRepo,
+		stockPeriodRepo:  stockPeriodRepo,
 	}
 }
 
@@ -48,11 +57,15 @@ func (s *stockTransaction) SearchWithFilters(db *gorm.DB, filters StockTransacti
 		query = query.Where("product_id = ?", *filters.ProductId)
 	}
 
+	if filters.WarehouseId != nil {
+		query = query.Where("warehouse_id = ?", *filters.WarehouseId)
+	}
+
 	if orderBy != "" {
 		query = query.Order(orderBy)
 	}
 
-	if err := query.Preload("Product").Preload("Product.Category").Find(&transactions).Error; err != nil {
+	if err := query.Preload("Product").Preload("Product.Category").Preload("Warehouse").Preload("Location").Preload("Lot").Find(&transactions).Error; err != nil {
 		s.logger.Error("Failed to search stock transactions with filters", slog.String("error", err.Error()))
 		return nil, err
 	}
@@ -73,6 +86,9 @@ func (s *stockTransaction) SearchWithFiltersAndPagination(db *gorm.DB, filters S
 	if filters.ProductId != nil {
 		query = query.Where("product_id = ?", *filters.ProductId)
 	}
+	if filters.WarehouseId != nil {
+		query = query.Where("warehouse_id = ?", *filters.WarehouseId)
+	}
 
 	// นับจำนวนทั้งหมดก่อน pagination
 	if err := query.Count(&total).Error; err != nil {
@@ -87,8 +103,8 @@ func (s *stockTransaction) SearchWithFiltersAndPagination(db *gorm.DB, filters S
 		query = query.Order(orderBy)
 	}
 
-	// ดึงข้อมูลแบบ pagination พร้อม preload Product
-	if err := query.Preload("Product").Preload("Product.Category").Limit(pageSize).Offset(offset).Find(&transactions).Error; err != nil {
+	// ดึงข้อมูลแบบ pagination พร้อม preload Product และคลัง
+	if err := query.Preload("Product").Preload("Product.Category").Preload("Warehouse").Preload("Location").Preload("Lot").Limit(pageSize).Offset(offset).Find(&transactions).Error; err != nil {
 		s.logger.Error("Failed to search stock transactions with filters and pagination", slog.String("error", err.Error()))
 		return nil, 0, err
 	}
@@ -96,45 +112,32 @@ func (s *stockTransaction) SearchWithFiltersAndPagination(db *gorm.DB, filters S
 	return transactions, total, nil
 }
 
+// Create inserts a ledger row, applies it to stock_balances and costs it against the cost layers.
+// tx must be a DB transaction so the ledger, the balance projection and the layers commit or roll back together.
+// A row dated inside a closed period is rejected with ErrPeriodClosed.
 func (s *stockTransaction) Create(tx *gorm.DB, transaction *model.StockTransaction) error {
 	if err := tx.Create(transaction).Error; err != nil {
-		s.logger.Error("Failed to create stock in transaction", slog.String("error", err.Error()))
+		s.logger.Error("Failed to create stock transaction", slog.String("error", err.Error()))
 		return err
 	}
-	return nil
-}
 
-func (s *stockTransaction) StockSummary(db *gorm.DB, productId uuid.UUID) (int64, int64, int64, error) {
-	var totalIn, totalOut, totalAdjust int64
-
-	// Sum IN transactions
-	if err := db.Model(&model.StockTransaction{}).
-		Where("product_id = ? AND type = ?", productId, model.TransactionTypeIn).
-		Select("COALESCE(SUM(quantity), 0)").
-		Scan(&totalIn).Error; err != nil {
-		s.logger.Error("Failed to sum IN transactions", slog.String("error", err.Error()))
-		return 0, 0, 0, err
+	if _, err := s.stockBalanceRepo.ApplyTransaction(tx, transaction); err != nil {
+		s.logger.Error("Failed to apply stock transaction to balance", slog.String("error", err.Error()))
+		return err
 	}
 
-	// Sum OUT transactions
-	if err := db.Model(&model.StockTransaction{}).
-		Where("product_id = ? AND type = ?", productId, model.TransactionTypeOut).
-		Select("COALESCE(SUM(quantity), 0)").
-		Scan(&totalOut).Error; err != nil {
-		s.logger.Error("Failed to sum OUT transactions", slog.String("error", err.Error()))
-		return 0, 0, 0, err
+	// ตรวจหลังจาก lock balance แล้ว การปิดงวดที่ทำพร้อมกันจะรอ transaction นี้ หรือ transaction นี้จะเห็นงวดที่ปิดแล้ว
+	if err := s.stockPeriodRepo.CheckOpen(tx, transaction.CreatedAt); err != nil {
+		s.logger.Error("Stock transaction falls in a closed period", slog.String("error", err.Error()))
+		return err
 	}
 
-	// Sum ADJUST transactions
-	if err := db.Model(&model.StockTransaction{}).
-		Where("product_id = ? AND type = ?", productId, model.TransactionTypeAdjust).
-		Select("COALESCE(SUM(quantity), 0)").
-		Scan(&totalAdjust).Error; err != nil {
-		s.logger.Error("Failed to sum ADJUST transactions", slog.String("error", err.Error()))
-		return 0, 0, 0, err
+	// balance row ถูก lock แล้ว cost layer ของสินค้าในคลังนี้จึงไม่ถูกตัดพร้อมกัน
+	if err := s.costLayerRepo.Apply(tx, transaction); err != nil {
+		s.logger.Error("Failed to cost stock transaction", slog.String("error", err.Error()))
+		return err
 	}
-
-	return totalIn, totalOut, totalAdjust, nil
+	return nil
 }
 
 func (s *stockTransaction) TransactionsByProduct(db *gorm.DB, productId uuid.UUID) ([]model.StockTransaction, error) {
@@ -148,17 +151,14 @@ func (s *stockTransaction) TransactionsByProduct(db *gorm.DB, productId uuid.UUI
 	return transactions, nil
 }
 
-func (r *stockTransaction) GetLatestByProduct(db *gorm.DB, productId uuid.UUID) (*model.StockTransaction, error) {
-	var stockTx model.StockTransaction
-	err := db.Where("product_id = ?", productId).
-		Order("created_at DESC").
-		First(&stockTx).Error
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, nil
-		}
-		r.logger.Error("Failed to find latest stock transaction", "error", err)
+// TransactionsByReference returns the ledger rows of one type written for a document, e.g. the OUT half of a stock transfer
+func (s *stockTransaction) TransactionsByReference(db *gorm.DB, referenceId uuid.UUID, transactionType model.TransactionType) ([]model.StockTransaction, error) {
+	var transactions []model.StockTransaction
+
+	if err := db.Where("reference_id = ? AND type = ?", referenceId, transactionType).Order("created_at ASC").Find(&transactions).Error; err != nil {
+		s.logger.Error("Failed to get transactions by reference", slog.String("error", err.Error()))
 		return nil, err
 	}
-	return &stockTx, nil
+
+	return transactions, nil
 }
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"mini-erp-backend/lib/testdb"
+	"mini-erp-backend/model"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type fakeBalanceRepo struct {
+	StockBalance
+	applied int
+}
+
+func (f *fakeBalanceRepo) ApplyTransaction(_ *gorm.DB, transaction *model.StockTransaction) (*model.StockBalance, error) {
+	f.applied++
+	balance := model.StockBalance{}
+	balance.Apply(*transaction)
+	return &balance, nil
+}
+
+type fakeCostLayerRepo struct {
+	CostLayer
+	applied int
+}
+
+func (f *fakeCostLayerRepo) Apply(_ *gorm.DB, _ *model.StockTransaction) error {
+	f.applied++
+	return nil
+}
+
+func TestStockTransactionCreateRejectsClosedPeriod(t *testing.T) {
+	// งวด 2026-09 ปิดแล้ว
+	periodEnd := time.Date(2026, 9, 30, 23, 59, 59, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		createdAt time.Time
+		wantErr   error
+	}{
+		{"back-dated into the closed period", time.Date(2026, 9, 15, 10, 0, 0, 0, time.UTC), ErrPeriodClosed},
+		{"at the end of the closed period", periodEnd, ErrPeriodClosed},
+		{"after the closed period", periodEnd.Add(time.Second), nil},
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testdb.Open(t, testdb.Result{
+				Contains: `FROM "stock_periods"`,
+				Columns:  []string{"stock_period_id", "year", "month", "period_end"},
+				Rows:     [][]driver.Value{{uuid.NewString(), int64(2026), int64(9), periodEnd}},
+			})
+			balances := &fakeBalanceRepo{}
+			costLayers := &fakeCostLayerRepo{}
+			repo := NewStockTransaction(logger, balances, costLayers, NewStockPeriod(logger))
+
+			err := repo.Create(db, &model.StockTransaction{
+				StockTransactionId: uuid.New(),
+				ProductId:          uuid.New(),
+				WarehouseId:        uuid.New(),
+				Type:               model.TransactionTypeIn,
+				Quantity:           5,
+				CreatedAt:          tt.createdAt,
+			})
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Create error = %v, want %v", err, tt.wantErr)
+				}
+				// ไม่คิดต้นทุนให้รายการที่ถูกปฏิเสธ ส่วน ledger และ balance ย้อนกลับเมื่อผู้เรียก rollback
+				if costLayers.applied != 0 {
+					t.Errorf("cost layers applied %d times, want none", costLayers.applied)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Create error: %v", err)
+			}
+			if balances.applied != 1 || costLayers.applied != 1 {
+				t.Errorf("balance applied %d, cost layers applied %d, want 1 each", balances.applied, costLayers.applied)
+			}
+		})
+	}
+}
+
+func TestStockTransactionCreateWithoutClosedPeriod(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	costLayers := &fakeCostLayerRepo{}
+	repo := NewStockTransaction(logger, &fakeBalanceRepo{}, costLayers, NewStockPeriod(logger))
+
+	err := repo.Create(testdb.Open(t), &model.StockTransaction{
+		StockTransactionId: uuid.New(),
+		Type:               model.TransactionTypeOut,
+		Quantity:           2,
+		CreatedAt:          time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("Create error: %v", err)
+	}
+	if costLayers.applied != 1 {
+		t.Errorf("cost layers applied %d times, want 1", costLayers.applied)
+	}
+}
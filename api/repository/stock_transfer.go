@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"log/slog"
+	"mini-erp-backend/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StockTransferSearchFilters struct {
+	Status      *model.StockTransferStatus
+	ProductId   *uuid.UUID
+	WarehouseId *uuid.UUID // คลังต้นทางหรือปลายทาง
+}
+
+type StockTransfer interface {
+	// Get
+	LockById(tx *gorm.DB, transferId uuid.UUID) (*model.StockTransfer, error)
+	SearchWithFilters(db *gorm.DB, filters StockTransferSearchFilters, orderBy string) ([]model.StockTransfer, error)
+	SearchWithFiltersAndPagination(db *gorm.DB, filters StockTransferSearchFilters, orderBy string, page int, pageSize int) ([]model.StockTransfer, int64, error)
+	// Create
+	Create(tx *gorm.DB, transfer *model.StockTransfer) error
+	// Update
+	MarkReceived(tx *gorm.DB, transfer *model.StockTransfer) error
+}
+
+type stockTransfer struct {
+	logger *slog.Logger
+}
+
+func NewStockTransfer(logger *slog.Logger) StockTransfer {
+	return &stockTransfer{
+		logger: logger,
+	}
+}
+
+// LockById loads the transfer with SELECT ... FOR UPDATE so it cannot be received twice
+func (s *stockTransfer) LockById(tx *gorm.DB, transferId uuid.UUID) (*model.StockTransfer, error) {
+	transfer := model.StockTransfer{}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("stock_transfer_id = ?", transferId).
+		First(&transfer).Error; err != nil {
+		s.logger.Error("Failed to lock stock transfer", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+func (s *stockTransfer) applyFilters(query *gorm.DB, filters StockTransferSearchFilters) *gorm.DB {
+	if filters.Status != nil {
+		query = query.Where("status = ?", *filters.Status)
+	}
+	if filters.ProductId != nil {
+		query = query.Where("product_id = ?", *filters.ProductId)
+	}
+	if filters.WarehouseId != nil {
+		query = query.Where("from_warehouse_id = ? OR to_warehouse_id = ?", *filters.WarehouseId, *filters.WarehouseId)
+	}
+	return query
+}
+
+func (s *stockTransfer) SearchWithFilters(db *gorm.DB, filters StockTransferSearchFilters, orderBy string) ([]model.StockTransfer, error) {
+	transfers := []model.StockTransfer{}
+	query := s.applyFilters(db.Model(&model.StockTransfer{}), filters)
+
+	if orderBy != "" {
+		query = query.Order(orderBy)
+	}
+
+	if err := query.Preload("Product").Preload("FromWarehouse").Preload("ToWarehouse").Find(&transfers).Error; err != nil {
+		s.logger.Error("Failed to search stock transfers with filters", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return transfers, nil
+}
+
+func (s *stockTransfer) SearchWithFiltersAndPagination(db *gorm.DB, filters StockTransferSearchFilters, orderBy string, page int, pageSize int) ([]model.StockTransfer, int64, error) {
+	transfers := []model.StockTransfer{}
+	var total int64
+
+	query := s.applyFilters(db.Model(&model.StockTransfer{}), filters)
+
+	// นับจำนวนทั้งหมดก่อน pagination
+	if err := query.Count(&total).Error; err != nil {
+		s.logger.Error("Failed to count stock transfers", slog.String("error", err.Error()))
+		return nil, 0, err
+	}
+	offset := (page - 1) * pageSize
+
+	if orderBy != "" {
+		query = query.Order(orderBy)
+	}
+
+	if err := query.Preload("Product").Preload("FromWarehouse").Preload("ToWarehouse").Limit(pageSize).Offset(offset).Find(&transfers).Error; err != nil {
+		s.logger.Error("Failed to search stock transfers with filters and pagination", slog.String("error", err.Error()))
+		return nil, 0, err
+	}
+
+	return transfers, total, nil
+}
+
+func (s *stockTransfer) Create(tx *gorm.DB, transfer *model.StockTransfer) error {
+	if err := tx.Omit(clause.Associations).Create(transfer).Error; err != nil {
+		s.logger.Error("Failed to create stock transfer", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (s *stockTransfer) MarkReceived(tx *gorm.DB, transfer *model.StockTransfer) error {
+	if err := tx.Model(&model.StockTransfer{}).
+		Where("stock_transfer_id = ?", transfer.StockTransferId).
+		Updates(map[string]interface{}{
+			"status":         transfer.Status,
+			"to_location_id": transfer.ToLocationId,
+			"received_at":    transfer.ReceivedAt,
+			"received_by":    transfer.ReceivedBy,
+		}).Error; err != nil {
+		s.logger.Error("Failed to mark stock transfer received", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+	"mini-erp-backend/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrSupplierProductNotFound = errors.New("supplier product not found")
+
+type SupplierProduct interface {
+	// Get
+	SearchBySupplierId(db *gorm.DB, supplierId uuid.UUID) ([]model.SupplierProduct, error)
+	Search(db *gorm.DB, supplierId, productId uuid.UUID) (*model.SupplierProduct, error)
+	SearchesByProductIds(db *gorm.DB, supplierId uuid.UUID, productIds []uuid.UUID) (map[uuid.UUID]model.SupplierProduct, error)
+	ExitedBySupplierAndProduct(db *gorm.DB, supplierId, productId uuid.UUID) (bool, error)
+	// Create
+	Create(tx *gorm.DB, supplierProduct *model.SupplierProduct) error
+	// Update
+	Update(tx *gorm.DB, supplierProduct *model.SupplierProduct) error
+	// Delete
+	Delete(tx *gorm.DB, supplierProduct *model.SupplierProduct) error
+}
+
+type supplierProduct struct {
+	logger *slog.Logger
+}
+
+func NewSupplierProduct(logger *slog.Logger) SupplierProduct {
+	return &supplierProduct{
+		logger: logger,
+	}
+}
+
+func (r *supplierProduct) SearchBySupplierId(db *gorm.DB, supplierId uuid.UUID) ([]model.SupplierProduct, error) {
+	supplierProducts := []model.SupplierProduct{}
+	if err := db.Preload("Product").
+		Joins("JOIN products ON products.product_id = supplier_products.product_id").
+		Where("supplier_products.supplier_id = ?", supplierId).
+		Order("products.product_code ASC").
+		Find(&supplierProducts).Error; err != nil {
+		r.logger.Error("Failed to search supplier products", "supplier_id", supplierId, "error", err)
+		return nil, err
+	}
+	return supplierProducts, nil
+}
+
+func (r *supplierProduct) Search(db *gorm.DB, supplierId, productId uuid.UUID) (*model.SupplierProduct, error) {
+	supplierProducts := []model.SupplierProduct{}
+	if err := db.Preload("Product").
+		Where("supplier_id = ? AND product_id = ?", supplierId, productId).
+		Limit(1).
+		Find(&supplierProducts).Error; err != nil {
+		r.logger.Error("Failed to get supplier product", "error", err)
+		return nil, err
+	}
+
+	if len(supplierProducts) == 0 {
+		return nil, ErrSupplierProductNotFound
+	}
+	return &supplierProducts[0], nil
+}
+
+// SearchesByProductIds returns the catalog entries of a supplier keyed by product id.
+// Products the supplier does not list are missing from the map.
+func (r *supplierProduct) SearchesByProductIds(db *gorm.DB, supplierId uuid.UUID, productIds []uuid.UUID) (map[uuid.UUID]model.SupplierProduct, error) {
+	supplierProducts := []model.SupplierProduct{}
+	if err := db.Where("supplier_id = ? AND product_id IN ?", supplierId, productIds).
+		Find(&supplierProducts).Error; err != nil {
+		r.logger.Error("Failed to search supplier products by product ids", "supplier_id", supplierId, "error", err)
+		return nil, err
+	}
+
+	byProduct := make(map[uuid.UUID]model.SupplierProduct, len(supplierProducts))
+	for _, sp := range supplierProducts {
+		byProduct[sp.ProductId] = sp
+	}
+	return byProduct, nil
+}
+
+func (r *supplierProduct) ExitedBySupplierAndProduct(db *gorm.DB, supplierId, productId uuid.UUID) (bool, error) {
+	var count int64
+	if err := db.Model(&model.SupplierProduct{}).
+		Where("supplier_id = ? AND product_id = ?", supplierId, productId).
+		Count(&count).Error; err != nil {
+		r.logger.Error("Failed to check if supplier product exists", "error", err)
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *supplierProduct) Create(tx *gorm.DB, supplierProduct *model.SupplierProduct) error {
+	if err := tx.Omit(clause.Associations).Create(supplierProduct).Error; err != nil {
+		r.logger.Error("Failed to create supplier product", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (r *supplierProduct) Update(tx *gorm.DB, supplierProduct *model.SupplierProduct) error {
+	if err := tx.Omit(clause.Associations).Save(supplierProduct).Error; err != nil {
+		r.logger.Error("Failed to update supplier product", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (r *supplierProduct) Delete(tx *gorm.DB, supplierProduct *model.SupplierProduct) error {
+	if err := tx.Delete(&model.SupplierProduct{}, "supplier_product_id = ?", supplierProduct.SupplierProductId).Error; err != nil {
+		r.logger.Error("Failed to delete supplier product", "error", err)
+		return err
+	}
+	return nil
+}
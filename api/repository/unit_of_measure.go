@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"mini-erp-backend/model"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrUnitNotFound = errors.New("unit of measure not found")
+
+type UnitOfMeasure interface {
+	// Get
+	SearchByProductId(db *gorm.DB, productId uuid.UUID) ([]model.UnitOfMeasure, error)
+	SearchById(db *gorm.DB, productId, unitOfMeasureId uuid.UUID) (*model.UnitOfMeasure, error)
+	ExitedByName(db *gorm.DB, productId uuid.UUID, name string) (bool, error)
+	Factor(db *gorm.DB, product *model.Product, unit *string) (int64, error)
+	FactorsByName(db *gorm.DB, name string) (map[uuid.UUID]int64, error)
+	// Create
+	Create(tx *gorm.DB, unit *model.UnitOfMeasure) error
+	// Delete
+	Delete(tx *gorm.DB, unit *model.UnitOfMeasure) error
+}
+
+type unitOfMeasure struct {
+	logger *slog.Logger
+}
+
+func NewUnitOfMeasure(logger *slog.Logger) UnitOfMeasure {
+	return &unitOfMeasure{
+		logger: logger,
+	}
+}
+
+func (r *unitOfMeasure) SearchByProductId(db *gorm.DB, productId uuid.UUID) ([]model.UnitOfMeasure, error) {
+	units := []model.UnitOfMeasure{}
+	if err := db.Where("product_id = ?", productId).Order("factor ASC").Find(&units).Error; err != nil {
+		r.logger.Error("Failed to search units of measure", "product_id", productId, "error", err)
+		return nil, err
+	}
+	return units, nil
+}
+
+func (r *unitOfMeasure) SearchById(db *gorm.DB, productId, unitOfMeasureId uuid.UUID) (*model.UnitOfMeasure, error) {
+	units := []model.UnitOfMeasure{}
+	if err := db.Where("product_id = ? AND unit_of_measure_id = ?", productId, unitOfMeasureId).Limit(1).Find(&units).Error; err != nil {
+		r.logger.Error("Failed to get unit of measure", "error", err)
+		return nil, err
+	}
+
+	if len(units) == 0 {
+		return nil, ErrUnitNotFound
+	}
+	return &units[0], nil
+}
+
+func (r *unitOfMeasure) ExitedByName(db *gorm.DB, productId uuid.UUID, name string) (bool, error) {
+	var count int64
+	if err := db.Model(&model.UnitOfMeasure{}).
+		Where("product_id = ? AND LOWER(name) = LOWER(?)", productId, name).
+		Count(&count).Error; err != nil {
+		r.logger.Error("Failed to check if unit of measure exists", "error", err)
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Factor returns how many base units one unit holds. A nil or empty unit, or the product's own unit, is the base unit.
+func (r *unitOfMeasure) Factor(db *gorm.DB, product *model.Product, unit *string) (int64, error) {
+	if unit == nil || *unit == "" || strings.EqualFold(*unit, product.Unit) {
+		return 1, nil
+	}
+
+	units := []model.UnitOfMeasure{}
+	if err := db.Where("product_id = ? AND LOWER(name) = LOWER(?)", product.ProductId, *unit).Limit(1).Find(&units).Error; err != nil {
+		r.logger.Error("Failed to get unit of measure", "error", err)
+		return 0, err
+	}
+
+	if len(units) == 0 {
+		return 0, fmt.Errorf("%w: %s has no unit %s", ErrUnitNotFound, product.ProductCode, *unit)
+	}
+	return units[0].Factor, nil
+}
+
+// FactorsByName returns the factor of the unit called name for every product that has it, keyed by product id
+func (r *unitOfMeasure) FactorsByName(db *gorm.DB, name string) (map[uuid.UUID]int64, error) {
+	units := []model.UnitOfMeasure{}
+	if err := db.Where("LOWER(name) = LOWER(?)", name).Find(&units).Error; err != nil {
+		r.logger.Error("Failed to search units of measure by name", "name", name, "error", err)
+		return nil, err
+	}
+
+	factors := make(map[uuid.UUID]int64, len(units))
+	for _, unit := range units {
+		factors[unit.ProductId] = unit.Factor
+	}
+	return factors, nil
+}
+
+func (r *unitOfMeasure) Create(tx *gorm.DB, unit *model.UnitOfMeasure) error {
+	if err := tx.Omit("Product").Create(unit).Error; err != nil {
+		r.logger.Error("Failed to create unit of measure", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (r *unitOfMeasure) Delete(tx *gorm.DB, unit *model.UnitOfMeasure) error {
+	if err := tx.Delete(&model.UnitOfMeasure{}, "unit_of_measure_id = ?", unit.UnitOfMeasureId).Error; err != nil {
+		r.logger.Error("Failed to delete unit of measure", "error", err)
+		return err
+	}
+	return nil
+}
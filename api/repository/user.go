@@ -10,14 +10,36 @@ import (
 	"gorm.io/gorm"
 )
 
+type UserSearchFilters struct {
+	Search   string // ค้นหาจาก username ชื่อ และนามสกุล
+	Role     string
+	Disabled *bool
+}
+
 type User interface {
 	Search(db *gorm.DB, username string) (*model.User, error)
 	SearchByConditions(db *gorm.DB, conditions map[string]interface{}) (*model.User, error)
+	SearchById(db *gorm.DB, userId uuid.UUID) (*model.User, error)
+	SearchWithFiltersAndPagination(db *gorm.DB, filters UserSearchFilters, orderBy string, page, pageSize int) ([]model.User, int64, error)
 	UpdateTokenByUserId(db *gorm.DB, userId uuid.UUID, token *string) error
+	UpdateProfile(db *gorm.DB, userId uuid.UUID, firstName, lastName string) error
+	UpdateRole(db *gorm.DB, userId uuid.UUID, role model.Role) error
+	UpdateDisabled(db *gorm.DB, userId uuid.UUID, disabled bool) error
+	UserIdsByRole(db *gorm.DB, role model.Role) ([]uuid.UUID, error)
+	UpdatePassword(db *gorm.DB, userId uuid.UUID, passwordHash string) error
+	UpdateMustChangePassword(db *gorm.DB, userId uuid.UUID, mustChange bool) error
+	UpdateMfaSecret(db *gorm.DB, userId uuid.UUID, secret *string) error
+	EnableMfa(db *gorm.DB, userId uuid.UUID, counter int64) error
+	DisableMfa(db *gorm.DB, userId uuid.UUID) error
+	UseMfaCounter(db *gorm.DB, userId uuid.UUID, counter int64) (bool, error)
+	SealMfaSecrets(db *gorm.DB, isSealed func(string) bool, seal func(string) (string, error)) (int64, error)
 	SearchUserByToken(db *gorm.DB, token string) (*model.User, error)
 	Create(db *gorm.DB, user model.User) error
 }
 
+// userProfileColumns คือคอลัมน์ที่ส่งออกไปได้ ไม่รวม password และ token
+var userProfileColumns = []string{"user_id", "username", "first_name", "last_name", "role", "disabled", "must_change_password", "mfa_enabled", "mfa_secret", "mfa_last_counter", "created_at", "updated_at"}
+
 type user struct {
 	logger *slog.Logger
 }
@@ -32,7 +54,7 @@ func (r *user) Search(db *gorm.DB, username string) (*model.User, error) {
 
 	if err := db.
 		Table("users").
-		Select("user_id", "username", "first_name", "last_name", "password", "role", "created_at", "updated_at").
+		Select("user_id", "username", "first_name", "last_name", "password", "role", "disabled", "must_change_password", "mfa_enabled", "mfa_secret", "mfa_last_counter", "created_at", "updated_at").
 		Where("username = ?", username).
 		First(&user).Error; err != nil {
 		if r.logger != nil {
@@ -48,7 +70,7 @@ func (r *user) SearchByConditions(db *gorm.DB, conditions map[string]interface{}
 
 	if err := db.
 		Table("users").
-		Select("user_id", "username", "first_name", "last_name", "password", "role", "token", "created_at", "updated_at").
+		Select("user_id", "username", "first_name", "last_name", "password", "role", "disabled", "must_change_password", "mfa_enabled", "mfa_secret", "mfa_last_counter", "token", "created_at", "updated_at").
 		Where(conditions).
 		First(&user).Error; err != nil {
 		if r.logger != nil {
@@ -60,6 +82,71 @@ func (r *user) SearchByConditions(db *gorm.DB, conditions map[string]interface{}
 	return &user, nil
 }
 
+func (r *user) SearchById(db *gorm.DB, userId uuid.UUID) (*model.User, error) {
+	var user model.User
+
+	if err := db.
+		Table("users").
+		Select(userProfileColumns).
+		Where("user_id = ?", userId).
+		First(&user).Error; err != nil {
+		if r.logger != nil {
+			r.logger.Error("query user by id failed", "user_id", userId, "error", err)
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *user) applyFilters(query *gorm.DB, filters UserSearchFilters) *gorm.DB {
+	if filters.Search != "" {
+		searchPattern := "%" + filters.Search + "%"
+		query = query.Where(
+			"username ILIKE ? OR first_name ILIKE ? OR last_name ILIKE ?",
+			searchPattern, searchPattern, searchPattern,
+		)
+	}
+	if filters.Role != "" {
+		query = query.Where("role = ?", strings.ToLower(filters.Role))
+	}
+	if filters.Disabled != nil {
+		query = query.Where("disabled = ?", *filters.Disabled)
+	}
+	return query
+}
+
+func (r *user) SearchWithFiltersAndPagination(db *gorm.DB, filters UserSearchFilters, orderBy string, page, pageSize int) ([]model.User, int64, error) {
+	users := []model.User{}
+	var total int64
+
+	query := r.applyFilters(db.Table("users"), filters)
+
+	// นับจำนวนทั้งหมด
+	if err := query.Count(&total).Error; err != nil {
+		if r.logger != nil {
+			r.logger.Error("count users with filters failed", "error", err)
+		}
+		return nil, 0, err
+	}
+
+	// เรียงลำดับ
+	if orderBy != "" {
+		query = query.Order(orderBy)
+	}
+
+	// ดึงข้อมูลแบบ pagination
+	offset := (page - 1) * pageSize
+	if err := query.Select(userProfileColumns).Offset(offset).Limit(pageSize).Find(&users).Error; err != nil {
+		if r.logger != nil {
+			r.logger.Error("search users with filters and pagination failed", "error", err)
+		}
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
 func (r user) UpdateTokenByUserId(db *gorm.DB, userId uuid.UUID, token *string) error {
 	if err := db.Model(&model.User{}).
 		Where("user_id = ?", userId).
@@ -73,6 +160,177 @@ func (r user) UpdateTokenByUserId(db *gorm.DB, userId uuid.UUID, token *string)
 	return nil
 }
 
+func (r *user) UpdateProfile(db *gorm.DB, userId uuid.UUID, firstName, lastName string) error {
+	if err := db.Model(&model.User{}).
+		Where("user_id = ?", userId).
+		Updates(map[string]interface{}{"first_name": firstName, "last_name": lastName}).
+		Error; err != nil {
+		if r.logger != nil {
+			r.logger.Error("can not update user profile", "user_id", userId, "error", err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *user) UpdateRole(db *gorm.DB, userId uuid.UUID, role model.Role) error {
+	if err := db.Model(&model.User{}).
+		Where("user_id = ?", userId).
+		Update("role", role).
+		Error; err != nil {
+		if r.logger != nil {
+			r.logger.Error("can not update user role", "user_id", userId, "error", err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *user) UpdateDisabled(db *gorm.DB, userId uuid.UUID, disabled bool) error {
+	if err := db.Model(&model.User{}).
+		Where("user_id = ?", userId).
+		Update("disabled", disabled).
+		Error; err != nil {
+		if r.logger != nil {
+			r.logger.Error("can not update user disabled flag", "user_id", userId, "error", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// UserIdsByRole returns the id of every user assigned to the role, disabled users included
+func (r *user) UserIdsByRole(db *gorm.DB, role model.Role) ([]uuid.UUID, error) {
+	userIds := []uuid.UUID{}
+	if err := db.Model(&model.User{}).
+		Where("role = ?", role).
+		Pluck("user_id", &userIds).
+		Error; err != nil {
+		if r.logger != nil {
+			r.logger.Error("can not search users by role", "role", role, "error", err)
+		}
+		return nil, err
+	}
+	return userIds, nil
+}
+
+// UpdatePassword stores a new bcrypt hash and clears the must change password flag
+func (r *user) UpdatePassword(db *gorm.DB, userId uuid.UUID, passwordHash string) error {
+	if err := db.Model(&model.User{}).
+		Where("user_id = ?", userId).
+		Updates(map[string]interface{}{"password": passwordHash, "must_change_password": false}).
+		Error; err != nil {
+		if r.logger != nil {
+			r.logger.Error("can not update user password", "user_id", userId, "error", err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *user) UpdateMustChangePassword(db *gorm.DB, userId uuid.UUID, mustChange bool) error {
+	if err := db.Model(&model.User{}).
+		Where("user_id = ?", userId).
+		Update("must_change_password", mustChange).
+		Error; err != nil {
+		if r.logger != nil {
+			r.logger.Error("can not update user must change password flag", "user_id", userId, "error", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// UpdateMfaSecret stores a pending TOTP secret, MFA stays disabled until a code from it is verified
+func (r *user) UpdateMfaSecret(db *gorm.DB, userId uuid.UUID, secret *string) error {
+	if err := db.Model(&model.User{}).
+		Where("user_id = ?", userId).
+		Updates(map[string]interface{}{"mfa_secret": secret, "mfa_enabled": false, "mfa_last_counter": 0}).
+		Error; err != nil {
+		if r.logger != nil {
+			r.logger.Error("can not update user mfa secret", "user_id", userId, "error", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// SealMfaSecrets encrypts the TOTP secrets stored in plaintext before they were encrypted at rest
+func (r *user) SealMfaSecrets(db *gorm.DB, isSealed func(string) bool, seal func(string) (string, error)) (int64, error) {
+	users := []model.User{}
+	if err := db.Select("user_id", "mfa_secret").
+		Where("mfa_secret IS NOT NULL").
+		Find(&users).Error; err != nil {
+		if r.logger != nil {
+			r.logger.Error("can not get user mfa secrets", "error", err)
+		}
+		return 0, err
+	}
+
+	var sealedCount int64
+	for _, u := range users {
+		if isSealed(*u.MfaSecret) {
+			continue
+		}
+
+		sealed, err := seal(*u.MfaSecret)
+		if err != nil {
+			return sealedCount, err
+		}
+		if err := db.Model(&model.User{}).
+			Where("user_id = ?", u.UserId).
+			Update("mfa_secret", sealed).Error; err != nil {
+			if r.logger != nil {
+				r.logger.Error("can not encrypt user mfa secret", "user_id", u.UserId, "error", err)
+			}
+			return sealedCount, err
+		}
+		sealedCount++
+	}
+	return sealedCount, nil
+}
+
+func (r *user) EnableMfa(db *gorm.DB, userId uuid.UUID, counter int64) error {
+	if err := db.Model(&model.User{}).
+		Where("user_id = ?", userId).
+		Updates(map[string]interface{}{"mfa_enabled": true, "mfa_last_counter": counter}).
+		Error; err != nil {
+		if r.logger != nil {
+			r.logger.Error("can not enable user mfa", "user_id", userId, "error", err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *user) DisableMfa(db *gorm.DB, userId uuid.UUID) error {
+	if err := db.Model(&model.User{}).
+		Where("user_id = ?", userId).
+		Updates(map[string]interface{}{"mfa_enabled": false, "mfa_secret": nil, "mfa_last_counter": 0}).
+		Error; err != nil {
+		if r.logger != nil {
+			r.logger.Error("can not disable user mfa", "user_id", userId, "error", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// UseMfaCounter records the time step of an accepted TOTP code, it returns false when the same
+// or a later step was already used so a code cannot be replayed even by concurrent requests
+func (r *user) UseMfaCounter(db *gorm.DB, userId uuid.UUID, counter int64) (bool, error) {
+	result := db.Model(&model.User{}).
+		Where("user_id = ? AND mfa_last_counter < ?", userId, counter).
+		Update("mfa_last_counter", counter)
+	if result.Error != nil {
+		if r.logger != nil {
+			r.logger.Error("can not update user mfa counter", "user_id", userId, "error", result.Error)
+		}
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
 func (r *user) SearchUserByToken(db *gorm.DB, token string) (*model.User, error) {
 	var user model.User
 
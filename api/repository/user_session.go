@@ -13,8 +13,15 @@ var ErrSessionRevoked = errors.New("session revoked")
 
 type UserSession interface {
 	Create(db *gorm.DB, session *model.UserSession) error
-	GetSessionByRefreshToken(db *gorm.DB, refreshToken string) (*model.UserSession, error)
-	UpdateAccessToken(db *gorm.DB, sessionId uuid.UUID, accessToken string) error
+	SearchBySessionId(db *gorm.DB, sessionId uuid.UUID) (*model.UserSession, error)
+	SearchByAccessUuid(db *gorm.DB, accessUuid string) (*model.UserSession, error)
+	Rotate(db *gorm.DB, sessionId uuid.UUID, accessToken string, accessUuid string, refreshTokenHash string) error
+	Revoke(db *gorm.DB, sessionId uuid.UUID) error
+	RevokeByUserId(db *gorm.DB, userId uuid.UUID) (int64, error)
+	RevokeOthersByUserId(db *gorm.DB, userId uuid.UUID, keepSessionId uuid.UUID) (int64, error)
+	RevokeByUserIds(db *gorm.DB, userIds []uuid.UUID) (int64, error)
+	UpdateMustChangePassword(db *gorm.DB, sessionId uuid.UUID, mustChange bool) error
+	UpdateMfaEnrollRequired(db *gorm.DB, sessionId uuid.UUID, required bool) error
 }
 
 type userSession struct {
@@ -47,39 +54,139 @@ func (r *userSession) Create(db *gorm.DB, session *model.UserSession) error {
 	return err
 }
 
-func (r *userSession) GetSessionByRefreshToken(db *gorm.DB, refreshToken string) (*model.UserSession, error) {
+func (r *userSession) SearchBySessionId(db *gorm.DB, sessionId uuid.UUID) (*model.UserSession, error) {
 	var session model.UserSession
-	// First try to find any session with this refresh token (include revoked flag)
 	err := db.Model(&model.UserSession{}).
-		Select("refresh_token", "revoked", "user_id", "session_id").
-		Where("refresh_token = ?", refreshToken).
+		Select("session_id", "user_id", "access_uuid", "revoked", "must_change_password", "mfa_enroll_required").
+		Where("session_id = ?", sessionId).
 		First(&session).Error
 
 	if err != nil {
 		if r.logger != nil {
-			r.logger.Error("failed to find user session by refresh token", "error", err)
+			r.logger.Error("failed to find user session", "session_id", sessionId, "error", err)
 		}
 		return nil, err
 	}
 
-	// If the session exists but is revoked, return a specific error
-	if session.Revoked {
+	return &session, nil
+}
+
+// SearchByAccessUuid returns the session that issued the access token, revoked or not
+func (r *userSession) SearchByAccessUuid(db *gorm.DB, accessUuid string) (*model.UserSession, error) {
+	var session model.UserSession
+	err := db.Model(&model.UserSession{}).
+		Select("session_id", "user_id", "access_uuid", "revoked", "must_change_password", "mfa_enroll_required").
+		Where("access_uuid = ?", accessUuid).
+		First(&session).Error
+
+	if err != nil {
 		if r.logger != nil {
-			r.logger.Warn("refresh token found but revoked", "session_id", session.SessionId)
+			r.logger.Error("failed to find user session by access uuid", "error", err)
 		}
-		return nil, ErrSessionRevoked
+		return nil, err
 	}
 
 	return &session, nil
 }
 
-func (r *userSession) UpdateAccessToken(db *gorm.DB, sessionId uuid.UUID, accessToken string) error {
-	// Only update the access_token. Refresh token rotation is handled explicitly elsewhere.
+// Rotate replaces the access token and the refresh token hash of a session after a refresh
+func (r *userSession) Rotate(db *gorm.DB, sessionId uuid.UUID, accessToken string, accessUuid string, refreshTokenHash string) error {
+	if err := db.Model(&model.UserSession{}).
+		Where("session_id = ?", sessionId).
+		Updates(map[string]interface{}{
+			"access_token":  accessToken,
+			"access_uuid":   accessUuid,
+			"refresh_token": refreshTokenHash,
+		}).Error; err != nil {
+		if r.logger != nil {
+			r.logger.Error("failed to rotate tokens for session", "session_id", sessionId, "error", err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *userSession) Revoke(db *gorm.DB, sessionId uuid.UUID) error {
+	if err := db.Model(&model.UserSession{}).
+		Where("session_id = ?", sessionId).
+		Update("revoked", true).Error; err != nil {
+		if r.logger != nil {
+			r.logger.Error("failed to revoke user session", "session_id", sessionId, "error", err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *userSession) RevokeByUserId(db *gorm.DB, userId uuid.UUID) (int64, error) {
+	result := db.Model(&model.UserSession{}).
+		Where("user_id = ? AND revoked = ?", userId, false).
+		Update("revoked", true)
+	if result.Error != nil {
+		if r.logger != nil {
+			r.logger.Error("failed to revoke user sessions", "user_id", userId, "error", result.Error)
+		}
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+// RevokeOthersByUserId revokes every active session of the user except keepSessionId
+func (r *userSession) RevokeOthersByUserId(db *gorm.DB, userId uuid.UUID, keepSessionId uuid.UUID) (int64, error) {
+	result := db.Model(&model.UserSession{}).
+		Where("user_id = ? AND session_id <> ? AND revoked = ?", userId, keepSessionId, false).
+		Update("revoked", true)
+	if result.Error != nil {
+		if r.logger != nil {
+			r.logger.Error("failed to revoke other user sessions", "user_id", userId, "error", result.Error)
+		}
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+// RevokeByUserIds revokes every active session of the given users
+func (r *userSession) RevokeByUserIds(db *gorm.DB, userIds []uuid.UUID) (int64, error) {
+	if len(userIds) == 0 {
+		return 0, nil
+	}
+
+	result := db.Model(&model.UserSession{}).
+		Where("user_id IN ? AND revoked = ?", userIds, false).
+		Update("revoked", true)
+	if result.Error != nil {
+		if r.logger != nil {
+			r.logger.Error("failed to revoke sessions of users", "users", len(userIds), "error", result.Error)
+		}
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (r *userSession) UpdateMustChangePassword(db *gorm.DB, sessionId uuid.UUID, mustChange bool) error {
+	if err := db.Model(&model.UserSession{}).
+		Where("session_id = ?", sessionId).
+		Update("must_change_password", mustChange).Error; err != nil {
+		if r.logger != nil {
+			r.logger.Error("failed to update session must change password flag", "session_id", sessionId, "error", err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *userSession) UpdateMfaEnrollRequired(db *gorm.DB, sessionId uuid.UUID, required bool) error {
 	if err := db.Model(&model.UserSession{}).
 		Where("session_id = ?", sessionId).
-		Update("access_token", accessToken).Error; err != nil {
+		Update("mfa_enroll_required", required).Error; err != nil {
 		if r.logger != nil {
-			r.logger.Error("failed to update access token for session", "session_id", sessionId, "error", err)
+			r.logger.Error("failed to update session mfa enroll required flag", "session_id", sessionId, "error", err)
 		}
 		return err
 	}
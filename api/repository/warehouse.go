@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"mini-erp-backend/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrWarehouseNotFound = errors.New("warehouse not found")
+	ErrLocationNotFound  = errors.New("location not found in warehouse")
+)
+
+// DefaultWarehouseCode is the warehouse created on first start so existing stock has a home
+const DefaultWarehouseCode = "MAIN"
+
+type WarehouseSearchFilters struct {
+	Search string // ค้นหาจากรหัสและชื่อคลัง
+}
+
+type Warehouse interface {
+	// Get
+	Search(db *gorm.DB, conditions map[string]interface{}, orderBy string) (*model.Warehouse, error)
+	SearchWithFilters(db *gorm.DB, filters WarehouseSearchFilters, orderBy string) ([]model.Warehouse, error)
+	SearchWithLocations(db *gorm.DB, warehouseId uuid.UUID) (*model.Warehouse, error)
+	ExitedByCode(db *gorm.DB, code string, excludeId *uuid.UUID) (bool, error)
+	Resolve(db *gorm.DB, warehouseId *uuid.UUID, locationId *uuid.UUID) (*model.Warehouse, error)
+	// Create
+	Create(tx *gorm.DB, warehouse *model.Warehouse) error
+	EnsureDefault(tx *gorm.DB) (*model.Warehouse, error)
+	// Update
+	Update(tx *gorm.DB, warehouse *model.Warehouse) error
+	ClearDefault(tx *gorm.DB, exceptId uuid.UUID) error
+	AssignDefaultWarehouse(tx *gorm.DB, table string, warehouseId uuid.UUID) (int64, error)
+
+	// Location
+	ExitedLocationByCode(db *gorm.DB, warehouseId uuid.UUID, code string) (bool, error)
+	CreateLocation(tx *gorm.DB, location *model.Location) error
+}
+
+type warehouse struct {
+	logger *slog.Logger
+}
+
+func NewWarehouse(logger *slog.Logger) Warehouse {
+	return &warehouse{
+		logger: logger,
+	}
+}
+
+func (r *warehouse) Search(db *gorm.DB, conditions map[string]interface{}, orderBy string) (*model.Warehouse, error) {
+	warehouses := []model.Warehouse{}
+
+	if err := db.Where(conditions).Order(orderBy).Limit(1).Find(&warehouses).Error; err != nil {
+		r.logger.Error("Failed to get warehouse", "error", err)
+		return nil, err
+	}
+
+	if len(warehouses) == 0 {
+		err := gorm.ErrRecordNotFound
+		r.logger.Error("Warehouse not found", "error", err)
+		return nil, err
+	}
+
+	return &warehouses[0], nil
+}
+
+func (r *warehouse) SearchWithFilters(db *gorm.DB, filters WarehouseSearchFilters, orderBy string) ([]model.Warehouse, error) {
+	warehouses := []model.Warehouse{}
+	query := db.Model(&model.Warehouse{})
+
+	if filters.Search != "" {
+		searchPattern := "%" + filters.Search + "%"
+		query = query.Where("code ILIKE ? OR name ILIKE ?", searchPattern, searchPattern)
+	}
+
+	if orderBy != "" {
+		query = query.Order(orderBy)
+	}
+
+	if err := query.Find(&warehouses).Error; err != nil {
+		r.logger.Error("Failed to search warehouses with filters", "error", err)
+		return nil, err
+	}
+
+	return warehouses, nil
+}
+
+func (r *warehouse) SearchWithLocations(db *gorm.DB, warehouseId uuid.UUID) (*model.Warehouse, error) {
+	warehouse := model.Warehouse{}
+
+	if err := db.Preload("Locations", func(db *gorm.DB) *gorm.DB {
+		return db.Order("code ASC")
+	}).Where("warehouse_id = ?", warehouseId).First(&warehouse).Error; err != nil {
+		r.logger.Error("Failed to get warehouse with locations", "warehouse_id", warehouseId, "error", err)
+		return nil, err
+	}
+
+	return &warehouse, nil
+}
+
+func (r *warehouse) ExitedByCode(db *gorm.DB, code string, excludeId *uuid.UUID) (bool, error) {
+	var count int64
+	query := db.Model(&model.Warehouse{}).Where("code = ?", code)
+	if excludeId != nil {
+		query = query.Where("warehouse_id != ?", *excludeId)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		r.logger.Error("Failed to check if warehouse exists by code", "error", err)
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Resolve returns the requested warehouse, or the default warehouse when warehouseId is nil.
+// When locationId is given it must be a location of that warehouse.
+func (r *warehouse) Resolve(db *gorm.DB, warehouseId *uuid.UUID, locationId *uuid.UUID) (*model.Warehouse, error) {
+	conditions := map[string]interface{}{"is_default": true}
+	if warehouseId != nil {
+		conditions = map[string]interface{}{"warehouse_id": *warehouseId}
+	}
+
+	warehouses := []model.Warehouse{}
+	if err := db.Where(conditions).Limit(1).Find(&warehouses).Error; err != nil {
+		r.logger.Error("Failed to resolve warehouse", "error", err)
+		return nil, err
+	}
+	if len(warehouses) == 0 {
+		return nil, ErrWarehouseNotFound
+	}
+
+	if locationId != nil {
+		var count int64
+		if err := db.Model(&model.Location{}).
+			Where("location_id = ? AND warehouse_id = ?", *locationId, warehouses[0].WarehouseId).
+			Count(&count).Error; err != nil {
+			r.logger.Error("Failed to resolve location", "error", err)
+			return nil, err
+		}
+		if count == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrLocationNotFound, warehouses[0].Code)
+		}
+	}
+
+	return &warehouses[0], nil
+}
+
+func (r *warehouse) Create(tx *gorm.DB, warehouse *model.Warehouse) error {
+	if err := tx.Omit("Locations").Create(warehouse).Error; err != nil {
+		r.logger.Error("Failed to create warehouse", "error", err)
+		return err
+	}
+	return nil
+}
+
+// EnsureDefault returns the default warehouse, creating MAIN when there is none yet
+func (r *warehouse) EnsureDefault(tx *gorm.DB) (*model.Warehouse, error) {
+	warehouses := []model.Warehouse{}
+	if err := tx.Where("is_default = ?", true).Limit(1).Find(&warehouses).Error; err != nil {
+		r.logger.Error("Failed to get default warehouse", "error", err)
+		return nil, err
+	}
+	if len(warehouses) > 0 {
+		return &warehouses[0], nil
+	}
+
+	warehouse := &model.Warehouse{
+		Code:      DefaultWarehouseCode,
+		Name:      "Main Warehouse",
+		IsDefault: true,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := r.Create(tx, warehouse); err != nil {
+		return nil, err
+	}
+
+	return warehouse, nil
+}
+
+func (r *warehouse) Update(tx *gorm.DB, warehouse *model.Warehouse) error {
+	if err := tx.Omit("Locations").Save(warehouse).Error; err != nil {
+		r.logger.Error("Failed to update warehouse", "error", err)
+		return err
+	}
+	return nil
+}
+
+// ClearDefault unsets is_default on every warehouse except exceptId so there is only one default
+func (r *warehouse) ClearDefault(tx *gorm.DB, exceptId uuid.UUID) error {
+	if err := tx.Model(&model.Warehouse{}).
+		Where("is_default = ? AND warehouse_id != ?", true, exceptId).
+		Update("is_default", false).Error; err != nil {
+		r.logger.Error("Failed to clear default warehouse", "error", err)
+		return err
+	}
+	return nil
+}
+
+// AssignDefaultWarehouse adds a nullable warehouse_id column to a table created before warehouses existed
+// and points every existing row at warehouseId, so AutoMigrate can then make the column NOT NULL.
+func (r *warehouse) AssignDefaultWarehouse(tx *gorm.DB, table string, warehouseId uuid.UUID) (int64, error) {
+	if err := tx.Exec("ALTER TABLE " + table + " ADD COLUMN IF NOT EXISTS warehouse_id uuid").Error; err != nil {
+		r.logger.Error("Failed to add warehouse column", "table", table, "error", err)
+		return 0, err
+	}
+
+	result := tx.Table(table).Where("warehouse_id IS NULL").Update("warehouse_id", warehouseId)
+	if result.Error != nil {
+		r.logger.Error("Failed to assign default warehouse", "table", table, "error", result.Error)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *warehouse) ExitedLocationByCode(db *gorm.DB, warehouseId uuid.UUID, code string) (bool, error) {
+	var count int64
+	if err := db.Model(&model.Location{}).
+		Where("warehouse_id = ? AND code = ?", warehouseId, code).
+		Count(&count).Error; err != nil {
+		r.logger.Error("Failed to check if location exists by code", "error", err)
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *warehouse) CreateLocation(tx *gorm.DB, location *model.Location) error {
+	if err := tx.Create(location).Error; err != nil {
+		r.logger.Error("Failed to create location", "error", err)
+		return err
+	}
+	return nil
+}
@@ -2,16 +2,28 @@ package api
 
 import (
 	"log/slog"
+	audit_log_handler "mini-erp-backend/api/handler/audit_log"
 	auth_handler "mini-erp-backend/api/handler/auth"
 	category_handler "mini-erp-backend/api/handler/category"
+	customer_handler "mini-erp-backend/api/handler/customer"
 	product_handler "mini-erp-backend/api/handler/product"
 	"mini-erp-backend/api/handler/purchase_order"
 	register_handler "mini-erp-backend/api/handler/register"
+	replenishment_handler "mini-erp-backend/api/handler/replenishment"
 	"mini-erp-backend/api/handler/report"
+	role_handler "mini-erp-backend/api/handler/role"
+	"mini-erp-backend/api/handler/sales_order"
+	serial_handler "mini-erp-backend/api/handler/serial_number"
+	stockcount_handler "mini-erp-backend/api/handler/stock_count"
+	stockperiod_handler "mini-erp-backend/api/handler/stock_period"
 	stocktransaction_handler "mini-erp-backend/api/handler/stock_transaction"
 	"mini-erp-backend/api/handler/supplier"
+	user_handler "mini-erp-backend/api/handler/user"
+	warehouse_handler "mini-erp-backend/api/handler/warehouse"
 	"mini-erp-backend/lib/jwt"
 	"mini-erp-backend/middleware"
+	"mini-erp-backend/model"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -21,36 +33,80 @@ func Register(
 	logger *slog.Logger,
 	jwt jwt.Manager,
 	mid *middleware.FiberMiddleware,
+	location *time.Location,
 ) {
 	v1 := app.Group("/api/v1")
 
 	// Auth routes
 	authGroupApi := v1.Group("/auth")
 	{
+		// AuditLog ต้องอยู่หลัง Authenticated ใส่ทีละ route เพราะ login/refresh/reset/verify ไม่มีผู้ใช้ที่ login อยู่
 		authGroupApi.Post("/login", auth_handler.Login(logger))
 		authGroupApi.Post("/token/refresh", auth_handler.RefreshAccessToken(logger))
+		authGroupApi.Post("/logout", mid.Authenticated(), mid.AuditLog(), auth_handler.Logout(logger))
+		authGroupApi.Post("/logout-all", mid.Authenticated(), mid.AuditLog(), auth_handler.LogoutAll(logger))
+		authGroupApi.Post("/password", mid.Authenticated(), mid.AuditLog(), auth_handler.ChangePassword(logger))
+		authGroupApi.Post("/password/reset", auth_handler.ResetPassword(logger))
+		authGroupApi.Post("/mfa/verify", auth_handler.VerifyMfa(logger))
+		authGroupApi.Post("/mfa/enroll", mid.Authenticated(), mid.AuditLog(), auth_handler.EnrollMfa(logger))
+		authGroupApi.Post("/mfa/enable", mid.Authenticated(), mid.AuditLog(), auth_handler.EnableMfa(logger))
+		authGroupApi.Post("/mfa/disable", mid.Authenticated(), mid.AuditLog(), auth_handler.DisableMfa(logger))
+		authGroupApi.Post("/mfa/recovery-codes", mid.Authenticated(), mid.AuditLog(), auth_handler.RegenerateRecoveryCodes(logger))
 	}
 
 	// Supplier routes
 	supplierGroup := v1.Group("/suppliers")
 	{
-		supplierGroup.Get("/", mid.RequireMinRole("viewer"), supplier.AllSuppliers(logger))
-		supplierGroup.Post("/", mid.RequireMinRole("admin"), supplier.CreateSupplier(logger))
-		supplierGroup.Get("/:id", mid.RequireMinRole("admin"), supplier.Supplier(logger))
-		supplierGroup.Put("/:id", mid.RequireMinRole("admin"), supplier.UpdateSupplier(logger))
-		supplierGroup.Delete("/:id", mid.RequireMinRole("admin"), supplier.DeleteSupplier(logger))
+		supplierGroup.Use(mid.Authenticated())
+		supplierGroup.Use(mid.AuditLog())
+
+		supplierGroup.Get("/", mid.RequirePermission(model.PermissionSupplierRead), supplier.AllSuppliers(logger))
+		supplierGroup.Post("/", mid.RequirePermission(model.PermissionSupplierManage), supplier.CreateSupplier(logger))
+		supplierGroup.Get("/:id", mid.RequirePermission(model.PermissionSupplierRead), supplier.Supplier(logger))
+		supplierGroup.Put("/:id", mid.RequirePermission(model.PermissionSupplierManage), supplier.UpdateSupplier(logger))
+		supplierGroup.Delete("/:id", mid.RequirePermission(model.PermissionSupplierManage), supplier.DeleteSupplier(logger))
+		supplierGroup.Get("/:id/products", mid.RequirePermission(model.PermissionSupplierRead), supplier.SupplierProducts(logger))
+		supplierGroup.Post("/:id/products", mid.RequirePermission(model.PermissionSupplierManage), supplier.CreateSupplierProduct(logger))
+		supplierGroup.Put("/:id/products/:productId", mid.RequirePermission(model.PermissionSupplierManage), supplier.UpdateSupplierProduct(logger))
+		supplierGroup.Delete("/:id/products/:productId", mid.RequirePermission(model.PermissionSupplierManage), supplier.DeleteSupplierProduct(logger))
 	}
 
 	// Purchase Order routes
 	purchaseOrderGroup := v1.Group("/purchase-orders")
 	{
 		purchaseOrderGroup.Use(mid.Authenticated())
+		purchaseOrderGroup.Use(mid.AuditLog())
+
+		purchaseOrderGroup.Get("/", mid.RequirePermission(model.PermissionPurchaseOrderRead), purchase_order.AllPurchaseOrders(logger))
+		purchaseOrderGroup.Post("/", mid.RequirePermission(model.PermissionPurchaseOrderWrite), purchase_order.CreatePurchaseOrder(logger))
+		purchaseOrderGroup.Get("/:id", mid.RequirePermission(model.PermissionPurchaseOrderRead), purchase_order.PurchaseOrder(logger))
+		purchaseOrderGroup.Put("/:id", mid.RequirePermission(model.PermissionPurchaseOrderWrite), purchase_order.UpdatePurchaseOrder(logger))
+		purchaseOrderGroup.Put("/:id/status", mid.RequirePermission(model.PermissionPurchaseOrderApprove), purchase_order.UpdatePurchaseOrderStatus(logger))
+		purchaseOrderGroup.Get("/:id/receipts", mid.RequirePermission(model.PermissionPurchaseOrderRead), purchase_order.GoodsReceipts(logger))
+		purchaseOrderGroup.Post("/:id/receipts", mid.RequirePermission(model.PermissionPurchaseOrderReceive), purchase_order.CreateGoodsReceipt(logger))
+	}
+
+	// Replenishment routes
+	replenishmentGroup := v1.Group("/replenishment")
+	{
+		replenishmentGroup.Use(mid.Authenticated())
+		replenishmentGroup.Use(mid.AuditLog())
+
+		replenishmentGroup.Get("/suggestions", mid.RequirePermission(model.PermissionReplenishmentRead), replenishment_handler.Suggestions(logger))
+		replenishmentGroup.Post("/generate", mid.RequirePermission(model.PermissionReplenishmentGenerate), replenishment_handler.Generate(logger))
+	}
+
+	// Sales Order routes
+	salesOrderGroup := v1.Group("/sales-orders")
+	{
+		salesOrderGroup.Use(mid.Authenticated())
+		salesOrderGroup.Use(mid.AuditLog())
 
-		purchaseOrderGroup.Get("/", mid.RequireMinRole("viewer"), purchase_order.AllPurchaseOrders(logger))
-		purchaseOrderGroup.Post("/", mid.RequireMinRole("staff"), purchase_order.CreatePurchaseOrder(logger))
-		purchaseOrderGroup.Get("/:id", mid.RequireMinRole("staff"), purchase_order.PurchaseOrder(logger))
-		purchaseOrderGroup.Put("/:id", mid.RequireMinRole("staff"), purchase_order.UpdatePurchaseOrder(logger))
-		purchaseOrderGroup.Put("/:id/status", mid.RequireMinRole("staff"), purchase_order.UpdatePurchaseOrderStatus(logger))
+		salesOrderGroup.Get("/", mid.RequirePermission(model.PermissionSalesOrderRead), sales_order.AllSalesOrders(logger))
+		salesOrderGroup.Post("/", mid.RequirePermission(model.PermissionSalesOrderWrite), sales_order.CreateSalesOrder(logger))
+		salesOrderGroup.Get("/:id", mid.RequirePermission(model.PermissionSalesOrderRead), sales_order.SalesOrder(logger))
+		salesOrderGroup.Put("/:id", mid.RequirePermission(model.PermissionSalesOrderWrite), sales_order.UpdateSalesOrder(logger))
+		salesOrderGroup.Put("/:id/status", mid.RequirePermission(model.PermissionSalesOrderWrite), sales_order.UpdateSalesOrderStatus(logger))
 	}
 
 	// Report routes
@@ -58,52 +114,165 @@ func Register(
 	{
 		reportGroup.Use(mid.Authenticated())
 
-		reportGroup.Get("/stock-summary", mid.RequireMinRole("admin"), report.StockSummary(logger))
-		reportGroup.Get("/stock-summary/export", mid.RequireMinRole("admin"), report.ExportStockSummaryCSV(logger))
-		reportGroup.Get("/stock-movements", mid.RequireMinRole("admin"), report.StockMovements(logger))
-		reportGroup.Get("/stock-movements/export", mid.RequireMinRole("admin"), report.ExportStockMovementExcel(logger))
-		reportGroup.Get("/purchase-summary", mid.RequireMinRole("admin"), report.PurchaseSummary(logger))
-		reportGroup.Get("/purchase-summary/export", mid.RequireMinRole("admin"), report.ExportPurchaseReportExcel(logger))
+		reportGroup.Get("/stock-summary", mid.RequirePermission(model.PermissionReportView), report.StockSummary(logger, location))
+		reportGroup.Get("/stock-summary/export", mid.RequirePermission(model.PermissionReportExport), report.ExportStockSummaryCSV(logger, location))
+		reportGroup.Get("/stock-movements", mid.RequirePermission(model.PermissionReportView), report.StockMovements(logger))
+		reportGroup.Get("/stock-movements/export", mid.RequirePermission(model.PermissionReportExport), report.ExportStockMovementExcel(logger))
+		reportGroup.Get("/expiring-lots", mid.RequirePermission(model.PermissionReportView), report.ExpiringLots(logger))
+		reportGroup.Get("/expiring-lots/export", mid.RequirePermission(model.PermissionReportExport), report.ExportExpiringLotsExcel(logger))
+		reportGroup.Get("/inventory-valuation", mid.RequirePermission(model.PermissionReportView), report.InventoryValuation(logger, location))
+		reportGroup.Get("/purchase-summary", mid.RequirePermission(model.PermissionReportView), report.PurchaseSummary(logger))
+		reportGroup.Get("/purchase-summary/export", mid.RequirePermission(model.PermissionReportExport), report.ExportPurchaseReportExcel(logger))
 	}
 
 	categoryGroupApi := v1.Group("/categories")
 	{
 		categoryGroupApi.Use(mid.Authenticated())
+		categoryGroupApi.Use(mid.AuditLog())
 
-		categoryGroupApi.Get("/", mid.RequireMinRole("viewer"), category_handler.Categories(logger))
-		categoryGroupApi.Get("/:id", mid.RequireMinRole("viewer"), category_handler.CategoryById(logger))
-		categoryGroupApi.Post("/", mid.RequireMinRole("admin"), category_handler.Create(logger))
-		categoryGroupApi.Patch("/:id", mid.RequireMinRole("admin"), category_handler.Update(logger))
-		categoryGroupApi.Delete("/:id", mid.RequireMinRole("admin"), category_handler.DeleteById(logger))
+		categoryGroupApi.Get("/", mid.RequirePermission(model.PermissionCategoryRead), category_handler.Categories(logger))
+		categoryGroupApi.Get("/:id", mid.RequirePermission(model.PermissionCategoryRead), category_handler.CategoryById(logger))
+		categoryGroupApi.Post("/", mid.RequirePermission(model.PermissionCategoryManage), category_handler.Create(logger))
+		categoryGroupApi.Patch("/:id", mid.RequirePermission(model.PermissionCategoryManage), category_handler.Update(logger))
+		categoryGroupApi.Delete("/:id", mid.RequirePermission(model.PermissionCategoryManage), category_handler.DeleteById(logger))
+	}
+
+	customerGroupApi := v1.Group("/customers")
+	{
+		customerGroupApi.Use(mid.Authenticated())
+		customerGroupApi.Use(mid.AuditLog())
+
+		customerGroupApi.Get("/", mid.RequirePermission(model.PermissionCustomerRead), customer_handler.Customers(logger))
+		customerGroupApi.Get("/:id", mid.RequirePermission(model.PermissionCustomerRead), customer_handler.CustomerById(logger))
+		customerGroupApi.Post("/", mid.RequirePermission(model.PermissionCustomerWrite), customer_handler.Create(logger))
+		customerGroupApi.Patch("/:id", mid.RequirePermission(model.PermissionCustomerWrite), customer_handler.Update(logger))
+		customerGroupApi.Delete("/:id", mid.RequirePermission(model.PermissionCustomerDelete), customer_handler.DeleteById(logger))
+	}
+
+	warehouseGroupApi := v1.Group("/warehouses")
+	{
+		warehouseGroupApi.Use(mid.Authenticated())
+		warehouseGroupApi.Use(mid.AuditLog())
+
+		warehouseGroupApi.Get("/", mid.RequirePermission(model.PermissionWarehouseRead), warehouse_handler.Warehouses(logger))
+		warehouseGroupApi.Get("/:id", mid.RequirePermission(model.PermissionWarehouseRead), warehouse_handler.WarehouseById(logger))
+		warehouseGroupApi.Post("/", mid.RequirePermission(model.PermissionWarehouseManage), warehouse_handler.Create(logger))
+		warehouseGroupApi.Patch("/:id", mid.RequirePermission(model.PermissionWarehouseManage), warehouse_handler.Update(logger))
+		warehouseGroupApi.Post("/:id/locations", mid.RequirePermission(model.PermissionWarehouseManage), warehouse_handler.CreateLocation(logger))
+	}
+
+	stockCountGroupApi := v1.Group("/stock-counts")
+	{
+		stockCountGroupApi.Use(mid.Authenticated())
+		stockCountGroupApi.Use(mid.AuditLog())
+
+		stockCountGroupApi.Get("/", mid.RequirePermission(model.PermissionStockCountRead), stockcount_handler.StockCounts(logger))
+		stockCountGroupApi.Get("/:id", mid.RequirePermission(model.PermissionStockCountRead), stockcount_handler.StockCountById(logger))
+		stockCountGroupApi.Post("/", mid.RequirePermission(model.PermissionStockCountWrite), stockcount_handler.CreateStockCount(logger))
+		stockCountGroupApi.Post("/:id/counts", mid.RequirePermission(model.PermissionStockCountWrite), stockcount_handler.SubmitStockCount(logger))
+		stockCountGroupApi.Post("/:id/approve", mid.RequirePermission(model.PermissionStockCountApprove), stockcount_handler.ApproveStockCount(logger))
+	}
+
+	stockPeriodGroupApi := v1.Group("/stock-periods")
+	{
+		stockPeriodGroupApi.Use(mid.Authenticated())
+		stockPeriodGroupApi.Use(mid.AuditLog())
+
+		stockPeriodGroupApi.Get("/", mid.RequirePermission(model.PermissionStockPeriodRead), stockperiod_handler.Periods(logger))
+		stockPeriodGroupApi.Get("/:id", mid.RequirePermission(model.PermissionStockPeriodRead), stockperiod_handler.PeriodById(logger))
+		stockPeriodGroupApi.Post("/close", mid.RequirePermission(model.PermissionStockPeriodClose), stockperiod_handler.ClosePeriod(logger))
+	}
+
+	serialGroupApi := v1.Group("/serials")
+	{
+		serialGroupApi.Use(mid.Authenticated())
+
+		serialGroupApi.Get("/:serial", mid.RequirePermission(model.PermissionStockRead), serial_handler.SerialTrace(logger))
 	}
 
 	productGroupApi := v1.Group("/products")
 	{
 		productGroupApi.Use(mid.Authenticated())
+		productGroupApi.Use(mid.AuditLog())
 
-		productGroupApi.Get("/", mid.RequireMinRole("viewer"), product_handler.Products(logger))
-		productGroupApi.Get("/:id", mid.RequireMinRole("viewer"), product_handler.ProductById(logger))
-		productGroupApi.Post("/", mid.RequireMinRole("staff"), product_handler.Create(logger))
-		productGroupApi.Patch("/:id", mid.RequireMinRole("staff"), product_handler.Update(logger))
-		productGroupApi.Delete("/:id", mid.RequireMinRole("staff"), product_handler.DeleteById(logger))
-		productGroupApi.Get("/:id/stock-summary", mid.RequireMinRole("viewer"), product_handler.ProductStockSummary(logger))
+		productGroupApi.Get("/", mid.RequirePermission(model.PermissionProductRead), product_handler.Products(logger))
+		productGroupApi.Get("/:id", mid.RequirePermission(model.PermissionProductRead), product_handler.ProductById(logger))
+		productGroupApi.Post("/", mid.RequirePermission(model.PermissionProductWrite), product_handler.Create(logger))
+		productGroupApi.Patch("/:id", mid.RequirePermission(model.PermissionProductWrite), product_handler.Update(logger))
+		productGroupApi.Delete("/:id", mid.RequirePermission(model.PermissionProductWrite), product_handler.DeleteById(logger))
+		productGroupApi.Get("/:id/stock-summary", mid.RequirePermission(model.PermissionProductRead), product_handler.ProductStockSummary(logger))
+		productGroupApi.Get("/:id/lots", mid.RequirePermission(model.PermissionProductRead), product_handler.ProductLots(logger))
+		productGroupApi.Get("/:id/units", mid.RequirePermission(model.PermissionProductRead), product_handler.ProductUnits(logger))
+		productGroupApi.Post("/:id/units", mid.RequirePermission(model.PermissionProductWrite), product_handler.CreateUnit(logger))
+		productGroupApi.Delete("/:id/units/:unitId", mid.RequirePermission(model.PermissionProductWrite), product_handler.DeleteUnit(logger))
 	}
 
 	stockGroupApi := v1.Group("/stocks")
 	{
 		stockGroupApi.Use(mid.Authenticated())
+		stockGroupApi.Use(mid.AuditLog())
+
+		stockGroupApi.Get("/", mid.RequirePermission(model.PermissionStockRead), stocktransaction_handler.StockTransactions(logger))
+		stockGroupApi.Post("/in", mid.RequirePermission(model.PermissionStockMove), stocktransaction_handler.StockIn(logger))
+		stockGroupApi.Post("/out", mid.RequirePermission(model.PermissionStockMove), stocktransaction_handler.StockOut(logger))
+		stockGroupApi.Post("/adjust", mid.RequirePermission(model.PermissionStockAdjust), stocktransaction_handler.StockAdjust(logger))
+		stockGroupApi.Post("/balances/rebuild", mid.RequirePermission(model.PermissionStockRebuild), stocktransaction_handler.RebuildStockBalance(logger))
+		stockGroupApi.Post("/transfer", mid.RequirePermission(model.PermissionStockMove), stocktransaction_handler.TransferStock(logger))
+		stockGroupApi.Get("/transfers", mid.RequirePermission(model.PermissionStockRead), stocktransaction_handler.StockTransfers(logger))
+		stockGroupApi.Post("/transfers/:id/receive", mid.RequirePermission(model.PermissionStockMove), stocktransaction_handler.ReceiveStockTransfer(logger))
+	}
+
+	// Audit log routes
+	auditLogGroupApi := v1.Group("/audit-logs")
+	{
+		auditLogGroupApi.Use(mid.Authenticated())
+
+		auditLogGroupApi.Get("/", mid.RequirePermission(model.PermissionAuditLogRead), audit_log_handler.AuditLogs(logger))
+	}
+
+	// User management routes
+	userGroupApi := v1.Group("/users")
+	{
+		userGroupApi.Use(mid.Authenticated())
+		userGroupApi.Use(mid.AuditLog())
+
+		userGroupApi.Get("/", mid.RequirePermission(model.PermissionUserManage), user_handler.Users(logger))
+		userGroupApi.Get("/:id", mid.RequirePermission(model.PermissionUserManage), user_handler.UserById(logger))
+		userGroupApi.Put("/:id", mid.RequirePermission(model.PermissionUserManage), user_handler.UpdateProfile(logger))
+		userGroupApi.Put("/:id/role", mid.RequirePermission(model.PermissionUserManage), user_handler.ChangeRole(logger))
+		userGroupApi.Post("/:id/deactivate", mid.RequirePermission(model.PermissionUserManage), user_handler.Deactivate(logger))
+		userGroupApi.Post("/:id/reactivate", mid.RequirePermission(model.PermissionUserManage), user_handler.Reactivate(logger))
+		userGroupApi.Post("/:id/password-reset", mid.RequirePermission(model.PermissionUserManage), user_handler.IssuePasswordReset(logger))
+	}
+
+	// Role and permission routes
+	roleGroupApi := v1.Group("/roles")
+	{
+		roleGroupApi.Use(mid.Authenticated())
+		roleGroupApi.Use(mid.AuditLog())
+
+		roleGroupApi.Get("/", mid.RequirePermission(model.PermissionRoleManage), role_handler.Roles(logger))
+		roleGroupApi.Get("/permissions", mid.RequirePermission(model.PermissionRoleManage), role_handler.Permissions(logger))
+		roleGroupApi.Get("/:name", mid.RequirePermission(model.PermissionRoleManage), role_handler.RoleByName(logger))
+		roleGroupApi.Post("/", mid.RequirePermission(model.PermissionRoleManage), role_handler.CreateRole(logger))
+		roleGroupApi.Put("/:name", mid.RequirePermission(model.PermissionRoleManage), role_handler.UpdateRole(logger))
+		roleGroupApi.Delete("/:name", mid.RequirePermission(model.PermissionRoleManage), role_handler.DeleteRole(logger))
+	}
+
+	// Login attempt routes
+	loginAttemptGroupApi := v1.Group("/login-attempts")
+	{
+		loginAttemptGroupApi.Use(mid.Authenticated())
 
-		stockGroupApi.Get("/", mid.RequireMinRole("viewer"), stocktransaction_handler.StockTransactions(logger))
-		stockGroupApi.Post("/in", mid.RequireMinRole("staff"), stocktransaction_handler.StockIn(logger))
-		stockGroupApi.Post("/out", mid.RequireMinRole("staff"), stocktransaction_handler.StockOut(logger))
-		stockGroupApi.Post("/adjust", mid.RequireMinRole("staff"), stocktransaction_handler.StockAdjust(logger))
+		loginAttemptGroupApi.Get("/", mid.RequirePermission(model.PermissionLoginAttemptRead), auth_handler.LoginAttempts(logger))
 	}
 
 	//Test Route (Add user regis)
 	registerGroupApi := v1.Group("/register") // Test only
 	{
 		registerGroupApi.Use(mid.Authenticated())
+		registerGroupApi.Use(mid.AuditLog())
 
-		registerGroupApi.Post("/", mid.RequireRole("admin"), register_handler.Register(logger))
+		registerGroupApi.Post("/", mid.RequirePermission(model.PermissionUserManage), register_handler.Register(logger))
 	}
 }
@@ -0,0 +1,19 @@
+package audit_log
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/api/service/audit_log/query"
+
+	"github.com/mehdihadeli/go-mediatr"
+	"gorm.io/gorm"
+)
+
+func NewService(logger *slog.Logger, db *gorm.DB, auditLogRepo repository.AuditLog) {
+	auditLogsService := query.NewAuditLogs(logger, db, auditLogRepo)
+
+	err := mediatr.RegisterRequestHandler(auditLogsService)
+	if err != nil {
+		panic(err)
+	}
+}
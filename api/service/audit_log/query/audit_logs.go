@@ -0,0 +1,104 @@
+package query
+
+import (
+	"context"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AuditLogs struct {
+	logger       *slog.Logger
+	db           *gorm.DB
+	auditLogRepo repository.AuditLog
+}
+
+type AuditLogsRequest struct {
+	Page      int        `json:"page"`
+	PageSize  int        `json:"page_size"`
+	UserId    *uuid.UUID `json:"user_id"`
+	Action    string     `json:"action"`
+	Entity    string     `json:"entity"`
+	EntityId  string     `json:"entity_id"`
+	FromDate  *time.Time `json:"from_date"`
+	ToDate    *time.Time `json:"to_date"`
+	SortOrder string     `json:"sort_order"` // asc หรือ desc
+}
+
+type AuditLogsResult struct {
+	AuditLogs  []model.AuditLog `json:"audit_logs"`
+	Total      int64            `json:"total"`
+	Page       int              `json:"page"`
+	PageSize   int              `json:"page_size"`
+	TotalPages int              `json:"total_pages"`
+}
+
+func NewAuditLogs(logger *slog.Logger, db *gorm.DB, auditLogRepo repository.AuditLog) *AuditLogs {
+	return &AuditLogs{
+		logger:       logger,
+		db:           db,
+		auditLogRepo: auditLogRepo,
+	}
+}
+
+func (a *AuditLogs) Handle(ctx context.Context, request AuditLogsRequest) (*AuditLogsResult, error) {
+	filters := repository.AuditLogSearchFilters{
+		UserId:   request.UserId,
+		Action:   request.Action,
+		Entity:   request.Entity,
+		EntityId: request.EntityId,
+		FromDate: request.FromDate,
+		ToDate:   request.ToDate,
+	}
+
+	orderBy := "created_at DESC" // default
+	if request.SortOrder == "asc" || request.SortOrder == "ASC" {
+		orderBy = "created_at ASC"
+	}
+
+	if request.Page <= 0 || request.PageSize <= 0 {
+		result, err := a.auditLogRepo.SearchWithFilters(a.db, filters, orderBy)
+		if err != nil {
+			a.logger.Error("Failed to get audit logs", slog.String("error", err.Error()))
+			return nil, err
+		}
+
+		response := &AuditLogsResult{
+			AuditLogs:  result,
+			Total:      int64(len(result)),
+			Page:       1,
+			PageSize:   len(result),
+			TotalPages: 1,
+		}
+		return response, nil
+	}
+
+	result, total, err := a.auditLogRepo.SearchWithFiltersAndPagination(a.db, filters, orderBy, request.Page, request.PageSize)
+	if err != nil {
+		a.logger.Error("Failed to get audit logs with pagination", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	// คำนวณจำนวนหน้าทั้งหมด
+	totalPages := 0
+	if total > 0 {
+		totalPages = int(total) / request.PageSize
+		if int(total)%request.PageSize > 0 {
+			totalPages++
+		}
+	}
+
+	response := &AuditLogsResult{
+		AuditLogs:  result,
+		Total:      total,
+		Page:       request.Page,
+		PageSize:   request.PageSize,
+		TotalPages: totalPages,
+	}
+
+	return response, nil
+}
@@ -4,7 +4,11 @@ import (
 	"log/slog"
 	"mini-erp-backend/api/repository"
 	"mini-erp-backend/api/service/auth/command"
+	"mini-erp-backend/api/service/auth/query"
+	"mini-erp-backend/lib/cache"
 	"mini-erp-backend/lib/jwt"
+	"mini-erp-backend/model"
+	"mini-erp-backend/utils"
 
 	"github.com/mehdihadeli/go-mediatr"
 	"gorm.io/gorm"
@@ -15,19 +19,90 @@ func NewService(
 	logger *slog.Logger,
 	jwtManager jwt.Manager,
 	userRepo repository.User,
+	sessionRepo repository.UserSession,
+	refreshTokenRepo repository.RefreshToken,
+	auditLogRepo repository.AuditLog,
+	resetTokenRepo repository.PasswordResetToken,
+	loginAttemptRepo repository.LoginAttempt,
+	mfaRepo repository.Mfa,
+	roleRepo repository.Role,
+	sessionCache *cache.Cache[string, model.UserSession],
+	passwordPolicy utils.PasswordPolicy,
+	loginThrottle utils.LoginThrottle,
+	mfaPolicy utils.MfaPolicy,
 ) {
 	LoginService := command.NewLoginByUsername(
 		domainDb,
 		logger,
 		jwtManager,
 		userRepo,
+		sessionRepo,
+		refreshTokenRepo,
+		loginAttemptRepo,
+		mfaRepo,
+		roleRepo,
+		sessionCache,
+		loginThrottle,
+		mfaPolicy,
 	)
 	RefreshLoginTokenService := command.NewRefreshAccessToken(
 		domainDb,
 		logger,
 		jwtManager,
 		userRepo,
+		sessionRepo,
+		refreshTokenRepo,
+		auditLogRepo,
+		roleRepo,
+		sessionCache,
 	)
+	LogoutService := command.NewLogout(
+		domainDb,
+		logger,
+		sessionRepo,
+		sessionCache,
+	)
+	LogoutAllService := command.NewLogoutAll(
+		domainDb,
+		logger,
+		sessionRepo,
+		sessionCache,
+	)
+	ChangePasswordService := command.NewChangePassword(
+		domainDb,
+		logger,
+		userRepo,
+		sessionRepo,
+		sessionCache,
+		passwordPolicy,
+	)
+	ResetPasswordService := command.NewResetPassword(
+		domainDb,
+		logger,
+		userRepo,
+		sessionRepo,
+		resetTokenRepo,
+		sessionCache,
+		passwordPolicy,
+	)
+	VerifyMfaService := command.NewVerifyMfa(
+		domainDb,
+		logger,
+		jwtManager,
+		userRepo,
+		sessionRepo,
+		refreshTokenRepo,
+		loginAttemptRepo,
+		mfaRepo,
+		roleRepo,
+		sessionCache,
+		mfaPolicy,
+	)
+	EnrollMfaService := command.NewEnrollMfa(domainDb, logger, userRepo, mfaPolicy)
+	EnableMfaService := command.NewEnableMfa(domainDb, logger, userRepo, sessionRepo, mfaRepo, sessionCache, mfaPolicy)
+	DisableMfaService := command.NewDisableMfa(domainDb, logger, userRepo, mfaRepo, roleRepo, mfaPolicy)
+	RegenerateRecoveryCodesService := command.NewRegenerateRecoveryCodes(domainDb, logger, userRepo, mfaRepo, mfaPolicy)
+	LoginAttemptsService := query.NewLoginAttempts(logger, domainDb, loginAttemptRepo)
 
 	err := mediatr.RegisterRequestHandler(LoginService)
 	if err != nil {
@@ -38,4 +113,54 @@ func NewService(
 	if err != nil {
 		panic(err)
 	}
+
+	err = mediatr.RegisterRequestHandler(LogoutService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(LogoutAllService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(ChangePasswordService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(ResetPasswordService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(LoginAttemptsService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(VerifyMfaService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(EnrollMfaService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(EnableMfaService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(DisableMfaService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(RegenerateRecoveryCodesService)
+	if err != nil {
+		panic(err)
+	}
 }
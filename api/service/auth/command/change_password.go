@@ -0,0 +1,139 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/lib/cache"
+	"mini-erp-backend/model"
+	"mini-erp-backend/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidCurrentPassword is returned when the current password given to change password does not match
+	ErrInvalidCurrentPassword = errors.New("current password is incorrect")
+	// ErrPasswordUnchanged is returned when the new password is the same as the current one
+	ErrPasswordUnchanged = errors.New("new password must be different from the current password")
+)
+
+type ChangePasswordRequest struct {
+	UserId          uuid.UUID
+	SessionId       uuid.UUID
+	CurrentPassword string
+	NewPassword     string
+}
+
+type ChangePasswordResult struct {
+	RevokedSessions int64  `json:"revoked_sessions"`
+	Message         string `json:"message"`
+}
+
+type ChangePassword struct {
+	domainDb       *gorm.DB
+	logger         *slog.Logger
+	userRepo       repository.User
+	sessionRepo    repository.UserSession
+	sessionCache   *cache.Cache[string, model.UserSession]
+	passwordPolicy utils.PasswordPolicy
+}
+
+func NewChangePassword(
+	domainDb *gorm.DB,
+	logger *slog.Logger,
+	userRepo repository.User,
+	sessionRepo repository.UserSession,
+	sessionCache *cache.Cache[string, model.UserSession],
+	passwordPolicy utils.PasswordPolicy,
+) *ChangePassword {
+	return &ChangePassword{
+		domainDb:       domainDb,
+		logger:         logger,
+		userRepo:       userRepo,
+		sessionRepo:    sessionRepo,
+		sessionCache:   sessionCache,
+		passwordPolicy: passwordPolicy,
+	}
+}
+
+func (p *ChangePassword) Handle(ctx context.Context, request *ChangePasswordRequest) (*ChangePasswordResult, error) {
+	if request == nil || request.UserId == uuid.Nil {
+		return nil, errors.New("user id is required")
+	}
+
+	if request.CurrentPassword == "" || request.NewPassword == "" {
+		return nil, errors.New("current password and new password are required")
+	}
+
+	user, err := p.userRepo.SearchByConditions(p.domainDb, map[string]interface{}{"user_id": request.UserId})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := utils.ComparePassword(user.Password, request.CurrentPassword); err != nil {
+		if p.logger != nil {
+			p.logger.Error("change password rejected, current password mismatch", "user_id", request.UserId)
+		}
+		return nil, ErrInvalidCurrentPassword
+	}
+
+	if request.NewPassword == request.CurrentPassword {
+		return nil, ErrPasswordUnchanged
+	}
+
+	if err := p.passwordPolicy.Validate(request.NewPassword); err != nil {
+		return nil, err
+	}
+
+	hashed, err := utils.HashPassword(request.NewPassword)
+	if err != nil {
+		if p.logger != nil {
+			p.logger.Error("password hash failed", "error", err)
+		}
+		return nil, err
+	}
+
+	tx := p.domainDb.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := p.userRepo.UpdatePassword(tx, user.UserId, hashed); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// session ปัจจุบันใช้งานต่อได้ ส่วน session อื่นต้อง login ใหม่ด้วยรหัสผ่านใหม่
+	revoked, err := p.sessionRepo.RevokeOthersByUserId(tx, user.UserId, request.SessionId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := p.sessionRepo.UpdateMustChangePassword(tx, request.SessionId, false); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		if p.logger != nil {
+			p.logger.Error("commit password change failed", "user_id", user.UserId, "error", err)
+		}
+		return nil, err
+	}
+
+	// ลบทุก session ของ user ออกจาก cache รวมถึง session ปัจจุบันที่ flag เปลี่ยนไปแล้ว
+	p.sessionCache.DeleteFunc(func(_ string, s model.UserSession) bool {
+		return s.UserId == user.UserId
+	})
+
+	return &ChangePasswordResult{
+		RevokedSessions: revoked,
+		Message:         "Password changed successfully",
+	}, nil
+}
@@ -0,0 +1,119 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DisableMfaRequest struct {
+	UserId   uuid.UUID
+	Password string
+	Code     string
+}
+
+type DisableMfaResult struct {
+	Message string `json:"message"`
+}
+
+// DisableMfa turns MFA off after checking both the password and a TOTP or recovery code
+type DisableMfa struct {
+	domainDb  *gorm.DB
+	logger    *slog.Logger
+	userRepo  repository.User
+	mfaRepo   repository.Mfa
+	roleRepo  repository.Role
+	mfaPolicy utils.MfaPolicy
+}
+
+func NewDisableMfa(
+	domainDb *gorm.DB,
+	logger *slog.Logger,
+	userRepo repository.User,
+	mfaRepo repository.Mfa,
+	roleRepo repository.Role,
+	mfaPolicy utils.MfaPolicy,
+) *DisableMfa {
+	return &DisableMfa{
+		domainDb:  domainDb,
+		logger:    logger,
+		userRepo:  userRepo,
+		mfaRepo:   mfaRepo,
+		roleRepo:  roleRepo,
+		mfaPolicy: mfaPolicy,
+	}
+}
+
+func (d *DisableMfa) Handle(ctx context.Context, request *DisableMfaRequest) (*DisableMfaResult, error) {
+	if request == nil || request.UserId == uuid.Nil {
+		return nil, errors.New("user id is required")
+	}
+
+	if request.Password == "" || request.Code == "" {
+		return nil, errors.New("password and code are required")
+	}
+
+	user, err := d.userRepo.SearchByConditions(d.domainDb, map[string]interface{}{"user_id": request.UserId})
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.MfaEnabled {
+		return nil, ErrMfaNotEnabled
+	}
+
+	required, err := mfaRequired(d.domainDb, d.roleRepo, d.mfaPolicy, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if required {
+		return nil, ErrMfaRequired
+	}
+
+	if err := utils.ComparePassword(user.Password, request.Password); err != nil {
+		return nil, ErrInvalidCurrentPassword
+	}
+
+	tx := d.domainDb.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	ok, err := verifyMfaCode(tx, d.userRepo, d.mfaRepo, d.mfaPolicy, user, request.Code)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if !ok {
+		tx.Rollback()
+		return nil, ErrInvalidMfaCode
+	}
+
+	if err := d.userRepo.DisableMfa(tx, user.UserId); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := d.mfaRepo.DeleteRecoveryCodes(tx, user.UserId); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		if d.logger != nil {
+			d.logger.Error("commit mfa disable failed", "user_id", user.UserId, "error", err)
+		}
+		return nil, err
+	}
+
+	return &DisableMfaResult{Message: "MFA disabled successfully"}, nil
+}
@@ -0,0 +1,129 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/lib/cache"
+	"mini-erp-backend/lib/totp"
+	"mini-erp-backend/model"
+	"mini-erp-backend/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type EnableMfaRequest struct {
+	UserId    uuid.UUID
+	SessionId uuid.UUID
+	Code      string
+}
+
+type RecoveryCodesResult struct {
+	RecoveryCodes []string `json:"recovery_codes"` // แสดงครั้งเดียว ในฐานข้อมูลเก็บแค่ hash
+}
+
+// EnableMfa verifies a code from the pending secret, turns MFA on and issues recovery codes
+type EnableMfa struct {
+	domainDb     *gorm.DB
+	logger       *slog.Logger
+	userRepo     repository.User
+	sessionRepo  repository.UserSession
+	mfaRepo      repository.Mfa
+	sessionCache *cache.Cache[string, model.UserSession]
+	mfaPolicy    utils.MfaPolicy
+}
+
+func NewEnableMfa(
+	domainDb *gorm.DB,
+	logger *slog.Logger,
+	userRepo repository.User,
+	sessionRepo repository.UserSession,
+	mfaRepo repository.Mfa,
+	sessionCache *cache.Cache[string, model.UserSession],
+	mfaPolicy utils.MfaPolicy,
+) *EnableMfa {
+	return &EnableMfa{
+		domainDb:     domainDb,
+		logger:       logger,
+		userRepo:     userRepo,
+		sessionRepo:  sessionRepo,
+		mfaRepo:      mfaRepo,
+		sessionCache: sessionCache,
+		mfaPolicy:    mfaPolicy,
+	}
+}
+
+func (e *EnableMfa) Handle(ctx context.Context, request *EnableMfaRequest) (*RecoveryCodesResult, error) {
+	if request == nil || request.UserId == uuid.Nil {
+		return nil, errors.New("user id is required")
+	}
+
+	user, err := e.userRepo.SearchByConditions(e.domainDb, map[string]interface{}{"user_id": request.UserId})
+	if err != nil {
+		return nil, err
+	}
+
+	if user.MfaEnabled {
+		return nil, ErrMfaAlreadyEnabled
+	}
+
+	secret, enrolled, err := mfaSecret(e.mfaPolicy, user)
+	if err != nil {
+		return nil, err
+	}
+	if !enrolled {
+		return nil, ErrMfaNotEnrolled
+	}
+
+	counter, ok := totp.Validate(secret, request.Code, time.Now())
+	if !ok {
+		return nil, ErrInvalidMfaCode
+	}
+
+	codes, rows, err := generateRecoveryCodes(user.UserId)
+	if err != nil {
+		if e.logger != nil {
+			e.logger.Error("generate recovery codes failed", "error", err)
+		}
+		return nil, err
+	}
+
+	tx := e.domainDb.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := e.userRepo.EnableMfa(tx, user.UserId, counter); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := e.mfaRepo.ReplaceRecoveryCodes(tx, user.UserId, rows); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// session ที่ถูกจำกัดให้ลงทะเบียน MFA ใช้งานได้ตามปกติหลังเปิด MFA แล้ว
+	if err := e.sessionRepo.UpdateMfaEnrollRequired(tx, request.SessionId, false); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		if e.logger != nil {
+			e.logger.Error("commit mfa enable failed", "user_id", user.UserId, "error", err)
+		}
+		return nil, err
+	}
+
+	e.sessionCache.DeleteFunc(func(_ string, s model.UserSession) bool {
+		return s.SessionId == request.SessionId
+	})
+
+	return &RecoveryCodesResult{RecoveryCodes: codes}, nil
+}
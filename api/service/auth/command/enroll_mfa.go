@@ -0,0 +1,85 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/lib/totp"
+	"mini-erp-backend/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type EnrollMfaRequest struct {
+	UserId uuid.UUID
+}
+
+type EnrollMfaResult struct {
+	Secret     string `json:"secret"`
+	OtpauthUri string `json:"otpauth_uri"`
+}
+
+// EnrollMfa generates a pending TOTP secret, MFA is enabled only after EnableMfa verifies a code from it
+type EnrollMfa struct {
+	domainDb  *gorm.DB
+	logger    *slog.Logger
+	userRepo  repository.User
+	mfaPolicy utils.MfaPolicy
+}
+
+func NewEnrollMfa(
+	domainDb *gorm.DB,
+	logger *slog.Logger,
+	userRepo repository.User,
+	mfaPolicy utils.MfaPolicy,
+) *EnrollMfa {
+	return &EnrollMfa{
+		domainDb:  domainDb,
+		logger:    logger,
+		userRepo:  userRepo,
+		mfaPolicy: mfaPolicy,
+	}
+}
+
+func (e *EnrollMfa) Handle(ctx context.Context, request *EnrollMfaRequest) (*EnrollMfaResult, error) {
+	if request == nil || request.UserId == uuid.Nil {
+		return nil, errors.New("user id is required")
+	}
+
+	user, err := e.userRepo.SearchByConditions(e.domainDb, map[string]interface{}{"user_id": request.UserId})
+	if err != nil {
+		return nil, err
+	}
+
+	if user.MfaEnabled {
+		return nil, ErrMfaAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		if e.logger != nil {
+			e.logger.Error("generate mfa secret failed", "error", err)
+		}
+		return nil, err
+	}
+
+	sealed, err := e.mfaPolicy.SecretBox.Seal(secret)
+	if err != nil {
+		if e.logger != nil {
+			e.logger.Error("encrypt mfa secret failed", "error", err)
+		}
+		return nil, err
+	}
+
+	// เรียกซ้ำได้ secret ใหม่จะแทนที่ secret ที่ยังไม่ได้ยืนยัน
+	if err := e.userRepo.UpdateMfaSecret(e.domainDb, user.UserId, &sealed); err != nil {
+		return nil, err
+	}
+
+	return &EnrollMfaResult{
+		Secret:     secret,
+		OtpauthUri: totp.URI(e.mfaPolicy.Issuer, user.Username, secret),
+	}, nil
+}
@@ -2,41 +2,73 @@ package command
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"log/slog"
+	"math"
 	"mini-erp-backend/api/repository"
+	"mini-erp-backend/lib/cache"
 	"mini-erp-backend/lib/jwt"
 	"mini-erp-backend/model"
+	"mini-erp-backend/utils"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrUserDisabled is returned when a deactivated user tries to log in.
+	ErrUserDisabled = errors.New("user is disabled")
+	// ErrInvalidCredentials is the only error for an unknown username or a wrong password, so usernames cannot be enumerated.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrTooManyAttempts is returned while the username or the IP is in backoff after failed logins.
+	ErrTooManyAttempts = errors.New("too many login attempts")
+	// ErrAccountLocked is returned while the username is locked after too many consecutive failures.
+	ErrAccountLocked = errors.New("account temporarily locked")
+)
+
 type LoginRequest struct {
 	Username string
 	Password string
+	IP       string
 }
 
 type LoginResult struct {
-	UserId          uuid.UUID `json:"id"`
-	Username        string    `json:"username"`
-	Role            string    `json:"role"`
-	FirstName       string    `json:"first_name"`
-	LastName        string    `json:"last_name"`
-	AccessToken     string    `json:"access_token"`
-	AccessTokenExp  int64     `json:"access_token_exp"`
-	RefreshToken    string    `json:"refresh_token"`
-	RefreshTokenExp int64     `json:"refresh_token_exp"`
-	SessionId       uuid.UUID `json:"session_id"`
+	UserId             uuid.UUID `json:"id"`
+	Username           string    `json:"username"`
+	Role               string    `json:"role"`
+	Permissions        []string  `json:"permissions"`
+	FirstName          string    `json:"first_name"`
+	LastName           string    `json:"last_name"`
+	AccessToken        string    `json:"access_token"`
+	AccessTokenExp     int64     `json:"access_token_exp"`
+	RefreshToken       string    `json:"refresh_token"`
+	RefreshTokenExp    int64     `json:"refresh_token_exp"`
+	SessionId          uuid.UUID `json:"session_id"`
+	MustChangePassword bool      `json:"must_change_password"`
+	MfaEnrollRequired  bool      `json:"mfa_enroll_required"`
+	// เมื่อ MfaRequired เป็น true จะไม่มี token ของ session ให้ส่ง MfaToken พร้อมรหัส TOTP ไปที่ /auth/mfa/verify
+	MfaRequired bool   `json:"mfa_required"`
+	MfaToken    string `json:"mfa_token,omitempty"`
+	MfaTokenExp int64  `json:"mfa_token_exp,omitempty"`
 }
 
 type LoginByUsername struct {
-	domainDb   *gorm.DB
-	logger     *slog.Logger
-	jwtManager jwt.Manager
-	userRepo   repository.User
+	domainDb         *gorm.DB
+	logger           *slog.Logger
+	userRepo         repository.User
+	loginAttemptRepo repository.LoginAttempt
+	mfaRepo          repository.Mfa
+	sessions         sessionIssuer
+	throttle         utils.LoginThrottle
+	mfaPolicy        utils.MfaPolicy
+	// dummyHash ใช้เทียบรหัสผ่านเมื่อไม่พบ username ให้เวลาตอบกลับใกล้เคียงกับกรณีรหัสผิด
+	dummyHash []byte
 }
 
 func NewLoginByUsername(
@@ -44,12 +76,35 @@ func NewLoginByUsername(
 	logger *slog.Logger,
 	jwtManager jwt.Manager,
 	userRepo repository.User,
+	sessionRepo repository.UserSession,
+	refreshTokenRepo repository.RefreshToken,
+	loginAttemptRepo repository.LoginAttempt,
+	mfaRepo repository.Mfa,
+	roleRepo repository.Role,
+	sessionCache *cache.Cache[string, model.UserSession],
+	throttle utils.LoginThrottle,
+	mfaPolicy utils.MfaPolicy,
 ) *LoginByUsername {
+	dummyHash, _ := bcrypt.GenerateFromPassword([]byte(uuid.NewString()), bcrypt.DefaultCost)
+
 	return &LoginByUsername{
-		domainDb:   domainDb,
-		logger:     logger,
-		jwtManager: jwtManager,
-		userRepo:   userRepo,
+		domainDb:         domainDb,
+		logger:           logger,
+		userRepo:         userRepo,
+		loginAttemptRepo: loginAttemptRepo,
+		mfaRepo:          mfaRepo,
+		sessions: sessionIssuer{
+			domainDb:         domainDb,
+			logger:           logger,
+			jwtManager:       jwtManager,
+			sessionRepo:      sessionRepo,
+			refreshTokenRepo: refreshTokenRepo,
+			roleRepo:         roleRepo,
+			sessionCache:     sessionCache,
+		},
+		throttle:  throttle,
+		mfaPolicy: mfaPolicy,
+		dummyHash: dummyHash,
 	}
 }
 
@@ -63,58 +118,206 @@ func (l *LoginByUsername) Handle(ctx context.Context, request *LoginRequest) (*L
 		return nil, errors.New("username and password are required")
 	}
 
-	user, err := l.userRepo.Search(l.domainDb, username)
+	user, err := l.authenticate(username, request)
 	if err != nil {
-		if l.logger != nil {
-			l.logger.Error("user lookup failed", "username", username, "error", err)
+		return nil, err
+	}
+
+	if user.MfaEnabled {
+		res, err := l.issueChallenge(user, request.IP)
+		if err != nil {
+			return nil, err
+		}
+
+		l.recordAttempt(l.domainDb, username, &user.UserId, request.IP, model.LoginResultMfaRequired)
+		return res, nil
+	}
+
+	// role ที่จัดการผู้ใช้หรือ role ได้แต่ยังไม่เปิด MFA ได้ session ที่ใช้ได้แค่ลงทะเบียน MFA เมื่อ policy บังคับ
+	enrollRequired, err := mfaRequired(l.domainDb, l.sessions.roleRepo, l.mfaPolicy, user)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := l.sessions.issue(user, enrollRequired)
+	if err != nil {
+		return nil, err
+	}
+
+	l.recordAttempt(l.domainDb, username, &user.UserId, request.IP, model.LoginResultSuccess)
+
+	return res, nil
+}
+
+// authenticate checks the throttle and the credentials and records a failed attempt while holding the advisory
+// lock of the username, so parallel requests cannot all pass the check before any of their failures is written
+func (l *LoginByUsername) authenticate(username string, request *LoginRequest) (*model.User, error) {
+	tx := l.domainDb.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
 		}
+	}()
+
+	if err := l.loginAttemptRepo.LockUsername(tx, username); err != nil {
+		tx.Rollback()
 		return nil, err
 	}
 
+	// ตรวจก่อนค้นหา user เพื่อให้ username ที่ไม่มีอยู่จริงถูกจำกัดแบบเดียวกัน
+	if result, err := l.checkThrottle(tx, username, request.IP); err != nil {
+		if result != "" {
+			l.recordAttempt(tx, username, nil, request.IP, result)
+		}
+		return nil, l.commitAttempt(tx, err)
+	}
+
+	user, err := l.userRepo.Search(tx, username)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			tx.Rollback()
+			if l.logger != nil {
+				l.logger.Error("user lookup failed", "username", username, "error", err)
+			}
+			return nil, err
+		}
+
+		_ = bcrypt.CompareHashAndPassword(l.dummyHash, []byte(request.Password))
+		l.recordAttempt(tx, username, nil, request.IP, model.LoginResultInvalidCredentials)
+		return nil, l.commitAttempt(tx, ErrInvalidCredentials)
+	}
+
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password)); err != nil {
 		if l.logger != nil {
 			l.logger.Error("invalid credentials", "username", username)
 		}
-		return nil, err
+		l.recordAttempt(tx, username, &user.UserId, request.IP, model.LoginResultInvalidCredentials)
+		return nil, l.commitAttempt(tx, ErrInvalidCredentials)
 	}
 
-	roleStr := string(user.Role)
-	token, err := l.jwtManager.GenerateLoginToken(user.UserId, roleStr)
-	if err != nil {
+	// ตรวจหลังเทียบรหัสผ่าน เพื่อไม่ให้รู้สถานะบัญชีได้จากการเดารหัส
+	if user.Disabled {
 		if l.logger != nil {
-			l.logger.Error("generate token failed", "user", user.UserId.String(), "error", err)
+			l.logger.Error("login rejected for disabled user", "username", username)
 		}
+		l.recordAttempt(tx, username, &user.UserId, request.IP, model.LoginResultDisabled)
+		return nil, l.commitAttempt(tx, ErrUserDisabled)
+	}
+
+	if err := tx.Commit().Error; err != nil {
 		return nil, err
 	}
 
-	session := &model.UserSession{
-		SessionId:    uuid.New(),
-		UserId:       user.UserId,
-		AccessToken:  token.AccessToken,
-		RefreshToken: token.RefreshToken,
-		Revoked:      false,
+	return user, nil
+}
+
+// commitAttempt commits the recorded attempt, which also releases the username lock, and returns the login error
+func (l *LoginByUsername) commitAttempt(tx *gorm.DB, loginErr error) error {
+	if err := tx.Commit().Error; err != nil && l.logger != nil {
+		l.logger.Error("commit login attempt failed", "error", err)
+	}
+	return loginErr
+}
+
+// issueChallenge stores a short-lived MFA challenge and returns its token in place of the session tokens
+func (l *LoginByUsername) issueChallenge(user *model.User, ip string) (*LoginResult, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(raw)
+
+	challenge := &model.MfaChallenge{
+		ChallengeId: uuid.New(),
+		UserId:      user.UserId,
+		Token:       utils.HashToken(token),
+		IP:          ip,
+		ExpireAt:    time.Now().Add(l.mfaPolicy.ChallengeTTL),
+	}
+
+	if err := l.mfaRepo.CreateChallenge(l.domainDb, challenge); err != nil {
+		return nil, err
+	}
+
+	return &LoginResult{
+		UserId:      user.UserId,
+		Username:    user.Username,
+		MfaRequired: true,
+		MfaToken:    token,
+		MfaTokenExp: challenge.ExpireAt.Unix(),
+	}, nil
+}
+
+// checkThrottle returns ErrAccountLocked or ErrTooManyAttempts, wrapped with the seconds to wait,
+// together with the attempt result to record, when the username or the IP has failed too often
+func (l *LoginByUsername) checkThrottle(db *gorm.DB, username string, ip string) (string, error) {
+	now := time.Now()
+	// ย้อนดูให้ครอบคลุมระยะเวลาล็อก ไม่อย่างนั้นความล้มเหลวจะหลุด window ก่อนหมดเวลาล็อก
+	lookback := l.throttle.Window
+	if l.throttle.LockoutDuration > lookback {
+		lookback = l.throttle.LockoutDuration
+	}
+	since := now.Add(-lookback)
+
+	if ip != "" {
+		failures, err := l.loginAttemptRepo.FailuresByIP(db, ip, since)
+		if err != nil {
+			return "", err
+		}
+
+		if wait := l.wait(failures, l.throttle.IPLockoutAfter, now); wait > 0 {
+			if l.logger != nil {
+				l.logger.Error("login throttled by ip", "ip", ip, "failures", failures.Count)
+			}
+			return model.LoginResultThrottled, fmt.Errorf("%w: retry after %d seconds", ErrTooManyAttempts, waitSeconds(wait))
+		}
+	}
+
+	failures, err := l.loginAttemptRepo.FailuresByUsername(db, username, since)
+	if err != nil {
+		return "", err
 	}
 
-	sessRepo := repository.NewUserSession(l.logger)
-	if err := sessRepo.Create(l.domainDb, session); err != nil {
+	// LockoutAfter <= 0 ปิดการล็อกบัญชี เหลือแค่ backoff
+	if l.throttle.LockoutAfter > 0 && failures.Count >= l.throttle.LockoutAfter && failures.LastFailure != nil {
+		if wait := failures.LastFailure.Add(l.throttle.LockoutDuration).Sub(now); wait > 0 {
+			if l.logger != nil {
+				l.logger.Error("login rejected for locked account", "username", username, "failures", failures.Count)
+			}
+			return model.LoginResultLocked, fmt.Errorf("%w: retry after %d seconds", ErrAccountLocked, waitSeconds(wait))
+		}
+		return "", nil
+	}
+
+	if wait := l.wait(failures, 0, now); wait > 0 {
 		if l.logger != nil {
-			l.logger.Error("create user session failed", "user", user.UserId.String(), "error", err)
+			l.logger.Error("login throttled by username", "username", username, "failures", failures.Count)
 		}
-		return nil, err
+		return model.LoginResultThrottled, fmt.Errorf("%w: retry after %d seconds", ErrTooManyAttempts, waitSeconds(wait))
 	}
 
-	res := &LoginResult{
-		UserId:          user.UserId,
-		Username:        user.Username,
-		Role:            string(user.Role),
-		FirstName:       user.FirstName,
-		LastName:        user.LastName,
-		AccessToken:     token.AccessToken,
-		AccessTokenExp:  token.AtExpires,
-		RefreshToken:    token.RefreshToken,
-		RefreshTokenExp: token.RtExpires,
-		SessionId:       session.SessionId,
+	return "", nil
+}
+
+// wait returns how long the caller must still wait after its last failure,
+// lockoutAfter > 0 blocks for the whole lockout duration once reached
+func (l *LoginByUsername) wait(failures *repository.LoginFailures, lockoutAfter int64, now time.Time) time.Duration {
+	if failures.Count == 0 || failures.LastFailure == nil {
+		return 0
 	}
 
-	return res, nil
+	delay := l.throttle.Backoff(failures.Count)
+	if lockoutAfter > 0 && failures.Count >= lockoutAfter {
+		delay = l.throttle.LockoutDuration
+	}
+
+	return failures.LastFailure.Add(delay).Sub(now)
+}
+
+func (l *LoginByUsername) recordAttempt(db *gorm.DB, username string, userId *uuid.UUID, ip string, result string) {
+	recordLoginAttempt(db, l.logger, l.loginAttemptRepo, username, userId, ip, result)
+}
+
+func waitSeconds(wait time.Duration) int64 {
+	return int64(math.Ceil(wait.Seconds()))
 }
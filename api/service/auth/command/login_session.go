@@ -0,0 +1,126 @@
+package command
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/lib/cache"
+	"mini-erp-backend/lib/jwt"
+	"mini-erp-backend/model"
+	"mini-erp-backend/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// sessionIssuer creates the session and the refresh token of a completed login,
+// shared by the password login and the MFA verification step
+type sessionIssuer struct {
+	domainDb         *gorm.DB
+	logger           *slog.Logger
+	jwtManager       jwt.Manager
+	sessionRepo      repository.UserSession
+	refreshTokenRepo repository.RefreshToken
+	roleRepo         repository.Role
+	sessionCache     *cache.Cache[string, model.UserSession]
+}
+
+func (i sessionIssuer) issue(user *model.User, mfaEnrollRequired bool) (*LoginResult, error) {
+	permissions, err := i.roleRepo.PermissionsByRole(i.domainDb, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	roleStr := string(user.Role)
+	token, err := i.jwtManager.GenerateLoginToken(user.UserId, roleStr, permissions)
+	if err != nil {
+		if i.logger != nil {
+			i.logger.Error("generate token failed", "user", user.UserId.String(), "error", err)
+		}
+		return nil, err
+	}
+
+	session := &model.UserSession{
+		SessionId:          uuid.New(),
+		UserId:             user.UserId,
+		AccessToken:        token.AccessToken,
+		AccessUuid:         token.AccessUuid,
+		RefreshToken:       utils.HashToken(token.RefreshToken),
+		Revoked:            false,
+		MustChangePassword: user.MustChangePassword,
+		MfaEnrollRequired:  mfaEnrollRequired,
+	}
+
+	tx := i.domainDb.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := i.sessionRepo.Create(tx, session); err != nil {
+		tx.Rollback()
+		if i.logger != nil {
+			i.logger.Error("create user session failed", "user", user.UserId.String(), "error", err)
+		}
+		return nil, err
+	}
+
+	refreshToken := &model.RefreshToken{
+		RefreshId:  uuid.New(),
+		UserNumber: user.UserId,
+		SessionId:  session.SessionId,
+		Token:      utils.HashToken(token.RefreshToken),
+		IssueAt:    time.Now(),
+		ExpireAt:   time.Unix(token.RtExpires, 0),
+	}
+
+	if err := i.refreshTokenRepo.Create(tx, refreshToken); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		if i.logger != nil {
+			i.logger.Error("commit login session failed", "user", user.UserId.String(), "error", err)
+		}
+		return nil, err
+	}
+
+	// Create revokes the previous sessions of this user, so drop them from the cache as well
+	i.sessionCache.DeleteFunc(func(_ string, s model.UserSession) bool {
+		return s.UserId == user.UserId
+	})
+
+	res := &LoginResult{
+		UserId:             user.UserId,
+		Username:           user.Username,
+		Role:               string(user.Role),
+		Permissions:        permissions,
+		FirstName:          user.FirstName,
+		LastName:           user.LastName,
+		AccessToken:        token.AccessToken,
+		AccessTokenExp:     token.AtExpires,
+		RefreshToken:       token.RefreshToken,
+		RefreshTokenExp:    token.RtExpires,
+		SessionId:          session.SessionId,
+		MustChangePassword: user.MustChangePassword,
+		MfaEnrollRequired:  mfaEnrollRequired,
+	}
+
+	return res, nil
+}
+
+// recordLoginAttempt บันทึกผล login การบันทึกล้มเหลวต้องไม่ทำให้ login ล้มเหลว
+func recordLoginAttempt(db *gorm.DB, logger *slog.Logger, loginAttemptRepo repository.LoginAttempt, username string, userId *uuid.UUID, ip string, result string) {
+	attempt := &model.LoginAttempt{
+		Username: username,
+		UserId:   userId,
+		IP:       ip,
+		Result:   result,
+	}
+
+	if err := loginAttemptRepo.Create(db, attempt); err != nil && logger != nil {
+		logger.Error("record login attempt failed", "username", username, "error", err)
+	}
+}
@@ -0,0 +1,61 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/lib/cache"
+	"mini-erp-backend/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type LogoutRequest struct {
+	SessionId uuid.UUID
+}
+
+type LogoutResult struct {
+	Message string `json:"message"`
+}
+
+type Logout struct {
+	domainDb     *gorm.DB
+	logger       *slog.Logger
+	sessionRepo  repository.UserSession
+	sessionCache *cache.Cache[string, model.UserSession]
+}
+
+func NewLogout(
+	domainDb *gorm.DB,
+	logger *slog.Logger,
+	sessionRepo repository.UserSession,
+	sessionCache *cache.Cache[string, model.UserSession],
+) *Logout {
+	return &Logout{
+		domainDb:     domainDb,
+		logger:       logger,
+		sessionRepo:  sessionRepo,
+		sessionCache: sessionCache,
+	}
+}
+
+func (l *Logout) Handle(ctx context.Context, request *LogoutRequest) (*LogoutResult, error) {
+	if request == nil || request.SessionId == uuid.Nil {
+		return nil, errors.New("session id is required")
+	}
+
+	if err := l.sessionRepo.Revoke(l.domainDb, request.SessionId); err != nil {
+		if l.logger != nil {
+			l.logger.Error("failed to revoke session", "session_id", request.SessionId, "error", err)
+		}
+		return nil, err
+	}
+
+	l.sessionCache.DeleteFunc(func(_ string, s model.UserSession) bool {
+		return s.SessionId == request.SessionId
+	})
+
+	return &LogoutResult{Message: "Logged out successfully"}, nil
+}
@@ -0,0 +1,66 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/lib/cache"
+	"mini-erp-backend/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type LogoutAllRequest struct {
+	UserId uuid.UUID
+}
+
+type LogoutAllResult struct {
+	Sessions int64  `json:"sessions"`
+	Message  string `json:"message"`
+}
+
+type LogoutAll struct {
+	domainDb     *gorm.DB
+	logger       *slog.Logger
+	sessionRepo  repository.UserSession
+	sessionCache *cache.Cache[string, model.UserSession]
+}
+
+func NewLogoutAll(
+	domainDb *gorm.DB,
+	logger *slog.Logger,
+	sessionRepo repository.UserSession,
+	sessionCache *cache.Cache[string, model.UserSession],
+) *LogoutAll {
+	return &LogoutAll{
+		domainDb:     domainDb,
+		logger:       logger,
+		sessionRepo:  sessionRepo,
+		sessionCache: sessionCache,
+	}
+}
+
+func (l *LogoutAll) Handle(ctx context.Context, request *LogoutAllRequest) (*LogoutAllResult, error) {
+	if request == nil || request.UserId == uuid.Nil {
+		return nil, errors.New("user id is required")
+	}
+
+	revoked, err := l.sessionRepo.RevokeByUserId(l.domainDb, request.UserId)
+	if err != nil {
+		if l.logger != nil {
+			l.logger.Error("failed to revoke user sessions", "user_id", request.UserId, "error", err)
+		}
+		return nil, err
+	}
+
+	l.sessionCache.DeleteFunc(func(_ string, s model.UserSession) bool {
+		return s.UserId == request.UserId
+	})
+
+	return &LogoutAllResult{
+		Sessions: revoked,
+		Message:  "Logged out from all sessions successfully",
+	}, nil
+}
@@ -0,0 +1,126 @@
+package command
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/lib/totp"
+	"mini-erp-backend/model"
+	"mini-erp-backend/utils"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrMfaAlreadyEnabled = errors.New("mfa is already enabled")
+	ErrMfaNotEnrolled    = errors.New("mfa enrollment has not been started")
+	ErrMfaNotEnabled     = errors.New("mfa is not enabled")
+	// ErrMfaRequired is returned when disabling MFA for a role the policy requires it for
+	ErrMfaRequired = errors.New("mfa is required for this role")
+	// ErrInvalidMfaCode is returned for a wrong, replayed or already used TOTP or recovery code
+	ErrInvalidMfaCode = errors.New("invalid mfa code")
+	// ErrInvalidMfaChallenge is returned when the MFA token is unknown, used, expired or out of attempts
+	ErrInvalidMfaChallenge = errors.New("invalid or expired mfa token")
+)
+
+// mfaRequiredPermissions are the permissions that let a role manage accounts or roles,
+// MfaPolicy.RequireForAdmin applies to every role holding one of them, whatever the role is called
+var mfaRequiredPermissions = []model.Permission{model.PermissionUserManage, model.PermissionRoleManage}
+
+// mfaRequired reports whether the policy requires MFA for the role of the user
+func mfaRequired(db *gorm.DB, roleRepo repository.Role, policy utils.MfaPolicy, user *model.User) (bool, error) {
+	if !policy.RequireForAdmin {
+		return false, nil
+	}
+
+	permissions, err := roleRepo.PermissionsByRole(db, user.Role)
+	if err != nil {
+		return false, err
+	}
+
+	for _, p := range mfaRequiredPermissions {
+		if utils.MissingPermission(permissions, []string{string(p)}) == "" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// recoveryCodeCount จำนวน recovery code ที่ออกให้ต่อครั้ง
+const recoveryCodeCount = 10
+
+// generateRecoveryCodes returns the codes to show the user once and the hashed rows to store
+func generateRecoveryCodes(userId uuid.UUID) ([]string, []model.MfaRecoveryCode, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	rows := make([]model.MfaRecoveryCode, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+
+		code := hex.EncodeToString(raw)
+		codes = append(codes, code[:5]+"-"+code[5:])
+		rows = append(rows, model.MfaRecoveryCode{
+			RecoveryCodeId: uuid.New(),
+			UserId:         userId,
+			Code:           utils.HashToken(code),
+		})
+	}
+
+	return codes, rows, nil
+}
+
+// normalizeRecoveryCode accepts a recovery code with or without the dash and in any case
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, "-", "")
+}
+
+// mfaSecret decrypts the TOTP secret of the user, ok is false when the user has none
+func mfaSecret(policy utils.MfaPolicy, user *model.User) (string, bool, error) {
+	if user.MfaSecret == nil {
+		return "", false, nil
+	}
+
+	plaintext, err := policy.SecretBox.Open(*user.MfaSecret)
+	if err != nil {
+		return "", false, err
+	}
+	return plaintext, true, nil
+}
+
+// verifyTotp accepts a TOTP code from the enabled secret of the user and records its time step against replay
+func verifyTotp(db *gorm.DB, userRepo repository.User, policy utils.MfaPolicy, user *model.User, code string) (bool, error) {
+	secret, ok, err := mfaSecret(policy, user)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	counter, ok := totp.Validate(secret, code, time.Now())
+	if !ok || counter <= user.MfaLastCounter {
+		return false, nil
+	}
+
+	return userRepo.UseMfaCounter(db, user.UserId, counter)
+}
+
+// verifyMfaCode accepts either a TOTP code or an unused recovery code
+func verifyMfaCode(db *gorm.DB, userRepo repository.User, mfaRepo repository.Mfa, policy utils.MfaPolicy, user *model.User, code string) (bool, error) {
+	ok, err := verifyTotp(db, userRepo, policy, user, code)
+	if err != nil || ok {
+		return ok, err
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if normalized == "" {
+		return false, nil
+	}
+
+	return mfaRepo.UseRecoveryCode(db, user.UserId, utils.HashToken(normalized))
+}
@@ -0,0 +1,153 @@
+package command
+
+import (
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/lib/secret"
+	"mini-erp-backend/lib/totp"
+	"mini-erp-backend/model"
+	"mini-erp-backend/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fakeUserRepo keeps mfa_last_counter in memory with the same conditional update as the database
+type fakeUserRepo struct {
+	repository.User
+	lastCounter int64
+}
+
+func (f *fakeUserRepo) UseMfaCounter(_ *gorm.DB, _ uuid.UUID, counter int64) (bool, error) {
+	if counter <= f.lastCounter {
+		return false, nil
+	}
+	f.lastCounter = counter
+	return true, nil
+}
+
+// fakeMfaRepo keeps unused recovery code hashes in memory, a used code is removed like in the database
+type fakeMfaRepo struct {
+	repository.Mfa
+	codes map[string]struct{}
+}
+
+func (f *fakeMfaRepo) UseRecoveryCode(_ *gorm.DB, _ uuid.UUID, codeHash string) (bool, error) {
+	if _, ok := f.codes[codeHash]; !ok {
+		return false, nil
+	}
+	delete(f.codes, codeHash)
+	return true, nil
+}
+
+func newMfaPolicy(t *testing.T) utils.MfaPolicy {
+	t.Helper()
+
+	box, err := secret.New("test key")
+	if err != nil {
+		t.Fatalf("secret.New error: %v", err)
+	}
+	return utils.MfaPolicy{SecretBox: box}
+}
+
+// newMfaUser returns a user with MFA enabled, its secret stored encrypted like in the database, and the plaintext secret
+func newMfaUser(t *testing.T, policy utils.MfaPolicy) (*model.User, string) {
+	t.Helper()
+
+	plaintext, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret error: %v", err)
+	}
+	sealed, err := policy.SecretBox.Seal(plaintext)
+	if err != nil {
+		t.Fatalf("Seal error: %v", err)
+	}
+	return &model.User{UserId: uuid.New(), MfaEnabled: true, MfaSecret: &sealed}, plaintext
+}
+
+func TestVerifyTotpRejectsReusedCounter(t *testing.T) {
+	policy := newMfaPolicy(t)
+	user, plaintext := newMfaUser(t, policy)
+	userRepo := &fakeUserRepo{}
+
+	code, err := totp.Code(plaintext, time.Now())
+	if err != nil {
+		t.Fatalf("Code error: %v", err)
+	}
+
+	ok, err := verifyTotp(nil, userRepo, policy, user, code)
+	if err != nil || !ok {
+		t.Fatalf("first use: ok = %v, err = %v, want accepted", ok, err)
+	}
+
+	// user ยังเป็นค่าที่อ่านมาก่อนใช้ code เหมือน request ที่ยิงพร้อมกัน ต้องถูกกันที่ conditional update
+	ok, err = verifyTotp(nil, userRepo, policy, user, code)
+	if err != nil || ok {
+		t.Fatalf("replay: ok = %v, err = %v, want rejected", ok, err)
+	}
+
+	user.MfaLastCounter = userRepo.lastCounter
+	ok, err = verifyTotp(nil, userRepo, policy, user, code)
+	if err != nil || ok {
+		t.Fatalf("replay after reload: ok = %v, err = %v, want rejected", ok, err)
+	}
+}
+
+func TestVerifyMfaCodeRecoveryCodeSingleUse(t *testing.T) {
+	policy := newMfaPolicy(t)
+	user, _ := newMfaUser(t, policy)
+
+	codes, rows, err := generateRecoveryCodes(user.UserId)
+	if err != nil {
+		t.Fatalf("generateRecoveryCodes error: %v", err)
+	}
+	if len(codes) != recoveryCodeCount || len(rows) != recoveryCodeCount {
+		t.Fatalf("got %d codes and %d rows, want %d", len(codes), len(rows), recoveryCodeCount)
+	}
+
+	mfaRepo := &fakeMfaRepo{codes: map[string]struct{}{}}
+	for _, row := range rows {
+		mfaRepo.codes[row.Code] = struct{}{}
+	}
+
+	// รับ code ตัวพิมพ์ใหญ่และมีช่องว่างได้ เพราะผู้ใช้พิมพ์เองจากที่จดไว้
+	input := " " + strings.ToUpper(codes[0]) + " "
+
+	ok, err := verifyMfaCode(nil, &fakeUserRepo{}, mfaRepo, policy, user, input)
+	if err != nil || !ok {
+		t.Fatalf("first use: ok = %v, err = %v, want accepted", ok, err)
+	}
+
+	ok, err = verifyMfaCode(nil, &fakeUserRepo{}, mfaRepo, policy, user, codes[0])
+	if err != nil || ok {
+		t.Fatalf("second use: ok = %v, err = %v, want rejected", ok, err)
+	}
+
+	ok, err = verifyMfaCode(nil, &fakeUserRepo{}, mfaRepo, policy, user, codes[1])
+	if err != nil || !ok {
+		t.Fatalf("other code: ok = %v, err = %v, want accepted", ok, err)
+	}
+}
+
+func TestVerifyTotpRejectsPlaintextSecret(t *testing.T) {
+	policy := newMfaPolicy(t)
+
+	plaintext, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret error: %v", err)
+	}
+	user := &model.User{UserId: uuid.New(), MfaEnabled: true, MfaSecret: &plaintext}
+
+	code, err := totp.Code(plaintext, time.Now())
+	if err != nil {
+		t.Fatalf("Code error: %v", err)
+	}
+
+	// secret ที่ไม่ได้เข้ารหัสต้องไม่ถูกใช้ตรง ๆ
+	ok, err := verifyTotp(nil, &fakeUserRepo{}, policy, user, code)
+	if err == nil || ok {
+		t.Fatalf("plaintext secret: ok = %v, err = %v, want an error", ok, err)
+	}
+}
@@ -2,30 +2,48 @@ package command
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"mini-erp-backend/api/repository"
+	"mini-erp-backend/lib/cache"
 	"mini-erp-backend/lib/jwt"
+	"mini-erp-backend/model"
+	"mini-erp-backend/utils"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
-type RefreshAccessTokenRequest struct{}
+// ErrRefreshTokenReused is returned when an already rotated refresh token is presented again.
+// The whole session is revoked when this happens.
+var ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+
+type RefreshAccessTokenRequest struct {
+	IP string
+}
 
 type RefreshAccessTokenResult struct {
-	UserId         uuid.UUID `json:"user_id"`
-	SessionId      uuid.UUID `json:"session_id"`
-	AccessToken    string    `json:"access_token"`
-	AccessTokenExp int64     `json:"access_token_exp"`
+	UserId          uuid.UUID `json:"user_id"`
+	SessionId       uuid.UUID `json:"session_id"`
+	AccessToken     string    `json:"access_token"`
+	AccessTokenExp  int64     `json:"access_token_exp"`
+	RefreshToken    string    `json:"refresh_token,omitempty"`
+	RefreshTokenExp int64     `json:"refresh_token_exp,omitempty"`
 }
 
 type RefreshAccessToken struct {
-	domainDb   *gorm.DB
-	logger     *slog.Logger
-	jwtManager jwt.Manager
-	userRepo   repository.User
+	domainDb         *gorm.DB
+	logger           *slog.Logger
+	jwtManager       jwt.Manager
+	userRepo         repository.User
+	sessionRepo      repository.UserSession
+	refreshTokenRepo repository.RefreshToken
+	auditLogRepo     repository.AuditLog
+	roleRepo         repository.Role
+	sessionCache     *cache.Cache[string, model.UserSession]
 }
 
 type refreshCtxKey string
@@ -37,17 +55,26 @@ func NewRefreshAccessToken(
 	logger *slog.Logger,
 	jwtManager jwt.Manager,
 	userRepo repository.User,
+	sessionRepo repository.UserSession,
+	refreshTokenRepo repository.RefreshToken,
+	auditLogRepo repository.AuditLog,
+	roleRepo repository.Role,
+	sessionCache *cache.Cache[string, model.UserSession],
 ) *RefreshAccessToken {
 	return &RefreshAccessToken{
-		domainDb:   domainDb,
-		logger:     logger,
-		jwtManager: jwtManager,
-		userRepo:   userRepo,
+		domainDb:         domainDb,
+		logger:           logger,
+		jwtManager:       jwtManager,
+		userRepo:         userRepo,
+		sessionRepo:      sessionRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		auditLogRepo:     auditLogRepo,
+		roleRepo:         roleRepo,
+		sessionCache:     sessionCache,
 	}
 }
 
 func (r *RefreshAccessToken) Handle(ctx context.Context, request *RefreshAccessTokenRequest) (*RefreshAccessTokenResult, error) {
-	var result *RefreshAccessTokenResult
 	// Read refresh token from context (set by HTTP handler)
 	var refreshToken string
 	if v := ctx.Value(RefreshTokenContextKey); v != nil {
@@ -73,52 +100,169 @@ func (r *RefreshAccessToken) Handle(ctx context.Context, request *RefreshAccessT
 		return nil, fmt.Errorf("invalid refresh token")
 	}
 
-	sessionRepo := repository.NewUserSession(r.logger)
-
-	session, err := sessionRepo.GetSessionByRefreshToken(r.domainDb, refreshToken)
+	tx := r.domainDb.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
 
+	stored, err := r.refreshTokenRepo.LockByToken(tx, utils.HashToken(refreshToken))
 	if err != nil {
-		if errors.Is(err, repository.ErrSessionRevoked) {
+		tx.Rollback()
+		return nil, fmt.Errorf("invalid refresh token")
+	}
+
+	if stored.UserNumber != claims.UserId {
+		tx.Rollback()
+		if r.logger != nil {
+			r.logger.Error("refresh token user mismatch", "token_user", claims.UserId, "stored_user", stored.UserNumber)
+		}
+		return nil, fmt.Errorf("invalid refresh token")
+	}
+
+	// token นี้ถูกใช้ไปแล้ว แปลว่ามีคนอื่นถือ token เดียวกันอยู่ ให้ยกเลิกทั้ง session
+	if stored.ConsumedAt != nil {
+		if err := r.revokeSessionFamily(tx, stored, request); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if err := tx.Commit().Error; err != nil {
 			if r.logger != nil {
-				r.logger.Error("refresh token has been revoked", "error", err)
+				r.logger.Error("failed to commit session revocation", "error", err)
 			}
-			return nil, fmt.Errorf("refresh token revoked")
+			return nil, err
 		}
 
-		if r.logger != nil {
-			r.logger.Error("refresh token not found in session store", "error", err)
-		}
+		r.sessionCache.DeleteFunc(func(_ string, s model.UserSession) bool {
+			return s.SessionId == stored.SessionId
+		})
+
+		return nil, ErrRefreshTokenReused
+	}
+
+	if stored.Revoked {
+		tx.Rollback()
+		return nil, fmt.Errorf("refresh token revoked")
+	}
+
+	session, err := r.sessionRepo.SearchBySessionId(tx, stored.SessionId)
+	if err != nil {
+		tx.Rollback()
 		return nil, fmt.Errorf("invalid refresh token")
 	}
 
-	if session.UserId != claims.UserId {
+	if session.Revoked {
+		tx.Rollback()
 		if r.logger != nil {
-			r.logger.Error("refresh token user mismatch", "token_user", claims.UserId, "session_user", session.UserId)
+			r.logger.Error("refresh token has been revoked", "session_id", session.SessionId)
 		}
+		return nil, fmt.Errorf("refresh token revoked")
+	}
+
+	// role และสิทธิ์อ่านใหม่ทุกครั้งที่ refresh การแก้ role ผ่าน API จึงมีผลภายในอายุของ access token
+	user, err := r.userRepo.SearchByConditions(tx, map[string]interface{}{"user_id": claims.UserId})
+	if err != nil {
+		tx.Rollback()
 		return nil, fmt.Errorf("invalid refresh token")
 	}
 
-	accessTokenDetail, err := r.jwtManager.GenerateAccessToken(claims.UserId, claims.Role)
+	permissions, err := r.roleRepo.PermissionsByRole(tx, user.Role)
 	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to generate access token")
+	}
+
+	token, err := r.jwtManager.GenerateLoginToken(user.UserId, string(user.Role), permissions)
+	if err != nil {
+		tx.Rollback()
 		if r.logger != nil {
-			r.logger.Error("failed to generate access token", "error", err)
+			r.logger.Error("failed to generate tokens", "error", err)
 		}
 		return nil, fmt.Errorf("failed to generate access token")
 	}
 
-	if err := sessionRepo.UpdateAccessToken(r.domainDb, session.SessionId, accessTokenDetail.AccessToken); err != nil {
+	next := &model.RefreshToken{
+		RefreshId:  uuid.New(),
+		UserNumber: claims.UserId,
+		SessionId:  session.SessionId,
+		Token:      utils.HashToken(token.RefreshToken),
+		IssueAt:    time.Now(),
+		ExpireAt:   time.Unix(token.RtExpires, 0),
+	}
+
+	if err := r.refreshTokenRepo.Create(tx, next); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to persist refresh token")
+	}
+
+	if err := r.refreshTokenRepo.Consume(tx, stored.RefreshId, next.RefreshId); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to persist refresh token")
+	}
+
+	if err := r.sessionRepo.Rotate(tx, session.SessionId, token.AccessToken, token.AccessUuid, next.Token); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to persist access token")
+	}
+
+	if err := tx.Commit().Error; err != nil {
 		if r.logger != nil {
-			r.logger.Error("failed to persist new access token", "error", err)
+			r.logger.Error("failed to commit token rotation", "error", err)
 		}
 		return nil, fmt.Errorf("failed to persist access token")
 	}
 
-	result = &RefreshAccessTokenResult{
-		UserId:         claims.UserId,
-		SessionId:      session.SessionId,
-		AccessToken:    accessTokenDetail.AccessToken,
-		AccessTokenExp: accessTokenDetail.AtExpires,
+	result := &RefreshAccessTokenResult{
+		UserId:          claims.UserId,
+		SessionId:       session.SessionId,
+		AccessToken:     token.AccessToken,
+		AccessTokenExp:  token.AtExpires,
+		RefreshToken:    token.RefreshToken,
+		RefreshTokenExp: token.RtExpires,
 	}
 
 	return result, nil
 }
+
+// revokeSessionFamily revokes the session a reused refresh token belongs to, every refresh token issued
+// for it, and records the event in the audit log
+func (r *RefreshAccessToken) revokeSessionFamily(tx *gorm.DB, stored *model.RefreshToken, request *RefreshAccessTokenRequest) error {
+	if r.logger != nil {
+		r.logger.Warn("refresh token reuse detected, revoking session",
+			"user_id", stored.UserNumber,
+			"session_id", stored.SessionId,
+			"refresh_id", stored.RefreshId,
+			"ip", request.IP,
+		)
+	}
+
+	if err := r.sessionRepo.Revoke(tx, stored.SessionId); err != nil {
+		return err
+	}
+
+	if err := r.refreshTokenRepo.RevokeBySessionId(tx, stored.SessionId); err != nil {
+		return err
+	}
+
+	detail, err := json.Marshal(map[string]interface{}{
+		"event":       "refresh_token_reuse",
+		"refresh_id":  stored.RefreshId,
+		"replaced_by": stored.ReplacedBy,
+		"consumed_at": stored.ConsumedAt,
+		"ip":          request.IP,
+	})
+	if err != nil {
+		return err
+	}
+
+	sessionId := stored.SessionId.String()
+	return r.auditLogRepo.Create(tx, &model.AuditLog{
+		UserId:   stored.UserNumber,
+		Action:   model.AuditActionSecurity,
+		Entity:   "sessions",
+		EntityId: &sessionId,
+		Detail:   detail,
+	})
+}
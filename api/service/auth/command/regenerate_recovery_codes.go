@@ -0,0 +1,97 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RegenerateRecoveryCodesRequest struct {
+	UserId uuid.UUID
+	Code   string
+}
+
+// RegenerateRecoveryCodes replaces every recovery code of the user, it needs a TOTP code, not a recovery code
+type RegenerateRecoveryCodes struct {
+	domainDb  *gorm.DB
+	logger    *slog.Logger
+	userRepo  repository.User
+	mfaRepo   repository.Mfa
+	mfaPolicy utils.MfaPolicy
+}
+
+func NewRegenerateRecoveryCodes(
+	domainDb *gorm.DB,
+	logger *slog.Logger,
+	userRepo repository.User,
+	mfaRepo repository.Mfa,
+	mfaPolicy utils.MfaPolicy,
+) *RegenerateRecoveryCodes {
+	return &RegenerateRecoveryCodes{
+		domainDb:  domainDb,
+		logger:    logger,
+		userRepo:  userRepo,
+		mfaRepo:   mfaRepo,
+		mfaPolicy: mfaPolicy,
+	}
+}
+
+func (r *RegenerateRecoveryCodes) Handle(ctx context.Context, request *RegenerateRecoveryCodesRequest) (*RecoveryCodesResult, error) {
+	if request == nil || request.UserId == uuid.Nil {
+		return nil, errors.New("user id is required")
+	}
+
+	user, err := r.userRepo.SearchByConditions(r.domainDb, map[string]interface{}{"user_id": request.UserId})
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.MfaEnabled {
+		return nil, ErrMfaNotEnabled
+	}
+
+	codes, rows, err := generateRecoveryCodes(user.UserId)
+	if err != nil {
+		if r.logger != nil {
+			r.logger.Error("generate recovery codes failed", "error", err)
+		}
+		return nil, err
+	}
+
+	tx := r.domainDb.Begin()
+	defer func() {
+		if rec := recover(); rec != nil {
+			tx.Rollback()
+		}
+	}()
+
+	ok, err := verifyTotp(tx, r.userRepo, r.mfaPolicy, user, request.Code)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if !ok {
+		tx.Rollback()
+		return nil, ErrInvalidMfaCode
+	}
+
+	if err := r.mfaRepo.ReplaceRecoveryCodes(tx, user.UserId, rows); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		if r.logger != nil {
+			r.logger.Error("commit recovery code regeneration failed", "user_id", user.UserId, "error", err)
+		}
+		return nil, err
+	}
+
+	return &RecoveryCodesResult{RecoveryCodes: codes}, nil
+}
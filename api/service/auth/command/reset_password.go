@@ -0,0 +1,124 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/lib/cache"
+	"mini-erp-backend/model"
+	"mini-erp-backend/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidResetToken is returned when a password reset token is unknown, already used or expired
+var ErrInvalidResetToken = errors.New("invalid or expired reset token")
+
+type ResetPasswordRequest struct {
+	Token       string
+	NewPassword string
+}
+
+type ResetPasswordResult struct {
+	Message string `json:"message"`
+}
+
+// ResetPassword redeems a reset token issued by an admin and sets the new password of its user
+type ResetPassword struct {
+	domainDb       *gorm.DB
+	logger         *slog.Logger
+	userRepo       repository.User
+	sessionRepo    repository.UserSession
+	resetTokenRepo repository.PasswordResetToken
+	sessionCache   *cache.Cache[string, model.UserSession]
+	passwordPolicy utils.PasswordPolicy
+}
+
+func NewResetPassword(
+	domainDb *gorm.DB,
+	logger *slog.Logger,
+	userRepo repository.User,
+	sessionRepo repository.UserSession,
+	resetTokenRepo repository.PasswordResetToken,
+	sessionCache *cache.Cache[string, model.UserSession],
+	passwordPolicy utils.PasswordPolicy,
+) *ResetPassword {
+	return &ResetPassword{
+		domainDb:       domainDb,
+		logger:         logger,
+		userRepo:       userRepo,
+		sessionRepo:    sessionRepo,
+		resetTokenRepo: resetTokenRepo,
+		sessionCache:   sessionCache,
+		passwordPolicy: passwordPolicy,
+	}
+}
+
+func (p *ResetPassword) Handle(ctx context.Context, request *ResetPasswordRequest) (*ResetPasswordResult, error) {
+	if request == nil || request.Token == "" || request.NewPassword == "" {
+		return nil, errors.New("token and new password are required")
+	}
+
+	if err := p.passwordPolicy.Validate(request.NewPassword); err != nil {
+		return nil, err
+	}
+
+	hashed, err := utils.HashPassword(request.NewPassword)
+	if err != nil {
+		if p.logger != nil {
+			p.logger.Error("password hash failed", "error", err)
+		}
+		return nil, err
+	}
+
+	tx := p.domainDb.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	stored, err := p.resetTokenRepo.LockByToken(tx, utils.HashToken(request.Token))
+	if err != nil {
+		tx.Rollback()
+		return nil, ErrInvalidResetToken
+	}
+
+	if stored.UsedAt != nil || time.Now().After(stored.ExpireAt) {
+		tx.Rollback()
+		if p.logger != nil {
+			p.logger.Error("password reset token used or expired", "reset_id", stored.ResetId)
+		}
+		return nil, ErrInvalidResetToken
+	}
+
+	if err := p.userRepo.UpdatePassword(tx, stored.UserId, hashed); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := p.resetTokenRepo.MarkUsed(tx, stored.ResetId); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if _, err := p.sessionRepo.RevokeByUserId(tx, stored.UserId); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		if p.logger != nil {
+			p.logger.Error("commit password reset failed", "user_id", stored.UserId, "error", err)
+		}
+		return nil, err
+	}
+
+	p.sessionCache.DeleteFunc(func(_ string, s model.UserSession) bool {
+		return s.UserId == stored.UserId
+	})
+
+	return &ResetPasswordResult{Message: "Password reset successfully"}, nil
+}
@@ -0,0 +1,144 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/lib/cache"
+	"mini-erp-backend/lib/jwt"
+	"mini-erp-backend/model"
+	"mini-erp-backend/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type VerifyMfaRequest struct {
+	MfaToken string
+	Code     string
+	IP       string
+}
+
+// VerifyMfa completes a login that returned an MFA challenge
+type VerifyMfa struct {
+	domainDb         *gorm.DB
+	logger           *slog.Logger
+	userRepo         repository.User
+	loginAttemptRepo repository.LoginAttempt
+	mfaRepo          repository.Mfa
+	sessions         sessionIssuer
+	mfaPolicy        utils.MfaPolicy
+}
+
+func NewVerifyMfa(
+	domainDb *gorm.DB,
+	logger *slog.Logger,
+	jwtManager jwt.Manager,
+	userRepo repository.User,
+	sessionRepo repository.UserSession,
+	refreshTokenRepo repository.RefreshToken,
+	loginAttemptRepo repository.LoginAttempt,
+	mfaRepo repository.Mfa,
+	roleRepo repository.Role,
+	sessionCache *cache.Cache[string, model.UserSession],
+	mfaPolicy utils.MfaPolicy,
+) *VerifyMfa {
+	return &VerifyMfa{
+		domainDb:         domainDb,
+		logger:           logger,
+		userRepo:         userRepo,
+		loginAttemptRepo: loginAttemptRepo,
+		mfaRepo:          mfaRepo,
+		sessions: sessionIssuer{
+			domainDb:         domainDb,
+			logger:           logger,
+			jwtManager:       jwtManager,
+			sessionRepo:      sessionRepo,
+			refreshTokenRepo: refreshTokenRepo,
+			roleRepo:         roleRepo,
+			sessionCache:     sessionCache,
+		},
+		mfaPolicy: mfaPolicy,
+	}
+}
+
+func (v *VerifyMfa) Handle(ctx context.Context, request *VerifyMfaRequest) (*LoginResult, error) {
+	if request == nil || request.MfaToken == "" || request.Code == "" {
+		return nil, errors.New("mfa token and code are required")
+	}
+
+	tx := v.domainDb.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	challenge, err := v.mfaRepo.LockChallengeByToken(tx, utils.HashToken(request.MfaToken))
+	if err != nil {
+		tx.Rollback()
+		return nil, ErrInvalidMfaChallenge
+	}
+
+	if challenge.UsedAt != nil || time.Now().After(challenge.ExpireAt) || challenge.Attempts >= v.mfaPolicy.ChallengeMaxAttempts {
+		tx.Rollback()
+		return nil, ErrInvalidMfaChallenge
+	}
+
+	user, err := v.userRepo.SearchByConditions(tx, map[string]interface{}{"user_id": challenge.UserId})
+	if err != nil {
+		tx.Rollback()
+		return nil, ErrInvalidMfaChallenge
+	}
+
+	if user.Disabled || !user.MfaEnabled {
+		tx.Rollback()
+		return nil, ErrInvalidMfaChallenge
+	}
+
+	ok, err := verifyMfaCode(tx, v.userRepo, v.mfaRepo, v.mfaPolicy, user, request.Code)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if !ok {
+		// นับครั้งที่ผิดทั้งใน challenge และใน login attempt เพื่อให้ backoff/lockout ของ username มีผลกับรหัส MFA ด้วย
+		if err := v.mfaRepo.IncrementChallengeAttempts(tx, challenge.ChallengeId); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			return nil, err
+		}
+
+		if v.logger != nil {
+			v.logger.Error("invalid mfa code", "user_id", user.UserId)
+		}
+		recordLoginAttempt(v.domainDb, v.logger, v.loginAttemptRepo, user.Username, &user.UserId, request.IP, model.LoginResultInvalidCredentials)
+		return nil, ErrInvalidMfaCode
+	}
+
+	if err := v.mfaRepo.ConsumeChallenge(tx, challenge.ChallengeId); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		if v.logger != nil {
+			v.logger.Error("commit mfa verification failed", "user_id", user.UserId, "error", err)
+		}
+		return nil, err
+	}
+
+	res, err := v.sessions.issue(user, false)
+	if err != nil {
+		return nil, err
+	}
+
+	recordLoginAttempt(v.domainDb, v.logger, v.loginAttemptRepo, user.Username, &user.UserId, request.IP, model.LoginResultSuccess)
+
+	return res, nil
+}
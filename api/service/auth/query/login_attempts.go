@@ -0,0 +1,98 @@
+package query
+
+import (
+	"context"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type LoginAttempts struct {
+	logger           *slog.Logger
+	db               *gorm.DB
+	loginAttemptRepo repository.LoginAttempt
+}
+
+type LoginAttemptsRequest struct {
+	Page      int        `json:"page"`
+	PageSize  int        `json:"page_size"`
+	Username  string     `json:"username"`
+	UserId    *uuid.UUID `json:"user_id"`
+	IP        string     `json:"ip"`
+	Result    string     `json:"result"` // success, invalid_credentials, disabled, locked หรือ throttled
+	FromDate  *time.Time `json:"from_date"`
+	ToDate    *time.Time `json:"to_date"`
+	SortOrder string     `json:"sort_order"` // asc หรือ desc
+}
+
+type LoginAttemptsResult struct {
+	LoginAttempts []model.LoginAttempt `json:"login_attempts"`
+	Total         int64                `json:"total"`
+	Page          int                  `json:"page"`
+	PageSize      int                  `json:"page_size"`
+	TotalPages    int                  `json:"total_pages"`
+}
+
+func NewLoginAttempts(logger *slog.Logger, db *gorm.DB, loginAttemptRepo repository.LoginAttempt) *LoginAttempts {
+	return &LoginAttempts{
+		logger:           logger,
+		db:               db,
+		loginAttemptRepo: loginAttemptRepo,
+	}
+}
+
+func (l *LoginAttempts) Handle(ctx context.Context, request LoginAttemptsRequest) (*LoginAttemptsResult, error) {
+	filters := repository.LoginAttemptSearchFilters{
+		Username: strings.TrimSpace(strings.ToLower(request.Username)),
+		UserId:   request.UserId,
+		IP:       request.IP,
+		Result:   request.Result,
+		FromDate: request.FromDate,
+		ToDate:   request.ToDate,
+	}
+
+	orderBy := "created_at DESC" // default
+	if request.SortOrder == "asc" || request.SortOrder == "ASC" {
+		orderBy = "created_at ASC"
+	}
+
+	// ตารางนี้โตเร็ว จึงบังคับ pagination เสมอ
+	page := request.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := request.PageSize
+	if pageSize <= 0 {
+		pageSize = 50
+	}
+
+	result, total, err := l.loginAttemptRepo.SearchWithFiltersAndPagination(l.db, filters, orderBy, page, pageSize)
+	if err != nil {
+		l.logger.Error("Failed to get login attempts with pagination", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	// คำนวณจำนวนหน้าทั้งหมด
+	totalPages := 0
+	if total > 0 {
+		totalPages = int(total) / pageSize
+		if int(total)%pageSize > 0 {
+			totalPages++
+		}
+	}
+
+	response := &LoginAttemptsResult{
+		LoginAttempts: result,
+		Total:         total,
+		Page:          page,
+		PageSize:      pageSize,
+		TotalPages:    totalPages,
+	}
+
+	return response, nil
+}
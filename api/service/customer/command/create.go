@@ -0,0 +1,82 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type Create struct {
+	logger       *slog.Logger
+	db           *gorm.DB
+	customerRepo repository.Customer
+}
+
+type CreateRequest struct {
+	Name            string          `json:"name"`
+	ContactName     *string         `json:"contact_name"`
+	Phone           *string         `json:"phone"`
+	Email           *string         `json:"email"`
+	Address         *string         `json:"address"`
+	TaxId           *string         `json:"tax_id"`
+	CreditLimit     decimal.Decimal `json:"credit_limit"`
+	PaymentTermDays int             `json:"payment_term_days"`
+}
+
+type CreateResult struct {
+	Customer model.Customer `json:"customer"`
+}
+
+func NewCreate(logger *slog.Logger, db *gorm.DB, customerRepo repository.Customer) *Create {
+	return &Create{
+		logger:       logger,
+		db:           db,
+		customerRepo: customerRepo,
+	}
+}
+
+func (c *Create) Handle(ctx context.Context, request CreateRequest) (*CreateResult, error) {
+	// ตรวจสอบเลขผู้เสียภาษีซ้ำ
+	if request.TaxId != nil && *request.TaxId != "" {
+		existed, err := c.customerRepo.ExitedByTaxId(c.db, *request.TaxId, nil)
+		if err != nil {
+			c.logger.Error("Failed to check if customer tax id exists", slog.String("error", err.Error()))
+			return nil, err
+		}
+
+		if existed {
+			c.logger.Error("Customer tax id already exists", slog.String("tax_id", *request.TaxId))
+			return nil, errors.New("customer tax id already exists")
+		}
+	}
+
+	customer := &model.Customer{
+		Name:            request.Name,
+		ContactName:     request.ContactName,
+		Phone:           request.Phone,
+		Email:           request.Email,
+		Address:         request.Address,
+		TaxId:           request.TaxId,
+		CreditLimit:     request.CreditLimit,
+		PaymentTermDays: request.PaymentTermDays,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+
+	if err := c.customerRepo.Create(c.db, customer); err != nil {
+		c.logger.Error("Failed to create customer", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	response := &CreateResult{
+		Customer: *customer,
+	}
+
+	return response, nil
+}
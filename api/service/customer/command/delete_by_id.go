@@ -0,0 +1,67 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DeleteById struct {
+	logger       *slog.Logger
+	db           *gorm.DB
+	customerRepo repository.Customer
+}
+
+type DeleteByIdRequest struct {
+	CustomerId uuid.UUID `json:"customer_id"`
+}
+
+type DeleteByIdResult struct {
+	Deleted bool   `json:"deleted"`
+	Message string `json:"message,omitempty"`
+}
+
+func NewDeleteById(logger *slog.Logger, db *gorm.DB, customerRepo repository.Customer) *DeleteById {
+	return &DeleteById{
+		logger:       logger,
+		db:           db,
+		customerRepo: customerRepo,
+	}
+}
+
+func (d *DeleteById) Handle(ctx context.Context, request DeleteByIdRequest) (*DeleteByIdResult, error) {
+	if _, err := d.customerRepo.Search(d.db, map[string]interface{}{
+		"customer_id": request.CustomerId,
+	}, ""); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("customer not found")
+		}
+		return nil, err
+	}
+
+	// ลูกค้าที่มี sales order แล้วลบไม่ได้ เพื่อเก็บประวัติการขาย
+	hasOrders, err := d.customerRepo.HasSalesOrders(d.db, request.CustomerId)
+	if err != nil {
+		return nil, err
+	}
+
+	if hasOrders {
+		d.logger.Error("Customer has sales orders", slog.String("customer_id", request.CustomerId.String()))
+		return nil, errors.New("customer is referenced by sales orders")
+	}
+
+	if err := d.customerRepo.DeleteById(d.db, request.CustomerId); err != nil {
+		d.logger.Error("Failed to delete customer by id", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	response := &DeleteByIdResult{
+		Deleted: true,
+		Message: "Customer deleted successfully",
+	}
+	return response, nil
+}
@@ -0,0 +1,97 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type Update struct {
+	logger       *slog.Logger
+	db           *gorm.DB
+	customerRepo repository.Customer
+}
+
+type UpdateRequest struct {
+	CustomerId      uuid.UUID       `json:"customer_id"`
+	Name            string          `json:"name"`
+	ContactName     *string         `json:"contact_name"`
+	Phone           *string         `json:"phone"`
+	Email           *string         `json:"email"`
+	Address         *string         `json:"address"`
+	TaxId           *string         `json:"tax_id"`
+	CreditLimit     decimal.Decimal `json:"credit_limit"`
+	PaymentTermDays int             `json:"payment_term_days"`
+}
+
+type UpdateResult struct {
+	Customer model.Customer `json:"customer"`
+}
+
+func NewUpdate(logger *slog.Logger, db *gorm.DB, customerRepo repository.Customer) *Update {
+	return &Update{
+		logger:       logger,
+		db:           db,
+		customerRepo: customerRepo,
+	}
+}
+
+func (u *Update) Handle(ctx context.Context, request UpdateRequest) (*UpdateResult, error) {
+	condition := map[string]interface{}{
+		"customer_id": request.CustomerId,
+	}
+
+	// ตรวจสอบว่า customer มีอยู่หรือไม่
+	customer, err := u.customerRepo.Search(u.db, condition, "")
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			u.logger.Error("Customer not found", slog.String("customer_id", request.CustomerId.String()))
+			return nil, errors.New("customer not found")
+		}
+		u.logger.Error("Failed to get customer", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	// ตรวจสอบเลขผู้เสียภาษีซ้ำ (ยกเว้นตัวเอง)
+	if request.TaxId != nil && *request.TaxId != "" {
+		existed, err := u.customerRepo.ExitedByTaxId(u.db, *request.TaxId, &request.CustomerId)
+		if err != nil {
+			u.logger.Error("Failed to check if customer tax id exists", slog.String("error", err.Error()))
+			return nil, err
+		}
+
+		if existed {
+			u.logger.Error("Customer tax id already exists", slog.String("tax_id", *request.TaxId))
+			return nil, errors.New("customer tax id already exists")
+		}
+	}
+
+	// อัพเดทข้อมูล
+	customer.Name = request.Name
+	customer.ContactName = request.ContactName
+	customer.Phone = request.Phone
+	customer.Email = request.Email
+	customer.Address = request.Address
+	customer.TaxId = request.TaxId
+	customer.CreditLimit = request.CreditLimit
+	customer.PaymentTermDays = request.PaymentTermDays
+	customer.UpdatedAt = time.Now()
+
+	if err := u.customerRepo.Update(u.db, customer); err != nil {
+		u.logger.Error("Failed to update customer", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	response := &UpdateResult{
+		Customer: *customer,
+	}
+
+	return response, nil
+}
@@ -0,0 +1,44 @@
+package customer
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/api/service/customer/command"
+	"mini-erp-backend/api/service/customer/query"
+
+	"github.com/mehdihadeli/go-mediatr"
+	"gorm.io/gorm"
+)
+
+func NewService(logger *slog.Logger, db *gorm.DB, customerRepo repository.Customer) {
+	customersService := query.NewCustomers(logger, db, customerRepo)
+	customerByIdService := query.NewCustomerById(logger, db, customerRepo)
+	createCustomerService := command.NewCreate(logger, db, customerRepo)
+	updateCustomerService := command.NewUpdate(logger, db, customerRepo)
+	deleteCustomerByIdService := command.NewDeleteById(logger, db, customerRepo)
+
+	err := mediatr.RegisterRequestHandler(customersService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(customerByIdService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(createCustomerService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(updateCustomerService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(deleteCustomerByIdService)
+	if err != nil {
+		panic(err)
+	}
+}
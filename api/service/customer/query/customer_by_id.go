@@ -0,0 +1,52 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CustomerById struct {
+	logger       *slog.Logger
+	db           *gorm.DB
+	customerRepo repository.Customer
+}
+
+type CustomerByIdRequest struct {
+	CustomerId uuid.UUID `json:"customer_id"`
+}
+
+type CustomerByIdResult struct {
+	Customer model.Customer `json:"customer"`
+}
+
+func NewCustomerById(logger *slog.Logger, db *gorm.DB, customerRepo repository.Customer) *CustomerById {
+	return &CustomerById{
+		logger:       logger,
+		db:           db,
+		customerRepo: customerRepo,
+	}
+}
+
+func (c *CustomerById) Handle(ctx context.Context, request CustomerByIdRequest) (*CustomerByIdResult, error) {
+	conditions := map[string]interface{}{
+		"customer_id": request.CustomerId,
+	}
+	result, err := c.customerRepo.Search(c.db, conditions, "")
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("customer not found")
+		}
+		c.logger.Error("Failed to get customer by id", slog.String("error", err.Error()))
+		return nil, err
+	}
+	response := &CustomerByIdResult{
+		Customer: *result,
+	}
+	return response, nil
+}
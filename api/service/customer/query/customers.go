@@ -0,0 +1,110 @@
+package query
+
+import (
+	"context"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type Customers struct {
+	logger       *slog.Logger
+	db           *gorm.DB
+	customerRepo repository.Customer
+}
+
+type CustomersRequest struct {
+	Page      int    `json:"page"`
+	PageSize  int    `json:"page_size"`
+	Search    string `json:"search"`     // ค้นหาจากชื่อ ผู้ติดต่อ อีเมล เบอร์โทร และเลขผู้เสียภาษี
+	SortBy    string `json:"sort_by"`    // ฟิลด์ที่ต้องการ sort
+	SortOrder string `json:"sort_order"` // asc หรือ desc
+}
+
+type CustomersResult struct {
+	Customers  []model.Customer `json:"customers"`
+	Total      int64            `json:"total"`
+	Page       int              `json:"page"`
+	PageSize   int              `json:"page_size"`
+	TotalPages int              `json:"total_pages"`
+}
+
+func NewCustomers(logger *slog.Logger, db *gorm.DB, customerRepo repository.Customer) *Customers {
+	return &Customers{
+		logger:       logger,
+		db:           db,
+		customerRepo: customerRepo,
+	}
+}
+
+func (c *Customers) Handle(ctx context.Context, request CustomersRequest) (*CustomersResult, error) {
+	filters := repository.CustomerSearchFilters{
+		Search: request.Search,
+	}
+
+	// สร้าง orderBy string
+	orderBy := "created_at DESC" // default
+	if request.SortBy != "" {
+		// กำหนดฟิลด์ที่อนุญาตให้ sort
+		allowedSortFields := map[string]bool{
+			"name":         true,
+			"credit_limit": true,
+			"created_at":   true,
+			"updated_at":   true,
+		}
+
+		if allowedSortFields[request.SortBy] {
+			sortOrder := "DESC"
+			if request.SortOrder == "asc" || request.SortOrder == "ASC" {
+				sortOrder = "ASC"
+			}
+			orderBy = request.SortBy + " " + sortOrder
+		}
+	}
+
+	// ตรวจสอบว่าจะใช้ pagination หรือไม่
+	if request.Page <= 0 || request.PageSize <= 0 {
+		result, err := c.customerRepo.SearchWithFilters(c.db, filters, orderBy)
+		if err != nil {
+			c.logger.Error("Failed to get customers", slog.String("error", err.Error()))
+			return nil, err
+		}
+
+		response := &CustomersResult{
+			Customers:  result,
+			Total:      int64(len(result)),
+			Page:       1,
+			PageSize:   len(result),
+			TotalPages: 1,
+		}
+		return response, nil
+	}
+
+	// ดึงข้อมูลแบบ pagination
+	result, total, err := c.customerRepo.SearchWithFiltersAndPagination(c.db, filters, orderBy, request.Page, request.PageSize)
+	if err != nil {
+		c.logger.Error("Failed to get customers with pagination", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	// คำนวณจำนวนหน้าทั้งหมด
+	totalPages := 0
+	if total > 0 {
+		totalPages = int(total) / request.PageSize
+		if int(total)%request.PageSize > 0 {
+			totalPages++
+		}
+	}
+
+	response := &CustomersResult{
+		Customers:  result,
+		Total:      total,
+		Page:       request.Page,
+		PageSize:   request.PageSize,
+		TotalPages: totalPages,
+	}
+
+	return response, nil
+}
@@ -12,31 +12,50 @@ import (
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrSerialBackorder is returned when a product is set to track serials and allow backorder at the same time
+	ErrSerialBackorder = errors.New("serial tracked product cannot allow backorder")
+	// ErrTrackSerialWithStock is returned when serial tracking is switched while the product has stock on hand
+	ErrTrackSerialWithStock = errors.New("cannot change serial tracking while product has stock")
+	// ErrInvalidCostingMethod is returned for a costing method other than FIFO or AVERAGE
+	ErrInvalidCostingMethod = errors.New("invalid costing method")
+)
+
 type Create struct {
-	logger      *slog.Logger
-	db          *gorm.DB
-	productRepo repository.Product
+	logger       *slog.Logger
+	db           *gorm.DB
+	productRepo  repository.Product
+	supplierRepo repository.Supplier
 }
 
 type CreateRequest struct {
-	ProductCode  string    `json:"product_code"`
-	CategoryId   uuid.UUID `json:"category_id"`
-	Name         string    `json:"name"`
-	CostPrice    float64   `json:"cost_price"`
-	SellingPrice float64   `json:"selling_price"`
-	Unit         string    `json:"unit"`
-	MinStock     int64     `json:"min_stock"`
+	ProductCode    string    `json:"product_code"`
+	CategoryId     uuid.UUID `json:"category_id"`
+	Name           string    `json:"name"`
+	CostPrice      float64   `json:"cost_price"`
+	SellingPrice   float64   `json:"selling_price"`
+	Unit           string    `json:"unit"`
+	MinStock       int64     `json:"min_stock"`
+	AllowBackorder bool      `json:"allow_backorder"`
+	TrackSerial    bool      `json:"track_serial"`
+	// CostingMethod is FIFO or AVERAGE, nil = use the global COSTING_METHOD
+	CostingMethod *model.CostingMethod `json:"costing_method"`
+	// PreferredSupplierId, ReorderQuantity and LeadTimeDays drive the replenishment suggestions
+	PreferredSupplierId *uuid.UUID `json:"preferred_supplier_id"`
+	ReorderQuantity     int64      `json:"reorder_quantity"`
+	LeadTimeDays        int64      `json:"lead_time_days"`
 }
 
 type CreateResult struct {
 	Product model.Product `json:"product"`
 }
 
-func NewCreate(logger *slog.Logger, db *gorm.DB, productRepo repository.Product) *Create {
+func NewCreate(logger *slog.Logger, db *gorm.DB, productRepo repository.Product, supplierRepo repository.Supplier) *Create {
 	return &Create{
-		logger:      logger,
-		db:          db,
-		productRepo: productRepo,
+		logger:       logger,
+		db:           db,
+		productRepo:  productRepo,
+		supplierRepo: supplierRepo,
 	}
 }
 
@@ -68,6 +87,21 @@ func (c *Create) Handle(ctx context.Context, request CreateRequest) (*CreateResu
 		return nil, errors.New("min stock cannot be negative")
 	}
 
+	// สินค้าที่ track serial ต้องมีของจริงทุกชิ้น จึง backorder ไม่ได้
+	if request.TrackSerial && request.AllowBackorder {
+		c.logger.Error("Serial tracked product cannot allow backorder")
+		return nil, ErrSerialBackorder
+	}
+
+	if request.CostingMethod != nil && !request.CostingMethod.IsValid() {
+		c.logger.Error("Invalid costing method", slog.String("costing_method", string(*request.CostingMethod)))
+		return nil, ErrInvalidCostingMethod
+	}
+
+	if err := validateReplenishment(c.db, c.logger, c.supplierRepo, request.PreferredSupplierId, request.ReorderQuantity, request.LeadTimeDays); err != nil {
+		return nil, err
+	}
+
 	// ตรวจสอบ product code ซ้ำ
 	existed, err := c.productRepo.ExitedByProductCode(c.db, request.ProductCode)
 	if err != nil {
@@ -81,17 +115,23 @@ func (c *Create) Handle(ctx context.Context, request CreateRequest) (*CreateResu
 	}
 
 	product := &model.Product{
-		ProductId:    uuid.New(),
-		ProductCode:  request.ProductCode,
-		CategoryId:   request.CategoryId,
-		Name:         request.Name,
-		CostPrice:    request.CostPrice,
-		SellingPrice: request.SellingPrice,
-		Unit:         request.Unit,
-		MinStock:     request.MinStock,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
-		Category:     nil, // ไม่ load category
+		ProductId:           uuid.New(),
+		ProductCode:         request.ProductCode,
+		CategoryId:          request.CategoryId,
+		Name:                request.Name,
+		CostPrice:           request.CostPrice,
+		SellingPrice:        request.SellingPrice,
+		Unit:                request.Unit,
+		MinStock:            request.MinStock,
+		AllowBackorder:      request.AllowBackorder,
+		TrackSerial:         request.TrackSerial,
+		CostingMethod:       request.CostingMethod,
+		PreferredSupplierId: request.PreferredSupplierId,
+		ReorderQuantity:     request.ReorderQuantity,
+		LeadTimeDays:        request.LeadTimeDays,
+		CreatedAt:           time.Now(),
+		UpdatedAt:           time.Now(),
+		Category:            nil, // ไม่ load category
 	}
 
 	if err := c.productRepo.Create(c.db, product); err != nil {
@@ -0,0 +1,100 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidUnit is returned for a unit without a name, with a factor below 2, or named like the base unit
+var ErrInvalidUnit = errors.New("invalid unit of measure")
+
+type CreateUnit struct {
+	logger      *slog.Logger
+	db          *gorm.DB
+	productRepo repository.Product
+	unitRepo    repository.UnitOfMeasure
+}
+
+type CreateUnitRequest struct {
+	ProductId uuid.UUID `json:"-"`
+	Name      string    `json:"name"`
+	// Factor is how many base units one of this unit holds, e.g. 12 for a box of 12 pcs
+	Factor int64 `json:"factor"`
+}
+
+type CreateUnitResult struct {
+	Unit model.UnitOfMeasure `json:"unit"`
+}
+
+func NewCreateUnit(logger *slog.Logger, db *gorm.DB, productRepo repository.Product, unitRepo repository.UnitOfMeasure) *CreateUnit {
+	return &CreateUnit{
+		logger:      logger,
+		db:          db,
+		productRepo: productRepo,
+		unitRepo:    unitRepo,
+	}
+}
+
+func (c *CreateUnit) Handle(ctx context.Context, request CreateUnitRequest) (*CreateUnitResult, error) {
+	request.Name = strings.TrimSpace(request.Name)
+	if request.Name == "" {
+		c.logger.Error("Unit name is required")
+		return nil, fmt.Errorf("%w: unit name is required", ErrInvalidUnit)
+	}
+
+	// factor 1 คือหน่วยฐานซึ่งมีอยู่แล้วใน Product.Unit
+	if request.Factor < 2 {
+		c.logger.Error("Unit factor must be at least 2", slog.Int64("factor", request.Factor))
+		return nil, fmt.Errorf("%w: factor must be at least 2", ErrInvalidUnit)
+	}
+
+	product, err := c.productRepo.Search(c.db, map[string]interface{}{
+		"product_id": request.ProductId,
+	}, "")
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product not found")
+		}
+		return nil, err
+	}
+
+	if strings.EqualFold(request.Name, product.Unit) {
+		c.logger.Error("Unit name is the base unit", slog.String("name", request.Name))
+		return nil, fmt.Errorf("%w: unit name is the base unit of the product", ErrInvalidUnit)
+	}
+
+	existed, err := c.unitRepo.ExitedByName(c.db, product.ProductId, request.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existed {
+		c.logger.Error("Unit already exists", slog.String("name", request.Name))
+		return nil, errors.New("unit already exists")
+	}
+
+	unit := &model.UnitOfMeasure{
+		UnitOfMeasureId: uuid.New(),
+		ProductId:       product.ProductId,
+		Name:            request.Name,
+		Factor:          request.Factor,
+		CreatedAt:       time.Now(),
+	}
+
+	if err := c.unitRepo.Create(c.db, unit); err != nil {
+		c.logger.Error("Failed to create unit of measure", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return &CreateUnitResult{
+		Unit: *unit,
+	}, nil
+}
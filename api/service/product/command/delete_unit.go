@@ -0,0 +1,49 @@
+package command
+
+import (
+	"context"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DeleteUnit struct {
+	logger   *slog.Logger
+	db       *gorm.DB
+	unitRepo repository.UnitOfMeasure
+}
+
+type DeleteUnitRequest struct {
+	ProductId       uuid.UUID `json:"product_id"`
+	UnitOfMeasureId uuid.UUID `json:"unit_of_measure_id"`
+}
+
+type DeleteUnitResult struct {
+	Message string `json:"message"`
+}
+
+func NewDeleteUnit(logger *slog.Logger, db *gorm.DB, unitRepo repository.UnitOfMeasure) *DeleteUnit {
+	return &DeleteUnit{
+		logger:   logger,
+		db:       db,
+		unitRepo: unitRepo,
+	}
+}
+
+// Handle removes a unit. Quantities already converted to the base unit are not affected.
+func (d *DeleteUnit) Handle(ctx context.Context, request DeleteUnitRequest) (*DeleteUnitResult, error) {
+	unit, err := d.unitRepo.SearchById(d.db, request.ProductId, request.UnitOfMeasureId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.unitRepo.Delete(d.db, unit); err != nil {
+		return nil, err
+	}
+
+	return &DeleteUnitResult{
+		Message: "Unit of measure deleted successfully",
+	}, nil
+}
@@ -0,0 +1,39 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidReplenishment is returned for a negative reorder quantity or lead time, or an unknown preferred supplier
+var ErrInvalidReplenishment = errors.New("invalid replenishment settings")
+
+// validateReplenishment checks the reorder settings of a product before it is saved
+func validateReplenishment(db *gorm.DB, logger *slog.Logger, supplierRepo repository.Supplier, preferredSupplierId *uuid.UUID, reorderQuantity, leadTimeDays int64) error {
+	if reorderQuantity < 0 {
+		logger.Error("Reorder quantity cannot be negative", slog.Int64("reorder_quantity", reorderQuantity))
+		return fmt.Errorf("%w: reorder quantity cannot be negative", ErrInvalidReplenishment)
+	}
+
+	if leadTimeDays < 0 {
+		logger.Error("Lead time cannot be negative", slog.Int64("lead_time_days", leadTimeDays))
+		return fmt.Errorf("%w: lead time cannot be negative", ErrInvalidReplenishment)
+	}
+
+	if preferredSupplierId == nil {
+		return nil
+	}
+
+	if _, err := supplierRepo.Search(db, map[string]interface{}{"supplier_id": *preferredSupplierId}, ""); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: preferred supplier does not exist", ErrInvalidReplenishment)
+		}
+		return err
+	}
+	return nil
+}
@@ -13,30 +13,43 @@ import (
 )
 
 type Update struct {
-	logger      *slog.Logger
-	db          *gorm.DB
-	productRepo repository.Product
+	logger           *slog.Logger
+	db               *gorm.DB
+	productRepo      repository.Product
+	stockBalanceRepo repository.StockBalance
+	supplierRepo     repository.Supplier
 }
 
 type UpdateRequest struct {
-	ProductId    uuid.UUID `json:"product_id"`
-	CategoryId   uuid.UUID `json:"category_id"`
-	Name         string    `json:"name"`
-	CostPrice    float64   `json:"cost_price"`
-	SellingPrice float64   `json:"selling_price"`
-	Unit         string    `json:"unit"`
-	MinStock     int64     `json:"min_stock"`
+	ProductId      uuid.UUID `json:"product_id"`
+	CategoryId     uuid.UUID `json:"category_id"`
+	Name           string    `json:"name"`
+	CostPrice      float64   `json:"cost_price"`
+	SellingPrice   float64   `json:"selling_price"`
+	Unit           string    `json:"unit"`
+	MinStock       int64     `json:"min_stock"`
+	AllowBackorder bool      `json:"allow_backorder"`
+	TrackSerial    bool      `json:"track_serial"`
+	// CostingMethod is FIFO or AVERAGE, nil = use the global COSTING_METHOD.
+	// A change applies to stock issued from now on, what is already on hand keeps its layers.
+	CostingMethod *model.CostingMethod `json:"costing_method"`
+	// PreferredSupplierId, ReorderQuantity and LeadTimeDays drive the replenishment suggestions
+	PreferredSupplierId *uuid.UUID `json:"preferred_supplier_id"`
+	ReorderQuantity     int64      `json:"reorder_quantity"`
+	LeadTimeDays        int64      `json:"lead_time_days"`
 }
 
 type UpdateResult struct {
 	Product model.Product `json:"product"`
 }
 
-func NewUpdate(logger *slog.Logger, db *gorm.DB, productRepo repository.Product) *Update {
+func NewUpdate(logger *slog.Logger, db *gorm.DB, productRepo repository.Product, stockBalanceRepo repository.StockBalance, supplierRepo repository.Supplier) *Update {
 	return &Update{
-		logger:      logger,
-		db:          db,
-		productRepo: productRepo,
+		logger:           logger,
+		db:               db,
+		productRepo:      productRepo,
+		stockBalanceRepo: stockBalanceRepo,
+		supplierRepo:     supplierRepo,
 	}
 }
 
@@ -55,12 +68,47 @@ func (u *Update) Handle(ctx context.Context, request UpdateRequest) (*UpdateResu
 		return nil, err
 	}
 
+	if request.TrackSerial && request.AllowBackorder {
+		u.logger.Error("Serial tracked product cannot allow backorder")
+		return nil, ErrSerialBackorder
+	}
+
+	if request.CostingMethod != nil && !request.CostingMethod.IsValid() {
+		u.logger.Error("Invalid costing method", slog.String("costing_method", string(*request.CostingMethod)))
+		return nil, ErrInvalidCostingMethod
+	}
+
+	if err := validateReplenishment(u.db, u.logger, u.supplierRepo, request.PreferredSupplierId, request.ReorderQuantity, request.LeadTimeDays); err != nil {
+		return nil, err
+	}
+
+	// เปลี่ยนการ track serial ได้เฉพาะตอนที่ไม่มีของในคลัง ไม่เช่นนั้นจำนวน serial จะไม่ตรงกับยอดคงเหลือ
+	if request.TrackSerial != product.TrackSerial {
+		balances, err := u.stockBalanceRepo.SearchesByProductId(u.db, product.ProductId)
+		if err != nil {
+			u.logger.Error("Failed to get stock balance", slog.String("error", err.Error()))
+			return nil, err
+		}
+		for _, balance := range balances {
+			if balance.Quantity != 0 {
+				u.logger.Error("Cannot change serial tracking with stock on hand", slog.String("product_id", product.ProductId.String()))
+				return nil, ErrTrackSerialWithStock
+			}
+		}
+	}
+
 	product.Name = request.Name
 	product.CategoryId = request.CategoryId
 	product.CostPrice = request.CostPrice
 	product.SellingPrice = request.SellingPrice
 	product.Unit = request.Unit
 	product.MinStock = request.MinStock
+	product.AllowBackorder = request.AllowBackorder
+	product.TrackSerial = request.TrackSerial
+	product.CostingMethod = request.CostingMethod
+	product.PreferredSupplierId = request.PreferredSupplierId
+	product.ReorderQuantity = request.ReorderQuantity
+	product.LeadTimeDays = request.LeadTimeDays
 	product.UpdatedAt = time.Now()
 
 	if err := u.productRepo.Update(u.db, product); err != nil {
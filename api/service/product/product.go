@@ -14,14 +14,21 @@ func NewService(
 	logger *slog.Logger,
 	db *gorm.DB,
 	productRepo repository.Product,
-	stockTransactionRepo repository.StockTransaction,
+	stockBalanceRepo repository.StockBalance,
+	lotRepo repository.Lot,
+	supplierRepo repository.Supplier,
+	unitRepo repository.UnitOfMeasure,
 ) {
 	productService := query.NewProducts(logger, db, productRepo)
 	productByIdService := query.NewProductById(logger, db, productRepo)
-	productStockSummaryService := query.NewProductStockSummary(logger, db, productRepo, stockTransactionRepo)
-	createProductService := command.NewCreate(logger, db, productRepo)
-	updateProductService := command.NewUpdate(logger, db, productRepo)
+	productStockSummaryService := query.NewProductStockSummary(logger, db, productRepo, stockBalanceRepo)
+	productLotsService := query.NewProductLots(logger, db, productRepo, lotRepo)
+	createProductService := command.NewCreate(logger, db, productRepo, supplierRepo)
+	updateProductService := command.NewUpdate(logger, db, productRepo, stockBalanceRepo, supplierRepo)
 	deleteProductByIdService := command.NewDeleteById(logger, db, productRepo)
+	productUnitsService := query.NewProductUnits(logger, db, productRepo, unitRepo)
+	createUnitService := command.NewCreateUnit(logger, db, productRepo, unitRepo)
+	deleteUnitService := command.NewDeleteUnit(logger, db, unitRepo)
 
 	err := mediatr.RegisterRequestHandler(productService)
 	if err != nil {
@@ -38,6 +45,11 @@ func NewService(
 		panic(err)
 	}
 
+	err = mediatr.RegisterRequestHandler(productLotsService)
+	if err != nil {
+		panic(err)
+	}
+
 	err = mediatr.RegisterRequestHandler(createProductService)
 	if err != nil {
 		panic(err)
@@ -52,4 +64,19 @@ func NewService(
 	if err != nil {
 		panic(err)
 	}
+
+	err = mediatr.RegisterRequestHandler(productUnitsService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(createUnitService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(deleteUnitService)
+	if err != nil {
+		panic(err)
+	}
 }
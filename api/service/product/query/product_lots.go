@@ -0,0 +1,64 @@
+package query
+
+import (
+	"context"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ProductLots struct {
+	logger      *slog.Logger
+	db          *gorm.DB
+	productRepo repository.Product
+	lotRepo     repository.Lot
+}
+
+type ProductLotsRequest struct {
+	ProductId   uuid.UUID  `json:"product_id"`
+	WarehouseId *uuid.UUID `json:"warehouse_id"` // ไม่ระบุ = ทุกคลัง
+}
+
+type ProductLotsResult struct {
+	ProductId uuid.UUID               `json:"product_id"`
+	Lots      []repository.LotBalance `json:"lots"`
+	Total     int64                   `json:"total"` // ยอดคงเหลือรวมของทุก lot
+}
+
+func NewProductLots(logger *slog.Logger, db *gorm.DB, productRepo repository.Product, lotRepo repository.Lot) *ProductLots {
+	return &ProductLots{
+		logger:      logger,
+		db:          db,
+		productRepo: productRepo,
+		lotRepo:     lotRepo,
+	}
+}
+
+func (p *ProductLots) Handle(ctx context.Context, request ProductLotsRequest) (*ProductLotsResult, error) {
+	_, err := p.productRepo.Search(p.db, map[string]interface{}{
+		"product_id": request.ProductId,
+	}, "")
+	if err != nil {
+		p.logger.Error("Failed to get product by id", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	lots, err := p.lotRepo.Balances(p.db, request.ProductId, request.WarehouseId)
+	if err != nil {
+		p.logger.Error("Failed to get lot balances", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	var total int64
+	for _, lot := range lots {
+		total += lot.Quantity
+	}
+
+	return &ProductLotsResult{
+		ProductId: request.ProductId,
+		Lots:      lots,
+		Total:     total,
+	}, nil
+}
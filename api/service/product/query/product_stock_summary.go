@@ -11,10 +11,10 @@ import (
 )
 
 type ProductStockSummary struct {
-	logger               *slog.Logger
-	db                   *gorm.DB
-	productRepo          repository.Product
-	stockTransactionRepo repository.StockTransaction
+	logger           *slog.Logger
+	db               *gorm.DB
+	productRepo      repository.Product
+	stockBalanceRepo repository.StockBalance
 }
 
 type StockSummary struct {
@@ -29,18 +29,19 @@ type ProductStockSummaryRequest struct {
 }
 
 type ProductStockSummaryResult struct {
-	Product    model.Product `json:"product"`
-	Stock      StockSummary  `json:"stock_summary"`
-	MinStock   int64         `json:"min_stock"`
-	IsLowStock bool          `json:"is_low_stock"`
+	Product    model.Product        `json:"product"`
+	Stock      StockSummary         `json:"stock_summary"`
+	Warehouses []model.StockBalance `json:"warehouses"` // ยอดคงเหลือแยกตามคลัง
+	MinStock   int64                `json:"min_stock"`
+	IsLowStock bool                 `json:"is_low_stock"`
 }
 
-func NewProductStockSummary(logger *slog.Logger, db *gorm.DB, productRepo repository.Product, stockTransactionRepo repository.StockTransaction) *ProductStockSummary {
+func NewProductStockSummary(logger *slog.Logger, db *gorm.DB, productRepo repository.Product, stockBalanceRepo repository.StockBalance) *ProductStockSummary {
 	return &ProductStockSummary{
-		logger:               logger,
-		db:                   db,
-		productRepo:          productRepo,
-		stockTransactionRepo: stockTransactionRepo,
+		logger:           logger,
+		db:               db,
+		productRepo:      productRepo,
+		stockBalanceRepo: stockBalanceRepo,
 	}
 }
 
@@ -55,24 +56,27 @@ func (p *ProductStockSummary) Handle(ctx context.Context, request ProductStockSu
 		return nil, err
 	}
 
-	totalIn, totalOut, totalAdjust, err := p.stockTransactionRepo.StockSummary(p.db, request.ProductId)
+	balances, err := p.stockBalanceRepo.SearchesByProductId(p.db, request.ProductId)
 	if err != nil {
-		p.logger.Error("Failed to get stock summary", slog.String("error", err.Error()))
+		p.logger.Error("Failed to get stock balance", slog.String("error", err.Error()))
 		return nil, err
 	}
 
-	currentStock := totalIn - totalOut + totalAdjust
+	// ยอดคงเหลือและยอดเคลื่อนไหวรวมทุกคลัง จาก stock_balances ไม่ต้องรวม ledger ใหม่ทุกครั้ง
+	stock := StockSummary{}
+	for _, balance := range balances {
+		stock.TotalIn += balance.TotalIn
+		stock.TotalOut += balance.TotalOut
+		stock.TotalAdjust += balance.TotalAdjust
+		stock.CurrentStock += balance.Quantity
+	}
 
 	response := &ProductStockSummaryResult{
-		Product: *product,
-		Stock: StockSummary{
-			TotalIn:      totalIn,
-			TotalOut:     totalOut,
-			TotalAdjust:  totalAdjust,
-			CurrentStock: currentStock,
-		},
+		Product:    *product,
+		Stock:      stock,
+		Warehouses: balances,
 		MinStock:   product.MinStock,
-		IsLowStock: currentStock < product.MinStock,
+		IsLowStock: stock.CurrentStock < product.MinStock,
 	}
 
 	return response, nil
@@ -0,0 +1,59 @@
+package query
+
+import (
+	"context"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ProductUnits struct {
+	logger      *slog.Logger
+	db          *gorm.DB
+	productRepo repository.Product
+	unitRepo    repository.UnitOfMeasure
+}
+
+type ProductUnitsRequest struct {
+	ProductId uuid.UUID `json:"product_id"`
+}
+
+type ProductUnitsResult struct {
+	ProductId uuid.UUID             `json:"product_id"`
+	BaseUnit  string                `json:"base_unit"`
+	Units     []model.UnitOfMeasure `json:"units"`
+}
+
+func NewProductUnits(logger *slog.Logger, db *gorm.DB, productRepo repository.Product, unitRepo repository.UnitOfMeasure) *ProductUnits {
+	return &ProductUnits{
+		logger:      logger,
+		db:          db,
+		productRepo: productRepo,
+		unitRepo:    unitRepo,
+	}
+}
+
+func (p *ProductUnits) Handle(ctx context.Context, request ProductUnitsRequest) (*ProductUnitsResult, error) {
+	product, err := p.productRepo.Search(p.db, map[string]interface{}{
+		"product_id": request.ProductId,
+	}, "")
+	if err != nil {
+		p.logger.Error("Failed to get product by id", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	units, err := p.unitRepo.SearchByProductId(p.db, request.ProductId)
+	if err != nil {
+		p.logger.Error("Failed to get units of measure", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return &ProductUnitsResult{
+		ProductId: product.ProductId,
+		BaseUnit:  product.Unit,
+		Units:     units,
+	}, nil
+}
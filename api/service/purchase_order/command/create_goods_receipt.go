@@ -0,0 +1,164 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CreateGoodsReceipt struct {
+	logger        *slog.Logger
+	db            *gorm.DB
+	PORepo        repository.PurchaseOrder
+	ReceiptRepo   repository.GoodsReceipt
+	StockRepo     repository.StockTransaction
+	WarehouseRepo repository.Warehouse
+	LotRepo       repository.Lot
+	SerialRepo    repository.SerialNumber
+	UnitRepo      repository.UnitOfMeasure
+}
+
+type CreateGoodsReceiptRequest struct {
+	PurchaseOrderId uuid.UUID              `json:"-"`
+	ReceivedBy      uuid.UUID              `json:"-"`
+	WarehouseId     *uuid.UUID             `json:"warehouse_id"` // ไม่ระบุ = คลังหลัก
+	LocationId      *uuid.UUID             `json:"location_id"`
+	Note            *string                `json:"note"`
+	Lines           []GoodsReceiptLineItem `json:"lines" validate:"required,min=1,dive"`
+}
+
+type GoodsReceiptLineItem struct {
+	PurchaseOrderItemId uuid.UUID  `json:"purchase_order_item_id" validate:"required"`
+	Quantity            uint64     `json:"quantity" validate:"required,min=1"`
+	Unit                *string    `json:"unit"`        // หน่วยของ quantity ไม่ระบุ = หน่วยฐาน (Product.Unit)
+	LotNumber           *string    `json:"lot_number"`  // ไม่ระบุ = ไม่ติดตาม lot
+	ExpiryDate          *time.Time `json:"expiry_date"` // ใช้ได้เมื่อระบุ lot_number
+	Serials             []string   `json:"serials"`     // บังคับสำหรับสินค้าที่ track serial
+}
+
+type CreateGoodsReceiptResult struct {
+	GoodsReceipt *model.GoodsReceipt       `json:"goods_receipt"`
+	Status       model.PurchaseOrderStatus `json:"status"`
+}
+
+func NewCreateGoodsReceipt(
+	logger *slog.Logger,
+	db *gorm.DB,
+	poRepo repository.PurchaseOrder,
+	receiptRepo repository.GoodsReceipt,
+	stockRepo repository.StockTransaction,
+	warehouseRepo repository.Warehouse,
+	lotRepo repository.Lot,
+	serialRepo repository.SerialNumber,
+	unitRepo repository.UnitOfMeasure,
+) *CreateGoodsReceipt {
+	return &CreateGoodsReceipt{
+		logger:        logger,
+		db:            db,
+		PORepo:        poRepo,
+		ReceiptRepo:   receiptRepo,
+		StockRepo:     stockRepo,
+		WarehouseRepo: warehouseRepo,
+		LotRepo:       lotRepo,
+		SerialRepo:    serialRepo,
+		UnitRepo:      unitRepo,
+	}
+}
+
+func (h *CreateGoodsReceipt) Handle(ctx context.Context, req *CreateGoodsReceiptRequest) (*CreateGoodsReceiptResult, error) {
+	if len(req.Lines) == 0 {
+		return nil, errors.New("goods receipt must have at least one line")
+	}
+
+	for _, line := range req.Lines {
+		if line.Quantity == 0 {
+			return nil, errors.New("quantity must be greater than 0")
+		}
+	}
+
+	// Begin transaction
+	tx := h.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	po, err := h.PORepo.LockById(tx, req.PurchaseOrderId)
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("purchase order not found")
+		}
+		return nil, err
+	}
+
+	if po.Status != model.Confirmed && po.Status != model.PartiallyReceived {
+		tx.Rollback()
+		h.logger.Error("Cannot receive goods for purchase order", "po_id", po.PurchaseOrderId, "status", po.Status)
+		return nil, fmt.Errorf("%w: cannot receive goods for %s purchase order", ErrInvalidStatusTransition, po.Status)
+	}
+
+	warehouse, err := h.WarehouseRepo.Resolve(tx, req.WarehouseId, req.LocationId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	items, err := h.PORepo.SearchItemsByPurchaseOrderId(tx, po.PurchaseOrderId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// แปลงจำนวนจากหน่วยที่ระบุเป็นหน่วยฐาน serial ต้องมีหนึ่งรายการต่อหน่วยฐาน
+	for i := range req.Lines {
+		line := &req.Lines[i]
+		for _, item := range items {
+			if item.PurchaseOrderItemId != line.PurchaseOrderItemId {
+				continue
+			}
+			factor, err := h.UnitRepo.Factor(tx, &item.Product, line.Unit)
+			if err != nil {
+				tx.Rollback()
+				h.logger.Error("Invalid unit", "purchase_order_item_id", line.PurchaseOrderItemId, "error", err)
+				return nil, err
+			}
+			line.Quantity *= uint64(factor)
+			line.Unit = nil
+			break
+		}
+	}
+
+	receipt, status, err := receiveGoods(tx, h.logger, h.PORepo, h.ReceiptRepo, h.StockRepo, h.LotRepo, h.SerialRepo, po, warehouse, req.LocationId, items, req.Lines, req.ReceivedBy, req.Note)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if status != po.Status {
+		if err := h.PORepo.UpdateStatus(tx, po.PurchaseOrderId, status); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		h.logger.Error("Failed to commit transaction", "error", err)
+		return nil, err
+	}
+
+	h.logger.Info("Goods receipt created", "po_id", po.PurchaseOrderId, "goods_receipt_id", receipt.GoodsReceiptId, "status", status)
+	return &CreateGoodsReceiptResult{
+		GoodsReceipt: receipt,
+		Status:       status,
+	}, nil
+}
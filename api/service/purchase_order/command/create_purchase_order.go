@@ -8,25 +8,36 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
 type CreatePurchaseOrder struct {
-	logger      *slog.Logger
-	db          *gorm.DB
-	PORepo      repository.PurchaseOrder
-	ProductRepo repository.Product
+	logger              *slog.Logger
+	db                  *gorm.DB
+	PORepo              repository.PurchaseOrder
+	ProductRepo         repository.Product
+	SupplierProductRepo repository.SupplierProduct
+	UnitRepo            repository.UnitOfMeasure
 }
 
 type CreatePurchaseOrderRequest struct {
 	SupplierId uuid.UUID                 `json:"supplier_id" validate:"required"`
-	CreatedBy  uuid.UUID                 `json:"created_by" validate:"required"`
+	CreatedBy  uuid.UUID                 `json:"-"`
 	Items      []CreatePurchaseOrderItem `json:"items" validate:"required,min=1,dive"`
+	// LegacyCreatedBy is deprecated, still accepted from older clients but ignored: the creator is the authenticated user
+	LegacyCreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	// DiscountAmount is deducted from the subtotal before tax
+	DiscountAmount decimal.Decimal `json:"discount_amount"`
+	// TaxRate is a percentage, e.g. 7 for VAT 7%
+	TaxRate decimal.Decimal `json:"tax_rate"`
 }
 
 type CreatePurchaseOrderItem struct {
 	ProductId uuid.UUID `json:"product_id" validate:"required"`
 	Quantity  uint64    `json:"quantity" validate:"required,min=1"`
+	// Unit is the unit Quantity is given in, nil = the product's base unit
+	Unit *string `json:"unit"`
 }
 
 func NewCreatePurchaseOrder(
@@ -34,12 +45,16 @@ func NewCreatePurchaseOrder(
 	db *gorm.DB,
 	poRepo repository.PurchaseOrder,
 	productRepo repository.Product,
+	supplierProductRepo repository.SupplierProduct,
+	unitRepo repository.UnitOfMeasure,
 ) *CreatePurchaseOrder {
 	return &CreatePurchaseOrder{
-		logger:      logger,
-		db:          db,
-		PORepo:      poRepo,
-		ProductRepo: productRepo,
+		logger:              logger,
+		db:                  db,
+		PORepo:              poRepo,
+		ProductRepo:         productRepo,
+		SupplierProductRepo: supplierProductRepo,
+		UnitRepo:            unitRepo,
 	}
 }
 
@@ -52,11 +67,23 @@ func (h *CreatePurchaseOrder) Handle(ctx context.Context, req *CreatePurchaseOrd
 		}
 	}()
 
-	// Fetch product prices and calculate total amount
-	var totalAmount uint64
-	itemPrices := make(map[uuid.UUID]float64)
-
+	productIds := make([]uuid.UUID, 0, len(req.Items))
 	for _, it := range req.Items {
+		productIds = append(productIds, it.ProductId)
+	}
+
+	// ราคาตาม catalog ของ supplier ถ้ามี ไม่เช่นนั้นใช้ cost price ของสินค้า
+	catalog, err := h.SupplierProductRepo.SearchesByProductIds(h.db, req.SupplierId, productIds)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Fetch product prices and convert quantities to the base unit
+	itemPrices := make(map[uuid.UUID]decimal.Decimal)
+	factors := make([]uint64, len(req.Items))
+
+	for i, it := range req.Items {
 		// Fetch product to get cost price
 		product, err := h.ProductRepo.Search(h.db, map[string]interface{}{
 			"product_id": it.ProductId,
@@ -67,12 +94,26 @@ func (h *CreatePurchaseOrder) Handle(ctx context.Context, req *CreatePurchaseOrd
 			return nil, err
 		}
 
+		factor, err := h.UnitRepo.Factor(h.db, product, it.Unit)
+		if err != nil {
+			tx.Rollback()
+			h.logger.Error("Invalid unit", "product_id", it.ProductId, "error", err)
+			return nil, err
+		}
+		factors[i] = uint64(factor)
+
+		// MOQ และขนาดแพ็คของ supplier เป็นหน่วยฐาน
+		price, err := supplierPrice(product, catalog, it.Quantity*factors[i])
+		if err != nil {
+			tx.Rollback()
+			h.logger.Error("Invalid order quantity", "product_id", it.ProductId, "error", err)
+			return nil, err
+		}
+
 		// Store price snapshot
-		itemPrices[it.ProductId] = product.CostPrice
-		totalAmount += uint64(product.CostPrice * float64(it.Quantity))
+		itemPrices[it.ProductId] = price
 	}
 
-	// Create Purchase Order
 	po := &model.PurchaseOrder{
 		PurchaseOrderId: uuid.New(),
 		SupplierId:      req.SupplierId,
@@ -81,25 +122,39 @@ func (h *CreatePurchaseOrder) Handle(ctx context.Context, req *CreatePurchaseOrd
 		CreatedBy:       req.CreatedBy,
 	}
 
+	items := make([]model.PurchaseOrderItem, 0, len(req.Items))
+	for i, it := range req.Items {
+		items = append(items, model.PurchaseOrderItem{
+			PurchaseOrderItemId: uuid.New(),
+			PurchaseOrderId:     po.PurchaseOrderId,
+			ProductId:           it.ProductId,
+			Quantity:            it.Quantity * factors[i],
+			Unit:                it.Unit,
+			UnitFactor:          factors[i],
+			Price:               itemPrices[it.ProductId],
+		})
+	}
+
+	// Calculate subtotal, tax and grand total
+	if err := applyTotals(po, items, req.DiscountAmount, req.TaxRate); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Create Purchase Order
 	if err := h.PORepo.Create(tx, po); err != nil {
 		tx.Rollback()
 		return nil, err
 	}
 
 	// Create Purchase Order Items
-	for _, it := range req.Items {
-		item := &model.PurchaseOrderItem{
-			PurchaseOrderItemId: uuid.New(),
-			PurchaseOrderId:     po.PurchaseOrderId,
-			ProductId:           it.ProductId,
-			Quantity:            it.Quantity,
-			Price:               itemPrices[it.ProductId],
-		}
-		if err := h.PORepo.CreateItem(tx, item); err != nil {
+	for i := range items {
+		if err := h.PORepo.CreateItem(tx, &items[i]); err != nil {
 			tx.Rollback()
 			return nil, err
 		}
 	}
+	po.PurchaseOrderItem = items
 
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
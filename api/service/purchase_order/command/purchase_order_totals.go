@@ -0,0 +1,35 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"mini-erp-backend/model"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvalidAmount is returned when the discount or tax rate of a purchase order is out of range
+var ErrInvalidAmount = errors.New("invalid purchase order amount")
+
+var hundred = decimal.NewFromInt(100)
+
+// applyTotals sets the discount and tax rate of po and recalculates its totals from items
+func applyTotals(po *model.PurchaseOrder, items []model.PurchaseOrderItem, discount, taxRate decimal.Decimal) error {
+	if discount.IsNegative() {
+		return fmt.Errorf("%w: discount must not be negative", ErrInvalidAmount)
+	}
+
+	if taxRate.IsNegative() || taxRate.GreaterThan(hundred) {
+		return fmt.Errorf("%w: tax rate must be between 0 and 100", ErrInvalidAmount)
+	}
+
+	po.DiscountAmount = discount.Round(2)
+	po.TaxRate = taxRate.Round(2)
+	po.CalculateTotals(items)
+
+	if po.DiscountAmount.GreaterThan(po.Subtotal) {
+		return fmt.Errorf("%w: discount %s exceeds subtotal %s", ErrInvalidAmount, po.DiscountAmount.StringFixed(2), po.Subtotal.StringFixed(2))
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrOverReceipt is returned when a goods receipt line is larger than what is still outstanding on the PO item
+var ErrOverReceipt = errors.New("received quantity exceeds outstanding quantity")
+
+// receiveGoods books a goods receipt for the given lines into warehouse:
+// one stock IN per line (with its lot, if any), the received quantity on the item, and the receipt itself.
+// It returns the status the order should move to afterwards.
+func receiveGoods(
+	tx *gorm.DB,
+	logger *slog.Logger,
+	poRepo repository.PurchaseOrder,
+	receiptRepo repository.GoodsReceipt,
+	stockRepo repository.StockTransaction,
+	lotRepo repository.Lot,
+	serialRepo repository.SerialNumber,
+	po *model.PurchaseOrder,
+	warehouse *model.Warehouse,
+	locationId *uuid.UUID,
+	items []*model.PurchaseOrderItem,
+	lines []GoodsReceiptLineItem,
+	receivedBy uuid.UUID,
+	note *string,
+) (*model.GoodsReceipt, model.PurchaseOrderStatus, error) {
+	itemById := make(map[uuid.UUID]*model.PurchaseOrderItem, len(items))
+	for _, item := range items {
+		itemById[item.PurchaseOrderItemId] = item
+	}
+
+	// รวมจำนวนต่อรายการ เผื่อส่งรายการเดียวกันมาหลายบรรทัด (เช่น คนละ lot)
+	quantities := make(map[uuid.UUID]uint64, len(lines))
+	for _, line := range lines {
+		item, ok := itemById[line.PurchaseOrderItemId]
+		if !ok {
+			return nil, "", fmt.Errorf("purchase order item %s not found", line.PurchaseOrderItemId)
+		}
+		quantities[line.PurchaseOrderItemId] += line.Quantity
+
+		if err := repository.CheckSerials(&item.Product, int64(line.Quantity), line.Serials); err != nil {
+			return nil, "", err
+		}
+
+		if quantities[line.PurchaseOrderItemId] > item.OutstandingQuantity() {
+			return nil, "", fmt.Errorf("%w: item %s outstanding %d, received %d",
+				ErrOverReceipt, item.PurchaseOrderItemId, item.OutstandingQuantity(), quantities[line.PurchaseOrderItemId])
+		}
+	}
+
+	receipt := &model.GoodsReceipt{
+		GoodsReceiptId:  uuid.New(),
+		PurchaseOrderId: po.PurchaseOrderId,
+		WarehouseId:     warehouse.WarehouseId,
+		LocationId:      locationId,
+		Note:            note,
+		ReceivedAt:      time.Now(),
+		ReceivedBy:      receivedBy,
+	}
+
+	for _, line := range lines {
+		if line.Quantity == 0 {
+			continue
+		}
+		item := itemById[line.PurchaseOrderItemId]
+
+		lot, err := lotRepo.Resolve(tx, item.ProductId, line.LotNumber, line.ExpiryDate)
+		if err != nil {
+			return nil, "", err
+		}
+		var lotId *uuid.UUID
+		if lot != nil {
+			lotId = &lot.LotId
+		}
+
+		// บันทึกเฉพาะจำนวนที่รับเข้า ยอดคงเหลือจะถูกอัปเดตใน stock_balances โดย repository
+		stockTx := &model.StockTransaction{
+			StockTransactionId: uuid.New(),
+			ProductId:          item.ProductId,
+			WarehouseId:        warehouse.WarehouseId,
+			LocationId:         locationId,
+			LotId:              lotId,
+			Quantity:           int64(line.Quantity),
+			UnitCost:           item.Price, // cost layer ใช้ราคาซื้อตาม PO
+			UnitCostSet:        true,
+			Type:               model.TransactionTypeIn,
+			Reason:             stringPtr("Purchase Order Received"),
+			ReferenceId:        &po.PurchaseOrderId,
+			CreatedAt:          time.Now(),
+			CreatedBy:          receivedBy.String(),
+		}
+
+		if err := stockRepo.Create(tx, stockTx); err != nil {
+			return nil, "", err
+		}
+
+		if item.Product.TrackSerial {
+			if err := serialRepo.Receive(tx, stockTx, line.Serials); err != nil {
+				return nil, "", err
+			}
+		}
+
+		if err := poRepo.AddItemReceivedQuantity(tx, item.PurchaseOrderItemId, line.Quantity); err != nil {
+			return nil, "", err
+		}
+		item.ReceivedQuantity += line.Quantity
+
+		receipt.Lines = append(receipt.Lines, model.GoodsReceiptLine{
+			GoodsReceiptLineId:  uuid.New(),
+			PurchaseOrderItemId: item.PurchaseOrderItemId,
+			ProductId:           item.ProductId,
+			Quantity:            line.Quantity,
+			StockTransactionId:  stockTx.StockTransactionId,
+			LotId:               lotId,
+		})
+
+		logger.Info("Stock transaction created",
+			"product_id", item.ProductId,
+			"quantity", line.Quantity,
+			"warehouse_id", warehouse.WarehouseId,
+			"po_id", po.PurchaseOrderId)
+	}
+
+	if len(receipt.Lines) == 0 {
+		return nil, "", errors.New("goods receipt must have at least one line with quantity")
+	}
+
+	if err := receiptRepo.Create(tx, receipt); err != nil {
+		return nil, "", err
+	}
+
+	// ปิด PO อัตโนมัติเมื่อรับครบทุกรายการ
+	status := model.Received
+	for _, item := range items {
+		if item.OutstandingQuantity() > 0 {
+			status = model.PartiallyReceived
+			break
+		}
+	}
+
+	return receipt, status, nil
+}
@@ -0,0 +1,33 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"mini-erp-backend/model"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvalidOrderQuantity is returned when an item is below the supplier's minimum order quantity or not a multiple of its pack size
+var ErrInvalidOrderQuantity = errors.New("invalid order quantity")
+
+// supplierPrice returns the price of an item on an order to the supplier of catalog. Products the supplier lists
+// are priced at its unit price and must respect its minimum order quantity and pack size, other products
+// fall back to Product.CostPrice.
+func supplierPrice(product *model.Product, catalog map[uuid.UUID]model.SupplierProduct, quantity uint64) (decimal.Decimal, error) {
+	supplierProduct, ok := catalog[product.ProductId]
+	if !ok {
+		return decimal.NewFromFloat(product.CostPrice), nil
+	}
+
+	if quantity < supplierProduct.MinOrderQuantity {
+		return decimal.Zero, fmt.Errorf("%w: %s must be ordered in at least %d", ErrInvalidOrderQuantity, product.ProductCode, supplierProduct.MinOrderQuantity)
+	}
+
+	if supplierProduct.PackSize > 1 && quantity%supplierProduct.PackSize != 0 {
+		return decimal.Zero, fmt.Errorf("%w: %s must be ordered in packs of %d", ErrInvalidOrderQuantity, product.ProductCode, supplierProduct.PackSize)
+	}
+
+	return supplierProduct.UnitPrice, nil
+}
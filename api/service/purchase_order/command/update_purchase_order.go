@@ -3,30 +3,40 @@ package command
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"mini-erp-backend/api/repository"
 	"mini-erp-backend/model"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
 type UpdatePurchaseOrder struct {
-	logger      *slog.Logger
-	db          *gorm.DB
-	PORepo      repository.PurchaseOrder
-	ProductRepo repository.Product
+	logger              *slog.Logger
+	db                  *gorm.DB
+	PORepo              repository.PurchaseOrder
+	ProductRepo         repository.Product
+	SupplierProductRepo repository.SupplierProduct
+	UnitRepo            repository.UnitOfMeasure
 }
 
 type UpdatePurchaseOrderRequest struct {
 	PurchaseOrderId uuid.UUID
 	SupplierId      uuid.UUID                 `json:"supplier_id" validate:"required"`
 	Items           []UpdatePurchaseOrderItem `json:"items" validate:"required,min=1,dive"`
+	// DiscountAmount is deducted from the subtotal before tax
+	DiscountAmount decimal.Decimal `json:"discount_amount"`
+	// TaxRate is a percentage, e.g. 7 for VAT 7%
+	TaxRate decimal.Decimal `json:"tax_rate"`
 }
 
 type UpdatePurchaseOrderItem struct {
 	ProductId uuid.UUID `json:"product_id" validate:"required"`
 	Quantity  uint64    `json:"quantity" validate:"required,min=1"`
+	// Unit is the unit Quantity is given in, nil = the product's base unit
+	Unit *string `json:"unit"`
 }
 
 func NewUpdatePurchaseOrder(
@@ -34,12 +44,16 @@ func NewUpdatePurchaseOrder(
 	db *gorm.DB,
 	poRepo repository.PurchaseOrder,
 	productRepo repository.Product,
+	supplierProductRepo repository.SupplierProduct,
+	unitRepo repository.UnitOfMeasure,
 ) *UpdatePurchaseOrder {
 	return &UpdatePurchaseOrder{
-		logger:      logger,
-		db:          db,
-		PORepo:      poRepo,
-		ProductRepo: productRepo,
+		logger:              logger,
+		db:                  db,
+		PORepo:              poRepo,
+		ProductRepo:         productRepo,
+		SupplierProductRepo: supplierProductRepo,
+		UnitRepo:            unitRepo,
 	}
 }
 
@@ -52,12 +66,13 @@ func (h *UpdatePurchaseOrder) Handle(ctx context.Context, req *UpdatePurchaseOrd
 		}
 	}()
 
-	// Find PO
-	po, err := h.PORepo.Search(tx, map[string]interface{}{
-		"purchase_order_id": req.PurchaseOrderId,
-	}, "")
+	// Find PO and lock it so the status cannot change while items are replaced
+	po, err := h.PORepo.LockById(tx, req.PurchaseOrderId)
 	if err != nil {
 		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("purchase order not found")
+		}
 		return nil, err
 	}
 
@@ -65,14 +80,26 @@ func (h *UpdatePurchaseOrder) Handle(ctx context.Context, req *UpdatePurchaseOrd
 	if po.Status != model.Draft {
 		tx.Rollback()
 		h.logger.Error("Cannot update purchase order - invalid status", "status", po.Status)
-		return nil, errors.New("can only update draft purchase orders")
+		return nil, fmt.Errorf("%w: can only update draft purchase orders", ErrInvalidStatusTransition)
 	}
 
-	// Fetch product prices and calculate total amount
-	var totalAmount uint64
-	itemPrices := make(map[uuid.UUID]float64)
-
+	productIds := make([]uuid.UUID, 0, len(req.Items))
 	for _, it := range req.Items {
+		productIds = append(productIds, it.ProductId)
+	}
+
+	// ราคาตาม catalog ของ supplier ถ้ามี ไม่เช่นนั้นใช้ cost price ของสินค้า
+	catalog, err := h.SupplierProductRepo.SearchesByProductIds(tx, req.SupplierId, productIds)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Fetch product prices and convert quantities to the base unit
+	itemPrices := make(map[uuid.UUID]decimal.Decimal)
+	factors := make([]uint64, len(req.Items))
+
+	for i, it := range req.Items {
 		// Fetch product to get cost price
 		product, err := h.ProductRepo.Search(tx, map[string]interface{}{
 			"product_id": it.ProductId,
@@ -83,13 +110,45 @@ func (h *UpdatePurchaseOrder) Handle(ctx context.Context, req *UpdatePurchaseOrd
 			return nil, err
 		}
 
+		factor, err := h.UnitRepo.Factor(tx, product, it.Unit)
+		if err != nil {
+			tx.Rollback()
+			h.logger.Error("Invalid unit", "product_id", it.ProductId, "error", err)
+			return nil, err
+		}
+		factors[i] = uint64(factor)
+
+		// MOQ และขนาดแพ็คของ supplier เป็นหน่วยฐาน
+		price, err := supplierPrice(product, catalog, it.Quantity*factors[i])
+		if err != nil {
+			tx.Rollback()
+			h.logger.Error("Invalid order quantity", "product_id", it.ProductId, "error", err)
+			return nil, err
+		}
+
 		// Store price snapshot
-		itemPrices[it.ProductId] = product.CostPrice
-		totalAmount += uint64(product.CostPrice * float64(it.Quantity))
+		itemPrices[it.ProductId] = price
 	}
 
-	// Update PO
+	items := make([]model.PurchaseOrderItem, 0, len(req.Items))
+	for i, it := range req.Items {
+		items = append(items, model.PurchaseOrderItem{
+			PurchaseOrderItemId: uuid.New(),
+			PurchaseOrderId:     po.PurchaseOrderId,
+			ProductId:           it.ProductId,
+			Quantity:            it.Quantity * factors[i],
+			Unit:                it.Unit,
+			UnitFactor:          factors[i],
+			Price:               itemPrices[it.ProductId],
+		})
+	}
+
+	// Update PO and recalculate its totals from the new items
 	po.SupplierId = req.SupplierId
+	if err := applyTotals(po, items, req.DiscountAmount, req.TaxRate); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
 
 	if err := h.PORepo.Update(tx, po); err != nil {
 		tx.Rollback()
@@ -102,19 +161,13 @@ func (h *UpdatePurchaseOrder) Handle(ctx context.Context, req *UpdatePurchaseOrd
 		return nil, err
 	}
 
-	for _, it := range req.Items {
-		item := &model.PurchaseOrderItem{
-			PurchaseOrderItemId: uuid.New(),
-			PurchaseOrderId:     po.PurchaseOrderId,
-			ProductId:           it.ProductId,
-			Quantity:            it.Quantity,
-			Price:               itemPrices[it.ProductId],
-		}
-		if err := h.PORepo.CreateItem(tx, item); err != nil {
+	for i := range items {
+		if err := h.PORepo.CreateItem(tx, &items[i]); err != nil {
 			tx.Rollback()
 			return nil, err
 		}
 	}
+	po.PurchaseOrderItem = items
 
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
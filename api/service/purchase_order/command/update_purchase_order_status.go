@@ -3,39 +3,55 @@ package command
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"mini-erp-backend/api/repository"
 	"mini-erp-backend/model"
-	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// ErrInvalidStatusTransition is returned when a purchase order cannot move from its current status to the requested one
+var ErrInvalidStatusTransition = errors.New("invalid purchase order status transition")
+
 type UpdatePOStatus struct {
-	logger    *slog.Logger
-	db        *gorm.DB
-	PORepo    repository.PurchaseOrder
-	StockRepo repository.StockTransaction
+	logger        *slog.Logger
+	db            *gorm.DB
+	PORepo        repository.PurchaseOrder
+	ReceiptRepo   repository.GoodsReceipt
+	StockRepo     repository.StockTransaction
+	WarehouseRepo repository.Warehouse
+	LotRepo       repository.Lot
+	SerialRepo    repository.SerialNumber
 }
 
 type UpdatePOStatusRequest struct {
 	PurchaseOrderId uuid.UUID
 	Status          model.PurchaseOrderStatus `json:"status" validate:"required"`
-	CreatedBy       uuid.UUID                 `json:"created_by" validate:"required"`
+	WarehouseId     *uuid.UUID                `json:"warehouse_id"` // คลังที่รับของเข้าเมื่อ RECEIVED ไม่ระบุ = คลังหลัก
+	CreatedBy       uuid.UUID                 `json:"-"`
 }
 
 func NewUpdatePOStatus(
 	logger *slog.Logger,
 	db *gorm.DB,
 	poRepo repository.PurchaseOrder,
+	receiptRepo repository.GoodsReceipt,
 	stockRepo repository.StockTransaction,
+	warehouseRepo repository.Warehouse,
+	lotRepo repository.Lot,
+	serialRepo repository.SerialNumber,
 ) *UpdatePOStatus {
 	return &UpdatePOStatus{
-		logger:    logger,
-		db:        db,
-		PORepo:    poRepo,
-		StockRepo: stockRepo,
+		logger:        logger,
+		db:            db,
+		PORepo:        poRepo,
+		ReceiptRepo:   receiptRepo,
+		StockRepo:     stockRepo,
+		WarehouseRepo: warehouseRepo,
+		LotRepo:       lotRepo,
+		SerialRepo:    serialRepo,
 	}
 }
 
@@ -48,11 +64,42 @@ func (h *UpdatePOStatus) Handle(ctx context.Context, req *UpdatePOStatusRequest)
 		}
 	}()
 
-	// If changing to RECEIVED, validate that items exist
-	var items []*model.PurchaseOrderItem
-	var err error
+	// ล็อก PO ไว้ก่อน กันการรับสินค้าซ้ำจาก request ที่เข้ามาพร้อมกัน
+	po, err := h.PORepo.LockById(tx, req.PurchaseOrderId)
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("purchase order not found")
+		}
+		return nil, err
+	}
+
+	// ส่งสถานะเดิมซ้ำ (เช่น RECEIVED สองครั้ง) ถือว่าสำเร็จโดยไม่ทำอะไรเพิ่ม
+	if po.Status == req.Status {
+		tx.Rollback()
+		h.logger.Info("Purchase order already in requested status", "po_id", req.PurchaseOrderId, "status", req.Status)
+		return map[string]interface{}{
+			"purchase_order_id": req.PurchaseOrderId,
+			"status":            po.Status,
+		}, nil
+	}
+
+	// PARTIALLY_RECEIVED is only reached through goods receipts
+	if req.Status == model.PartiallyReceived || !po.Status.CanTransitionTo(req.Status) {
+		tx.Rollback()
+		h.logger.Error("Invalid purchase order status transition", "po_id", req.PurchaseOrderId, "from", po.Status, "to", req.Status)
+		return nil, fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, po.Status, req.Status)
+	}
+
+	// If status is RECEIVED, receive everything still outstanding in one goods receipt
 	if req.Status == model.Received {
-		items, err = h.PORepo.SearchItemsByPurchaseOrderId(tx, req.PurchaseOrderId)
+		warehouse, err := h.WarehouseRepo.Resolve(tx, req.WarehouseId, nil)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		items, err := h.PORepo.SearchItemsByPurchaseOrderId(tx, req.PurchaseOrderId)
 		if err != nil {
 			tx.Rollback()
 			return nil, err
@@ -62,6 +109,19 @@ func (h *UpdatePOStatus) Handle(ctx context.Context, req *UpdatePOStatusRequest)
 			h.logger.Error("Cannot receive purchase order without items", "po_id", req.PurchaseOrderId)
 			return nil, errors.New("cannot receive purchase order without items")
 		}
+
+		outstanding := make([]GoodsReceiptLineItem, 0, len(items))
+		for _, item := range items {
+			outstanding = append(outstanding, GoodsReceiptLineItem{
+				PurchaseOrderItemId: item.PurchaseOrderItemId,
+				Quantity:            item.OutstandingQuantity(),
+			})
+		}
+
+		if _, _, err := receiveGoods(tx, h.logger, h.PORepo, h.ReceiptRepo, h.StockRepo, h.LotRepo, h.SerialRepo, po, warehouse, nil, items, outstanding, req.CreatedBy, nil); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 	}
 
 	// Update status
@@ -70,56 +130,6 @@ func (h *UpdatePOStatus) Handle(ctx context.Context, req *UpdatePOStatusRequest)
 		return nil, err
 	}
 
-	// If status is RECEIVED, create Stock IN transactions
-	if req.Status == model.Received {
-		// Create Stock IN transactions for each item
-		for _, item := range items {
-			// หา transaction ล่าสุด สำหรับ product_id
-			latestStockTx, err := h.StockRepo.GetLatestByProduct(tx, item.ProductId)
-			if err != nil {
-				tx.Rollback()
-				return nil, err
-			}
-
-			// คำนวณ quantity ใหม่: PO item quantity + quantity ของ transaction ล่าสุด
-			newQuantity := int64(item.Quantity)
-			if latestStockTx != nil {
-				newQuantity = latestStockTx.Quantity + int64(item.Quantity)
-			}
-
-			// สร้าง transaction ใหม่
-			stockTx := &model.StockTransaction{
-				StockTransactionId: uuid.New(),
-				ProductId:          item.ProductId,
-				Quantity:           newQuantity,
-				Type:               "IN",
-				Reason:             stringPtr("Purchase Order Received"),
-				ReferenceId:        &req.PurchaseOrderId,
-				CreatedAt:          time.Now(),
-				CreatedBy:          req.CreatedBy.String(),
-			}
-
-			if err := h.StockRepo.Create(tx, stockTx); err != nil {
-				tx.Rollback()
-				return nil, err
-			}
-
-			if latestStockTx != nil {
-				h.logger.Info("Stock transaction created with cumulative quantity",
-					"product_id", item.ProductId,
-					"po_item_quantity", item.Quantity,
-					"previous_quantity", latestStockTx.Quantity,
-					"new_quantity", newQuantity,
-					"po_id", req.PurchaseOrderId)
-			} else {
-				h.logger.Info("Stock transaction created (first)",
-					"product_id", item.ProductId,
-					"quantity", newQuantity,
-					"po_id", req.PurchaseOrderId)
-			}
-		}
-	}
-
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
 		h.logger.Error("Failed to commit transaction", "error", err)
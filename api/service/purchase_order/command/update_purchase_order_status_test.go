@@ -0,0 +1,80 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/lib/testdb"
+	"mini-erp-backend/model"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fakePORepo holds one purchase order and records status updates
+type fakePORepo struct {
+	repository.PurchaseOrder
+	po      model.PurchaseOrder
+	updated []model.PurchaseOrderStatus
+}
+
+func (f *fakePORepo) LockById(_ *gorm.DB, _ uuid.UUID) (*model.PurchaseOrder, error) {
+	po := f.po
+	return &po, nil
+}
+
+func (f *fakePORepo) UpdateStatus(_ *gorm.DB, _ uuid.UUID, status model.PurchaseOrderStatus) error {
+	f.updated = append(f.updated, status)
+	return nil
+}
+
+func TestUpdatePOStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    model.PurchaseOrderStatus
+		to      model.PurchaseOrderStatus
+		wantErr error
+		updated bool
+	}{
+		{"confirm a draft", model.Draft, model.Confirmed, nil, true},
+		{"cancel a confirmed order", model.Confirmed, model.Cancelled, nil, true},
+		{"receive again is a no-op", model.Received, model.Received, nil, false},
+		{"cancel again is a no-op", model.Cancelled, model.Cancelled, nil, false},
+		{"receive a draft", model.Draft, model.Received, ErrInvalidStatusTransition, false},
+		{"cancel a received order", model.Received, model.Cancelled, ErrInvalidStatusTransition, false},
+		{"reopen a cancelled order", model.Cancelled, model.Confirmed, ErrInvalidStatusTransition, false},
+		{"cancel a partially received order", model.PartiallyReceived, model.Cancelled, ErrInvalidStatusTransition, false},
+		{"back to draft", model.Confirmed, model.Draft, ErrInvalidStatusTransition, false},
+		{"partially received by hand", model.Confirmed, model.PartiallyReceived, ErrInvalidStatusTransition, false},
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poId := uuid.New()
+			poRepo := &fakePORepo{po: model.PurchaseOrder{PurchaseOrderId: poId, Status: tt.from}}
+			handler := NewUpdatePOStatus(logger, testdb.Open(t), poRepo, nil, nil, nil, nil, nil)
+
+			result, err := handler.Handle(context.Background(), &UpdatePOStatusRequest{PurchaseOrderId: poId, Status: tt.to})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Handle error = %v, want %v", err, tt.wantErr)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Handle error: %v", err)
+				}
+				if status := result.(map[string]interface{})["status"]; status != tt.to {
+					t.Errorf("status = %v, want %s", status, tt.to)
+				}
+			}
+
+			if updated := len(poRepo.updated) > 0; updated != tt.updated {
+				t.Errorf("status written = %v, want %v", updated, tt.updated)
+			}
+		})
+	}
+}
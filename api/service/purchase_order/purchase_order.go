@@ -10,18 +10,27 @@ import (
 	"gorm.io/gorm"
 )
 
-func NewService(db *gorm.DB, logger *slog.Logger, purchaseOrderRepo repository.PurchaseOrder) error {
-	// Initialize repositories
-	poRepo := repository.NewPurchaseOrder(logger)
-	stockRepo := repository.NewStockTransaction(logger)
-	productRepo := repository.NewProduct(logger)
-
+func NewService(
+	db *gorm.DB,
+	logger *slog.Logger,
+	poRepo repository.PurchaseOrder,
+	receiptRepo repository.GoodsReceipt,
+	stockRepo repository.StockTransaction,
+	productRepo repository.Product,
+	warehouseRepo repository.Warehouse,
+	lotRepo repository.Lot,
+	serialRepo repository.SerialNumber,
+	supplierProductRepo repository.SupplierProduct,
+	unitRepo repository.UnitOfMeasure,
+) error {
 	// Register command handlers
-	createPurchaseOrderHandler := command.NewCreatePurchaseOrder(logger, db, poRepo, productRepo)
-	updatePurchaseOrderHandler := command.NewUpdatePurchaseOrder(logger, db, poRepo, productRepo)
-	updatePOStatusHandler := command.NewUpdatePOStatus(logger, db, poRepo, stockRepo)
+	createPurchaseOrderHandler := command.NewCreatePurchaseOrder(logger, db, poRepo, productRepo, supplierProductRepo, unitRepo)
+	updatePurchaseOrderHandler := command.NewUpdatePurchaseOrder(logger, db, poRepo, productRepo, supplierProductRepo, unitRepo)
+	updatePOStatusHandler := command.NewUpdatePOStatus(logger, db, poRepo, receiptRepo, stockRepo, warehouseRepo, lotRepo, serialRepo)
+	createGoodsReceiptHandler := command.NewCreateGoodsReceipt(logger, db, poRepo, receiptRepo, stockRepo, warehouseRepo, lotRepo, serialRepo, unitRepo)
 	getPurchaseOrderHandler := query.NewPurchaseOrder(logger, db, poRepo)
 	getAllPurchaseOrdersHandler := query.NewAllPurchaseOrders(logger, db, poRepo)
+	getGoodsReceiptsHandler := query.NewGoodsReceipts(logger, db, poRepo, receiptRepo)
 
 	err := mediatr.RegisterRequestHandler(createPurchaseOrderHandler)
 	if err != nil {
@@ -38,6 +47,11 @@ func NewService(db *gorm.DB, logger *slog.Logger, purchaseOrderRepo repository.P
 		return err
 	}
 
+	err = mediatr.RegisterRequestHandler(createGoodsReceiptHandler)
+	if err != nil {
+		return err
+	}
+
 	// Register query handlers
 	err = mediatr.RegisterRequestHandler[*query.PurchaseOrderRequest, *query.PurchaseOrderResult](getPurchaseOrderHandler)
 	if err != nil {
@@ -49,6 +63,11 @@ func NewService(db *gorm.DB, logger *slog.Logger, purchaseOrderRepo repository.P
 		return err
 	}
 
+	err = mediatr.RegisterRequestHandler[*query.GoodsReceiptsRequest, *query.GoodsReceiptsResult](getGoodsReceiptsHandler)
+	if err != nil {
+		return err
+	}
+
 	logger.Info("Purchase Order handlers registered successfully")
 	return nil
 }
@@ -0,0 +1,60 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type GoodsReceipts struct {
+	logger      *slog.Logger
+	db          *gorm.DB
+	PORepo      repository.PurchaseOrder
+	ReceiptRepo repository.GoodsReceipt
+}
+
+type GoodsReceiptsRequest struct {
+	PurchaseOrderId uuid.UUID `json:"purchase_order_id" validate:"required"`
+}
+
+type GoodsReceiptsResult struct {
+	GoodsReceipts []*model.GoodsReceipt `json:"goods_receipts"`
+}
+
+func NewGoodsReceipts(
+	logger *slog.Logger,
+	db *gorm.DB,
+	poRepo repository.PurchaseOrder,
+	receiptRepo repository.GoodsReceipt,
+) *GoodsReceipts {
+	return &GoodsReceipts{
+		logger:      logger,
+		db:          db,
+		PORepo:      poRepo,
+		ReceiptRepo: receiptRepo,
+	}
+}
+
+func (h *GoodsReceipts) Handle(ctx context.Context, req *GoodsReceiptsRequest) (*GoodsReceiptsResult, error) {
+	if _, err := h.PORepo.Search(h.db, map[string]interface{}{
+		"purchase_order_id": req.PurchaseOrderId,
+	}, ""); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("purchase order not found")
+		}
+		return nil, err
+	}
+
+	receipts, err := h.ReceiptRepo.SearchesByPurchaseOrderId(h.db, req.PurchaseOrderId)
+	if err != nil {
+		h.logger.Error("Failed to get goods receipts", "po_id", req.PurchaseOrderId, "error", err)
+		return nil, err
+	}
+
+	return &GoodsReceiptsResult{GoodsReceipts: receipts}, nil
+}
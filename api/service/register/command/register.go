@@ -3,14 +3,15 @@ package command
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"mini-erp-backend/api/repository"
 	"mini-erp-backend/lib/jwt"
 	"mini-erp-backend/model"
+	"mini-erp-backend/utils"
 	"strings"
 
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -20,8 +21,13 @@ type RegisterRequest struct {
 	LastName  string `json:"last_name" form:"last_name" query:"last_name"`
 	Password  string `json:"password" form:"password" query:"password"`
 	Role      string `json:"role" form:"role" query:"role"`
+	// ActorPermissions สิทธิ์ของผู้สร้างบัญชี role ของผู้ใช้ใหม่ต้องไม่มีสิทธิ์เกินนี้
+	ActorPermissions []string `json:"-" form:"-" query:"-"`
 }
 
+// ErrPrivilegeEscalation is returned when the new user's role grants a permission the creator does not have
+var ErrPrivilegeEscalation = errors.New("cannot assign a role with permissions you do not have")
+
 type RegisterResult struct {
 	UserId string `json:"user_id"`
 }
@@ -31,6 +37,8 @@ type UserRegister struct {
 	logger     *slog.Logger
 	jwtManager jwt.Manager
 	regisRepo  repository.User
+	roleRepo   repository.Role
+	policy     utils.PasswordPolicy
 }
 
 func NewUserRegister(
@@ -38,12 +46,16 @@ func NewUserRegister(
 	logger *slog.Logger,
 	jwtManager jwt.Manager,
 	regisRepo repository.User,
+	roleRepo repository.Role,
+	policy utils.PasswordPolicy,
 ) *UserRegister {
 	return &UserRegister{
 		domainDb:   domainDb,
 		logger:     logger,
 		jwtManager: jwtManager,
 		regisRepo:  regisRepo,
+		roleRepo:   roleRepo,
+		policy:     policy,
 	}
 }
 
@@ -67,17 +79,27 @@ func (r *UserRegister) Handle(ctx context.Context, request *RegisterRequest) (*R
 	lastName := strings.ToLower(strings.TrimSpace(request.LastName))
 	roleStr := strings.ToLower(strings.TrimSpace(request.Role))
 
-	// validate role enum
-	if roleStr != string(model.RoleAdmin) &&
-		roleStr != string(model.RoleStaff) &&
-		roleStr != string(model.RoleViewer) {
-		return nil, errors.New("invalid role, allowed: admin, staff, viewer")
-	}
+	// validate role against the roles stored in the database
 	role := model.Role(roleStr)
+	target, err := r.roleRepo.SearchByName(r.domainDb, role)
+	if err != nil {
+		if errors.Is(err, repository.ErrRoleNotFound) {
+			return nil, errors.New("invalid role, the role does not exist")
+		}
+		return nil, err
+	}
+
+	rolePermissions := make([]string, 0, len(target.Permissions))
+	for _, p := range target.Permissions {
+		rolePermissions = append(rolePermissions, string(p))
+	}
+	if missing := utils.MissingPermission(request.ActorPermissions, rolePermissions); missing != "" {
+		return nil, fmt.Errorf("%w: %s", ErrPrivilegeEscalation, missing)
+	}
 
 	// check duplicate username
 	var existing model.User
-	err := r.domainDb.
+	err = r.domainDb.
 		Table("users").
 		Where("username = ?", username).
 		First(&existing).Error
@@ -92,8 +114,13 @@ func (r *UserRegister) Handle(ctx context.Context, request *RegisterRequest) (*R
 		return nil, err
 	}
 
+	// validate password policy
+	if err := r.policy.Validate(request.Password); err != nil {
+		return nil, err
+	}
+
 	// hash password
-	hashedPw, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	hashedPw, err := utils.HashPassword(request.Password)
 	if err != nil {
 		if r.logger != nil {
 			r.logger.Error("password hash failed", "error", err)
@@ -106,7 +133,7 @@ func (r *UserRegister) Handle(ctx context.Context, request *RegisterRequest) (*R
 		Username:  username,
 		FirstName: firstName,
 		LastName:  lastName,
-		Password:  string(hashedPw),
+		Password:  hashedPw,
 		Role:      role,
 	}
 
@@ -5,6 +5,7 @@ import (
 	"mini-erp-backend/api/repository"
 	"mini-erp-backend/api/service/register/command"
 	"mini-erp-backend/lib/jwt"
+	"mini-erp-backend/utils"
 
 	"github.com/mehdihadeli/go-mediatr"
 	"gorm.io/gorm"
@@ -15,12 +16,16 @@ func NewService(
 	logger *slog.Logger,
 	jwtManager jwt.Manager,
 	regisRepo repository.User,
+	roleRepo repository.Role,
+	passwordPolicy utils.PasswordPolicy,
 ) {
 	RegisterService := command.NewUserRegister(
 		domainDb,
 		logger,
 		jwtManager,
 		regisRepo,
+		roleRepo,
+		passwordPolicy,
 	)
 
 	err := mediatr.RegisterRequestHandler(RegisterService)
@@ -0,0 +1,84 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	pocommand "mini-erp-backend/api/service/purchase_order/command"
+	"mini-erp-backend/model"
+
+	"github.com/google/uuid"
+	"github.com/mehdihadeli/go-mediatr"
+	"gorm.io/gorm"
+)
+
+type Generate struct {
+	logger            *slog.Logger
+	db                *gorm.DB
+	replenishmentRepo repository.Replenishment
+}
+
+type GenerateRequest struct {
+	// SupplierIds limits the orders to these suppliers, empty = every supplier with a suggestion
+	SupplierIds []uuid.UUID `json:"supplier_ids"`
+	CreatedBy   uuid.UUID   `json:"-"`
+}
+
+type GenerateResult struct {
+	PurchaseOrders []*model.PurchaseOrder `json:"purchase_orders"`
+	Message        string                 `json:"message"`
+}
+
+func NewGenerate(logger *slog.Logger, db *gorm.DB, replenishmentRepo repository.Replenishment) *Generate {
+	return &Generate{
+		logger:            logger,
+		db:                db,
+		replenishmentRepo: replenishmentRepo,
+	}
+}
+
+// Handle raises one DRAFT purchase order per supplier through the CreatePurchaseOrder command.
+// Draft orders count as on order, so calling it again does not order the same shortage twice.
+func (g *Generate) Handle(ctx context.Context, request GenerateRequest) (*GenerateResult, error) {
+	lines, err := g.replenishmentRepo.SearchSuggestions(g.db, repository.ReplenishmentSearchFilters{
+		SupplierIds: request.SupplierIds,
+	})
+	if err != nil {
+		g.logger.Error("Failed to get replenishment suggestions", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	groups, _ := repository.GroupReplenishmentBySupplier(lines)
+
+	orders := []*model.PurchaseOrder{}
+	for _, group := range groups {
+		items := make([]pocommand.CreatePurchaseOrderItem, 0, len(group.Lines))
+		for _, line := range group.Lines {
+			items = append(items, pocommand.CreatePurchaseOrderItem{
+				ProductId: line.ProductId,
+				Quantity:  uint64(line.SuggestedQuantity),
+			})
+		}
+
+		result, err := mediatr.Send[*pocommand.CreatePurchaseOrderRequest, interface{}](ctx, &pocommand.CreatePurchaseOrderRequest{
+			SupplierId: group.SupplierId,
+			CreatedBy:  request.CreatedBy,
+			Items:      items,
+		})
+		if err != nil {
+			g.logger.Error("Failed to create replenishment purchase order", slog.String("supplier_id", group.SupplierId.String()), slog.String("error", err.Error()))
+			return nil, fmt.Errorf("failed to create purchase order for supplier %s after %d orders were created: %w", group.SupplierName, len(orders), err)
+		}
+
+		if po, ok := result.(*model.PurchaseOrder); ok {
+			orders = append(orders, po)
+		}
+	}
+
+	g.logger.Info("Replenishment purchase orders generated", slog.Int("count", len(orders)))
+	return &GenerateResult{
+		PurchaseOrders: orders,
+		Message:        fmt.Sprintf("%d draft purchase orders created", len(orders)),
+	}, nil
+}
@@ -0,0 +1,56 @@
+package query
+
+import (
+	"context"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Suggestions struct {
+	logger            *slog.Logger
+	db                *gorm.DB
+	replenishmentRepo repository.Replenishment
+}
+
+type SuggestionsRequest struct {
+	SupplierId *uuid.UUID
+}
+
+type SuggestionsResult struct {
+	Suppliers []repository.ReplenishmentGroup `json:"suppliers"`
+	// Unassigned are products below their reorder point without a preferred supplier
+	Unassigned []repository.ReplenishmentLine `json:"unassigned"`
+	Total      int64                          `json:"total"`
+}
+
+func NewSuggestions(logger *slog.Logger, db *gorm.DB, replenishmentRepo repository.Replenishment) *Suggestions {
+	return &Suggestions{
+		logger:            logger,
+		db:                db,
+		replenishmentRepo: replenishmentRepo,
+	}
+}
+
+func (s *Suggestions) Handle(ctx context.Context, request SuggestionsRequest) (*SuggestionsResult, error) {
+	filters := repository.ReplenishmentSearchFilters{}
+	if request.SupplierId != nil {
+		filters.SupplierIds = []uuid.UUID{*request.SupplierId}
+	}
+
+	lines, err := s.replenishmentRepo.SearchSuggestions(s.db, filters)
+	if err != nil {
+		s.logger.Error("Failed to get replenishment suggestions", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	suppliers, unassigned := repository.GroupReplenishmentBySupplier(lines)
+
+	return &SuggestionsResult{
+		Suppliers:  suppliers,
+		Unassigned: unassigned,
+		Total:      int64(len(lines)),
+	}, nil
+}
@@ -0,0 +1,26 @@
+package replenishment
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/api/service/replenishment/command"
+	"mini-erp-backend/api/service/replenishment/query"
+
+	"github.com/mehdihadeli/go-mediatr"
+	"gorm.io/gorm"
+)
+
+func NewService(logger *slog.Logger, db *gorm.DB, replenishmentRepo repository.Replenishment) {
+	suggestionsService := query.NewSuggestions(logger, db, replenishmentRepo)
+	generateService := command.NewGenerate(logger, db, replenishmentRepo)
+
+	err := mediatr.RegisterRequestHandler(suggestionsService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(generateService)
+	if err != nil {
+		panic(err)
+	}
+}
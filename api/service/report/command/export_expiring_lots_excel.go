@@ -0,0 +1,134 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+type ExportExpiringLotsExcel struct {
+	logger     *slog.Logger
+	db         *gorm.DB
+	reportRepo repository.Report
+}
+
+type ExportExpiringLotsExcelRequest struct {
+	Days        int
+	WarehouseId *uuid.UUID
+}
+
+type ExportExpiringLotsExcelResult struct {
+	Data     []byte
+	Filename string
+}
+
+func NewExportExpiringLotsExcel(
+	logger *slog.Logger,
+	db *gorm.DB,
+	reportRepo repository.Report,
+) *ExportExpiringLotsExcel {
+	return &ExportExpiringLotsExcel{
+		logger:     logger,
+		db:         db,
+		reportRepo: reportRepo,
+	}
+}
+
+func (h *ExportExpiringLotsExcel) Handle(ctx context.Context, req *ExportExpiringLotsExcelRequest) (*ExportExpiringLotsExcelResult, error) {
+	expiresBefore := time.Now().AddDate(0, 0, req.Days)
+
+	lots, err := h.reportRepo.GetExpiringLots(h.db, expiresBefore, req.WarehouseId)
+	if err != nil {
+		h.logger.Error("Failed to get expiring lots for export", "error", err)
+		return nil, err
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheetName := "Expiring Lots"
+	index, err := f.NewSheet(sheetName)
+	if err != nil {
+		return nil, err
+	}
+	f.SetActiveSheet(index)
+	f.DeleteSheet("Sheet1")
+
+	// Create header style
+	headerStyle, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Size: 11},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#D3D3D3"}, Pattern: 1},
+		Alignment: &excelize.Alignment{
+			Horizontal: "center",
+			Vertical:   "center",
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// lot ที่หมดอายุแล้วแสดงเป็นสีแดง
+	expiredStyle, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Color: "#C00000"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Write headers
+	headers := []string{"Lot Number", "Expiry Date", "Days To Expiry", "Product Code", "Product Name", "Warehouse", "Quantity"}
+	for i, header := range headers {
+		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
+		f.SetCellValue(sheetName, cell, header)
+		f.SetCellStyle(sheetName, cell, cell, headerStyle)
+	}
+
+	// Write data
+	for i, lot := range lots {
+		row := i + 2
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), lot.LotNumber)
+		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), lot.ExpiryDate.Format("02-01-2006"))
+		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), lot.DaysToExpiry)
+		f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), lot.ProductCode)
+		f.SetCellValue(sheetName, fmt.Sprintf("E%d", row), lot.ProductName)
+		f.SetCellValue(sheetName, fmt.Sprintf("F%d", row), lot.WarehouseCode)
+		f.SetCellValue(sheetName, fmt.Sprintf("G%d", row), lot.Quantity)
+
+		if lot.DaysToExpiry < 0 {
+			f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("G%d", row), expiredStyle)
+		}
+	}
+
+	// Auto-fit columns
+	for i := 1; i <= len(headers); i++ {
+		col, _ := excelize.ColumnNumberToName(i)
+		f.SetColWidth(sheetName, col, col, 15)
+	}
+
+	// Freeze first row
+	f.SetPanes(sheetName, &excelize.Panes{
+		Freeze:      true,
+		XSplit:      0,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	})
+
+	buffer, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+
+	filename := fmt.Sprintf("expiring_lots_%s.xlsx", expiresBefore.Format("02-01-2006"))
+
+	return &ExportExpiringLotsExcelResult{
+		Data:     buffer.Bytes(),
+		Filename: filename,
+	}, nil
+}
@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"mini-erp-backend/api/repository"
 
+	"github.com/shopspring/decimal"
 	"github.com/xuri/excelize/v2"
 	"gorm.io/gorm"
 )
@@ -88,23 +89,23 @@ func (h *ExportPurchaseReportExcel) Handle(ctx context.Context, req *ExportPurch
 
 	// Write data
 	var totalOrders int64
-	var totalAmount uint64
+	totalAmount := decimal.Zero
 	for i, s := range summary {
 		row := i + 4
 		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), s.Status)
 		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), s.TotalOrders)
-		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), s.TotalAmount)
-		f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), fmt.Sprintf("%.2f", s.AverageAmount))
+		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), s.TotalAmount.InexactFloat64())
+		f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), s.AverageAmount.InexactFloat64())
 
 		totalOrders += s.TotalOrders
-		totalAmount += s.TotalAmount
+		totalAmount = totalAmount.Add(s.TotalAmount)
 	}
 
 	// Write summary row
 	summaryRow := len(summary) + 5
 	f.SetCellValue(sheetName, fmt.Sprintf("A%d", summaryRow), "TOTAL")
 	f.SetCellValue(sheetName, fmt.Sprintf("B%d", summaryRow), totalOrders)
-	f.SetCellValue(sheetName, fmt.Sprintf("C%d", summaryRow), totalAmount)
+	f.SetCellValue(sheetName, fmt.Sprintf("C%d", summaryRow), totalAmount.InexactFloat64())
 
 	summaryStyle, _ := f.NewStyle(&excelize.Style{
 		Font: &excelize.Font{Bold: true, Size: 11},
@@ -112,6 +113,16 @@ func (h *ExportPurchaseReportExcel) Handle(ctx context.Context, req *ExportPurch
 	})
 	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", summaryRow), fmt.Sprintf("D%d", summaryRow), summaryStyle)
 
+	// Show amounts with 2 decimal places
+	amountStyle, _ := f.NewStyle(&excelize.Style{NumFmt: 4})
+	f.SetCellStyle(sheetName, "C4", fmt.Sprintf("D%d", len(summary)+3), amountStyle)
+	amountSummaryStyle, _ := f.NewStyle(&excelize.Style{
+		Font:   &excelize.Font{Bold: true, Size: 11},
+		Fill:   excelize.Fill{Type: "pattern", Color: []string{"#E7E6E6"}, Pattern: 1},
+		NumFmt: 4,
+	})
+	f.SetCellStyle(sheetName, fmt.Sprintf("C%d", summaryRow), fmt.Sprintf("D%d", summaryRow), amountSummaryStyle)
+
 	// Auto-fit columns
 	f.SetColWidth(sheetName, "A", "A", 15)
 	f.SetColWidth(sheetName, "B", "B", 15)
@@ -9,6 +9,7 @@ import (
 	"mini-erp-backend/api/repository"
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -18,7 +19,11 @@ type ExportStockSummaryCSV struct {
 	reportRepo repository.Report
 }
 
-type ExportStockSummaryCSVRequest struct{}
+type ExportStockSummaryCSVRequest struct {
+	WarehouseId *uuid.UUID `json:"warehouse_id"` // ไม่ระบุ = รวมทุกคลัง
+	AsOf        *time.Time `json:"as_of"`        // ไม่ระบุ = ยอดปัจจุบัน
+	Unit        *string    `json:"unit"`         // แสดงยอดเป็นหน่วยนี้ด้วย สำหรับสินค้าที่มีหน่วยนี้
+}
 
 type ExportStockSummaryCSVResult struct {
 	Data     []byte
@@ -38,7 +43,7 @@ func NewExportStockSummaryCSV(
 }
 
 func (h *ExportStockSummaryCSV) Handle(ctx context.Context, req *ExportStockSummaryCSVRequest) (*ExportStockSummaryCSVResult, error) {
-	products, err := h.reportRepo.GetStockSummary(h.db)
+	products, err := h.reportRepo.GetStockSummary(h.db, req.WarehouseId, req.AsOf, req.Unit)
 	if err != nil {
 		h.logger.Error("Failed to get stock summary for export", "error", err)
 		return nil, err
@@ -53,13 +58,19 @@ func (h *ExportStockSummaryCSV) Handle(ctx context.Context, req *ExportStockSumm
 		"Product Name",
 		"Category",
 		"Stock On Hand",
+		"Unit",
 		"Cost Price",
 		"Selling Price",
 		"Total Cost Value",
 		"Total Selling Value",
+		"In Transit",
+		"In Transit Cost Value",
 		"Min Stock",
 		"Low Stock",
 	}
+	if req.Unit != nil {
+		header = append(header, "Display Quantity", "Display Unit")
+	}
 	if err := writer.Write(header); err != nil {
 		return nil, err
 	}
@@ -76,13 +87,19 @@ func (h *ExportStockSummaryCSV) Handle(ctx context.Context, req *ExportStockSumm
 			p.Name,
 			p.CategoryName,
 			fmt.Sprintf("%d", p.StockOnHand),
+			p.Unit,
 			fmt.Sprintf("%.2f", p.CostPrice),
 			fmt.Sprintf("%.2f", p.SellingPrice),
 			fmt.Sprintf("%.2f", p.TotalCostValue),
 			fmt.Sprintf("%.2f", p.TotalSellingValue),
+			fmt.Sprintf("%d", p.InTransit),
+			fmt.Sprintf("%.2f", p.InTransitCostValue),
 			fmt.Sprintf("%d", p.MinStock),
 			lowStock,
 		}
+		if req.Unit != nil {
+			row = append(row, p.DisplayQuantity.String(), p.DisplayUnit)
+		}
 		if err := writer.Write(row); err != nil {
 			return nil, err
 		}
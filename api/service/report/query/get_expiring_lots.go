@@ -0,0 +1,64 @@
+package query
+
+import (
+	"context"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ExpiringLots struct {
+	logger     *slog.Logger
+	db         *gorm.DB
+	reportRepo repository.Report
+}
+
+type ExpiringLotsRequest struct {
+	Days        int        `json:"days"`         // lot ที่หมดอายุภายใน N วันนับจากวันนี้
+	WarehouseId *uuid.UUID `json:"warehouse_id"` // ไม่ระบุ = ทุกคลัง
+}
+
+type ExpiringLotsResult struct {
+	ExpiresBefore time.Time                      `json:"expires_before"`
+	Lots          []repository.ExpiringLotResult `json:"lots"`
+	ExpiredCount  int                            `json:"expired_count"` // lot ที่หมดอายุแล้วแต่ยังมีของ
+	TotalQuantity int64                          `json:"total_quantity"`
+}
+
+func NewExpiringLots(
+	logger *slog.Logger,
+	db *gorm.DB,
+	reportRepo repository.Report,
+) *ExpiringLots {
+	return &ExpiringLots{
+		logger:     logger,
+		db:         db,
+		reportRepo: reportRepo,
+	}
+}
+
+func (h *ExpiringLots) Handle(ctx context.Context, req *ExpiringLotsRequest) (*ExpiringLotsResult, error) {
+	expiresBefore := time.Now().AddDate(0, 0, req.Days)
+
+	lots, err := h.reportRepo.GetExpiringLots(h.db, expiresBefore, req.WarehouseId)
+	if err != nil {
+		h.logger.Error("Failed to get expiring lots", "error", err)
+		return nil, err
+	}
+
+	result := &ExpiringLotsResult{
+		ExpiresBefore: expiresBefore,
+		Lots:          lots,
+	}
+	for _, lot := range lots {
+		result.TotalQuantity += lot.Quantity
+		if lot.DaysToExpiry < 0 {
+			result.ExpiredCount++
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,68 @@
+package query
+
+import (
+	"context"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type InventoryValuation struct {
+	logger     *slog.Logger
+	db         *gorm.DB
+	reportRepo repository.Report
+}
+
+type InventoryValuationRequest struct {
+	AsOf        time.Time  `json:"as_of"`        // มูลค่า ณ สิ้นวันนี้
+	WarehouseId *uuid.UUID `json:"warehouse_id"` // ไม่ระบุ = ทุกคลัง
+}
+
+type InventoryValuationResult struct {
+	AsOf          time.Time                             `json:"as_of"`
+	Products      []repository.InventoryValuationResult `json:"products"`
+	TotalQuantity int64                                 `json:"total_quantity"`
+	TotalValue    decimal.Decimal                       `json:"total_value"`
+	// TotalInTransit and TotalInTransitValue are the part of the totals shipped between warehouses and not arrived yet
+	TotalInTransit      int64           `json:"total_in_transit"`
+	TotalInTransitValue decimal.Decimal `json:"total_in_transit_value"`
+}
+
+func NewInventoryValuation(
+	logger *slog.Logger,
+	db *gorm.DB,
+	reportRepo repository.Report,
+) *InventoryValuation {
+	return &InventoryValuation{
+		logger:     logger,
+		db:         db,
+		reportRepo: reportRepo,
+	}
+}
+
+func (h *InventoryValuation) Handle(ctx context.Context, req *InventoryValuationRequest) (*InventoryValuationResult, error) {
+	products, err := h.reportRepo.GetInventoryValuation(h.db, req.AsOf, req.WarehouseId)
+	if err != nil {
+		h.logger.Error("Failed to get inventory valuation", "error", err)
+		return nil, err
+	}
+
+	result := &InventoryValuationResult{
+		AsOf:                req.AsOf,
+		Products:            products,
+		TotalValue:          decimal.Zero,
+		TotalInTransitValue: decimal.Zero,
+	}
+	for _, p := range products {
+		result.TotalQuantity += p.Quantity
+		result.TotalValue = result.TotalValue.Add(p.TotalValue)
+		result.TotalInTransit += p.InTransit
+		result.TotalInTransitValue = result.TotalInTransitValue.Add(p.InTransitValue)
+	}
+
+	return result, nil
+}
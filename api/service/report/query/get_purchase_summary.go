@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"mini-erp-backend/api/repository"
 
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
@@ -22,9 +23,9 @@ type PurchaseSummaryRequest struct {
 type PurchaseSummaryResult struct {
 	Summary        []repository.PurchaseSummaryResult `json:"summary"`
 	TotalOrders    int64                              `json:"total_orders"`
-	TotalAmount    uint64                             `json:"total_amount"`
+	TotalAmount    decimal.Decimal                    `json:"total_amount"`
 	ReceivedOrders int64                              `json:"received_orders"`
-	ReceivedAmount uint64                             `json:"received_amount"`
+	ReceivedAmount decimal.Decimal                    `json:"received_amount"`
 }
 
 func NewPurchaseSummary(
@@ -47,13 +48,13 @@ func (h *PurchaseSummary) Handle(ctx context.Context, req *PurchaseSummaryReques
 	}
 
 	var totalOrders int64
-	var totalAmount uint64
+	totalAmount := decimal.Zero
 	var receivedOrders int64
-	var receivedAmount uint64
+	receivedAmount := decimal.Zero
 
 	for _, s := range summary {
 		totalOrders += s.TotalOrders
-		totalAmount += s.TotalAmount
+		totalAmount = totalAmount.Add(s.TotalAmount)
 		if s.Status == "RECEIVED" {
 			receivedOrders = s.TotalOrders
 			receivedAmount = s.TotalAmount
@@ -4,7 +4,9 @@ import (
 	"context"
 	"log/slog"
 	"mini-erp-backend/api/repository"
+	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -14,15 +16,24 @@ type StockSummary struct {
 	reportRepo repository.Report
 }
 
-type StockSummaryRequest struct{}
+type StockSummaryRequest struct {
+	WarehouseId *uuid.UUID `json:"warehouse_id"` // ไม่ระบุ = รวมทุกคลัง
+	AsOf        *time.Time `json:"as_of"`        // ไม่ระบุ = ยอดปัจจุบัน
+	Unit        *string    `json:"unit"`         // แสดงยอดเป็นหน่วยนี้ด้วย สำหรับสินค้าที่มีหน่วยนี้
+}
 
 type StockSummaryResult struct {
-	Products          []repository.StockSummaryResult `json:"products"`
-	TotalStockOnHand  int64                           `json:"total_stock_on_hand"`
-	TotalCostValue    float64                         `json:"total_cost_value"`
-	TotalSellingValue float64                         `json:"total_selling_value"`
-	LowStock          []repository.StockSummaryResult `json:"low_stock"`
-	LowStockCount     int                             `json:"low_stock_count"`
+	AsOf              *time.Time                               `json:"as_of"`
+	Products          []repository.StockSummaryResult          `json:"products"`
+	Warehouses        []repository.WarehouseStockSummaryResult `json:"warehouses"`
+	TotalStockOnHand  int64                                    `json:"total_stock_on_hand"`
+	TotalCostValue    float64                                  `json:"total_cost_value"`
+	TotalSellingValue float64                                  `json:"total_selling_value"`
+	// TotalInTransit is stock shipped between warehouses that has not arrived, not part of TotalStockOnHand
+	TotalInTransit          int64                           `json:"total_in_transit"`
+	TotalInTransitCostValue float64                         `json:"total_in_transit_cost_value"`
+	LowStock                []repository.StockSummaryResult `json:"low_stock"`
+	LowStockCount           int                             `json:"low_stock_count"`
 }
 
 func NewStockSummary(
@@ -38,15 +49,23 @@ func NewStockSummary(
 }
 
 func (h *StockSummary) Handle(ctx context.Context, req *StockSummaryRequest) (*StockSummaryResult, error) {
-	products, err := h.reportRepo.GetStockSummary(h.db)
+	products, err := h.reportRepo.GetStockSummary(h.db, req.WarehouseId, req.AsOf, req.Unit)
 	if err != nil {
 		h.logger.Error("Failed to get stock summary", "error", err)
 		return nil, err
 	}
 
+	warehouses, err := h.reportRepo.GetWarehouseStockSummary(h.db, req.WarehouseId, req.AsOf)
+	if err != nil {
+		h.logger.Error("Failed to get warehouse stock summary", "error", err)
+		return nil, err
+	}
+
 	var totalStock int64
 	var totalCost float64
 	var totalSelling float64
+	var totalInTransit int64
+	var totalInTransitCost float64
 	var lowStock []repository.StockSummaryResult
 
 	for i := range products {
@@ -54,6 +73,8 @@ func (h *StockSummary) Handle(ctx context.Context, req *StockSummaryRequest) (*S
 		totalStock += p.StockOnHand
 		totalCost += p.TotalCostValue
 		totalSelling += p.TotalSellingValue
+		totalInTransit += p.InTransit
+		totalInTransitCost += p.InTransitCostValue
 		if p.StockOnHand < p.MinStock {
 			p.IsLowStock = true
 			lowStock = append(lowStock, *p)
@@ -61,11 +82,15 @@ func (h *StockSummary) Handle(ctx context.Context, req *StockSummaryRequest) (*S
 	}
 
 	return &StockSummaryResult{
-		Products:          products,
-		TotalStockOnHand:  totalStock,
-		TotalCostValue:    totalCost,
-		TotalSellingValue: totalSelling,
-		LowStock:          lowStock,
-		LowStockCount:     len(lowStock),
+		AsOf:                    req.AsOf,
+		Products:                products,
+		Warehouses:              warehouses,
+		TotalStockOnHand:        totalStock,
+		TotalCostValue:          totalCost,
+		TotalSellingValue:       totalSelling,
+		TotalInTransit:          totalInTransit,
+		TotalInTransitCostValue: totalInTransitCost,
+		LowStock:                lowStock,
+		LowStockCount:           len(lowStock),
 	}, nil
 }
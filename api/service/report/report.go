@@ -16,6 +16,8 @@ func NewService(logger *slog.Logger, db *gorm.DB, reportRepo repository.Report)
 	getStockSummaryHandler := query.NewStockSummary(logger, db, reportRepo)
 	getStockMovementsHandler := query.NewStockMovements(logger, db, reportRepo)
 	getPurchaseSummaryHandler := query.NewPurchaseSummary(logger, db, reportRepo)
+	getExpiringLotsHandler := query.NewExpiringLots(logger, db, reportRepo)
+	getInventoryValuationHandler := query.NewInventoryValuation(logger, db, reportRepo)
 
 	err := mediatr.RegisterRequestHandler(getStockSummaryHandler)
 	if err != nil {
@@ -32,10 +34,21 @@ func NewService(logger *slog.Logger, db *gorm.DB, reportRepo repository.Report)
 		return err
 	}
 
+	err = mediatr.RegisterRequestHandler(getExpiringLotsHandler)
+	if err != nil {
+		return err
+	}
+
+	err = mediatr.RegisterRequestHandler(getInventoryValuationHandler)
+	if err != nil {
+		return err
+	}
+
 	// Register export handlers
 	exportStockSummaryCSVHandler := command.NewExportStockSummaryCSV(logger, db, reportRepo)
 	exportStockMovementExcelHandler := command.NewExportStockMovementExcel(logger, db, reportRepo)
 	exportPurchaseReportExcelHandler := command.NewExportPurchaseReportExcel(logger, db, reportRepo)
+	exportExpiringLotsExcelHandler := command.NewExportExpiringLotsExcel(logger, db, reportRepo)
 
 	err = mediatr.RegisterRequestHandler(exportStockSummaryCSVHandler)
 	if err != nil {
@@ -52,6 +65,11 @@ func NewService(logger *slog.Logger, db *gorm.DB, reportRepo repository.Report)
 		return err
 	}
 
+	err = mediatr.RegisterRequestHandler(exportExpiringLotsExcelHandler)
+	if err != nil {
+		return err
+	}
+
 	logger.Info("Report handlers registered successfully")
 	return nil
 }
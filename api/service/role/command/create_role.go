@@ -0,0 +1,90 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type CreateRole struct {
+	logger   *slog.Logger
+	db       *gorm.DB
+	roleRepo repository.Role
+}
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type CreateRoleResult struct {
+	Role model.RoleDefinition `json:"role"`
+}
+
+func NewCreateRole(logger *slog.Logger, db *gorm.DB, roleRepo repository.Role) *CreateRole {
+	return &CreateRole{
+		logger:   logger,
+		db:       db,
+		roleRepo: roleRepo,
+	}
+}
+
+func (c *CreateRole) Handle(ctx context.Context, request CreateRoleRequest) (*CreateRoleResult, error) {
+	name, err := normalizeRoleName(request.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := parsePermissions(request.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.roleRepo.SearchByName(c.db, name); err == nil {
+		return nil, ErrRoleExists
+	} else if !errors.Is(err, repository.ErrRoleNotFound) {
+		return nil, err
+	}
+
+	role := &model.RoleDefinition{
+		Name:        name,
+		Description: strings.TrimSpace(request.Description),
+	}
+
+	tx := c.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := c.roleRepo.Create(tx, role); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := c.roleRepo.ReplacePermissions(tx, name, permissions); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.logger.Error("Failed to commit role creation", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	created, err := c.roleRepo.SearchByName(c.db, name)
+	if err != nil {
+		return nil, err
+	}
+
+	c.logger.Info("Role created", "role", name, "permissions", len(permissions))
+
+	return &CreateRoleResult{Role: *created}, nil
+}
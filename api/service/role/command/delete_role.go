@@ -0,0 +1,80 @@
+package command
+
+import (
+	"context"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type DeleteRole struct {
+	logger   *slog.Logger
+	db       *gorm.DB
+	roleRepo repository.Role
+	userRepo repository.User
+}
+
+type DeleteRoleRequest struct {
+	Name string `json:"name"`
+}
+
+type DeleteRoleResult struct {
+	Message string `json:"message"`
+}
+
+func NewDeleteRole(logger *slog.Logger, db *gorm.DB, roleRepo repository.Role, userRepo repository.User) *DeleteRole {
+	return &DeleteRole{
+		logger:   logger,
+		db:       db,
+		roleRepo: roleRepo,
+		userRepo: userRepo,
+	}
+}
+
+func (d *DeleteRole) Handle(ctx context.Context, request DeleteRoleRequest) (*DeleteRoleResult, error) {
+	name := model.Role(strings.ToLower(strings.TrimSpace(request.Name)))
+
+	role, err := d.roleRepo.SearchByName(d.db, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if role.System {
+		return nil, ErrSystemRole
+	}
+
+	tx := d.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	userIds, err := d.userRepo.UserIdsByRole(tx, name)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if len(userIds) > 0 {
+		tx.Rollback()
+		return nil, ErrRoleInUse
+	}
+
+	if err := d.roleRepo.Delete(tx, name); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		d.logger.Error("Failed to commit role deletion", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	d.logger.Info("Role deleted", "role", name)
+
+	return &DeleteRoleResult{Message: "role deleted"}, nil
+}
@@ -0,0 +1,48 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"mini-erp-backend/model"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrInvalidRoleName   = errors.New("invalid role name, use lowercase letters, digits and underscores")
+	ErrRoleExists        = errors.New("role already exists")
+	ErrUnknownPermission = errors.New("unknown permission")
+	// ErrSystemRole role ที่ seed ไว้ (admin, staff, viewer) แก้สิทธิ์ได้แต่ลบไม่ได้
+	ErrSystemRole = errors.New("built-in roles cannot be deleted")
+	ErrRoleInUse  = errors.New("role is still assigned to users")
+	// ErrRoleLockout กันไม่ให้ admin ถูกถอดสิทธิ์จัดการ role จนไม่มีใครแก้กลับได้
+	ErrRoleLockout = errors.New("the admin role must keep the role.manage permission")
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+
+func normalizeRoleName(name string) (model.Role, error) {
+	role := strings.ToLower(strings.TrimSpace(name))
+	if !roleNamePattern.MatchString(role) {
+		return "", ErrInvalidRoleName
+	}
+	return model.Role(role), nil
+}
+
+// parsePermissions validates the permission names and drops duplicates
+func parsePermissions(names []string) ([]model.Permission, error) {
+	seen := make(map[model.Permission]struct{}, len(names))
+	permissions := make([]model.Permission, 0, len(names))
+	for _, name := range names {
+		p := model.Permission(strings.ToLower(strings.TrimSpace(name)))
+		if !model.IsPermission(p) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownPermission, name)
+		}
+		if _, ok := seen[p]; ok {
+			continue
+		}
+		seen[p] = struct{}{}
+		permissions = append(permissions, p)
+	}
+	return permissions, nil
+}
@@ -0,0 +1,143 @@
+package command
+
+import (
+	"context"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/lib/cache"
+	"mini-erp-backend/model"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type UpdateRole struct {
+	logger       *slog.Logger
+	db           *gorm.DB
+	roleRepo     repository.Role
+	userRepo     repository.User
+	sessionRepo  repository.UserSession
+	sessionCache *cache.Cache[string, model.UserSession]
+}
+
+type UpdateRoleRequest struct {
+	Name        string   `json:"-"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type UpdateRoleResult struct {
+	Role            model.RoleDefinition `json:"role"`
+	RevokedSessions int64                `json:"revoked_sessions"`
+}
+
+func NewUpdateRole(
+	logger *slog.Logger,
+	db *gorm.DB,
+	roleRepo repository.Role,
+	userRepo repository.User,
+	sessionRepo repository.UserSession,
+	sessionCache *cache.Cache[string, model.UserSession],
+) *UpdateRole {
+	return &UpdateRole{
+		logger:       logger,
+		db:           db,
+		roleRepo:     roleRepo,
+		userRepo:     userRepo,
+		sessionRepo:  sessionRepo,
+		sessionCache: sessionCache,
+	}
+}
+
+func (u *UpdateRole) Handle(ctx context.Context, request UpdateRoleRequest) (*UpdateRoleResult, error) {
+	name := model.Role(strings.ToLower(strings.TrimSpace(request.Name)))
+
+	permissions, err := parsePermissions(request.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := u.roleRepo.SearchByName(u.db, name)
+	if err != nil {
+		return nil, err
+	}
+
+	granted := make(map[model.Permission]struct{}, len(permissions))
+	for _, p := range permissions {
+		granted[p] = struct{}{}
+	}
+
+	if name == model.RoleAdmin {
+		if _, ok := granted[model.PermissionRoleManage]; !ok {
+			return nil, ErrRoleLockout
+		}
+	}
+
+	// access token เก็บสิทธิ์ไว้ใน claims การถอดสิทธิ์จึงต้องยกเลิก session ของผู้ใช้ใน role นี้
+	removed := false
+	for _, p := range current.Permissions {
+		if _, ok := granted[p]; !ok {
+			removed = true
+			break
+		}
+	}
+
+	tx := u.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := u.roleRepo.UpdateDescription(tx, name, strings.TrimSpace(request.Description)); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := u.roleRepo.ReplacePermissions(tx, name, permissions); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var userIds []uuid.UUID
+	var revoked int64
+	if removed {
+		userIds, err = u.userRepo.UserIdsByRole(tx, name)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		revoked, err = u.sessionRepo.RevokeByUserIds(tx, userIds)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		u.logger.Error("Failed to commit role update", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	if len(userIds) > 0 {
+		affected := make(map[uuid.UUID]struct{}, len(userIds))
+		for _, id := range userIds {
+			affected[id] = struct{}{}
+		}
+		u.sessionCache.DeleteFunc(func(_ string, s model.UserSession) bool {
+			_, ok := affected[s.UserId]
+			return ok
+		})
+	}
+
+	updated, err := u.roleRepo.SearchByName(u.db, name)
+	if err != nil {
+		return nil, err
+	}
+
+	u.logger.Info("Role updated", "role", name, "permissions", len(permissions), "revoked_sessions", revoked)
+
+	return &UpdateRoleResult{Role: *updated, RevokedSessions: revoked}, nil
+}
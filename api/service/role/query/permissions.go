@@ -0,0 +1,23 @@
+package query
+
+import (
+	"context"
+	"mini-erp-backend/model"
+)
+
+// Permissions lists every permission a role can be granted
+type Permissions struct{}
+
+type PermissionsRequest struct{}
+
+type PermissionsResult struct {
+	Permissions []model.Permission `json:"permissions"`
+}
+
+func NewPermissions() *Permissions {
+	return &Permissions{}
+}
+
+func (p *Permissions) Handle(ctx context.Context, request PermissionsRequest) (*PermissionsResult, error) {
+	return &PermissionsResult{Permissions: model.AllPermissions}, nil
+}
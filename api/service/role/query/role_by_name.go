@@ -0,0 +1,42 @@
+package query
+
+import (
+	"context"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type RoleByName struct {
+	logger   *slog.Logger
+	db       *gorm.DB
+	roleRepo repository.Role
+}
+
+type RoleByNameRequest struct {
+	Name string `json:"name"`
+}
+
+type RoleByNameResult struct {
+	Role model.RoleDefinition `json:"role"`
+}
+
+func NewRoleByName(logger *slog.Logger, db *gorm.DB, roleRepo repository.Role) *RoleByName {
+	return &RoleByName{
+		logger:   logger,
+		db:       db,
+		roleRepo: roleRepo,
+	}
+}
+
+func (r *RoleByName) Handle(ctx context.Context, request RoleByNameRequest) (*RoleByNameResult, error) {
+	role, err := r.roleRepo.SearchByName(r.db, model.Role(strings.ToLower(strings.TrimSpace(request.Name))))
+	if err != nil {
+		return nil, err
+	}
+
+	return &RoleByNameResult{Role: *role}, nil
+}
@@ -0,0 +1,40 @@
+package query
+
+import (
+	"context"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type Roles struct {
+	logger   *slog.Logger
+	db       *gorm.DB
+	roleRepo repository.Role
+}
+
+type RolesRequest struct{}
+
+type RolesResult struct {
+	Roles []model.RoleDefinition `json:"roles"`
+}
+
+func NewRoles(logger *slog.Logger, db *gorm.DB, roleRepo repository.Role) *Roles {
+	return &Roles{
+		logger:   logger,
+		db:       db,
+		roleRepo: roleRepo,
+	}
+}
+
+func (r *Roles) Handle(ctx context.Context, request RolesRequest) (*RolesResult, error) {
+	roles, err := r.roleRepo.SearchAll(r.db)
+	if err != nil {
+		r.logger.Error("Failed to get roles", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return &RolesResult{Roles: roles}, nil
+}
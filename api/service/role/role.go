@@ -0,0 +1,59 @@
+package role
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/api/service/role/command"
+	"mini-erp-backend/api/service/role/query"
+	"mini-erp-backend/lib/cache"
+	"mini-erp-backend/model"
+
+	"github.com/mehdihadeli/go-mediatr"
+	"gorm.io/gorm"
+)
+
+func NewService(
+	logger *slog.Logger,
+	db *gorm.DB,
+	roleRepo repository.Role,
+	userRepo repository.User,
+	sessionRepo repository.UserSession,
+	sessionCache *cache.Cache[string, model.UserSession],
+) {
+	rolesService := query.NewRoles(logger, db, roleRepo)
+	roleByNameService := query.NewRoleByName(logger, db, roleRepo)
+	permissionsService := query.NewPermissions()
+	createRoleService := command.NewCreateRole(logger, db, roleRepo)
+	updateRoleService := command.NewUpdateRole(logger, db, roleRepo, userRepo, sessionRepo, sessionCache)
+	deleteRoleService := command.NewDeleteRole(logger, db, roleRepo, userRepo)
+
+	err := mediatr.RegisterRequestHandler(rolesService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(roleByNameService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(permissionsService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(createRoleService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(updateRoleService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(deleteRoleService)
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,152 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type CreateSalesOrder struct {
+	logger       *slog.Logger
+	db           *gorm.DB
+	SORepo       repository.SalesOrder
+	ProductRepo  repository.Product
+	CustomerRepo repository.Customer
+}
+
+type CreateSalesOrderRequest struct {
+	CustomerId uuid.UUID              `json:"customer_id" validate:"required"`
+	CreatedBy  uuid.UUID              `json:"-"`
+	Items      []CreateSalesOrderItem `json:"items" validate:"required,min=1,dive"`
+}
+
+type CreateSalesOrderItem struct {
+	ProductId uuid.UUID `json:"product_id" validate:"required"`
+	Quantity  uint64    `json:"quantity" validate:"required,min=1"`
+}
+
+func NewCreateSalesOrder(
+	logger *slog.Logger,
+	db *gorm.DB,
+	soRepo repository.SalesOrder,
+	productRepo repository.Product,
+	customerRepo repository.Customer,
+) *CreateSalesOrder {
+	return &CreateSalesOrder{
+		logger:       logger,
+		db:           db,
+		SORepo:       soRepo,
+		ProductRepo:  productRepo,
+		CustomerRepo: customerRepo,
+	}
+}
+
+func (h *CreateSalesOrder) Handle(ctx context.Context, req *CreateSalesOrderRequest) (interface{}, error) {
+	if len(req.Items) == 0 {
+		return nil, errors.New("sales order must have at least one item")
+	}
+
+	// Begin transaction
+	tx := h.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if _, err := h.CustomerRepo.Search(tx, map[string]interface{}{
+		"customer_id": req.CustomerId,
+	}, ""); err != nil {
+		tx.Rollback()
+		h.logger.Error("Customer not found", "customer_id", req.CustomerId, "error", err)
+		return nil, errors.New("customer not found")
+	}
+
+	itemPrices, err := sellingPrices(tx, h.ProductRepo, productIdsOf(req.Items))
+	if err != nil {
+		tx.Rollback()
+		h.logger.Error("Product not found", "error", err)
+		return nil, err
+	}
+
+	// Create Sales Order
+	so := &model.SalesOrder{
+		SalesOrderId: uuid.New(),
+		CustomerId:   req.CustomerId,
+		Status:       model.SalesOrderDraft,
+		CreatedAt:    time.Now(),
+		CreatedBy:    req.CreatedBy,
+	}
+
+	if err := h.SORepo.Create(tx, so); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Create Sales Order Items
+	for _, it := range req.Items {
+		if it.Quantity == 0 {
+			tx.Rollback()
+			return nil, errors.New("quantity must be greater than 0")
+		}
+
+		item := &model.SalesOrderItem{
+			SalesOrderItemId: uuid.New(),
+			SalesOrderId:     so.SalesOrderId,
+			ProductId:        it.ProductId,
+			Quantity:         it.Quantity,
+			Price:            itemPrices[it.ProductId],
+		}
+		if err := h.SORepo.CreateItem(tx, item); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		so.SalesOrderItem = append(so.SalesOrderItem, *item)
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		h.logger.Error("Failed to commit transaction", "error", err)
+		return nil, err
+	}
+
+	h.logger.Info("Sales order created successfully", "so_id", so.SalesOrderId)
+	return so, nil
+}
+
+func productIdsOf(items []CreateSalesOrderItem) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(items))
+	for _, it := range items {
+		ids = append(ids, it.ProductId)
+	}
+	return ids
+}
+
+// sellingPrices snapshots the current selling price of every product on the order
+func sellingPrices(tx *gorm.DB, productRepo repository.Product, productIds []uuid.UUID) (map[uuid.UUID]decimal.Decimal, error) {
+	prices := make(map[uuid.UUID]decimal.Decimal, len(productIds))
+
+	for _, productId := range productIds {
+		if _, ok := prices[productId]; ok {
+			continue
+		}
+
+		product, err := productRepo.Search(tx, map[string]interface{}{
+			"product_id": productId,
+		}, "")
+		if err != nil {
+			return nil, errors.New("product not found")
+		}
+
+		prices[productId] = decimal.NewFromFloat(product.SellingPrice)
+	}
+
+	return prices, nil
+}
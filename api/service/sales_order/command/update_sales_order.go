@@ -0,0 +1,127 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type UpdateSalesOrder struct {
+	logger       *slog.Logger
+	db           *gorm.DB
+	SORepo       repository.SalesOrder
+	ProductRepo  repository.Product
+	CustomerRepo repository.Customer
+}
+
+type UpdateSalesOrderRequest struct {
+	SalesOrderId uuid.UUID
+	CustomerId   uuid.UUID              `json:"customer_id" validate:"required"`
+	Items        []CreateSalesOrderItem `json:"items" validate:"required,min=1,dive"`
+}
+
+func NewUpdateSalesOrder(
+	logger *slog.Logger,
+	db *gorm.DB,
+	soRepo repository.SalesOrder,
+	productRepo repository.Product,
+	customerRepo repository.Customer,
+) *UpdateSalesOrder {
+	return &UpdateSalesOrder{
+		logger:       logger,
+		db:           db,
+		SORepo:       soRepo,
+		ProductRepo:  productRepo,
+		CustomerRepo: customerRepo,
+	}
+}
+
+func (h *UpdateSalesOrder) Handle(ctx context.Context, req *UpdateSalesOrderRequest) (interface{}, error) {
+	if len(req.Items) == 0 {
+		return nil, errors.New("sales order must have at least one item")
+	}
+
+	// Begin transaction
+	tx := h.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	so, err := h.SORepo.LockById(tx, req.SalesOrderId)
+	if err != nil {
+		tx.Rollback()
+		return nil, errors.New("sales order not found")
+	}
+
+	// Only DRAFT can be updated
+	if so.Status != model.SalesOrderDraft {
+		tx.Rollback()
+		h.logger.Error("Cannot update sales order - invalid status", "status", so.Status)
+		return nil, fmt.Errorf("%w: can only update draft sales orders", ErrInvalidStatusTransition)
+	}
+
+	if _, err := h.CustomerRepo.Search(tx, map[string]interface{}{
+		"customer_id": req.CustomerId,
+	}, ""); err != nil {
+		tx.Rollback()
+		h.logger.Error("Customer not found", "customer_id", req.CustomerId, "error", err)
+		return nil, errors.New("customer not found")
+	}
+
+	itemPrices, err := sellingPrices(tx, h.ProductRepo, productIdsOf(req.Items))
+	if err != nil {
+		tx.Rollback()
+		h.logger.Error("Product not found", "error", err)
+		return nil, err
+	}
+
+	so.CustomerId = req.CustomerId
+
+	if err := h.SORepo.Update(tx, so); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Delete existing items and replace with new items
+	if err := h.SORepo.DeleteItemsBySalesOrderId(tx, so.SalesOrderId); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	for _, it := range req.Items {
+		if it.Quantity == 0 {
+			tx.Rollback()
+			return nil, errors.New("quantity must be greater than 0")
+		}
+
+		item := &model.SalesOrderItem{
+			SalesOrderItemId: uuid.New(),
+			SalesOrderId:     so.SalesOrderId,
+			ProductId:        it.ProductId,
+			Quantity:         it.Quantity,
+			Price:            itemPrices[it.ProductId],
+		}
+		if err := h.SORepo.CreateItem(tx, item); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		so.SalesOrderItem = append(so.SalesOrderItem, *item)
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		h.logger.Error("Failed to commit transaction", "error", err)
+		return nil, err
+	}
+
+	h.logger.Info("Sales order updated successfully", "so_id", req.SalesOrderId)
+	return so, nil
+}
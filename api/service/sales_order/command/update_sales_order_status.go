@@ -0,0 +1,242 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidStatusTransition is returned when a sales order cannot move from its current status to the requested one
+var ErrInvalidStatusTransition = errors.New("invalid sales order status transition")
+
+type UpdateSOStatus struct {
+	logger           *slog.Logger
+	db               *gorm.DB
+	SORepo           repository.SalesOrder
+	StockRepo        repository.StockTransaction
+	StockBalanceRepo repository.StockBalance
+	ProductRepo      repository.Product
+	WarehouseRepo    repository.Warehouse
+	LotRepo          repository.Lot
+	SerialRepo       repository.SerialNumber
+}
+
+type UpdateSOStatusRequest struct {
+	SalesOrderId uuid.UUID
+	Status       model.SalesOrderStatus `json:"status" validate:"required"`
+	WarehouseId  *uuid.UUID             `json:"warehouse_id"` // คลังที่ส่งของออก ไม่ระบุ = คลังหลัก
+	// Serials lists the units shipped per product_id, required for serial tracked products
+	Serials   map[uuid.UUID][]string `json:"serials"`
+	CreatedBy uuid.UUID              `json:"-"`
+}
+
+func NewUpdateSOStatus(
+	logger *slog.Logger,
+	db *gorm.DB,
+	soRepo repository.SalesOrder,
+	stockRepo repository.StockTransaction,
+	stockBalanceRepo repository.StockBalance,
+	productRepo repository.Product,
+	warehouseRepo repository.Warehouse,
+	lotRepo repository.Lot,
+	serialRepo repository.SerialNumber,
+) *UpdateSOStatus {
+	return &UpdateSOStatus{
+		logger:           logger,
+		db:               db,
+		SORepo:           soRepo,
+		StockRepo:        stockRepo,
+		StockBalanceRepo: stockBalanceRepo,
+		ProductRepo:      productRepo,
+		WarehouseRepo:    warehouseRepo,
+		LotRepo:          lotRepo,
+		SerialRepo:       serialRepo,
+	}
+}
+
+func (h *UpdateSOStatus) Handle(ctx context.Context, req *UpdateSOStatusRequest) (interface{}, error) {
+	// Begin transaction
+	tx := h.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// ล็อก order ไว้ก่อน กันการ ship ซ้ำพร้อมกันสองครั้ง
+	so, err := h.SORepo.LockById(tx, req.SalesOrderId)
+	if err != nil {
+		tx.Rollback()
+		return nil, errors.New("sales order not found")
+	}
+
+	if !so.Status.CanTransitionTo(req.Status) {
+		tx.Rollback()
+		h.logger.Error("Invalid sales order status transition", "so_id", so.SalesOrderId, "from", so.Status, "to", req.Status)
+		return nil, fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, so.Status, req.Status)
+	}
+
+	so.Status = req.Status
+
+	if req.Status == model.SalesOrderShipped {
+		if err := h.ship(tx, so, req.WarehouseId, req.Serials, req.CreatedBy); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		shippedAt := time.Now()
+		so.ShippedAt = &shippedAt
+	}
+
+	if err := h.SORepo.UpdateStatus(tx, so); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		h.logger.Error("Failed to commit transaction", "error", err)
+		return nil, err
+	}
+
+	h.logger.Info("Sales order status updated", "so_id", req.SalesOrderId, "status", req.Status)
+	return map[string]interface{}{
+		"sales_order_id": req.SalesOrderId,
+		"status":         req.Status,
+	}, nil
+}
+
+// ship checks that every product on the order has enough stock in the warehouse and writes OUT transactions per item, split over lots FEFO
+func (h *UpdateSOStatus) ship(tx *gorm.DB, so *model.SalesOrder, warehouseId *uuid.UUID, serials map[uuid.UUID][]string, createdBy uuid.UUID) error {
+	warehouse, err := h.WarehouseRepo.Resolve(tx, warehouseId, nil)
+	if err != nil {
+		return err
+	}
+
+	items, err := h.SORepo.SearchItemsBySalesOrderId(tx, so.SalesOrderId)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		h.logger.Error("Cannot ship sales order without items", "so_id", so.SalesOrderId)
+		return errors.New("cannot ship sales order without items")
+	}
+
+	// รวมจำนวนต่อสินค้า เผื่อมีสินค้าเดียวกันหลายบรรทัด
+	required := make(map[uuid.UUID]int64)
+	productIds := []uuid.UUID{}
+	for _, item := range items {
+		if _, ok := required[item.ProductId]; !ok {
+			productIds = append(productIds, item.ProductId)
+		}
+		required[item.ProductId] += int64(item.Quantity)
+	}
+
+	for productId := range serials {
+		if _, ok := required[productId]; !ok {
+			return fmt.Errorf("%w: product %s is not on the order", repository.ErrSerialNotTracked, productId)
+		}
+	}
+
+	// ล็อกตามลำดับ product_id เสมอ เพื่อไม่ให้เกิด deadlock กับ order อื่นที่มีสินค้าซ้ำกัน
+	sort.Slice(productIds, func(i, j int) bool {
+		return bytes.Compare(productIds[i][:], productIds[j][:]) < 0
+	})
+
+	products := make(map[uuid.UUID]*model.Product, len(productIds))
+	for _, productId := range productIds {
+		product, err := h.ProductRepo.Search(tx, map[string]interface{}{
+			"product_id": productId,
+		}, "")
+		if err != nil {
+			return errors.New("product not found")
+		}
+		products[productId] = product
+
+		if err := repository.CheckSerials(product, required[productId], serials[productId]); err != nil {
+			return err
+		}
+
+		balance, err := h.StockBalanceRepo.LockByProductId(tx, warehouse.WarehouseId, productId)
+		if err != nil {
+			return err
+		}
+
+		if !product.AllowBackorder && balance.Quantity < required[productId] {
+			h.logger.Error("Insufficient stock to ship sales order",
+				"so_id", so.SalesOrderId,
+				"warehouse_id", warehouse.WarehouseId,
+				"product_id", productId,
+				"available", balance.Quantity,
+				"requested", required[productId])
+			return fmt.Errorf("%w: product %s available %d, requested %d",
+				repository.ErrInsufficientStock, product.ProductCode, balance.Quantity, required[productId])
+		}
+	}
+
+	serialOffset := make(map[uuid.UUID]int, len(productIds))
+	for _, item := range items {
+		// ตัด stock ตาม lot ที่หมดอายุก่อน (FEFO) หนึ่งรายการต่อ lot ไม่ตัดจาก lot ที่หมดอายุแล้ว สินค้าที่มี serial ตัดตาม lot ของแต่ละ serial
+		var allocations []repository.LotAllocation
+		var err error
+		if products[item.ProductId].TrackSerial {
+			offset := serialOffset[item.ProductId]
+			serialOffset[item.ProductId] += int(item.Quantity)
+			allocations, err = h.SerialRepo.AllocateByLot(tx, warehouse.WarehouseId, item.ProductId, serials[item.ProductId][offset:offset+int(item.Quantity)])
+			if err == nil {
+				err = h.LotRepo.RejectExpired(tx, allocations)
+			}
+		} else {
+			allocations, err = h.LotRepo.AllocateFEFO(tx, warehouse.WarehouseId, item.ProductId, int64(item.Quantity))
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, allocation := range allocations {
+			stockTx := &model.StockTransaction{
+				StockTransactionId: uuid.New(),
+				ProductId:          item.ProductId,
+				WarehouseId:        warehouse.WarehouseId,
+				LotId:              allocation.LotId,
+				Quantity:           allocation.Quantity,
+				Type:               model.TransactionTypeOut,
+				Reason:             stringPtr("Sales Order Shipped"),
+				ReferenceId:        &so.SalesOrderId,
+				CreatedAt:          time.Now(),
+				CreatedBy:          createdBy.String(),
+			}
+
+			if err := h.StockRepo.Create(tx, stockTx); err != nil {
+				return err
+			}
+
+			if products[item.ProductId].TrackSerial {
+				if err := h.SerialRepo.Issue(tx, stockTx, allocation.Serials, model.SerialShipped); err != nil {
+					return err
+				}
+			}
+		}
+
+		h.logger.Info("Stock transaction created",
+			"product_id", item.ProductId,
+			"quantity", item.Quantity,
+			"lots", len(allocations),
+			"so_id", so.SalesOrderId)
+	}
+
+	return nil
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
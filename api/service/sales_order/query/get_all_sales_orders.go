@@ -0,0 +1,58 @@
+package query
+
+import (
+	"context"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AllSalesOrders struct {
+	logger *slog.Logger
+	db     *gorm.DB
+	SORepo repository.SalesOrder
+}
+
+type AllSalesOrdersRequest struct {
+	Status     *model.SalesOrderStatus `json:"status"`
+	CustomerId *uuid.UUID              `json:"customer_id"`
+}
+
+type AllSalesOrdersResult struct {
+	SalesOrders []*model.SalesOrder `json:"sales_orders"`
+}
+
+func NewAllSalesOrders(
+	logger *slog.Logger,
+	db *gorm.DB,
+	soRepo repository.SalesOrder,
+) *AllSalesOrders {
+	return &AllSalesOrders{
+		logger: logger,
+		db:     db,
+		SORepo: soRepo,
+	}
+}
+
+func (h *AllSalesOrders) Handle(ctx context.Context, req *AllSalesOrdersRequest) (*AllSalesOrdersResult, error) {
+	conditions := make(map[string]interface{})
+
+	if req.Status != nil {
+		conditions["status"] = *req.Status
+	}
+
+	if req.CustomerId != nil {
+		conditions["customer_id"] = *req.CustomerId
+	}
+
+	sos, err := h.SORepo.Searches(h.db, conditions, "created_at DESC")
+	if err != nil {
+		h.logger.Error("Failed to get all sales orders", "error", err)
+		return nil, err
+	}
+
+	return &AllSalesOrdersResult{SalesOrders: sos}, nil
+}
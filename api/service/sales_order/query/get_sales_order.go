@@ -0,0 +1,49 @@
+package query
+
+import (
+	"context"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SalesOrder struct {
+	logger *slog.Logger
+	db     *gorm.DB
+	SORepo repository.SalesOrder
+}
+
+type SalesOrderRequest struct {
+	SalesOrderId uuid.UUID `json:"sales_order_id" validate:"required"`
+}
+
+type SalesOrderResult struct {
+	SalesOrder *model.SalesOrder `json:"sales_order"`
+}
+
+func NewSalesOrder(
+	logger *slog.Logger,
+	db *gorm.DB,
+	soRepo repository.SalesOrder,
+) *SalesOrder {
+	return &SalesOrder{
+		logger: logger,
+		db:     db,
+		SORepo: soRepo,
+	}
+}
+
+func (h *SalesOrder) Handle(ctx context.Context, req *SalesOrderRequest) (*SalesOrderResult, error) {
+	so, err := h.SORepo.Search(h.db, map[string]interface{}{
+		"sales_order_id": req.SalesOrderId,
+	}, "")
+	if err != nil {
+		h.logger.Error("Failed to get sales order", "so_id", req.SalesOrderId, "error", err)
+		return nil, err
+	}
+
+	return &SalesOrderResult{SalesOrder: so}, nil
+}
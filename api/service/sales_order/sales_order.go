@@ -0,0 +1,60 @@
+package sales_order
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/api/service/sales_order/command"
+	"mini-erp-backend/api/service/sales_order/query"
+
+	"github.com/mehdihadeli/go-mediatr"
+	"gorm.io/gorm"
+)
+
+func NewService(
+	db *gorm.DB,
+	logger *slog.Logger,
+	soRepo repository.SalesOrder,
+	stockRepo repository.StockTransaction,
+	stockBalanceRepo repository.StockBalance,
+	productRepo repository.Product,
+	customerRepo repository.Customer,
+	warehouseRepo repository.Warehouse,
+	lotRepo repository.Lot,
+	serialRepo repository.SerialNumber,
+) error {
+	// Register command handlers
+	createSalesOrderHandler := command.NewCreateSalesOrder(logger, db, soRepo, productRepo, customerRepo)
+	updateSalesOrderHandler := command.NewUpdateSalesOrder(logger, db, soRepo, productRepo, customerRepo)
+	updateSOStatusHandler := command.NewUpdateSOStatus(logger, db, soRepo, stockRepo, stockBalanceRepo, productRepo, warehouseRepo, lotRepo, serialRepo)
+	getSalesOrderHandler := query.NewSalesOrder(logger, db, soRepo)
+	getAllSalesOrdersHandler := query.NewAllSalesOrders(logger, db, soRepo)
+
+	err := mediatr.RegisterRequestHandler(createSalesOrderHandler)
+	if err != nil {
+		return err
+	}
+
+	err = mediatr.RegisterRequestHandler(updateSalesOrderHandler)
+	if err != nil {
+		return err
+	}
+
+	err = mediatr.RegisterRequestHandler(updateSOStatusHandler)
+	if err != nil {
+		return err
+	}
+
+	// Register query handlers
+	err = mediatr.RegisterRequestHandler[*query.SalesOrderRequest, *query.SalesOrderResult](getSalesOrderHandler)
+	if err != nil {
+		return err
+	}
+
+	err = mediatr.RegisterRequestHandler[*query.AllSalesOrdersRequest, *query.AllSalesOrdersResult](getAllSalesOrdersHandler)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Sales Order handlers registered successfully")
+	return nil
+}
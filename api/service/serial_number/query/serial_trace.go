@@ -0,0 +1,109 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SerialTrace struct {
+	logger           *slog.Logger
+	db               *gorm.DB
+	serialRepo       repository.SerialNumber
+	goodsReceiptRepo repository.GoodsReceipt
+	salesOrderRepo   repository.SalesOrder
+}
+
+type SerialTraceRequest struct {
+	Serial string `json:"serial"`
+}
+
+// SerialTraceUnit is the history of one unit with where it came from and where it went
+type SerialTraceUnit struct {
+	SerialNumber    model.SerialNumber      `json:"serial_number"`
+	PurchaseOrderId *uuid.UUID              `json:"purchase_order_id"` // PO ที่รับหน่วยนี้เข้ามา
+	GoodsReceiptId  *uuid.UUID              `json:"goods_receipt_id"`
+	Outbound        *model.StockTransaction `json:"outbound"`       // รายการขาออกล่าสุด (ส่งของหรือตัดทิ้ง)
+	SalesOrderId    *uuid.UUID              `json:"sales_order_id"` // SO ที่ส่งหน่วยนี้ออกไป
+}
+
+type SerialTraceResult struct {
+	Units []SerialTraceUnit `json:"units"` // serial เดียวกันอาจมีได้หลายสินค้า
+}
+
+func NewSerialTrace(
+	logger *slog.Logger,
+	db *gorm.DB,
+	serialRepo repository.SerialNumber,
+	goodsReceiptRepo repository.GoodsReceipt,
+	salesOrderRepo repository.SalesOrder,
+) *SerialTrace {
+	return &SerialTrace{
+		logger:           logger,
+		db:               db,
+		serialRepo:       serialRepo,
+		goodsReceiptRepo: goodsReceiptRepo,
+		salesOrderRepo:   salesOrderRepo,
+	}
+}
+
+func (s *SerialTrace) Handle(ctx context.Context, request SerialTraceRequest) (*SerialTraceResult, error) {
+	serialNumbers, err := s.serialRepo.SearchBySerial(s.db, request.Serial)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("serial number not found")
+		}
+		return nil, err
+	}
+
+	result := &SerialTraceResult{Units: make([]SerialTraceUnit, 0, len(serialNumbers))}
+	for _, serialNumber := range serialNumbers {
+		unit := SerialTraceUnit{SerialNumber: serialNumber}
+
+		for _, movement := range serialNumber.Movements {
+			transaction := movement.StockTransaction
+			if transaction == nil {
+				continue
+			}
+
+			// ย้อนกลับ: รายการรับเข้าแรกที่มาจากใบรับสินค้าของ PO
+			if unit.PurchaseOrderId == nil && transaction.Type == model.TransactionTypeIn {
+				line, err := s.goodsReceiptRepo.SearchLineByStockTransactionId(s.db, transaction.StockTransactionId)
+				if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil, err
+				}
+				if line != nil {
+					unit.PurchaseOrderId = &line.GoodsReceipt.PurchaseOrderId
+					unit.GoodsReceiptId = &line.GoodsReceiptId
+				}
+			}
+
+			// ไปข้างหน้า: รายการล่าสุดที่นำหน่วยนี้ออกจาก stock
+			if movement.Status == model.SerialShipped || movement.Status == model.SerialScrapped {
+				unit.Outbound = transaction
+				unit.SalesOrderId = nil
+			}
+		}
+
+		if unit.Outbound != nil && unit.Outbound.ReferenceId != nil {
+			so, err := s.salesOrderRepo.Search(s.db, map[string]interface{}{
+				"sales_order_id": *unit.Outbound.ReferenceId,
+			}, "")
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, err
+			}
+			if so != nil {
+				unit.SalesOrderId = &so.SalesOrderId
+			}
+		}
+
+		result.Units = append(result.Units, unit)
+	}
+
+	return result, nil
+}
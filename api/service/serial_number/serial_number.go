@@ -0,0 +1,25 @@
+package serial_number
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/api/service/serial_number/query"
+
+	"github.com/mehdihadeli/go-mediatr"
+	"gorm.io/gorm"
+)
+
+func NewService(
+	logger *slog.Logger,
+	db *gorm.DB,
+	serialRepo repository.SerialNumber,
+	goodsReceiptRepo repository.GoodsReceipt,
+	salesOrderRepo repository.SalesOrder,
+) {
+	serialTraceService := query.NewSerialTrace(logger, db, serialRepo, goodsReceiptRepo, salesOrderRepo)
+
+	err := mediatr.RegisterRequestHandler(serialTraceService)
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,248 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ApproveStockCount struct {
+	logger               *slog.Logger
+	db                   *gorm.DB
+	stockCountRepo       repository.StockCount
+	stockTransactionRepo repository.StockTransaction
+	stockBalanceRepo     repository.StockBalance
+	lotRepo              repository.Lot
+	serialRepo           repository.SerialNumber
+}
+
+type ApproveStockCountRequest struct {
+	StockCountId uuid.UUID `json:"-"`
+	ApprovedBy   uuid.UUID `json:"-"`
+}
+
+type ApproveStockCountResult struct {
+	StockCount   model.StockCount         `json:"stock_count"`
+	Transactions []model.StockTransaction `json:"transactions"` // ADJUST ของสินค้าที่มีผลต่าง หนึ่งรายการต่อ lot
+	Message      string                   `json:"message"`
+}
+
+func NewApproveStockCount(logger *slog.Logger, db *gorm.DB, stockCountRepo repository.StockCount, stockTransactionRepo repository.StockTransaction, stockBalanceRepo repository.StockBalance, lotRepo repository.Lot, serialRepo repository.SerialNumber) *ApproveStockCount {
+	return &ApproveStockCount{
+		logger:               logger,
+		db:                   db,
+		stockCountRepo:       stockCountRepo,
+		stockTransactionRepo: stockTransactionRepo,
+		stockBalanceRepo:     stockBalanceRepo,
+		lotRepo:              lotRepo,
+		serialRepo:           serialRepo,
+	}
+}
+
+// Handle posts ADJUSTs of counted minus expected quantity for every counted line with a difference,
+// all in one DB transaction referencing the count. Lines that were never counted are left unchanged.
+// A shortage is taken from lots like a negative POST /stocks/adjust (expired lots first, one ADJUST per lot);
+// for serial tracked products the counted serials are compared with the units in stock instead,
+// missing units are scrapped from their lots and units found extra are received.
+func (a *ApproveStockCount) Handle(ctx context.Context, request ApproveStockCountRequest) (*ApproveStockCountResult, error) {
+	tx := a.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	locked, err := a.stockCountRepo.LockById(tx, request.StockCountId)
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrStockCountNotFound
+		}
+		return nil, err
+	}
+
+	if locked.Status != model.StockCountOpen {
+		tx.Rollback()
+		a.logger.Error("Stock count is not open", slog.String("status", string(locked.Status)))
+		return nil, repository.ErrStockCountNotOpen
+	}
+
+	stockCount, err := a.stockCountRepo.SearchWithLines(tx, locked.StockCountId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	now := time.Now()
+	reason := "Stock Count " + stockCount.StockCountId.String()
+	transactions := []model.StockTransaction{}
+	for i := range stockCount.Lines {
+		line := &stockCount.Lines[i]
+		variance := line.Variance()
+		// สินค้า serial อาจนับได้จำนวนตรงแต่เป็นคนละหน่วย จึงต้องเทียบ serial ทุกครั้ง
+		if variance == nil || (*variance == 0 && !line.Product.TrackSerial) {
+			continue
+		}
+
+		// ปรับลดห้ามทำให้ยอดติดลบ เช่นเดียวกับ POST /stocks/adjust (serial ที่หาไม่พบอยู่ใน stock จริงเสมอ)
+		if *variance < 0 && !line.Product.AllowBackorder && !line.Product.TrackSerial {
+			balance, err := a.stockBalanceRepo.LockByProductId(tx, stockCount.WarehouseId, line.ProductId)
+			if err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			if balance.Quantity+*variance < 0 {
+				tx.Rollback()
+				a.logger.Error("Insufficient stock", slog.String("product_id", line.ProductId.String()), slog.Int64("available", balance.Quantity), slog.Int64("adjust", *variance))
+				return nil, fmt.Errorf("%w: product %s available %d, requested %d", repository.ErrInsufficientStock, line.Product.ProductCode, balance.Quantity, -*variance)
+			}
+		}
+
+		allocations, err := a.allocate(tx, stockCount.WarehouseId, line)
+		if err != nil {
+			tx.Rollback()
+			a.logger.Error("Failed to allocate stock count variance", slog.String("product_id", line.ProductId.String()), slog.String("error", err.Error()))
+			return nil, err
+		}
+
+		for _, allocation := range allocations {
+			transaction := &model.StockTransaction{
+				StockTransactionId: uuid.New(),
+				ProductId:          line.ProductId,
+				WarehouseId:        stockCount.WarehouseId,
+				LotId:              allocation.LotId,
+				Type:               model.TransactionTypeAdjust,
+				Quantity:           allocation.Quantity,
+				Reason:             &reason,
+				ReferenceId:        &stockCount.StockCountId,
+				CreatedAt:          now,
+				CreatedBy:          request.ApprovedBy.String(),
+			}
+
+			if err := a.stockTransactionRepo.Create(tx, transaction); err != nil {
+				tx.Rollback()
+				a.logger.Error("Failed to create stock count adjustment", slog.String("error", err.Error()))
+				return nil, err
+			}
+
+			// นับได้เกิน = รับ serial เข้า stock, หาไม่พบ = ตัด serial ออกเป็น SCRAPPED
+			if line.Product.TrackSerial {
+				if allocation.Quantity > 0 {
+					err = a.serialRepo.Receive(tx, transaction, allocation.Serials)
+				} else {
+					err = a.serialRepo.Issue(tx, transaction, allocation.Serials, model.SerialScrapped)
+				}
+				if err != nil {
+					tx.Rollback()
+					a.logger.Error("Failed to adjust serial numbers", slog.String("error", err.Error()))
+					return nil, err
+				}
+			}
+
+			if line.StockTransactionId == nil {
+				if err := a.stockCountRepo.SetLineTransaction(tx, line.StockCountLineId, transaction.StockTransactionId); err != nil {
+					tx.Rollback()
+					return nil, err
+				}
+				line.StockTransactionId = &transaction.StockTransactionId
+			}
+			transactions = append(transactions, *transaction)
+		}
+	}
+
+	stockCount.Status = model.StockCountApproved
+	stockCount.ApprovedAt = &now
+	stockCount.ApprovedBy = &request.ApprovedBy
+	if err := a.stockCountRepo.MarkApproved(tx, stockCount); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		a.logger.Error("Failed to commit transaction", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return &ApproveStockCountResult{
+		StockCount:   *stockCount,
+		Transactions: transactions,
+		Message:      fmt.Sprintf("Stock count approved, %d adjustments posted", len(transactions)),
+	}, nil
+}
+
+// allocate splits the variance of a counted line into ADJUST quantities.
+// A surplus is one positive allocation without a lot, a shortage is taken from lots in FEFO order
+// (expired lots included, they are what a count usually writes off).
+// Serial tracked products compare the counted serials with the units in stock at the warehouse:
+// missing units are allocated by the lot they were received in, extra units are one positive allocation.
+func (a *ApproveStockCount) allocate(tx *gorm.DB, warehouseId uuid.UUID, line *model.StockCountLine) ([]repository.LotAllocation, error) {
+	if line.Product.TrackSerial {
+		// นับไว้ก่อนที่ใบนับจะเก็บ serial ต้องส่งจำนวนนับใหม่พร้อม serial
+		if err := repository.CheckSerials(line.Product, *line.CountedQuantity, line.CountedSerials); err != nil {
+			return nil, err
+		}
+
+		inStock, err := a.serialRepo.InStock(tx, warehouseId, line.ProductId)
+		if err != nil {
+			return nil, err
+		}
+
+		counted := make(map[string]struct{}, len(line.CountedSerials))
+		for _, serial := range line.CountedSerials {
+			counted[serial] = struct{}{}
+		}
+		stocked := make(map[string]struct{}, len(inStock))
+		missing := []string{}
+		for _, serial := range inStock {
+			stocked[serial] = struct{}{}
+			if _, ok := counted[serial]; !ok {
+				missing = append(missing, serial)
+			}
+		}
+		extra := []string{}
+		for _, serial := range line.CountedSerials {
+			if _, ok := stocked[serial]; !ok {
+				extra = append(extra, serial)
+			}
+		}
+
+		allocations := []repository.LotAllocation{}
+		if len(missing) > 0 {
+			allocations, err = a.serialRepo.AllocateByLot(tx, warehouseId, line.ProductId, missing)
+			if err != nil {
+				return nil, err
+			}
+			for i := range allocations {
+				allocations[i].Quantity = -allocations[i].Quantity
+			}
+		}
+		if len(extra) > 0 {
+			allocations = append(allocations, repository.LotAllocation{Quantity: int64(len(extra)), Serials: extra})
+		}
+		return allocations, nil
+	}
+
+	variance := *line.Variance()
+	if variance == 0 {
+		return nil, nil
+	}
+	if variance > 0 {
+		return []repository.LotAllocation{{Quantity: variance}}, nil
+	}
+
+	allocations, err := a.lotRepo.AllocateFEFOWithExpired(tx, warehouseId, line.ProductId, -variance)
+	if err != nil {
+		return nil, err
+	}
+	for i := range allocations {
+		allocations[i].Quantity = -allocations[i].Quantity
+	}
+	return allocations, nil
+}
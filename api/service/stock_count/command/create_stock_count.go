@@ -0,0 +1,93 @@
+package command
+
+import (
+	"context"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CreateStockCount struct {
+	logger         *slog.Logger
+	db             *gorm.DB
+	stockCountRepo repository.StockCount
+	warehouseRepo  repository.Warehouse
+}
+
+type CreateStockCountRequest struct {
+	WarehouseId *uuid.UUID `json:"warehouse_id"` // ไม่ระบุ = คลังหลัก
+	CategoryId  *uuid.UUID `json:"category_id"`  // ไม่ระบุ = ทุกสินค้าในคลัง
+	Note        *string    `json:"note"`
+	CreatedBy   uuid.UUID  `json:"-"`
+}
+
+type CreateStockCountResult struct {
+	StockCount model.StockCount `json:"stock_count"`
+}
+
+func NewCreateStockCount(logger *slog.Logger, db *gorm.DB, stockCountRepo repository.StockCount, warehouseRepo repository.Warehouse) *CreateStockCount {
+	return &CreateStockCount{
+		logger:         logger,
+		db:             db,
+		stockCountRepo: stockCountRepo,
+		warehouseRepo:  warehouseRepo,
+	}
+}
+
+func (c *CreateStockCount) Handle(ctx context.Context, request CreateStockCountRequest) (*CreateStockCountResult, error) {
+	tx := c.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	warehouse, err := c.warehouseRepo.Resolve(tx, request.WarehouseId, nil)
+	if err != nil {
+		tx.Rollback()
+		c.logger.Error("Failed to resolve warehouse", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	// ยอดที่ควรมีถูก freeze ณ ตอนสร้างใบนับ การเคลื่อนไหวหลังจากนี้ไม่กระทบ variance
+	lines, err := c.stockCountRepo.ExpectedLines(tx, warehouse.WarehouseId, request.CategoryId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if len(lines) == 0 {
+		tx.Rollback()
+		c.logger.Error("No products to count", slog.String("warehouse_id", warehouse.WarehouseId.String()))
+		return nil, repository.ErrStockCountNoProducts
+	}
+
+	stockCount := &model.StockCount{
+		StockCountId: uuid.New(),
+		WarehouseId:  warehouse.WarehouseId,
+		CategoryId:   request.CategoryId,
+		Status:       model.StockCountOpen,
+		Note:         request.Note,
+		CreatedAt:    time.Now(),
+		CreatedBy:    request.CreatedBy,
+		Lines:        lines,
+	}
+
+	if err := c.stockCountRepo.Create(tx, stockCount); err != nil {
+		tx.Rollback()
+		c.logger.Error("Failed to create stock count", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.logger.Error("Failed to commit transaction", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return &CreateStockCountResult{
+		StockCount: *stockCount,
+	}, nil
+}
@@ -0,0 +1,132 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrProductNotInCount is returned when a submitted product is not one of the lines of the count
+var ErrProductNotInCount = errors.New("product is not part of this stock count")
+
+type SubmitStockCount struct {
+	logger         *slog.Logger
+	db             *gorm.DB
+	stockCountRepo repository.StockCount
+}
+
+type SubmitStockCountLine struct {
+	ProductId       uuid.UUID `json:"product_id"`
+	CountedQuantity int64     `json:"counted_quantity"`
+	Serials         []string  `json:"serials"` // บังคับสำหรับสินค้าที่ track serial: serial ที่นับพบ หนึ่งรายการต่อหน่วย
+}
+
+type SubmitStockCountRequest struct {
+	StockCountId uuid.UUID              `json:"-"`
+	Lines        []SubmitStockCountLine `json:"lines"` // ส่งได้หลายครั้ง จำนวนล่าสุดของแต่ละสินค้าจะแทนที่ของเดิม
+	CountedBy    uuid.UUID              `json:"-"`
+}
+
+type SubmitStockCountResult struct {
+	StockCountId uuid.UUID `json:"stock_count_id"`
+	Updated      int       `json:"updated"`
+	Message      string    `json:"message"`
+}
+
+func NewSubmitStockCount(logger *slog.Logger, db *gorm.DB, stockCountRepo repository.StockCount) *SubmitStockCount {
+	return &SubmitStockCount{
+		logger:         logger,
+		db:             db,
+		stockCountRepo: stockCountRepo,
+	}
+}
+
+func (s *SubmitStockCount) Handle(ctx context.Context, request SubmitStockCountRequest) (*SubmitStockCountResult, error) {
+	if len(request.Lines) == 0 {
+		s.logger.Error("No counted lines submitted")
+		return nil, errors.New("at least one counted line is required")
+	}
+	for _, line := range request.Lines {
+		if line.CountedQuantity < 0 {
+			s.logger.Error("Counted quantity cannot be negative", slog.Int64("counted_quantity", line.CountedQuantity))
+			return nil, errors.New("counted quantity cannot be negative")
+		}
+	}
+
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	stockCount, err := s.stockCountRepo.LockById(tx, request.StockCountId)
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrStockCountNotFound
+		}
+		return nil, err
+	}
+
+	if stockCount.Status != model.StockCountOpen {
+		tx.Rollback()
+		s.logger.Error("Stock count is not open", slog.String("status", string(stockCount.Status)))
+		return nil, repository.ErrStockCountNotOpen
+	}
+
+	withLines, err := s.stockCountRepo.SearchWithLines(tx, stockCount.StockCountId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	products := make(map[uuid.UUID]*model.Product, len(withLines.Lines))
+	for _, line := range withLines.Lines {
+		products[line.ProductId] = line.Product
+	}
+
+	countedAt := time.Now()
+	for _, line := range request.Lines {
+		product, ok := products[line.ProductId]
+		if !ok {
+			tx.Rollback()
+			s.logger.Error("Product not in stock count", slog.String("product_id", line.ProductId.String()))
+			return nil, fmt.Errorf("%w: %s", ErrProductNotInCount, line.ProductId)
+		}
+
+		if err := repository.CheckSerials(product, line.CountedQuantity, line.Serials); err != nil {
+			tx.Rollback()
+			s.logger.Error("Invalid counted serial numbers", slog.String("error", err.Error()))
+			return nil, err
+		}
+
+		found, err := s.stockCountRepo.UpdateCounted(tx, stockCount.StockCountId, line.ProductId, line.CountedQuantity, line.Serials, request.CountedBy, countedAt)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if !found {
+			tx.Rollback()
+			s.logger.Error("Product not in stock count", slog.String("product_id", line.ProductId.String()))
+			return nil, fmt.Errorf("%w: %s", ErrProductNotInCount, line.ProductId)
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		s.logger.Error("Failed to commit transaction", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return &SubmitStockCountResult{
+		StockCountId: stockCount.StockCountId,
+		Updated:      len(request.Lines),
+		Message:      "Counted quantities saved",
+	}, nil
+}
@@ -0,0 +1,87 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type StockCountById struct {
+	logger         *slog.Logger
+	db             *gorm.DB
+	stockCountRepo repository.StockCount
+}
+
+type StockCountByIdRequest struct {
+	StockCountId uuid.UUID `json:"stock_count_id"`
+}
+
+type StockCountVariance struct {
+	ProductId        uuid.UUID `json:"product_id"`
+	ProductCode      string    `json:"product_code"`
+	ProductName      string    `json:"product_name"`
+	ExpectedQuantity int64     `json:"expected_quantity"`
+	CountedQuantity  int64     `json:"counted_quantity"`
+	Variance         int64     `json:"variance"` // นับได้ - ที่ควรมี
+}
+
+type StockCountByIdResult struct {
+	StockCount     model.StockCount     `json:"stock_count"`
+	Variances      []StockCountVariance `json:"variances"` // เฉพาะสินค้าที่นับแล้วและมีผลต่าง
+	CountedLines   int                  `json:"counted_lines"`
+	UncountedLines int                  `json:"uncounted_lines"`
+}
+
+func NewStockCountById(logger *slog.Logger, db *gorm.DB, stockCountRepo repository.StockCount) *StockCountById {
+	return &StockCountById{
+		logger:         logger,
+		db:             db,
+		stockCountRepo: stockCountRepo,
+	}
+}
+
+func (s *StockCountById) Handle(ctx context.Context, request StockCountByIdRequest) (*StockCountByIdResult, error) {
+	stockCount, err := s.stockCountRepo.SearchWithLines(s.db, request.StockCountId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrStockCountNotFound
+		}
+		s.logger.Error("Failed to get stock count by id", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	result := &StockCountByIdResult{
+		StockCount: *stockCount,
+		Variances:  []StockCountVariance{},
+	}
+	for _, line := range stockCount.Lines {
+		variance := line.Variance()
+		if variance == nil {
+			result.UncountedLines++
+			continue
+		}
+		result.CountedLines++
+
+		if *variance == 0 {
+			continue
+		}
+		item := StockCountVariance{
+			ProductId:        line.ProductId,
+			ExpectedQuantity: line.ExpectedQuantity,
+			CountedQuantity:  *line.CountedQuantity,
+			Variance:         *variance,
+		}
+		if line.Product != nil {
+			item.ProductCode = line.Product.ProductCode
+			item.ProductName = line.Product.Name
+		}
+		result.Variances = append(result.Variances, item)
+	}
+
+	return result, nil
+}
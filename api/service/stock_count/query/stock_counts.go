@@ -0,0 +1,80 @@
+package query
+
+import (
+	"context"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type StockCounts struct {
+	logger         *slog.Logger
+	db             *gorm.DB
+	stockCountRepo repository.StockCount
+}
+
+type StockCountsRequest struct {
+	Page        int                     `json:"page"`
+	PageSize    int                     `json:"page_size"`
+	Status      *model.StockCountStatus `json:"status"`
+	WarehouseId *uuid.UUID              `json:"warehouse_id"`
+}
+
+type StockCountsResult struct {
+	StockCounts []model.StockCount `json:"stock_counts"`
+	Total       int64              `json:"total"`
+	Page        int                `json:"page"`
+	PageSize    int                `json:"page_size"`
+	TotalPages  int                `json:"total_pages"`
+}
+
+func NewStockCounts(logger *slog.Logger, db *gorm.DB, stockCountRepo repository.StockCount) *StockCounts {
+	return &StockCounts{
+		logger:         logger,
+		db:             db,
+		stockCountRepo: stockCountRepo,
+	}
+}
+
+func (s *StockCounts) Handle(ctx context.Context, request StockCountsRequest) (*StockCountsResult, error) {
+	filters := repository.StockCountSearchFilters{
+		Status:      request.Status,
+		WarehouseId: request.WarehouseId,
+	}
+	orderBy := "created_at DESC"
+
+	if request.Page <= 0 || request.PageSize <= 0 {
+		counts, err := s.stockCountRepo.SearchWithFilters(s.db, filters, orderBy)
+		if err != nil {
+			s.logger.Error("Failed to get stock counts", slog.String("error", err.Error()))
+			return nil, err
+		}
+
+		return &StockCountsResult{
+			StockCounts: counts,
+			Total:       int64(len(counts)),
+			Page:        1,
+			PageSize:    len(counts),
+			TotalPages:  1,
+		}, nil
+	}
+
+	counts, total, err := s.stockCountRepo.SearchWithFiltersAndPagination(s.db, filters, orderBy, request.Page, request.PageSize)
+	if err != nil {
+		s.logger.Error("Failed to search stock counts with filters and pagination", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	totalPages := int((total + int64(request.PageSize) - 1) / int64(request.PageSize))
+
+	return &StockCountsResult{
+		StockCounts: counts,
+		Total:       total,
+		Page:        request.Page,
+		PageSize:    request.PageSize,
+		TotalPages:  totalPages,
+	}, nil
+}
@@ -0,0 +1,53 @@
+package stock_count
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/api/service/stock_count/command"
+	"mini-erp-backend/api/service/stock_count/query"
+
+	"github.com/mehdihadeli/go-mediatr"
+	"gorm.io/gorm"
+)
+
+func NewService(
+	logger *slog.Logger,
+	db *gorm.DB,
+	stockCountRepo repository.StockCount,
+	stockTransactionRepo repository.StockTransaction,
+	stockBalanceRepo repository.StockBalance,
+	warehouseRepo repository.Warehouse,
+	lotRepo repository.Lot,
+	serialRepo repository.SerialNumber,
+) {
+	stockCountsService := query.NewStockCounts(logger, db, stockCountRepo)
+	stockCountByIdService := query.NewStockCountById(logger, db, stockCountRepo)
+	createStockCountService := command.NewCreateStockCount(logger, db, stockCountRepo, warehouseRepo)
+	submitStockCountService := command.NewSubmitStockCount(logger, db, stockCountRepo)
+	approveStockCountService := command.NewApproveStockCount(logger, db, stockCountRepo, stockTransactionRepo, stockBalanceRepo, lotRepo, serialRepo)
+
+	err := mediatr.RegisterRequestHandler(stockCountsService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(stockCountByIdService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(createStockCountService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(submitStockCountService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(approveStockCountService)
+	if err != nil {
+		panic(err)
+	}
+}
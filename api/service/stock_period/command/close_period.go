@@ -0,0 +1,100 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ClosePeriod struct {
+	logger          *slog.Logger
+	db              *gorm.DB
+	stockPeriodRepo repository.StockPeriod
+	// location เขตเวลาที่ใช้ตัดเดือนของงวด ตรงกับ as_of ของรายงาน
+	location *time.Location
+}
+
+type ClosePeriodRequest struct {
+	Year     int       `json:"year"`
+	Month    int       `json:"month"` // 1-12
+	ClosedBy uuid.UUID `json:"-"`
+}
+
+type ClosePeriodResult struct {
+	Period  model.StockPeriod `json:"period"`
+	Message string            `json:"message"`
+}
+
+func NewClosePeriod(logger *slog.Logger, db *gorm.DB, stockPeriodRepo repository.StockPeriod, location *time.Location) *ClosePeriod {
+	return &ClosePeriod{
+		logger:          logger,
+		db:              db,
+		stockPeriodRepo: stockPeriodRepo,
+		location:        location,
+	}
+}
+
+func (c *ClosePeriod) Handle(ctx context.Context, request ClosePeriodRequest) (*ClosePeriodResult, error) {
+	if request.Month < 1 || request.Month > 12 {
+		c.logger.Error("Invalid month", slog.Int("month", request.Month))
+		return nil, errors.New("month must be between 1 and 12")
+	}
+
+	// งวดปิดได้เมื่อสิ้นเดือนแล้วเท่านั้น
+	firstDay := time.Date(request.Year, time.Month(request.Month), 1, 0, 0, 0, 0, c.location)
+	periodEnd := firstDay.AddDate(0, 1, 0).Add(-time.Second)
+	if !time.Now().After(periodEnd) {
+		c.logger.Error("Stock period has not ended", slog.Int("year", request.Year), slog.Int("month", request.Month))
+		return nil, repository.ErrPeriodNotEnded
+	}
+
+	tx := c.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	existed, err := c.stockPeriodRepo.ExitedByMonth(tx, request.Year, request.Month)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if existed {
+		tx.Rollback()
+		c.logger.Error("Stock period already closed", slog.Int("year", request.Year), slog.Int("month", request.Month))
+		return nil, repository.ErrPeriodAlreadyClosed
+	}
+
+	period := &model.StockPeriod{
+		StockPeriodId: uuid.New(),
+		Year:          request.Year,
+		Month:         request.Month,
+		PeriodEnd:     periodEnd,
+		ClosedAt:      time.Now(),
+		ClosedBy:      request.ClosedBy,
+	}
+
+	// snapshot ถูกเขียนครั้งเดียวตอนปิดงวดและไม่มีการแก้ไขภายหลัง
+	if err := c.stockPeriodRepo.Close(tx, period); err != nil {
+		tx.Rollback()
+		c.logger.Error("Failed to close stock period", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.logger.Error("Failed to commit transaction", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return &ClosePeriodResult{
+		Period:  *period,
+		Message: "Stock period closed",
+	}, nil
+}
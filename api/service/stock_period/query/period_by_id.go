@@ -0,0 +1,49 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PeriodById struct {
+	logger          *slog.Logger
+	db              *gorm.DB
+	stockPeriodRepo repository.StockPeriod
+}
+
+type PeriodByIdRequest struct {
+	StockPeriodId uuid.UUID `json:"stock_period_id"`
+}
+
+type PeriodByIdResult struct {
+	Period model.StockPeriod `json:"period"` // รวม snapshot ของทุกสินค้าในทุกคลัง
+}
+
+func NewPeriodById(logger *slog.Logger, db *gorm.DB, stockPeriodRepo repository.StockPeriod) *PeriodById {
+	return &PeriodById{
+		logger:          logger,
+		db:              db,
+		stockPeriodRepo: stockPeriodRepo,
+	}
+}
+
+func (p *PeriodById) Handle(ctx context.Context, request PeriodByIdRequest) (*PeriodByIdResult, error) {
+	period, err := p.stockPeriodRepo.SearchWithSnapshots(p.db, request.StockPeriodId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrPeriodNotFound
+		}
+		p.logger.Error("Failed to get stock period by id", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return &PeriodByIdResult{
+		Period: *period,
+	}, nil
+}
@@ -0,0 +1,44 @@
+package query
+
+import (
+	"context"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type Periods struct {
+	logger          *slog.Logger
+	db              *gorm.DB
+	stockPeriodRepo repository.StockPeriod
+}
+
+type PeriodsRequest struct{}
+
+type PeriodsResult struct {
+	Periods []model.StockPeriod `json:"periods"`
+	Total   int64               `json:"total"`
+}
+
+func NewPeriods(logger *slog.Logger, db *gorm.DB, stockPeriodRepo repository.StockPeriod) *Periods {
+	return &Periods{
+		logger:          logger,
+		db:              db,
+		stockPeriodRepo: stockPeriodRepo,
+	}
+}
+
+func (p *Periods) Handle(ctx context.Context, request PeriodsRequest) (*PeriodsResult, error) {
+	periods, err := p.stockPeriodRepo.SearchAll(p.db)
+	if err != nil {
+		p.logger.Error("Failed to get stock periods", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return &PeriodsResult{
+		Periods: periods,
+		Total:   int64(len(periods)),
+	}, nil
+}
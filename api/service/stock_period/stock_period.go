@@ -0,0 +1,33 @@
+package stock_period
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/api/service/stock_period/command"
+	"mini-erp-backend/api/service/stock_period/query"
+	"time"
+
+	"github.com/mehdihadeli/go-mediatr"
+	"gorm.io/gorm"
+)
+
+func NewService(logger *slog.Logger, db *gorm.DB, stockPeriodRepo repository.StockPeriod, location *time.Location) {
+	periodsService := query.NewPeriods(logger, db, stockPeriodRepo)
+	periodByIdService := query.NewPeriodById(logger, db, stockPeriodRepo)
+	closePeriodService := command.NewClosePeriod(logger, db, stockPeriodRepo, location)
+
+	err := mediatr.RegisterRequestHandler(periodsService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(periodByIdService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(closePeriodService)
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,63 @@
+package command
+
+import (
+	"context"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+
+	"gorm.io/gorm"
+)
+
+type RebuildStockBalance struct {
+	logger           *slog.Logger
+	db               *gorm.DB
+	stockBalanceRepo repository.StockBalance
+}
+
+type RebuildStockBalanceRequest struct{}
+
+type RebuildStockBalanceResult struct {
+	Products int64  `json:"products"`
+	Message  string `json:"message"`
+}
+
+func NewRebuildStockBalance(logger *slog.Logger, db *gorm.DB, stockBalanceRepo repository.StockBalance) *RebuildStockBalance {
+	return &RebuildStockBalance{
+		logger:           logger,
+		db:               db,
+		stockBalanceRepo: stockBalanceRepo,
+	}
+}
+
+func (s *RebuildStockBalance) Handle(ctx context.Context, request RebuildStockBalanceRequest) (*RebuildStockBalanceResult, error) {
+	// เริ่ม transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// คำนวณ stock_balances ใหม่ทั้งหมดจาก ledger
+	products, err := s.stockBalanceRepo.RebuildFromLedger(tx)
+	if err != nil {
+		tx.Rollback()
+		s.logger.Error("Failed to rebuild stock balances", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		s.logger.Error("Failed to commit transaction", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	s.logger.Info("Stock balances rebuilt from ledger", slog.Int64("products", products))
+
+	response := &RebuildStockBalanceResult{
+		Products: products,
+		Message:  "Stock balances rebuilt successfully",
+	}
+
+	return response, nil
+}
@@ -0,0 +1,121 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ReceiveStockTransfer struct {
+	logger               *slog.Logger
+	db                   *gorm.DB
+	stockTransactionRepo repository.StockTransaction
+	stockTransferRepo    repository.StockTransfer
+	warehouseRepo        repository.Warehouse
+	serialRepo           repository.SerialNumber
+}
+
+type ReceiveStockTransferRequest struct {
+	StockTransferId uuid.UUID  `json:"-"`
+	ToLocationId    *uuid.UUID `json:"to_location_id"` // ไม่ระบุ = ใช้ location ที่กำหนดตอนโอน
+	ReceivedBy      string     `json:"-"`
+}
+
+type ReceiveStockTransferResult struct {
+	Transfer     model.StockTransfer      `json:"transfer"`
+	Transactions []model.StockTransaction `json:"transactions"`
+	Message      string                   `json:"message"`
+}
+
+func NewReceiveStockTransfer(
+	logger *slog.Logger,
+	db *gorm.DB,
+	stockTransactionRepo repository.StockTransaction,
+	stockTransferRepo repository.StockTransfer,
+	warehouseRepo repository.Warehouse,
+	serialRepo repository.SerialNumber,
+) *ReceiveStockTransfer {
+	return &ReceiveStockTransfer{
+		logger:               logger,
+		db:                   db,
+		stockTransactionRepo: stockTransactionRepo,
+		stockTransferRepo:    stockTransferRepo,
+		warehouseRepo:        warehouseRepo,
+		serialRepo:           serialRepo,
+	}
+}
+
+func (s *ReceiveStockTransfer) Handle(ctx context.Context, request ReceiveStockTransferRequest) (*ReceiveStockTransferResult, error) {
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// ล็อกใบโอนไว้ กันการรับเข้าซ้ำจาก request ที่เข้ามาพร้อมกัน
+	transfer, err := s.stockTransferRepo.LockById(tx, request.StockTransferId)
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("stock transfer not found")
+		}
+		return nil, err
+	}
+
+	if transfer.Status != model.StockTransferInTransit {
+		tx.Rollback()
+		s.logger.Error("Stock transfer is not in transit", slog.String("status", string(transfer.Status)))
+		return nil, fmt.Errorf("%w: status is %s", ErrTransferNotInTransit, transfer.Status)
+	}
+
+	if request.ToLocationId != nil {
+		if _, err := s.warehouseRepo.Resolve(tx, &transfer.ToWarehouseId, request.ToLocationId); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		transfer.ToLocationId = request.ToLocationId
+	}
+
+	// รับเข้าตาม lot เดียวกับที่ตัดออกจากคลังต้นทาง
+	outs, err := s.stockTransactionRepo.TransactionsByReference(tx, transfer.StockTransferId, model.TransactionTypeOut)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	ins, err := transferIn(tx, s.stockTransactionRepo, s.serialRepo, transfer, outs, request.ReceivedBy)
+	if err != nil {
+		tx.Rollback()
+		s.logger.Error("Failed to create transfer in", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	receivedAt := time.Now()
+	transfer.Status = model.StockTransferReceived
+	transfer.ReceivedAt = &receivedAt
+	transfer.ReceivedBy = &request.ReceivedBy
+
+	if err := s.stockTransferRepo.MarkReceived(tx, transfer); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		s.logger.Error("Failed to commit transaction", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return &ReceiveStockTransferResult{
+		Transfer:     *transfer,
+		Transactions: ins,
+		Message:      "Stock transfer received",
+	}, nil
+}
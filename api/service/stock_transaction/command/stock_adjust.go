@@ -3,6 +3,7 @@ package command
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"mini-erp-backend/api/repository"
 	"mini-erp-backend/model"
@@ -16,28 +17,45 @@ type StockAdjust struct {
 	logger               *slog.Logger
 	db                   *gorm.DB
 	stockTransactionRepo repository.StockTransaction
+	stockBalanceRepo     repository.StockBalance
 	productRepo          repository.Product
+	warehouseRepo        repository.Warehouse
+	lotRepo              repository.Lot
+	serialRepo           repository.SerialNumber
+	unitRepo             repository.UnitOfMeasure
 }
 
 type StockAdjustRequest struct {
-	ProductId uuid.UUID `json:"product_id"`
-	Quantity  int64     `json:"quantity"`             // + เพิ่ม, - ลด
-	Reason    string    `json:"reason"`               // REQUIRED สำหรับ ADJUST
-	CreatedBy string    `json:"created_by,omitempty"` // ผู้ทำรายการ
+	ProductId   uuid.UUID  `json:"product_id"`
+	WarehouseId *uuid.UUID `json:"warehouse_id"` // ไม่ระบุ = คลังหลัก
+	LocationId  *uuid.UUID `json:"location_id"`
+	Quantity    int64      `json:"quantity"` // + เพิ่ม, - ลด
+	Unit        *string    `json:"unit"`     // ไม่ระบุ = หน่วยฐาน (Product.Unit)
+	Reason      string     `json:"reason"`   // REQUIRED สำหรับ ADJUST
+	Serials     []string   `json:"serials"`  // บังคับสำหรับสินค้าที่ track serial: + รับเข้า, - ตัดเป็น SCRAPPED
+	// TransactionDate back-dates the movement, nil = now. It cannot fall inside a closed period.
+	TransactionDate *time.Time `json:"transaction_date"`
+	CreatedBy       string     `json:"created_by,omitempty"` // ผู้ทำรายการ
 }
 
 type StockAdjustResult struct {
-	Transaction  model.StockTransaction `json:"transaction"`
-	CurrentStock int64                  `json:"current_stock"`
-	Message      string                 `json:"message"`
+	Transaction  model.StockTransaction   `json:"transaction"`  // รายการแรก
+	Transactions []model.StockTransaction `json:"transactions"` // ปรับลดได้หนึ่งรายการต่อ lot ที่ถูกตัด
+	CurrentStock int64                    `json:"current_stock"`
+	Message      string                   `json:"message"`
 }
 
-func NewStockAdjust(logger *slog.Logger, db *gorm.DB, stockTransactionRepo repository.StockTransaction, productRepo repository.Product) *StockAdjust {
+func NewStockAdjust(logger *slog.Logger, db *gorm.DB, stockTransactionRepo repository.StockTransaction, stockBalanceRepo repository.StockBalance, productRepo repository.Product, warehouseRepo repository.Warehouse, lotRepo repository.Lot, serialRepo repository.SerialNumber, unitRepo repository.UnitOfMeasure) *StockAdjust {
 	return &StockAdjust{
 		logger:               logger,
 		db:                   db,
 		stockTransactionRepo: stockTransactionRepo,
+		stockBalanceRepo:     stockBalanceRepo,
 		productRepo:          productRepo,
+		warehouseRepo:        warehouseRepo,
+		lotRepo:              lotRepo,
+		serialRepo:           serialRepo,
+		unitRepo:             unitRepo,
 	}
 }
 
@@ -53,6 +71,12 @@ func (s *StockAdjust) Handle(ctx context.Context, request StockAdjustRequest) (*
 		return nil, errors.New("quantity cannot be 0 for adjustment")
 	}
 
+	createdAt, err := transactionTime(request.TransactionDate)
+	if err != nil {
+		s.logger.Error("Invalid transaction date", slog.String("error", err.Error()))
+		return nil, err
+	}
+
 	// เริ่ม transaction
 	tx := s.db.Begin()
 	defer func() {
@@ -66,39 +90,121 @@ func (s *StockAdjust) Handle(ctx context.Context, request StockAdjustRequest) (*
 	}
 
 	// ดึงข้อมูล product
-	_, err := s.productRepo.Search(tx, condition, "")
+	product, err := s.productRepo.Search(tx, condition, "")
 	if err != nil {
 		tx.Rollback()
 		s.logger.Error("Product not found", slog.String("error", err.Error()))
 		return nil, errors.New("product not found")
 	}
 
-	// สร้าง transaction
-	transaction := &model.StockTransaction{
-		StockTransactionId: uuid.New(),
-		ProductId:          request.ProductId,
-		Type:               model.TransactionTypeAdjust,
-		Quantity:           request.Quantity,
-		Reason:             &request.Reason,
-		CreatedAt:          time.Now(),
-		CreatedBy:          request.CreatedBy,
+	// แปลงจำนวนจากหน่วยที่ระบุเป็นหน่วยฐานของสินค้า
+	factor, err := s.unitRepo.Factor(tx, product, request.Unit)
+	if err != nil {
+		tx.Rollback()
+		s.logger.Error("Failed to resolve unit", slog.String("error", err.Error()))
+		return nil, err
+	}
+	request.Quantity *= factor
+
+	units := request.Quantity
+	if units < 0 {
+		units = -units
+	}
+	if err := repository.CheckSerials(product, units, request.Serials); err != nil {
+		tx.Rollback()
+		s.logger.Error("Invalid serial numbers", slog.String("error", err.Error()))
+		return nil, err
 	}
 
-	// บันทึก stock adjust
-	if err := s.stockTransactionRepo.Create(tx, transaction); err != nil {
+	// หาคลังที่ทำรายการ ไม่ระบุจะใช้คลังหลัก
+	warehouse, err := s.warehouseRepo.Resolve(tx, request.WarehouseId, request.LocationId)
+	if err != nil {
 		tx.Rollback()
-		s.logger.Error("Failed to create stock adjust", slog.String("error", err.Error()))
+		s.logger.Error("Failed to resolve warehouse", slog.String("error", err.Error()))
 		return nil, err
 	}
 
-	// คำนวณ calculated stock (SUM(IN) - SUM(OUT) + SUM(ADJUST))
-	totalIn, totalOut, totalAdjust, err := s.stockTransactionRepo.StockSummary(tx, request.ProductId)
+	// ปรับลดต้องล็อกยอดคงเหลือ (SELECT ... FOR UPDATE) และห้ามติดลบ
+	if request.Quantity < 0 {
+		balance, err := s.stockBalanceRepo.LockByProductId(tx, warehouse.WarehouseId, request.ProductId)
+		if err != nil {
+			tx.Rollback()
+			s.logger.Error("Failed to lock stock balance", slog.String("error", err.Error()))
+			return nil, err
+		}
+
+		if !product.AllowBackorder && balance.Quantity+request.Quantity < 0 {
+			tx.Rollback()
+			s.logger.Error("Insufficient stock", slog.Int64("available", balance.Quantity), slog.Int64("adjust", request.Quantity))
+			return nil, fmt.Errorf("%w: available %d, requested %d", repository.ErrInsufficientStock, balance.Quantity, -request.Quantity)
+		}
+	}
+
+	// ปรับเพิ่มเป็นรายการเดียวไม่มี lot ปรับลดแบ่งตาม lot ที่หมดอายุก่อน (รวม lot ที่หมดอายุแล้ว เพราะมักเป็นการตัดของเสีย)
+	// สินค้าที่มี serial ตัดตาม lot ของแต่ละ serial
+	allocations := []repository.LotAllocation{{Quantity: request.Quantity, Serials: request.Serials}}
+	if request.Quantity < 0 {
+		if product.TrackSerial {
+			allocations, err = s.serialRepo.AllocateByLot(tx, warehouse.WarehouseId, request.ProductId, request.Serials)
+		} else {
+			allocations, err = s.lotRepo.AllocateFEFOWithExpired(tx, warehouse.WarehouseId, request.ProductId, -request.Quantity)
+		}
+		if err != nil {
+			tx.Rollback()
+			s.logger.Error("Failed to allocate lots", slog.String("error", err.Error()))
+			return nil, err
+		}
+		for i := range allocations {
+			allocations[i].Quantity = -allocations[i].Quantity
+		}
+	}
+
+	transactions := make([]model.StockTransaction, 0, len(allocations))
+	for _, allocation := range allocations {
+		transaction := &model.StockTransaction{
+			StockTransactionId: uuid.New(),
+			ProductId:          request.ProductId,
+			WarehouseId:        warehouse.WarehouseId,
+			LocationId:         request.LocationId,
+			LotId:              allocation.LotId,
+			Type:               model.TransactionTypeAdjust,
+			Quantity:           allocation.Quantity,
+			Reason:             &request.Reason,
+			CreatedAt:          createdAt,
+			CreatedBy:          request.CreatedBy,
+		}
+
+		// บันทึก stock adjust
+		if err := s.stockTransactionRepo.Create(tx, transaction); err != nil {
+			tx.Rollback()
+			s.logger.Error("Failed to create stock adjust", slog.String("error", err.Error()))
+			return nil, err
+		}
+
+		// ปรับเพิ่ม = รับ serial เข้า stock, ปรับลด = ตัด serial ออกเป็น SCRAPPED
+		if product.TrackSerial {
+			if allocation.Quantity > 0 {
+				err = s.serialRepo.Receive(tx, transaction, allocation.Serials)
+			} else {
+				err = s.serialRepo.Issue(tx, transaction, allocation.Serials, model.SerialScrapped)
+			}
+			if err != nil {
+				tx.Rollback()
+				s.logger.Error("Failed to adjust serial numbers", slog.String("error", err.Error()))
+				return nil, err
+			}
+		}
+		transactions = append(transactions, *transaction)
+	}
+
+	// อ่านยอดคงเหลือของคลังนี้จาก stock_balances (อัปเดตใน transaction เดียวกับ ledger)
+	balance, err := s.stockBalanceRepo.SearchByProductId(tx, warehouse.WarehouseId, request.ProductId)
 	if err != nil {
 		tx.Rollback()
-		s.logger.Error("Failed to get stock summary", slog.String("error", err.Error()))
+		s.logger.Error("Failed to get stock balance", slog.String("error", err.Error()))
 		return nil, err
 	}
-	currentStock := totalIn - totalOut + totalAdjust
+	currentStock := balance.Quantity
 
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
@@ -112,7 +218,8 @@ func (s *StockAdjust) Handle(ctx context.Context, request StockAdjustRequest) (*
 	}
 
 	response := &StockAdjustResult{
-		Transaction:  *transaction,
+		Transaction:  transactions[0],
+		Transactions: transactions,
 		CurrentStock: currentStock,
 		Message:      "Stock ADJUSTED successfully (" + adjustType + ")",
 	}
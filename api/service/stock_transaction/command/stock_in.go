@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
@@ -16,15 +17,30 @@ type StockIn struct {
 	logger               *slog.Logger
 	db                   *gorm.DB
 	stockTransactionRepo repository.StockTransaction
+	stockBalanceRepo     repository.StockBalance
 	productRepo          repository.Product
+	warehouseRepo        repository.Warehouse
+	lotRepo              repository.Lot
+	serialRepo           repository.SerialNumber
+	unitRepo             repository.UnitOfMeasure
 }
 
 type StockInRequest struct {
 	ProductId   uuid.UUID  `json:"product_id"`
+	WarehouseId *uuid.UUID `json:"warehouse_id"` // ไม่ระบุ = คลังหลัก
+	LocationId  *uuid.UUID `json:"location_id"`
 	Quantity    int64      `json:"quantity"`
+	Unit        *string    `json:"unit"` // ไม่ระบุ = หน่วยฐาน (Product.Unit)
 	Reason      *string    `json:"reason"`
 	ReferenceId *uuid.UUID `json:"reference_id"`
-	CreatedBy   string     `json:"created_by,omitempty"` // ผู้ทำรายการ
+	LotNumber   *string    `json:"lot_number"`  // ไม่ระบุ = ไม่ติดตาม lot
+	ExpiryDate  *time.Time `json:"expiry_date"` // ใช้ได้เมื่อระบุ lot_number
+	Serials     []string   `json:"serials"`     // บังคับสำหรับสินค้าที่ track serial หนึ่งหมายเลขต่อหนึ่งชิ้น
+	// UnitCost is the cost of one unit received, nil = current average cost of the warehouse
+	UnitCost *decimal.Decimal `json:"unit_cost"`
+	// TransactionDate back-dates the movement, nil = now. It cannot fall inside a closed period.
+	TransactionDate *time.Time `json:"transaction_date"`
+	CreatedBy       string     `json:"created_by,omitempty"` // ผู้ทำรายการ
 }
 
 type StockInResult struct {
@@ -33,12 +49,17 @@ type StockInResult struct {
 	Message      string                 `json:"message"`
 }
 
-func NewStockIn(logger *slog.Logger, db *gorm.DB, stockTransactionRepo repository.StockTransaction, productRepo repository.Product) *StockIn {
+func NewStockIn(logger *slog.Logger, db *gorm.DB, stockTransactionRepo repository.StockTransaction, stockBalanceRepo repository.StockBalance, productRepo repository.Product, warehouseRepo repository.Warehouse, lotRepo repository.Lot, serialRepo repository.SerialNumber, unitRepo repository.UnitOfMeasure) *StockIn {
 	return &StockIn{
 		logger:               logger,
 		db:                   db,
 		stockTransactionRepo: stockTransactionRepo,
+		stockBalanceRepo:     stockBalanceRepo,
 		productRepo:          productRepo,
+		warehouseRepo:        warehouseRepo,
+		lotRepo:              lotRepo,
+		serialRepo:           serialRepo,
+		unitRepo:             unitRepo,
 	}
 }
 
@@ -49,6 +70,17 @@ func (s *StockIn) Handle(ctx context.Context, request StockInRequest) (*StockInR
 		return nil, errors.New("quantity must be greater than 0")
 	}
 
+	if request.UnitCost != nil && request.UnitCost.IsNegative() {
+		s.logger.Error("Unit cost cannot be negative", slog.String("unit_cost", request.UnitCost.String()))
+		return nil, errors.New("unit cost cannot be negative")
+	}
+
+	createdAt, err := transactionTime(request.TransactionDate)
+	if err != nil {
+		s.logger.Error("Invalid transaction date", slog.String("error", err.Error()))
+		return nil, err
+	}
+
 	// เริ่ม transaction
 	tx := s.db.Begin()
 	defer func() {
@@ -61,24 +93,70 @@ func (s *StockIn) Handle(ctx context.Context, request StockInRequest) (*StockInR
 	}
 
 	// ดึงข้อมูล product
-	_, err := s.productRepo.Search(tx, condition, "")
+	product, err := s.productRepo.Search(tx, condition, "")
 	if err != nil {
 		tx.Rollback()
 		s.logger.Error("Product not found", slog.String("error", err.Error()))
 		return nil, errors.New("product not found")
 	}
 
+	// แปลงจำนวนจากหน่วยที่ระบุเป็นหน่วยฐานของสินค้า
+	factor, err := s.unitRepo.Factor(tx, product, request.Unit)
+	if err != nil {
+		tx.Rollback()
+		s.logger.Error("Failed to resolve unit", slog.String("error", err.Error()))
+		return nil, err
+	}
+	request.Quantity *= factor
+
+	// ต้นทุนที่ระบุเป็นต่อหน่วยที่รับเข้า เก็บเป็นต่อหน่วยฐาน
+	if request.UnitCost != nil && factor > 1 {
+		unitCost := request.UnitCost.Div(decimal.NewFromInt(factor)).Round(4)
+		request.UnitCost = &unitCost
+	}
+
+	if err := repository.CheckSerials(product, request.Quantity, request.Serials); err != nil {
+		tx.Rollback()
+		s.logger.Error("Invalid serial numbers", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	// หาคลังที่ทำรายการ ไม่ระบุจะใช้คลังหลัก
+	warehouse, err := s.warehouseRepo.Resolve(tx, request.WarehouseId, request.LocationId)
+	if err != nil {
+		tx.Rollback()
+		s.logger.Error("Failed to resolve warehouse", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	// หา lot ตามเลขที่ระบุ ถ้ายังไม่มีจะสร้างใหม่
+	lot, err := s.lotRepo.Resolve(tx, request.ProductId, request.LotNumber, request.ExpiryDate)
+	if err != nil {
+		tx.Rollback()
+		s.logger.Error("Failed to resolve lot", slog.String("error", err.Error()))
+		return nil, err
+	}
+
 	// สร้าง transaction
 	transaction := &model.StockTransaction{
 		StockTransactionId: uuid.New(),
 		ProductId:          request.ProductId,
+		WarehouseId:        warehouse.WarehouseId,
+		LocationId:         request.LocationId,
 		Type:               model.TransactionTypeIn,
 		Quantity:           request.Quantity,
 		Reason:             request.Reason,
 		ReferenceId:        request.ReferenceId,
-		CreatedAt:          time.Now(),
+		CreatedAt:          createdAt,
 		CreatedBy:          request.CreatedBy,
 	}
+	if lot != nil {
+		transaction.LotId = &lot.LotId
+	}
+	if request.UnitCost != nil {
+		transaction.UnitCost = *request.UnitCost
+		transaction.UnitCostSet = true
+	}
 
 	// บันทึก stock in
 	if err := s.stockTransactionRepo.Create(tx, transaction); err != nil {
@@ -86,15 +164,24 @@ func (s *StockIn) Handle(ctx context.Context, request StockInRequest) (*StockInR
 		s.logger.Error("Failed to create stock in", slog.String("error", err.Error()))
 		return nil, err
 	}
+	transaction.Lot = lot
+
+	if product.TrackSerial {
+		if err := s.serialRepo.Receive(tx, transaction, request.Serials); err != nil {
+			tx.Rollback()
+			s.logger.Error("Failed to receive serial numbers", slog.String("error", err.Error()))
+			return nil, err
+		}
+	}
 
-	// คำนวณ calculated stock (SUM(IN) - SUM(OUT) + SUM(ADJUST))
-	totalIn, totalOut, totalAdjust, err := s.stockTransactionRepo.StockSummary(tx, request.ProductId)
+	// อ่านยอดคงเหลือของคลังนี้จาก stock_balances (อัปเดตใน transaction เดียวกับ ledger)
+	balance, err := s.stockBalanceRepo.SearchByProductId(tx, warehouse.WarehouseId, request.ProductId)
 	if err != nil {
 		tx.Rollback()
-		s.logger.Error("Failed to get stock summary", slog.String("error", err.Error()))
+		s.logger.Error("Failed to get stock balance", slog.String("error", err.Error()))
 		return nil, err
 	}
-	currentStock := totalIn - totalOut + totalAdjust
+	currentStock := balance.Quantity
 
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
@@ -3,6 +3,7 @@ package command
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"mini-erp-backend/api/repository"
 	"mini-erp-backend/model"
@@ -16,28 +17,45 @@ type StockOut struct {
 	logger               *slog.Logger
 	db                   *gorm.DB
 	stockTransactionRepo repository.StockTransaction
+	stockBalanceRepo     repository.StockBalance
 	productRepo          repository.Product
+	warehouseRepo        repository.Warehouse
+	lotRepo              repository.Lot
+	serialRepo           repository.SerialNumber
+	unitRepo             repository.UnitOfMeasure
 }
 
 type StockOutRequest struct {
-	ProductId uuid.UUID `json:"product_id"`
-	Quantity  int64     `json:"quantity"`
-	Reason    *string   `json:"reason"`
-	CreatedBy string    `json:"created_by,omitempty"` // ผู้ทำรายการ
+	ProductId   uuid.UUID  `json:"product_id"`
+	WarehouseId *uuid.UUID `json:"warehouse_id"` // ไม่ระบุ = คลังหลัก
+	LocationId  *uuid.UUID `json:"location_id"`
+	Quantity    int64      `json:"quantity"`
+	Unit        *string    `json:"unit"` // ไม่ระบุ = หน่วยฐาน (Product.Unit)
+	Reason      *string    `json:"reason"`
+	Serials     []string   `json:"serials"` // บังคับสำหรับสินค้าที่ track serial
+	// TransactionDate back-dates the movement, nil = now. It cannot fall inside a closed period.
+	TransactionDate *time.Time `json:"transaction_date"`
+	CreatedBy       string     `json:"created_by,omitempty"` // ผู้ทำรายการ
 }
 
 type StockOutResult struct {
-	Transaction  model.StockTransaction `json:"transaction"`
-	CurrentStock int64                  `json:"current_stock"`
-	Message      string                 `json:"message"`
+	Transaction  model.StockTransaction   `json:"transaction"`  // รายการแรก
+	Transactions []model.StockTransaction `json:"transactions"` // หนึ่งรายการต่อ lot ที่ถูกตัด (FEFO)
+	CurrentStock int64                    `json:"current_stock"`
+	Message      string                   `json:"message"`
 }
 
-func NewStockOut(logger *slog.Logger, db *gorm.DB, stockTransactionRepo repository.StockTransaction, productRepo repository.Product) *StockOut {
+func NewStockOut(logger *slog.Logger, db *gorm.DB, stockTransactionRepo repository.StockTransaction, stockBalanceRepo repository.StockBalance, productRepo repository.Product, warehouseRepo repository.Warehouse, lotRepo repository.Lot, serialRepo repository.SerialNumber, unitRepo repository.UnitOfMeasure) *StockOut {
 	return &StockOut{
 		logger:               logger,
 		db:                   db,
 		stockTransactionRepo: stockTransactionRepo,
+		stockBalanceRepo:     stockBalanceRepo,
 		productRepo:          productRepo,
+		warehouseRepo:        warehouseRepo,
+		lotRepo:              lotRepo,
+		serialRepo:           serialRepo,
+		unitRepo:             unitRepo,
 	}
 }
 
@@ -48,6 +66,12 @@ func (s *StockOut) Handle(ctx context.Context, request StockOutRequest) (*StockO
 		return nil, errors.New("quantity must be greater than 0")
 	}
 
+	createdAt, err := transactionTime(request.TransactionDate)
+	if err != nil {
+		s.logger.Error("Invalid transaction date", slog.String("error", err.Error()))
+		return nil, err
+	}
+
 	// เริ่ม transaction
 	tx := s.db.Begin()
 	defer func() {
@@ -61,39 +85,107 @@ func (s *StockOut) Handle(ctx context.Context, request StockOutRequest) (*StockO
 	}
 
 	// ดึงข้อมูล product
-	_, err := s.productRepo.Search(tx, condition, "")
+	product, err := s.productRepo.Search(tx, condition, "")
 	if err != nil {
 		tx.Rollback()
 		s.logger.Error("Product not found", slog.String("error", err.Error()))
 		return nil, errors.New("product not found")
 	}
 
-	// สร้าง transaction
-	transaction := &model.StockTransaction{
-		StockTransactionId: uuid.New(),
-		ProductId:          request.ProductId,
-		Type:               model.TransactionTypeOut,
-		Quantity:           request.Quantity,
-		Reason:             request.Reason,
-		CreatedAt:          time.Now(),
-		CreatedBy:          request.CreatedBy,
+	// แปลงจำนวนจากหน่วยที่ระบุเป็นหน่วยฐานของสินค้า
+	factor, err := s.unitRepo.Factor(tx, product, request.Unit)
+	if err != nil {
+		tx.Rollback()
+		s.logger.Error("Failed to resolve unit", slog.String("error", err.Error()))
+		return nil, err
+	}
+	request.Quantity *= factor
+
+	if err := repository.CheckSerials(product, request.Quantity, request.Serials); err != nil {
+		tx.Rollback()
+		s.logger.Error("Invalid serial numbers", slog.String("error", err.Error()))
+		return nil, err
 	}
 
-	// บันทึก stock out
-	if err := s.stockTransactionRepo.Create(tx, transaction); err != nil {
+	// หาคลังที่ทำรายการ ไม่ระบุจะใช้คลังหลัก
+	warehouse, err := s.warehouseRepo.Resolve(tx, request.WarehouseId, request.LocationId)
+	if err != nil {
 		tx.Rollback()
-		s.logger.Error("Failed to create stock out", slog.String("error", err.Error()))
+		s.logger.Error("Failed to resolve warehouse", slog.String("error", err.Error()))
 		return nil, err
 	}
 
-	// คำนวณ calculated stock (SUM(IN) - SUM(OUT) + SUM(ADJUST))
-	totalIn, totalOut, totalAdjust, err := s.stockTransactionRepo.StockSummary(tx, request.ProductId)
+	// ล็อกยอดคงเหลือ (SELECT ... FOR UPDATE) กันการตัด stock พร้อมกันจนติดลบ
+	balance, err := s.stockBalanceRepo.LockByProductId(tx, warehouse.WarehouseId, request.ProductId)
+	if err != nil {
+		tx.Rollback()
+		s.logger.Error("Failed to lock stock balance", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	if !product.AllowBackorder && balance.Quantity < request.Quantity {
+		tx.Rollback()
+		s.logger.Error("Insufficient stock", slog.Int64("available", balance.Quantity), slog.Int64("requested", request.Quantity))
+		return nil, fmt.Errorf("%w: available %d, requested %d", repository.ErrInsufficientStock, balance.Quantity, request.Quantity)
+	}
+
+	// แบ่งจำนวนที่ตัดออกตาม lot ที่หมดอายุก่อน (FEFO) ไม่ตัดจาก lot ที่หมดอายุแล้ว ส่วนที่ไม่มี lot จะตัดเป็นรายการสุดท้าย
+	// สินค้าที่มี serial ตัดตาม lot ที่แต่ละ serial รับเข้ามา
+	var allocations []repository.LotAllocation
+	if product.TrackSerial {
+		allocations, err = s.serialRepo.AllocateByLot(tx, warehouse.WarehouseId, request.ProductId, request.Serials)
+		if err == nil {
+			err = s.lotRepo.RejectExpired(tx, allocations)
+		}
+	} else {
+		allocations, err = s.lotRepo.AllocateFEFO(tx, warehouse.WarehouseId, request.ProductId, request.Quantity)
+	}
+	if err != nil {
+		tx.Rollback()
+		s.logger.Error("Failed to allocate lots", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	transactions := make([]model.StockTransaction, 0, len(allocations))
+	for _, allocation := range allocations {
+		transaction := &model.StockTransaction{
+			StockTransactionId: uuid.New(),
+			ProductId:          request.ProductId,
+			WarehouseId:        warehouse.WarehouseId,
+			LocationId:         request.LocationId,
+			LotId:              allocation.LotId,
+			Type:               model.TransactionTypeOut,
+			Quantity:           allocation.Quantity,
+			Reason:             request.Reason,
+			CreatedAt:          createdAt,
+			CreatedBy:          request.CreatedBy,
+		}
+
+		// บันทึก stock out
+		if err := s.stockTransactionRepo.Create(tx, transaction); err != nil {
+			tx.Rollback()
+			s.logger.Error("Failed to create stock out", slog.String("error", err.Error()))
+			return nil, err
+		}
+
+		if product.TrackSerial {
+			if err := s.serialRepo.Issue(tx, transaction, allocation.Serials, model.SerialShipped); err != nil {
+				tx.Rollback()
+				s.logger.Error("Failed to issue serial numbers", slog.String("error", err.Error()))
+				return nil, err
+			}
+		}
+		transactions = append(transactions, *transaction)
+	}
+
+	// อ่านยอดคงเหลือของคลังนี้จาก stock_balances (อัปเดตใน transaction เดียวกับ ledger)
+	balance, err = s.stockBalanceRepo.SearchByProductId(tx, warehouse.WarehouseId, request.ProductId)
 	if err != nil {
 		tx.Rollback()
-		s.logger.Error("Failed to get stock summary", slog.String("error", err.Error()))
+		s.logger.Error("Failed to get stock balance", slog.String("error", err.Error()))
 		return nil, err
 	}
-	currentStock := totalIn - totalOut + totalAdjust
+	currentStock := balance.Quantity
 
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
@@ -102,7 +194,8 @@ func (s *StockOut) Handle(ctx context.Context, request StockOutRequest) (*StockO
 	}
 
 	response := &StockOutResult{
-		Transaction:  *transaction,
+		Transaction:  transactions[0],
+		Transactions: transactions,
 		CurrentStock: currentStock,
 		Message:      "Stock OUT successful",
 	}
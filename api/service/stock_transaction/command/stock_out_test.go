@@ -0,0 +1,135 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/lib/testdb"
+	"mini-erp-backend/model"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type fakeProductRepo struct {
+	repository.Product
+	product *model.Product
+}
+
+func (f *fakeProductRepo) Search(_ *gorm.DB, _ map[string]interface{}, _ string) (*model.Product, error) {
+	return f.product, nil
+}
+
+// fakeUnitRepo only knows the base unit
+type fakeUnitRepo struct {
+	repository.UnitOfMeasure
+}
+
+func (f *fakeUnitRepo) Factor(_ *gorm.DB, _ *model.Product, _ *string) (int64, error) {
+	return 1, nil
+}
+
+type fakeWarehouseRepo struct {
+	repository.Warehouse
+	warehouse *model.Warehouse
+}
+
+func (f *fakeWarehouseRepo) Resolve(_ *gorm.DB, _ *uuid.UUID, _ *uuid.UUID) (*model.Warehouse, error) {
+	return f.warehouse, nil
+}
+
+// fakeStockBalanceRepo keeps one balance in memory
+type fakeStockBalanceRepo struct {
+	repository.StockBalance
+	balance model.StockBalance
+}
+
+func (f *fakeStockBalanceRepo) LockByProductId(_ *gorm.DB, _, _ uuid.UUID) (*model.StockBalance, error) {
+	balance := f.balance
+	return &balance, nil
+}
+
+func (f *fakeStockBalanceRepo) SearchByProductId(_ *gorm.DB, _, _ uuid.UUID) (*model.StockBalance, error) {
+	balance := f.balance
+	return &balance, nil
+}
+
+// fakeLotRepo issues the whole quantity without a lot
+type fakeLotRepo struct {
+	repository.Lot
+}
+
+func (f *fakeLotRepo) AllocateFEFO(_ *gorm.DB, _, _ uuid.UUID, quantity int64) ([]repository.LotAllocation, error) {
+	return []repository.LotAllocation{{Quantity: quantity}}, nil
+}
+
+// fakeStockTransactionRepo records the ledger rows and applies them to the balance like the real repository
+type fakeStockTransactionRepo struct {
+	repository.StockTransaction
+	balances *fakeStockBalanceRepo
+	created  []model.StockTransaction
+}
+
+func (f *fakeStockTransactionRepo) Create(_ *gorm.DB, transaction *model.StockTransaction) error {
+	f.balances.balance.Apply(*transaction)
+	f.created = append(f.created, *transaction)
+	return nil
+}
+
+func newStockOut(t *testing.T, product *model.Product, available int64) (*StockOut, *fakeStockTransactionRepo) {
+	t.Helper()
+
+	warehouse := &model.Warehouse{WarehouseId: uuid.New()}
+	balances := &fakeStockBalanceRepo{balance: model.StockBalance{WarehouseId: warehouse.WarehouseId, ProductId: product.ProductId, Quantity: available}}
+	transactions := &fakeStockTransactionRepo{balances: balances}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	stockOut := NewStockOut(logger, testdb.Open(t), transactions, balances, &fakeProductRepo{product: product},
+		&fakeWarehouseRepo{warehouse: warehouse}, &fakeLotRepo{}, nil, &fakeUnitRepo{})
+	return stockOut, transactions
+}
+
+func TestStockOutRejectsInsufficientStock(t *testing.T) {
+	product := &model.Product{ProductId: uuid.New(), ProductCode: "P-001"}
+	stockOut, transactions := newStockOut(t, product, 3)
+
+	_, err := stockOut.Handle(context.Background(), StockOutRequest{ProductId: product.ProductId, Quantity: 5})
+	if !errors.Is(err, repository.ErrInsufficientStock) {
+		t.Fatalf("Handle error = %v, want ErrInsufficientStock", err)
+	}
+	if len(transactions.created) != 0 {
+		t.Errorf("created %d ledger rows, want none", len(transactions.created))
+	}
+}
+
+func TestStockOutAllowsBackorder(t *testing.T) {
+	product := &model.Product{ProductId: uuid.New(), ProductCode: "P-002", AllowBackorder: true}
+	stockOut, transactions := newStockOut(t, product, 3)
+
+	result, err := stockOut.Handle(context.Background(), StockOutRequest{ProductId: product.ProductId, Quantity: 5})
+	if err != nil {
+		t.Fatalf("Handle error: %v", err)
+	}
+	if len(transactions.created) != 1 || transactions.created[0].Type != model.TransactionTypeOut || transactions.created[0].Quantity != 5 {
+		t.Errorf("created = %+v, want one OUT of 5", transactions.created)
+	}
+	if result.CurrentStock != -2 {
+		t.Errorf("CurrentStock = %d, want -2", result.CurrentStock)
+	}
+}
+
+func TestStockOutWithinStock(t *testing.T) {
+	product := &model.Product{ProductId: uuid.New(), ProductCode: "P-003"}
+	stockOut, _ := newStockOut(t, product, 5)
+
+	result, err := stockOut.Handle(context.Background(), StockOutRequest{ProductId: product.ProductId, Quantity: 5})
+	if err != nil {
+		t.Fatalf("Handle error: %v", err)
+	}
+	if result.CurrentStock != 0 {
+		t.Errorf("CurrentStock = %d, want 0", result.CurrentStock)
+	}
+}
@@ -0,0 +1,23 @@
+package command
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrFutureTransactionDate is returned for a transaction_date later than now
+var ErrFutureTransactionDate = errors.New("transaction date cannot be in the future")
+
+// transactionTime returns the time a movement is recorded at: now, or the back-dated transaction_date.
+// Dates inside a closed period are rejected later by the stock transaction repository.
+func transactionTime(date *time.Time) (time.Time, error) {
+	now := time.Now()
+	if date == nil {
+		return now, nil
+	}
+
+	if date.After(now) {
+		return time.Time{}, ErrFutureTransactionDate
+	}
+	return *date, nil
+}
@@ -0,0 +1,281 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrSameWarehouse is returned when a transfer has the same source and destination warehouse
+	ErrSameWarehouse = errors.New("source and destination warehouse must be different")
+	// ErrTransferNotInTransit is returned when receiving a transfer that has already arrived
+	ErrTransferNotInTransit = errors.New("stock transfer is not in transit")
+)
+
+type TransferStock struct {
+	logger               *slog.Logger
+	db                   *gorm.DB
+	stockTransactionRepo repository.StockTransaction
+	stockBalanceRepo     repository.StockBalance
+	stockTransferRepo    repository.StockTransfer
+	productRepo          repository.Product
+	warehouseRepo        repository.Warehouse
+	lotRepo              repository.Lot
+	serialRepo           repository.SerialNumber
+}
+
+type TransferStockRequest struct {
+	ProductId       uuid.UUID  `json:"product_id"`
+	FromWarehouseId uuid.UUID  `json:"from_warehouse_id"`
+	FromLocationId  *uuid.UUID `json:"from_location_id"`
+	ToWarehouseId   uuid.UUID  `json:"to_warehouse_id"`
+	ToLocationId    *uuid.UUID `json:"to_location_id"`
+	Quantity        int64      `json:"quantity"`
+	InTransit       bool       `json:"in_transit"` // true = ส่งออกแล้วแต่ยังไม่ถึงปลายทาง ต้องรับเข้าภายหลัง
+	Note            *string    `json:"note"`
+	Serials         []string   `json:"serials"` // บังคับสำหรับสินค้าที่ track serial
+	CreatedBy       string     `json:"-"`       // ผู้ทำรายการ
+}
+
+type TransferStockResult struct {
+	Transfer     model.StockTransfer      `json:"transfer"`
+	Transactions []model.StockTransaction `json:"transactions"`
+	Message      string                   `json:"message"`
+}
+
+func NewTransferStock(
+	logger *slog.Logger,
+	db *gorm.DB,
+	stockTransactionRepo repository.StockTransaction,
+	stockBalanceRepo repository.StockBalance,
+	stockTransferRepo repository.StockTransfer,
+	productRepo repository.Product,
+	warehouseRepo repository.Warehouse,
+	lotRepo repository.Lot,
+	serialRepo repository.SerialNumber,
+) *TransferStock {
+	return &TransferStock{
+		logger:               logger,
+		db:                   db,
+		stockTransactionRepo: stockTransactionRepo,
+		stockBalanceRepo:     stockBalanceRepo,
+		stockTransferRepo:    stockTransferRepo,
+		productRepo:          productRepo,
+		warehouseRepo:        warehouseRepo,
+		lotRepo:              lotRepo,
+		serialRepo:           serialRepo,
+	}
+}
+
+func (s *TransferStock) Handle(ctx context.Context, request TransferStockRequest) (*TransferStockResult, error) {
+	if request.Quantity <= 0 {
+		s.logger.Error("Quantity must be greater than 0")
+		return nil, errors.New("quantity must be greater than 0")
+	}
+
+	if request.FromWarehouseId == request.ToWarehouseId {
+		return nil, ErrSameWarehouse
+	}
+
+	// เริ่ม transaction ขาออกและขาเข้าต้อง commit พร้อมกัน
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	product, err := s.productRepo.Search(tx, map[string]interface{}{
+		"product_id": request.ProductId,
+	}, "")
+	if err != nil {
+		tx.Rollback()
+		s.logger.Error("Product not found", slog.String("error", err.Error()))
+		return nil, errors.New("product not found")
+	}
+
+	if err := repository.CheckSerials(product, request.Quantity, request.Serials); err != nil {
+		tx.Rollback()
+		s.logger.Error("Invalid serial numbers", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	if _, err := s.warehouseRepo.Resolve(tx, &request.FromWarehouseId, request.FromLocationId); err != nil {
+		tx.Rollback()
+		s.logger.Error("Failed to resolve source warehouse", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	if _, err := s.warehouseRepo.Resolve(tx, &request.ToWarehouseId, request.ToLocationId); err != nil {
+		tx.Rollback()
+		s.logger.Error("Failed to resolve destination warehouse", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	// ล็อกยอดคงเหลือคลังต้นทาง การโอนย้ายห้ามทำให้ติดลบแม้สินค้าจะอนุญาต backorder
+	balance, err := s.stockBalanceRepo.LockByProductId(tx, request.FromWarehouseId, request.ProductId)
+	if err != nil {
+		tx.Rollback()
+		s.logger.Error("Failed to lock stock balance", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	if balance.Quantity < request.Quantity {
+		tx.Rollback()
+		s.logger.Error("Insufficient stock to transfer", slog.Int64("available", balance.Quantity), slog.Int64("requested", request.Quantity))
+		return nil, fmt.Errorf("%w: available %d, requested %d", repository.ErrInsufficientStock, balance.Quantity, request.Quantity)
+	}
+
+	now := time.Now()
+	transfer := &model.StockTransfer{
+		StockTransferId: uuid.New(),
+		ProductId:       request.ProductId,
+		FromWarehouseId: request.FromWarehouseId,
+		FromLocationId:  request.FromLocationId,
+		ToWarehouseId:   request.ToWarehouseId,
+		ToLocationId:    request.ToLocationId,
+		Quantity:        request.Quantity,
+		Status:          model.StockTransferInTransit,
+		Note:            request.Note,
+		ShippedAt:       now,
+		ShippedBy:       request.CreatedBy,
+	}
+
+	// โอนทันทีถือว่ารับเข้าปลายทางในเวลาเดียวกัน
+	if !request.InTransit {
+		transfer.Status = model.StockTransferReceived
+		transfer.ReceivedAt = &now
+		transfer.ReceivedBy = &request.CreatedBy
+	}
+
+	if err := s.stockTransferRepo.Create(tx, transfer); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// ตัดออกจากคลังต้นทางตาม FEFO เพื่อให้ lot ติดไปกับสินค้าที่โอน ของหมดอายุโอนได้ (เช่นย้ายไปคลังรอทำลาย) สินค้าที่มี serial ตัดตาม lot ของแต่ละ serial
+	var allocations []repository.LotAllocation
+	if product.TrackSerial {
+		allocations, err = s.serialRepo.AllocateByLot(tx, transfer.FromWarehouseId, transfer.ProductId, request.Serials)
+	} else {
+		allocations, err = s.lotRepo.AllocateFEFOWithExpired(tx, transfer.FromWarehouseId, transfer.ProductId, transfer.Quantity)
+	}
+	if err != nil {
+		tx.Rollback()
+		s.logger.Error("Failed to allocate lots", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	outs := make([]model.StockTransaction, 0, len(allocations))
+	for _, allocation := range allocations {
+		out := &model.StockTransaction{
+			StockTransactionId: uuid.New(),
+			ProductId:          transfer.ProductId,
+			WarehouseId:        transfer.FromWarehouseId,
+			LocationId:         transfer.FromLocationId,
+			LotId:              allocation.LotId,
+			Type:               model.TransactionTypeOut,
+			Quantity:           allocation.Quantity,
+			Reason:             stringPtr("Stock Transfer Out"),
+			ReferenceId:        &transfer.StockTransferId,
+			CreatedAt:          now,
+			CreatedBy:          request.CreatedBy,
+		}
+
+		if err := s.stockTransactionRepo.Create(tx, out); err != nil {
+			tx.Rollback()
+			s.logger.Error("Failed to create transfer out", slog.String("error", err.Error()))
+			return nil, err
+		}
+
+		// serial ที่โอนจะอยู่ในสถานะ IN_TRANSIT จนกว่าปลายทางจะรับเข้า
+		if product.TrackSerial {
+			if err := s.serialRepo.Issue(tx, out, allocation.Serials, model.SerialInTransit); err != nil {
+				tx.Rollback()
+				s.logger.Error("Failed to issue serial numbers", slog.String("error", err.Error()))
+				return nil, err
+			}
+		}
+		outs = append(outs, *out)
+	}
+
+	transactions := outs
+	message := "Stock transfer shipped, waiting to be received"
+
+	if transfer.Status == model.StockTransferReceived {
+		ins, err := transferIn(tx, s.stockTransactionRepo, s.serialRepo, transfer, outs, request.CreatedBy)
+		if err != nil {
+			tx.Rollback()
+			s.logger.Error("Failed to create transfer in", slog.String("error", err.Error()))
+			return nil, err
+		}
+		transactions = append(transactions, ins...)
+		message = "Stock transfer completed"
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		s.logger.Error("Failed to commit transaction", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	s.logger.Info("Stock transfer created",
+		slog.String("stock_transfer_id", transfer.StockTransferId.String()),
+		slog.String("status", string(transfer.Status)))
+
+	return &TransferStockResult{
+		Transfer:     *transfer,
+		Transactions: transactions,
+		Message:      message,
+	}, nil
+}
+
+// transferIn writes the IN half of a transfer into its destination warehouse, one row per lot that was shipped,
+// and brings the serials shipped with each row into stock there
+func transferIn(tx *gorm.DB, stockTransactionRepo repository.StockTransaction, serialRepo repository.SerialNumber, transfer *model.StockTransfer, outs []model.StockTransaction, createdBy string) ([]model.StockTransaction, error) {
+	ins := make([]model.StockTransaction, 0, len(outs))
+	for _, out := range outs {
+		in := &model.StockTransaction{
+			StockTransactionId: uuid.New(),
+			ProductId:          transfer.ProductId,
+			WarehouseId:        transfer.ToWarehouseId,
+			LocationId:         transfer.ToLocationId,
+			LotId:              out.LotId,
+			Type:               model.TransactionTypeIn,
+			Quantity:           out.Quantity,
+			UnitCost:           out.UnitCost, // ของที่ย้ายคลังยังมีต้นทุนเท่ากับที่ตัดออกจากคลังต้นทาง
+			UnitCostSet:        true,
+			Reason:             stringPtr("Stock Transfer In"),
+			ReferenceId:        &transfer.StockTransferId,
+			CreatedAt:          time.Now(),
+			CreatedBy:          createdBy,
+		}
+
+		if err := stockTransactionRepo.Create(tx, in); err != nil {
+			return nil, err
+		}
+
+		serials, err := serialRepo.SerialsByTransaction(tx, out.StockTransactionId)
+		if err != nil {
+			return nil, err
+		}
+		if len(serials) > 0 {
+			if err := serialRepo.Arrive(tx, in, serials); err != nil {
+				return nil, err
+			}
+		}
+		ins = append(ins, *in)
+	}
+	return ins, nil
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
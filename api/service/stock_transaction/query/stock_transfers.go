@@ -0,0 +1,101 @@
+package query
+
+import (
+	"context"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type StockTransfers struct {
+	logger            *slog.Logger
+	db                *gorm.DB
+	stockTransferRepo repository.StockTransfer
+}
+
+type StockTransfersRequest struct {
+	Page        int                        `json:"page"`
+	PageSize    int                        `json:"page_size"`
+	Status      *model.StockTransferStatus `json:"status"`
+	ProductId   *uuid.UUID                 `json:"product_id"`
+	WarehouseId *uuid.UUID                 `json:"warehouse_id"` // คลังต้นทางหรือปลายทาง
+	SortBy      string                     `json:"sort_by"`
+	SortOrder   string                     `json:"sort_order"`
+}
+
+type StockTransfersResult struct {
+	Transfers  []model.StockTransfer `json:"transfers"`
+	Total      int64                 `json:"total"`
+	Page       int                   `json:"page"`
+	PageSize   int                   `json:"page_size"`
+	TotalPages int                   `json:"total_pages"`
+}
+
+func NewStockTransfers(logger *slog.Logger, db *gorm.DB, stockTransferRepo repository.StockTransfer) *StockTransfers {
+	return &StockTransfers{
+		logger:            logger,
+		db:                db,
+		stockTransferRepo: stockTransferRepo,
+	}
+}
+
+func (s *StockTransfers) Handle(ctx context.Context, request StockTransfersRequest) (*StockTransfersResult, error) {
+	filters := repository.StockTransferSearchFilters{
+		Status:      request.Status,
+		ProductId:   request.ProductId,
+		WarehouseId: request.WarehouseId,
+	}
+
+	orderBy := "shipped_at DESC" // default
+	if request.SortBy != "" {
+		allowedSortFields := map[string]bool{
+			"shipped_at":  true,
+			"received_at": true,
+			"quantity":    true,
+			"status":      true,
+		}
+
+		if allowedSortFields[request.SortBy] {
+			sortOrder := "DESC"
+			if request.SortOrder == "asc" || request.SortOrder == "ASC" {
+				sortOrder = "ASC"
+			}
+			orderBy = request.SortBy + " " + sortOrder
+		}
+	}
+
+	if request.Page <= 0 || request.PageSize <= 0 {
+		transfers, err := s.stockTransferRepo.SearchWithFilters(s.db, filters, orderBy)
+		if err != nil {
+			s.logger.Error("Failed to get stock transfers", slog.String("error", err.Error()))
+			return nil, err
+		}
+
+		return &StockTransfersResult{
+			Transfers:  transfers,
+			Total:      int64(len(transfers)),
+			Page:       1,
+			PageSize:   len(transfers),
+			TotalPages: 1,
+		}, nil
+	}
+
+	transfers, total, err := s.stockTransferRepo.SearchWithFiltersAndPagination(s.db, filters, orderBy, request.Page, request.PageSize)
+	if err != nil {
+		s.logger.Error("Failed to search stock transfers with filters and pagination", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	totalPages := int((total + int64(request.PageSize) - 1) / int64(request.PageSize))
+
+	return &StockTransfersResult{
+		Transfers:  transfers,
+		Total:      total,
+		Page:       request.Page,
+		PageSize:   request.PageSize,
+		TotalPages: totalPages,
+	}, nil
+}
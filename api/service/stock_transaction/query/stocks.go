@@ -17,12 +17,13 @@ type Stocks struct {
 }
 
 type StocksRequest struct {
-	Page      int        `json:"page"`
-	PageSize  int        `json:"page_size"`
-	Search    string     `json:"search"`
-	ProductId *uuid.UUID `json:"product_id"`
-	SortBy    string     `json:"sort_by"`    // ฟิลด์ที่ต้องการ sort
-	SortOrder string     `json:"sort_order"` // asc หรือ desc
+	Page        int        `json:"page"`
+	PageSize    int        `json:"page_size"`
+	Search      string     `json:"search"`
+	ProductId   *uuid.UUID `json:"product_id"`
+	WarehouseId *uuid.UUID `json:"warehouse_id"`
+	SortBy      string     `json:"sort_by"`    // ฟิลด์ที่ต้องการ sort
+	SortOrder   string     `json:"sort_order"` // asc หรือ desc
 }
 
 type StocksResult struct {
@@ -43,8 +44,9 @@ func NewStocks(logger *slog.Logger, db *gorm.DB, stockTransactionRepo repository
 
 func (s *Stocks) Handle(ctx context.Context, request StocksRequest) (*StocksResult, error) {
 	filters := repository.StockTransactionSearchFilters{
-		Search:    request.Search,
-		ProductId: request.ProductId,
+		Search:      request.Search,
+		ProductId:   request.ProductId,
+		WarehouseId: request.WarehouseId,
 	}
 
 	orderBy := "created_at DESC" // default
@@ -10,11 +10,26 @@ import (
 	"gorm.io/gorm"
 )
 
-func NewService(logger *slog.Logger, db *gorm.DB, stockTransactionRepo repository.StockTransaction, productRepo repository.Product) {
+func NewService(
+	logger *slog.Logger,
+	db *gorm.DB,
+	stockTransactionRepo repository.StockTransaction,
+	stockBalanceRepo repository.StockBalance,
+	productRepo repository.Product,
+	warehouseRepo repository.Warehouse,
+	stockTransferRepo repository.StockTransfer,
+	lotRepo repository.Lot,
+	serialRepo repository.SerialNumber,
+	unitRepo repository.UnitOfMeasure,
+) {
 	stockService := query.NewStocks(logger, db, stockTransactionRepo)
-	stockInService := command.NewStockIn(logger, db, stockTransactionRepo, productRepo)
-	stockOutService := command.NewStockOut(logger, db, stockTransactionRepo, productRepo)
-	stockAdjustService := command.NewStockAdjust(logger, db, stockTransactionRepo, productRepo)
+	stockInService := command.NewStockIn(logger, db, stockTransactionRepo, stockBalanceRepo, productRepo, warehouseRepo, lotRepo, serialRepo, unitRepo)
+	stockOutService := command.NewStockOut(logger, db, stockTransactionRepo, stockBalanceRepo, productRepo, warehouseRepo, lotRepo, serialRepo, unitRepo)
+	stockAdjustService := command.NewStockAdjust(logger, db, stockTransactionRepo, stockBalanceRepo, productRepo, warehouseRepo, lotRepo, serialRepo, unitRepo)
+	rebuildStockBalanceService := command.NewRebuildStockBalance(logger, db, stockBalanceRepo)
+	stockTransfersService := query.NewStockTransfers(logger, db, stockTransferRepo)
+	transferStockService := command.NewTransferStock(logger, db, stockTransactionRepo, stockBalanceRepo, stockTransferRepo, productRepo, warehouseRepo, lotRepo, serialRepo)
+	receiveStockTransferService := command.NewReceiveStockTransfer(logger, db, stockTransactionRepo, stockTransferRepo, warehouseRepo, serialRepo)
 
 	err := mediatr.RegisterRequestHandler(stockService)
 	if err != nil {
@@ -35,4 +50,24 @@ func NewService(logger *slog.Logger, db *gorm.DB, stockTransactionRepo repositor
 	if err != nil {
 		panic(err)
 	}
+
+	err = mediatr.RegisterRequestHandler(rebuildStockBalanceService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(stockTransfersService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(transferStockService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(receiveStockTransferService)
+	if err != nil {
+		panic(err)
+	}
 }
@@ -0,0 +1,93 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CreateSupplierProduct struct {
+	logger              *slog.Logger
+	db                  *gorm.DB
+	SupplierRepo        repository.Supplier
+	SupplierProductRepo repository.SupplierProduct
+	ProductRepo         repository.Product
+}
+
+type CreateSupplierProductRequest struct {
+	SupplierId uuid.UUID `json:"-"`
+	ProductId  uuid.UUID `json:"product_id"`
+	SupplierProductTerms
+}
+
+func NewCreateSupplierProduct(
+	logger *slog.Logger,
+	db *gorm.DB,
+	supplierRepo repository.Supplier,
+	supplierProductRepo repository.SupplierProduct,
+	productRepo repository.Product,
+) *CreateSupplierProduct {
+	return &CreateSupplierProduct{
+		logger:              logger,
+		db:                  db,
+		SupplierRepo:        supplierRepo,
+		SupplierProductRepo: supplierProductRepo,
+		ProductRepo:         productRepo,
+	}
+}
+
+func (h *CreateSupplierProduct) Handle(ctx context.Context, cmd *CreateSupplierProductRequest) (interface{}, error) {
+	if err := cmd.normalize(); err != nil {
+		return nil, err
+	}
+
+	if _, err := h.SupplierRepo.Search(h.db, map[string]interface{}{"supplier_id": cmd.SupplierId}, ""); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("supplier not found")
+		}
+		return nil, err
+	}
+
+	if _, err := h.ProductRepo.Search(h.db, map[string]interface{}{"product_id": cmd.ProductId}, ""); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product not found")
+		}
+		return nil, err
+	}
+
+	existed, err := h.SupplierProductRepo.ExitedBySupplierAndProduct(h.db, cmd.SupplierId, cmd.ProductId)
+	if err != nil {
+		return nil, err
+	}
+	if existed {
+		h.logger.Warn("Product already exists in supplier catalog", "supplier_id", cmd.SupplierId, "product_id", cmd.ProductId)
+		return nil, ErrSupplierProductExists
+	}
+
+	supplierProduct := &model.SupplierProduct{
+		SupplierProductId: uuid.New(),
+		SupplierId:        cmd.SupplierId,
+		ProductId:         cmd.ProductId,
+		SupplierSku:       cmd.SupplierSku,
+		UnitPrice:         cmd.UnitPrice,
+		MinOrderQuantity:  cmd.MinOrderQuantity,
+		PackSize:          cmd.PackSize,
+		LeadTimeDays:      cmd.LeadTimeDays,
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+	}
+
+	if err := h.SupplierProductRepo.Create(h.db, supplierProduct); err != nil {
+		h.logger.Error("Failed to create supplier product", "error", err)
+		return nil, err
+	}
+
+	h.logger.Info("Supplier product created successfully", "supplier_id", cmd.SupplierId, "product_id", cmd.ProductId)
+	return supplierProduct, nil
+}
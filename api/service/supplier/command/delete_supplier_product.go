@@ -0,0 +1,43 @@
+package command
+
+import (
+	"context"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DeleteSupplierProduct struct {
+	logger              *slog.Logger
+	db                  *gorm.DB
+	SupplierProductRepo repository.SupplierProduct
+}
+
+type DeleteSupplierProductRequest struct {
+	SupplierId uuid.UUID `json:"-"`
+	ProductId  uuid.UUID `json:"-"`
+}
+
+func NewDeleteSupplierProduct(logger *slog.Logger, db *gorm.DB, supplierProductRepo repository.SupplierProduct) *DeleteSupplierProduct {
+	return &DeleteSupplierProduct{
+		logger:              logger,
+		db:                  db,
+		SupplierProductRepo: supplierProductRepo,
+	}
+}
+
+func (h *DeleteSupplierProduct) Handle(ctx context.Context, cmd *DeleteSupplierProductRequest) (interface{}, error) {
+	supplierProduct, err := h.SupplierProductRepo.Search(h.db, cmd.SupplierId, cmd.ProductId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.SupplierProductRepo.Delete(h.db, supplierProduct); err != nil {
+		return nil, err
+	}
+
+	h.logger.Info("Supplier product deleted successfully", "supplier_id", cmd.SupplierId, "product_id", cmd.ProductId)
+	return nil, nil
+}
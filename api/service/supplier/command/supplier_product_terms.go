@@ -0,0 +1,43 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrInvalidSupplierProduct is returned for a negative price or lead time in a supplier catalog entry
+	ErrInvalidSupplierProduct = errors.New("invalid supplier product")
+	// ErrSupplierProductExists is returned when the supplier already lists the product
+	ErrSupplierProductExists = errors.New("product already exists in supplier catalog")
+)
+
+// SupplierProductTerms are the fields shared by create and update of a supplier catalog entry
+type SupplierProductTerms struct {
+	SupplierSku      *string         `json:"supplier_sku"`
+	UnitPrice        decimal.Decimal `json:"unit_price"`
+	MinOrderQuantity uint64          `json:"min_order_quantity"` // 0 = ไม่มีขั้นต่ำ (1)
+	PackSize         uint64          `json:"pack_size"`          // 0 = ขายเป็นชิ้น (1)
+	LeadTimeDays     *int64          `json:"lead_time_days"`     // ไม่ระบุ = ใช้ lead time ของสินค้า
+}
+
+// normalize validates the terms and defaults MinOrderQuantity and PackSize to 1
+func (t *SupplierProductTerms) normalize() error {
+	if t.UnitPrice.IsNegative() {
+		return fmt.Errorf("%w: unit price cannot be negative", ErrInvalidSupplierProduct)
+	}
+
+	if t.LeadTimeDays != nil && *t.LeadTimeDays < 0 {
+		return fmt.Errorf("%w: lead time cannot be negative", ErrInvalidSupplierProduct)
+	}
+
+	if t.MinOrderQuantity == 0 {
+		t.MinOrderQuantity = 1
+	}
+	if t.PackSize == 0 {
+		t.PackSize = 1
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package command
+
+import (
+	"context"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type UpdateSupplierProduct struct {
+	logger              *slog.Logger
+	db                  *gorm.DB
+	SupplierProductRepo repository.SupplierProduct
+}
+
+type UpdateSupplierProductRequest struct {
+	SupplierId uuid.UUID `json:"-"`
+	ProductId  uuid.UUID `json:"-"`
+	SupplierProductTerms
+}
+
+func NewUpdateSupplierProduct(logger *slog.Logger, db *gorm.DB, supplierProductRepo repository.SupplierProduct) *UpdateSupplierProduct {
+	return &UpdateSupplierProduct{
+		logger:              logger,
+		db:                  db,
+		SupplierProductRepo: supplierProductRepo,
+	}
+}
+
+func (h *UpdateSupplierProduct) Handle(ctx context.Context, cmd *UpdateSupplierProductRequest) (interface{}, error) {
+	if err := cmd.normalize(); err != nil {
+		return nil, err
+	}
+
+	supplierProduct, err := h.SupplierProductRepo.Search(h.db, cmd.SupplierId, cmd.ProductId)
+	if err != nil {
+		return nil, err
+	}
+
+	supplierProduct.SupplierSku = cmd.SupplierSku
+	supplierProduct.UnitPrice = cmd.UnitPrice
+	supplierProduct.MinOrderQuantity = cmd.MinOrderQuantity
+	supplierProduct.PackSize = cmd.PackSize
+	supplierProduct.LeadTimeDays = cmd.LeadTimeDays
+	supplierProduct.UpdatedAt = time.Now()
+
+	if err := h.SupplierProductRepo.Update(h.db, supplierProduct); err != nil {
+		h.logger.Error("Failed to update supplier product", "error", err)
+		return nil, err
+	}
+
+	h.logger.Info("Supplier product updated successfully", "supplier_id", cmd.SupplierId, "product_id", cmd.ProductId)
+	return supplierProduct, nil
+}
@@ -0,0 +1,57 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SupplierProducts struct {
+	logger              *slog.Logger
+	db                  *gorm.DB
+	SupplierRepo        repository.Supplier
+	SupplierProductRepo repository.SupplierProduct
+}
+
+type SupplierProductsRequest struct {
+	SupplierId uuid.UUID `json:"supplier_id"`
+}
+
+type SupplierProductsResult struct {
+	SupplierProducts []model.SupplierProduct `json:"supplier_products"`
+	Total            int64                   `json:"total"`
+}
+
+func NewSupplierProducts(logger *slog.Logger, db *gorm.DB, supplierRepo repository.Supplier, supplierProductRepo repository.SupplierProduct) *SupplierProducts {
+	return &SupplierProducts{
+		logger:              logger,
+		db:                  db,
+		SupplierRepo:        supplierRepo,
+		SupplierProductRepo: supplierProductRepo,
+	}
+}
+
+func (h *SupplierProducts) Handle(ctx context.Context, req *SupplierProductsRequest) (*SupplierProductsResult, error) {
+	if _, err := h.SupplierRepo.Search(h.db, map[string]interface{}{"supplier_id": req.SupplierId}, ""); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("supplier not found")
+		}
+		return nil, err
+	}
+
+	supplierProducts, err := h.SupplierProductRepo.SearchBySupplierId(h.db, req.SupplierId)
+	if err != nil {
+		h.logger.Error("Failed to get supplier products", "error", err)
+		return nil, err
+	}
+
+	return &SupplierProductsResult{
+		SupplierProducts: supplierProducts,
+		Total:            int64(len(supplierProducts)),
+	}, nil
+}
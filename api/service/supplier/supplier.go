@@ -10,14 +10,24 @@ import (
 	"gorm.io/gorm"
 )
 
-func NewService(logger *slog.Logger, db *gorm.DB, supplierRepo repository.Supplier) {
+func NewService(
+	logger *slog.Logger,
+	db *gorm.DB,
+	supplierRepo repository.Supplier,
+	supplierProductRepo repository.SupplierProduct,
+	productRepo repository.Product,
+) {
 	// Register command handlers
 	createSupplierHandler := command.NewCreateSupplier(logger, db, supplierRepo)
 	updateSupplierHandler := command.NewUpdateSupplier(logger, db, supplierRepo)
 	deleteSupplierHandler := command.NewDeleteSupplier(logger, db, supplierRepo)
+	createSupplierProductHandler := command.NewCreateSupplierProduct(logger, db, supplierRepo, supplierProductRepo, productRepo)
+	updateSupplierProductHandler := command.NewUpdateSupplierProduct(logger, db, supplierProductRepo)
+	deleteSupplierProductHandler := command.NewDeleteSupplierProduct(logger, db, supplierProductRepo)
 
 	getSupplierHandler := query.NewSupplier(logger, db, supplierRepo)
 	getAllSuppliersHandler := query.NewAllSuppliers(logger, db, supplierRepo)
+	getSupplierProductsHandler := query.NewSupplierProducts(logger, db, supplierRepo, supplierProductRepo)
 
 	err := mediatr.RegisterRequestHandler(createSupplierHandler)
 	if err != nil {
@@ -34,6 +44,21 @@ func NewService(logger *slog.Logger, db *gorm.DB, supplierRepo repository.Suppli
 		panic(err)
 	}
 
+	err = mediatr.RegisterRequestHandler(createSupplierProductHandler)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(updateSupplierProductHandler)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(deleteSupplierProductHandler)
+	if err != nil {
+		panic(err)
+	}
+
 	// Register query handlers
 	err = mediatr.RegisterRequestHandler(getSupplierHandler)
 	if err != nil {
@@ -44,4 +69,9 @@ func NewService(logger *slog.Logger, db *gorm.DB, supplierRepo repository.Suppli
 	if err != nil {
 		panic(err)
 	}
+
+	err = mediatr.RegisterRequestHandler(getSupplierProductsHandler)
+	if err != nil {
+		panic(err)
+	}
 }
@@ -0,0 +1,137 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/lib/cache"
+	"mini-erp-backend/model"
+	"mini-erp-backend/utils"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ChangeRole struct {
+	logger       *slog.Logger
+	db           *gorm.DB
+	userRepo     repository.User
+	roleRepo     repository.Role
+	sessionRepo  repository.UserSession
+	sessionCache *cache.Cache[string, model.UserSession]
+}
+
+type ChangeRoleRequest struct {
+	UserId           uuid.UUID `json:"-"`
+	ActorId          uuid.UUID `json:"-"`
+	ActorPermissions []string  `json:"-"`
+	Role             string    `json:"role"`
+}
+
+type ChangeRoleResult struct {
+	User            model.User `json:"user"`
+	RevokedSessions int64      `json:"revoked_sessions"`
+}
+
+func NewChangeRole(
+	logger *slog.Logger,
+	db *gorm.DB,
+	userRepo repository.User,
+	roleRepo repository.Role,
+	sessionRepo repository.UserSession,
+	sessionCache *cache.Cache[string, model.UserSession],
+) *ChangeRole {
+	return &ChangeRole{
+		logger:       logger,
+		db:           db,
+		userRepo:     userRepo,
+		roleRepo:     roleRepo,
+		sessionRepo:  sessionRepo,
+		sessionCache: sessionCache,
+	}
+}
+
+func (c *ChangeRole) Handle(ctx context.Context, request ChangeRoleRequest) (*ChangeRoleResult, error) {
+	role := model.Role(strings.ToLower(strings.TrimSpace(request.Role)))
+	target, err := c.roleRepo.SearchByName(c.db, role)
+	if err != nil {
+		if errors.Is(err, repository.ErrRoleNotFound) {
+			return nil, ErrInvalidRole
+		}
+		return nil, err
+	}
+
+	next := make([]string, 0, len(target.Permissions))
+	for _, p := range target.Permissions {
+		next = append(next, string(p))
+	}
+
+	// ห้ามให้ role ที่มีสิทธิ์เกินผู้สั่ง รวมถึงการเลื่อน role ตัวเอง
+	if err := checkWithinActor(request.ActorPermissions, next); err != nil {
+		return nil, err
+	}
+
+	user, err := findUser(c.db, c.logger, c.userRepo, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Role == role {
+		return &ChangeRoleResult{User: *user}, nil
+	}
+
+	current, err := c.roleRepo.PermissionsByRole(c.db, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	// ผู้ใช้ที่ถือ role สูงกว่าผู้สั่ง ถูกเปลี่ยน role ไม่ได้
+	if err := checkWithinActor(request.ActorPermissions, current); err != nil {
+		return nil, err
+	}
+
+	// access token เก็บสิทธิ์ไว้ใน claims การลดสิทธิ์จึงต้องยกเลิก session ที่ออกไปแล้ว
+	demoted := utils.MissingPermission(next, current) != ""
+	if demoted && request.UserId == request.ActorId {
+		return nil, ErrSelfModification
+	}
+
+	tx := c.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := c.userRepo.UpdateRole(tx, user.UserId, role); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var revoked int64
+	if demoted {
+		revoked, err = c.sessionRepo.RevokeByUserId(tx, user.UserId)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.logger.Error("Failed to commit role change", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	if demoted {
+		c.sessionCache.DeleteFunc(func(_ string, s model.UserSession) bool {
+			return s.UserId == user.UserId
+		})
+	}
+
+	c.logger.Info("User role changed", "user_id", user.UserId, "from", user.Role, "to", role, "revoked_sessions", revoked)
+
+	user.Role = role
+	return &ChangeRoleResult{User: *user, RevokedSessions: revoked}, nil
+}
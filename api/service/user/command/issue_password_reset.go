@@ -0,0 +1,140 @@
+package command
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/lib/cache"
+	"mini-erp-backend/model"
+	"mini-erp-backend/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// IssuePasswordReset creates a single-use reset token for a user and forces a password change at next login
+type IssuePasswordReset struct {
+	logger         *slog.Logger
+	db             *gorm.DB
+	userRepo       repository.User
+	roleRepo       repository.Role
+	sessionRepo    repository.UserSession
+	resetTokenRepo repository.PasswordResetToken
+	sessionCache   *cache.Cache[string, model.UserSession]
+	resetTokenTTL  time.Duration
+}
+
+type IssuePasswordResetRequest struct {
+	UserId           uuid.UUID
+	ActorId          uuid.UUID
+	ActorPermissions []string
+}
+
+type IssuePasswordResetResult struct {
+	UserId     uuid.UUID `json:"user_id"`
+	ResetToken string    `json:"reset_token"` // แสดงครั้งเดียว ในฐานข้อมูลเก็บแค่ hash
+	ExpireAt   time.Time `json:"expire_at"`
+}
+
+func NewIssuePasswordReset(
+	logger *slog.Logger,
+	db *gorm.DB,
+	userRepo repository.User,
+	roleRepo repository.Role,
+	sessionRepo repository.UserSession,
+	resetTokenRepo repository.PasswordResetToken,
+	sessionCache *cache.Cache[string, model.UserSession],
+	resetTokenTTL time.Duration,
+) *IssuePasswordReset {
+	return &IssuePasswordReset{
+		logger:         logger,
+		db:             db,
+		userRepo:       userRepo,
+		roleRepo:       roleRepo,
+		sessionRepo:    sessionRepo,
+		resetTokenRepo: resetTokenRepo,
+		sessionCache:   sessionCache,
+		resetTokenTTL:  resetTokenTTL,
+	}
+}
+
+func (i *IssuePasswordReset) Handle(ctx context.Context, request IssuePasswordResetRequest) (*IssuePasswordResetResult, error) {
+	user, err := findUser(i.db, i.logger, i.userRepo, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	// reset token ให้คนถือเข้าบัญชีนั้นได้ จึงออกให้ได้เฉพาะผู้ใช้ที่ role มีสิทธิ์ไม่เกินผู้สั่ง
+	permissions, err := i.roleRepo.PermissionsByRole(i.db, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkWithinActor(request.ActorPermissions, permissions); err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		i.logger.Error("Failed to generate reset token", slog.String("error", err.Error()))
+		return nil, err
+	}
+	token := hex.EncodeToString(raw)
+
+	resetToken := &model.PasswordResetToken{
+		ResetId:   uuid.New(),
+		UserId:    user.UserId,
+		Token:     utils.HashToken(token),
+		ExpireAt:  time.Now().Add(i.resetTokenTTL),
+		CreatedBy: request.ActorId,
+	}
+
+	tx := i.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// token ที่ออกก่อนหน้านี้และยังไม่ได้ใช้จะใช้ไม่ได้อีก
+	if err := i.resetTokenRepo.DeleteUnusedByUserId(tx, user.UserId); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := i.resetTokenRepo.Create(tx, resetToken); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := i.userRepo.UpdateMustChangePassword(tx, user.UserId, true); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// session เดิมไม่มี flag บังคับเปลี่ยนรหัสผ่าน จึงต้องให้ login ใหม่
+	if _, err := i.sessionRepo.RevokeByUserId(tx, user.UserId); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		i.logger.Error("Failed to commit password reset", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	i.sessionCache.DeleteFunc(func(_ string, s model.UserSession) bool {
+		return s.UserId == user.UserId
+	})
+
+	i.logger.Info("Password reset issued", "user_id", user.UserId, "issued_by", request.ActorId, "expire_at", resetToken.ExpireAt)
+
+	return &IssuePasswordResetResult{
+		UserId:     user.UserId,
+		ResetToken: token,
+		ExpireAt:   resetToken.ExpireAt,
+	}, nil
+}
@@ -0,0 +1,100 @@
+package command
+
+import (
+	"context"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/lib/cache"
+	"mini-erp-backend/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SetDisabled deactivates or reactivates a user, deactivation also revokes every active session
+type SetDisabled struct {
+	logger       *slog.Logger
+	db           *gorm.DB
+	userRepo     repository.User
+	sessionRepo  repository.UserSession
+	sessionCache *cache.Cache[string, model.UserSession]
+}
+
+type SetDisabledRequest struct {
+	UserId   uuid.UUID
+	ActorId  uuid.UUID
+	Disabled bool
+}
+
+type SetDisabledResult struct {
+	User            model.User `json:"user"`
+	RevokedSessions int64      `json:"revoked_sessions"`
+}
+
+func NewSetDisabled(
+	logger *slog.Logger,
+	db *gorm.DB,
+	userRepo repository.User,
+	sessionRepo repository.UserSession,
+	sessionCache *cache.Cache[string, model.UserSession],
+) *SetDisabled {
+	return &SetDisabled{
+		logger:       logger,
+		db:           db,
+		userRepo:     userRepo,
+		sessionRepo:  sessionRepo,
+		sessionCache: sessionCache,
+	}
+}
+
+func (s *SetDisabled) Handle(ctx context.Context, request SetDisabledRequest) (*SetDisabledResult, error) {
+	if request.Disabled && request.UserId == request.ActorId {
+		return nil, ErrSelfModification
+	}
+
+	user, err := findUser(s.db, s.logger, s.userRepo, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Disabled == request.Disabled {
+		return &SetDisabledResult{User: *user}, nil
+	}
+
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := s.userRepo.UpdateDisabled(tx, user.UserId, request.Disabled); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var revoked int64
+	if request.Disabled {
+		revoked, err = s.sessionRepo.RevokeByUserId(tx, user.UserId)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		s.logger.Error("Failed to commit user status change", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	if request.Disabled {
+		s.sessionCache.DeleteFunc(func(_ string, session model.UserSession) bool {
+			return session.UserId == user.UserId
+		})
+	}
+
+	s.logger.Info("User status changed", "user_id", user.UserId, "disabled", request.Disabled, "revoked_sessions", revoked)
+
+	user.Disabled = request.Disabled
+	return &SetDisabledResult{User: *user, RevokedSessions: revoked}, nil
+}
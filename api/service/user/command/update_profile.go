@@ -0,0 +1,62 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type UpdateProfile struct {
+	logger   *slog.Logger
+	db       *gorm.DB
+	userRepo repository.User
+}
+
+type UpdateProfileRequest struct {
+	UserId    uuid.UUID `json:"-"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+}
+
+type UpdateProfileResult struct {
+	User model.User `json:"user"`
+}
+
+func NewUpdateProfile(logger *slog.Logger, db *gorm.DB, userRepo repository.User) *UpdateProfile {
+	return &UpdateProfile{
+		logger:   logger,
+		db:       db,
+		userRepo: userRepo,
+	}
+}
+
+func (u *UpdateProfile) Handle(ctx context.Context, request UpdateProfileRequest) (*UpdateProfileResult, error) {
+	// normalize เหมือนตอน register
+	firstName := strings.ToLower(strings.TrimSpace(request.FirstName))
+	lastName := strings.ToLower(strings.TrimSpace(request.LastName))
+	if firstName == "" || lastName == "" {
+		return nil, errors.New("first name and last name are required")
+	}
+
+	if _, err := findUser(u.db, u.logger, u.userRepo, request.UserId); err != nil {
+		return nil, err
+	}
+
+	if err := u.userRepo.UpdateProfile(u.db, request.UserId, firstName, lastName); err != nil {
+		u.logger.Error("Failed to update user profile", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	user, err := findUser(u.db, u.logger, u.userRepo, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UpdateProfileResult{User: *user}, nil
+}
@@ -0,0 +1,43 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+	"mini-erp-backend/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidRole = errors.New("invalid role, the role does not exist")
+	// ErrSelfModification กันไม่ให้ admin ปิดบัญชีหรือลดสิทธิ์ตัวเองจนไม่มีใครเข้าระบบได้
+	ErrSelfModification = errors.New("cannot disable or demote your own account")
+	// ErrPrivilegeEscalation ผู้ใช้ที่มี user.manage จัดการได้เฉพาะ role ที่สิทธิ์ไม่เกินสิทธิ์ของตัวเอง
+	ErrPrivilegeEscalation = errors.New("cannot manage a user whose role has permissions you do not have")
+)
+
+// checkWithinActor rejects the action when the role permissions include one the actor's access token does not carry
+func checkWithinActor(actorPermissions, rolePermissions []string) error {
+	if missing := utils.MissingPermission(actorPermissions, rolePermissions); missing != "" {
+		return fmt.Errorf("%w: %s", ErrPrivilegeEscalation, missing)
+	}
+	return nil
+}
+
+// findUser returns the user or a "user not found" error the handlers map to 404
+func findUser(db *gorm.DB, logger *slog.Logger, userRepo repository.User, userId uuid.UUID) (*model.User, error) {
+	user, err := userRepo.SearchById(db, userId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Error("User not found", slog.String("user_id", userId.String()))
+			return nil, errors.New("user not found")
+		}
+		logger.Error("Failed to get user", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,50 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type UserById struct {
+	logger   *slog.Logger
+	db       *gorm.DB
+	userRepo repository.User
+}
+
+type UserByIdRequest struct {
+	UserId uuid.UUID `json:"user_id"`
+}
+
+type UserByIdResult struct {
+	User model.User `json:"user"`
+}
+
+func NewUserById(logger *slog.Logger, db *gorm.DB, userRepo repository.User) *UserById {
+	return &UserById{
+		logger:   logger,
+		db:       db,
+		userRepo: userRepo,
+	}
+}
+
+func (u *UserById) Handle(ctx context.Context, request UserByIdRequest) (*UserByIdResult, error) {
+	result, err := u.userRepo.SearchById(u.db, request.UserId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		u.logger.Error("Failed to get user by id", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	response := &UserByIdResult{
+		User: *result,
+	}
+	return response, nil
+}
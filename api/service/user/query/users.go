@@ -0,0 +1,107 @@
+package query
+
+import (
+	"context"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type Users struct {
+	logger   *slog.Logger
+	db       *gorm.DB
+	userRepo repository.User
+}
+
+type UsersRequest struct {
+	Page      int    `json:"page"`
+	PageSize  int    `json:"page_size"`
+	Search    string `json:"search"`     // ค้นหาจาก username ชื่อ และนามสกุล
+	Role      string `json:"role"`       // admin, staff หรือ viewer
+	Disabled  *bool  `json:"disabled"`   // กรองตามสถานะบัญชี
+	SortBy    string `json:"sort_by"`    // ฟิลด์ที่ต้องการ sort
+	SortOrder string `json:"sort_order"` // asc หรือ desc
+}
+
+type UsersResult struct {
+	Users      []model.User `json:"users"`
+	Total      int64        `json:"total"`
+	Page       int          `json:"page"`
+	PageSize   int          `json:"page_size"`
+	TotalPages int          `json:"total_pages"`
+}
+
+func NewUsers(logger *slog.Logger, db *gorm.DB, userRepo repository.User) *Users {
+	return &Users{
+		logger:   logger,
+		db:       db,
+		userRepo: userRepo,
+	}
+}
+
+func (u *Users) Handle(ctx context.Context, request UsersRequest) (*UsersResult, error) {
+	filters := repository.UserSearchFilters{
+		Search:   request.Search,
+		Role:     request.Role,
+		Disabled: request.Disabled,
+	}
+
+	// สร้าง orderBy string
+	orderBy := "created_at DESC" // default
+	if request.SortBy != "" {
+		// กำหนดฟิลด์ที่อนุญาตให้ sort
+		allowedSortFields := map[string]bool{
+			"username":   true,
+			"first_name": true,
+			"last_name":  true,
+			"role":       true,
+			"created_at": true,
+			"updated_at": true,
+		}
+
+		if allowedSortFields[request.SortBy] {
+			sortOrder := "DESC"
+			if request.SortOrder == "asc" || request.SortOrder == "ASC" {
+				sortOrder = "ASC"
+			}
+			orderBy = request.SortBy + " " + sortOrder
+		}
+	}
+
+	// ค่าเริ่มต้นของ pagination
+	page := request.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := request.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	result, total, err := u.userRepo.SearchWithFiltersAndPagination(u.db, filters, orderBy, page, pageSize)
+	if err != nil {
+		u.logger.Error("Failed to get users with pagination", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	// คำนวณจำนวนหน้าทั้งหมด
+	totalPages := 0
+	if total > 0 {
+		totalPages = int(total) / pageSize
+		if int(total)%pageSize > 0 {
+			totalPages++
+		}
+	}
+
+	response := &UsersResult{
+		Users:      result,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}
+
+	return response, nil
+}
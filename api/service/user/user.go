@@ -0,0 +1,62 @@
+package user
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/api/service/user/command"
+	"mini-erp-backend/api/service/user/query"
+	"mini-erp-backend/lib/cache"
+	"mini-erp-backend/model"
+	"time"
+
+	"github.com/mehdihadeli/go-mediatr"
+	"gorm.io/gorm"
+)
+
+func NewService(
+	logger *slog.Logger,
+	db *gorm.DB,
+	userRepo repository.User,
+	roleRepo repository.Role,
+	sessionRepo repository.UserSession,
+	resetTokenRepo repository.PasswordResetToken,
+	sessionCache *cache.Cache[string, model.UserSession],
+	resetTokenTTL time.Duration,
+) {
+	usersService := query.NewUsers(logger, db, userRepo)
+	userByIdService := query.NewUserById(logger, db, userRepo)
+	updateProfileService := command.NewUpdateProfile(logger, db, userRepo)
+	changeRoleService := command.NewChangeRole(logger, db, userRepo, roleRepo, sessionRepo, sessionCache)
+	setDisabledService := command.NewSetDisabled(logger, db, userRepo, sessionRepo, sessionCache)
+	issuePasswordResetService := command.NewIssuePasswordReset(logger, db, userRepo, roleRepo, sessionRepo, resetTokenRepo, sessionCache, resetTokenTTL)
+
+	err := mediatr.RegisterRequestHandler(usersService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(userByIdService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(updateProfileService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(changeRoleService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(setDisabledService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(issuePasswordResetService)
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,92 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Create struct {
+	logger        *slog.Logger
+	db            *gorm.DB
+	warehouseRepo repository.Warehouse
+}
+
+type CreateRequest struct {
+	Code      string  `json:"code"`
+	Name      string  `json:"name"`
+	Address   *string `json:"address"`
+	IsDefault bool    `json:"is_default"` // true = ใช้เป็นคลังหลักแทนคลังเดิม
+}
+
+type CreateResult struct {
+	Warehouse model.Warehouse `json:"warehouse"`
+}
+
+func NewCreate(logger *slog.Logger, db *gorm.DB, warehouseRepo repository.Warehouse) *Create {
+	return &Create{
+		logger:        logger,
+		db:            db,
+		warehouseRepo: warehouseRepo,
+	}
+}
+
+func (c *Create) Handle(ctx context.Context, request CreateRequest) (*CreateResult, error) {
+	// ตรวจสอบรหัสคลังซ้ำ
+	existed, err := c.warehouseRepo.ExitedByCode(c.db, request.Code, nil)
+	if err != nil {
+		c.logger.Error("Failed to check if warehouse code exists", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	if existed {
+		c.logger.Error("Warehouse code already exists", slog.String("code", request.Code))
+		return nil, errors.New("warehouse code already exists")
+	}
+
+	tx := c.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	warehouse := &model.Warehouse{
+		Code:      request.Code,
+		Name:      request.Name,
+		Address:   request.Address,
+		IsDefault: request.IsDefault,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if err := c.warehouseRepo.Create(tx, warehouse); err != nil {
+		tx.Rollback()
+		c.logger.Error("Failed to create warehouse", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	// มีคลังหลักได้คลังเดียว
+	if warehouse.IsDefault {
+		if err := c.warehouseRepo.ClearDefault(tx, warehouse.WarehouseId); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.logger.Error("Failed to commit transaction", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	response := &CreateResult{
+		Warehouse: *warehouse,
+	}
+
+	return response, nil
+}
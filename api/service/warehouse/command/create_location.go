@@ -0,0 +1,74 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CreateLocation struct {
+	logger        *slog.Logger
+	db            *gorm.DB
+	warehouseRepo repository.Warehouse
+}
+
+type CreateLocationRequest struct {
+	WarehouseId uuid.UUID `json:"warehouse_id"`
+	Code        string    `json:"code"` // เช่น A-01-03 (โซน-ชั้น-ช่อง)
+	Description *string   `json:"description"`
+}
+
+type CreateLocationResult struct {
+	Location model.Location `json:"location"`
+}
+
+func NewCreateLocation(logger *slog.Logger, db *gorm.DB, warehouseRepo repository.Warehouse) *CreateLocation {
+	return &CreateLocation{
+		logger:        logger,
+		db:            db,
+		warehouseRepo: warehouseRepo,
+	}
+}
+
+func (c *CreateLocation) Handle(ctx context.Context, request CreateLocationRequest) (*CreateLocationResult, error) {
+	if _, err := c.warehouseRepo.Resolve(c.db, &request.WarehouseId, nil); err != nil {
+		c.logger.Error("Failed to get warehouse", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	// รหัส location ต้องไม่ซ้ำภายในคลังเดียวกัน
+	existed, err := c.warehouseRepo.ExitedLocationByCode(c.db, request.WarehouseId, request.Code)
+	if err != nil {
+		c.logger.Error("Failed to check if location code exists", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	if existed {
+		c.logger.Error("Location code already exists", slog.String("code", request.Code))
+		return nil, errors.New("location code already exists in this warehouse")
+	}
+
+	location := &model.Location{
+		WarehouseId: request.WarehouseId,
+		Code:        request.Code,
+		Description: request.Description,
+		CreatedAt:   time.Now(),
+	}
+
+	if err := c.warehouseRepo.CreateLocation(c.db, location); err != nil {
+		c.logger.Error("Failed to create location", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	response := &CreateLocationResult{
+		Location: *location,
+	}
+
+	return response, nil
+}
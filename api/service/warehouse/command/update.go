@@ -0,0 +1,113 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrDefaultWarehouseRequired is returned when an update would leave no default warehouse
+var ErrDefaultWarehouseRequired = errors.New("a default warehouse is required, mark another warehouse as default instead")
+
+type Update struct {
+	logger        *slog.Logger
+	db            *gorm.DB
+	warehouseRepo repository.Warehouse
+}
+
+type UpdateRequest struct {
+	WarehouseId uuid.UUID `json:"warehouse_id"`
+	Code        string    `json:"code"`
+	Name        string    `json:"name"`
+	Address     *string   `json:"address"`
+	IsDefault   bool      `json:"is_default"`
+}
+
+type UpdateResult struct {
+	Warehouse model.Warehouse `json:"warehouse"`
+}
+
+func NewUpdate(logger *slog.Logger, db *gorm.DB, warehouseRepo repository.Warehouse) *Update {
+	return &Update{
+		logger:        logger,
+		db:            db,
+		warehouseRepo: warehouseRepo,
+	}
+}
+
+func (u *Update) Handle(ctx context.Context, request UpdateRequest) (*UpdateResult, error) {
+	condition := map[string]interface{}{
+		"warehouse_id": request.WarehouseId,
+	}
+
+	// ตรวจสอบว่า warehouse มีอยู่หรือไม่
+	warehouse, err := u.warehouseRepo.Search(u.db, condition, "")
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			u.logger.Error("Warehouse not found", slog.String("warehouse_id", request.WarehouseId.String()))
+			return nil, repository.ErrWarehouseNotFound
+		}
+		u.logger.Error("Failed to get warehouse", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	// ตรวจสอบรหัสคลังซ้ำ (ยกเว้นตัวเอง)
+	existed, err := u.warehouseRepo.ExitedByCode(u.db, request.Code, &request.WarehouseId)
+	if err != nil {
+		u.logger.Error("Failed to check if warehouse code exists", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	if existed {
+		u.logger.Error("Warehouse code already exists", slog.String("code", request.Code))
+		return nil, errors.New("warehouse code already exists")
+	}
+
+	if warehouse.IsDefault && !request.IsDefault {
+		return nil, ErrDefaultWarehouseRequired
+	}
+
+	tx := u.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// อัพเดทข้อมูล
+	warehouse.Code = request.Code
+	warehouse.Name = request.Name
+	warehouse.Address = request.Address
+	warehouse.IsDefault = request.IsDefault
+	warehouse.UpdatedAt = time.Now()
+
+	if err := u.warehouseRepo.Update(tx, warehouse); err != nil {
+		tx.Rollback()
+		u.logger.Error("Failed to update warehouse", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	if warehouse.IsDefault {
+		if err := u.warehouseRepo.ClearDefault(tx, warehouse.WarehouseId); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		u.logger.Error("Failed to commit transaction", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	response := &UpdateResult{
+		Warehouse: *warehouse,
+	}
+
+	return response, nil
+}
@@ -0,0 +1,50 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WarehouseById struct {
+	logger        *slog.Logger
+	db            *gorm.DB
+	warehouseRepo repository.Warehouse
+}
+
+type WarehouseByIdRequest struct {
+	WarehouseId uuid.UUID `json:"warehouse_id"`
+}
+
+type WarehouseByIdResult struct {
+	Warehouse model.Warehouse `json:"warehouse"`
+}
+
+func NewWarehouseById(logger *slog.Logger, db *gorm.DB, warehouseRepo repository.Warehouse) *WarehouseById {
+	return &WarehouseById{
+		logger:        logger,
+		db:            db,
+		warehouseRepo: warehouseRepo,
+	}
+}
+
+func (w *WarehouseById) Handle(ctx context.Context, request WarehouseByIdRequest) (*WarehouseByIdResult, error) {
+	result, err := w.warehouseRepo.SearchWithLocations(w.db, request.WarehouseId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrWarehouseNotFound
+		}
+		w.logger.Error("Failed to get warehouse by id", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	response := &WarehouseByIdResult{
+		Warehouse: *result,
+	}
+	return response, nil
+}
@@ -0,0 +1,52 @@
+package query
+
+import (
+	"context"
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type Warehouses struct {
+	logger        *slog.Logger
+	db            *gorm.DB
+	warehouseRepo repository.Warehouse
+}
+
+type WarehousesRequest struct {
+	Search string `json:"search"` // ค้นหาจากรหัสและชื่อคลัง
+}
+
+type WarehousesResult struct {
+	Warehouses []model.Warehouse `json:"warehouses"`
+	Total      int64             `json:"total"`
+}
+
+func NewWarehouses(logger *slog.Logger, db *gorm.DB, warehouseRepo repository.Warehouse) *Warehouses {
+	return &Warehouses{
+		logger:        logger,
+		db:            db,
+		warehouseRepo: warehouseRepo,
+	}
+}
+
+func (w *Warehouses) Handle(ctx context.Context, request WarehousesRequest) (*WarehousesResult, error) {
+	filters := repository.WarehouseSearchFilters{
+		Search: request.Search,
+	}
+
+	// คลังหลักขึ้นก่อนเสมอ
+	result, err := w.warehouseRepo.SearchWithFilters(w.db, filters, "is_default DESC, code ASC")
+	if err != nil {
+		w.logger.Error("Failed to get warehouses", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	response := &WarehousesResult{
+		Warehouses: result,
+		Total:      int64(len(result)),
+	}
+	return response, nil
+}
@@ -0,0 +1,44 @@
+package warehouse
+
+import (
+	"log/slog"
+	"mini-erp-backend/api/repository"
+	"mini-erp-backend/api/service/warehouse/command"
+	"mini-erp-backend/api/service/warehouse/query"
+
+	"github.com/mehdihadeli/go-mediatr"
+	"gorm.io/gorm"
+)
+
+func NewService(logger *slog.Logger, db *gorm.DB, warehouseRepo repository.Warehouse) {
+	warehousesService := query.NewWarehouses(logger, db, warehouseRepo)
+	warehouseByIdService := query.NewWarehouseById(logger, db, warehouseRepo)
+	createWarehouseService := command.NewCreate(logger, db, warehouseRepo)
+	updateWarehouseService := command.NewUpdate(logger, db, warehouseRepo)
+	createLocationService := command.NewCreateLocation(logger, db, warehouseRepo)
+
+	err := mediatr.RegisterRequestHandler(warehousesService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(warehouseByIdService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(createWarehouseService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(updateWarehouseService)
+	if err != nil {
+		panic(err)
+	}
+
+	err = mediatr.RegisterRequestHandler(createLocationService)
+	if err != nil {
+		panic(err)
+	}
+}
@@ -13,6 +13,30 @@ var (
 	RefreshTokenExpMinsKey = "LOGIN_REFRESH_EXP_MINS"
 	AllowOriginKey         = "ALLOW_ORIGINS"
 	AllowCredentialKey     = "ALLOW_CREDENTIALS"
+	SessionCacheTTLSecsKey = "SESSION_CACHE_TTL_SECS"
+	CostingMethodKey       = "COSTING_METHOD"
+	BusinessTimezoneKey    = "BUSINESS_TIMEZONE"
+
+	PasswordMinLengthKey         = "PASSWORD_MIN_LENGTH"
+	PasswordRequireUpperKey      = "PASSWORD_REQUIRE_UPPER"
+	PasswordRequireLowerKey      = "PASSWORD_REQUIRE_LOWER"
+	PasswordRequireDigitKey      = "PASSWORD_REQUIRE_DIGIT"
+	PasswordRequireSymbolKey     = "PASSWORD_REQUIRE_SYMBOL"
+	PasswordResetTokenExpMinsKey = "PASSWORD_RESET_TOKEN_EXP_MINS"
+
+	LoginAttemptWindowMinsKey   = "LOGIN_ATTEMPT_WINDOW_MINS"
+	LoginBackoffAfterKey        = "LOGIN_BACKOFF_AFTER"
+	LoginBackoffBaseSecsKey     = "LOGIN_BACKOFF_BASE_SECS"
+	LoginBackoffMaxSecsKey      = "LOGIN_BACKOFF_MAX_SECS"
+	LoginLockoutAfterKey        = "LOGIN_LOCKOUT_AFTER"
+	LoginIPLockoutAfterKey      = "LOGIN_IP_LOCKOUT_AFTER"
+	LoginLockoutDurationMinsKey = "LOGIN_LOCKOUT_DURATION_MINS"
+
+	MfaIssuerKey               = "MFA_ISSUER"
+	MfaRequireAdminKey         = "MFA_REQUIRE_ADMIN"
+	MfaChallengeExpMinsKey     = "MFA_CHALLENGE_EXP_MINS"
+	MfaChallengeMaxAttemptsKey = "MFA_CHALLENGE_MAX_ATTEMPTS"
+	MfaSecretKey               = "MFA_SECRET_KEY"
 )
 
 func LoadEnvironment() {
@@ -27,6 +51,28 @@ func LoadEnvironment() {
 	}
 
 	viper.AutomaticEnv()
+
+	viper.SetDefault(SessionCacheTTLSecsKey, 30)
+	viper.SetDefault(CostingMethodKey, "FIFO")
+	viper.SetDefault(BusinessTimezoneKey, "Local")
+	viper.SetDefault(PasswordMinLengthKey, 8)
+	viper.SetDefault(PasswordRequireUpperKey, true)
+	viper.SetDefault(PasswordRequireLowerKey, true)
+	viper.SetDefault(PasswordRequireDigitKey, true)
+	viper.SetDefault(PasswordRequireSymbolKey, false)
+	viper.SetDefault(PasswordResetTokenExpMinsKey, 60)
+	viper.SetDefault(LoginAttemptWindowMinsKey, 15)
+	viper.SetDefault(LoginBackoffAfterKey, 3)
+	viper.SetDefault(LoginBackoffBaseSecsKey, 1)
+	viper.SetDefault(LoginBackoffMaxSecsKey, 60)
+	viper.SetDefault(LoginLockoutAfterKey, 5)
+	viper.SetDefault(LoginIPLockoutAfterKey, 50)
+	viper.SetDefault(LoginLockoutDurationMinsKey, 15)
+	viper.SetDefault(MfaIssuerKey, "mini-ERP")
+	viper.SetDefault(MfaRequireAdminKey, false)
+	viper.SetDefault(MfaChallengeExpMinsKey, 5)
+	viper.SetDefault(MfaChallengeMaxAttemptsKey, 5)
+	viper.SetDefault(MfaSecretKey, "")
 }
 
 func GetString(key string) string {
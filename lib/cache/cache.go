@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+type entry[V any] struct {
+	value    V
+	expireAt time.Time
+}
+
+// Cache is a small in-memory key/value store whose entries expire after a fixed TTL
+type Cache[K comparable, V any] struct {
+	mu      sync.RWMutex
+	ttl     time.Duration
+	entries map[K]entry[V]
+}
+
+func New[K comparable, V any](ttl time.Duration) *Cache[K, V] {
+	return &Cache[K, V]{
+		ttl:     ttl,
+		entries: make(map[K]entry[V]),
+	}
+}
+
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.RLock()
+	e, ok := c.entries[key]
+	c.mu.RUnlock()
+
+	if !ok || time.Now().After(e.expireAt) {
+		var zero V
+		return zero, false
+	}
+
+	return e.value, true
+}
+
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// ลบ entry ที่หมดอายุแล้วไปพร้อมกับการเขียน เพื่อไม่ให้ map โตขึ้นเรื่อย ๆ
+	now := time.Now()
+	for k, e := range c.entries {
+		if now.After(e.expireAt) {
+			delete(c.entries, k)
+		}
+	}
+
+	c.entries[key] = entry[V]{value: value, expireAt: now.Add(c.ttl)}
+}
+
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+}
+
+// DeleteFunc removes every entry for which match returns true
+func (c *Cache[K, V]) DeleteFunc(match func(key K, value V) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, e := range c.entries {
+		if match(k, e.value) {
+			delete(c.entries, k)
+		}
+	}
+}
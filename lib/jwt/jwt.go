@@ -26,10 +26,11 @@ type LoginTokenDetail struct {
 }
 
 type LoginAccessClaims struct {
-	AccessUuid string    `json:"access_uuid"`
-	Authorized bool      `json:"authorized"`
-	UserId     uuid.UUID `json:"user_id"`
-	Role       string    `json:"role"`
+	AccessUuid  string    `json:"access_uuid"`
+	Authorized  bool      `json:"authorized"`
+	UserId      uuid.UUID `json:"user_id"`
+	Role        string    `json:"role"`
+	Permissions []string  `json:"permissions"`
 	jwt.RegisteredClaims
 }
 
@@ -53,11 +54,11 @@ type manager struct {
 }
 
 type Manager interface {
-	GenerateLoginToken(userId uuid.UUID, role string) (*LoginTokenDetail, error)
+	GenerateLoginToken(userId uuid.UUID, role string, permissions []string) (*LoginTokenDetail, error)
 	GetAccessTokenFromContext(c *fiber.Ctx) (token string, err error)
 	ExtractAccessToken(tokenStr string) (*LoginAccessClaims, error)
 	ExtractRefreshToken(tokenStr string) (*LoginRefreshClaims, error)
-	GenerateAccessToken(userId uuid.UUID, role string) (*LoginTokenDetail, error)
+	GenerateAccessToken(userId uuid.UUID, role string, permissions []string) (*LoginTokenDetail, error)
 }
 
 func New(logger *slog.Logger) Manager {
@@ -111,7 +112,8 @@ func (m *manager) validateToken(secret string) jwt.Keyfunc {
 	}
 }
 
-func (m *manager) GenerateLoginToken(userId uuid.UUID, role string) (*LoginTokenDetail, error) {
+// GenerateLoginToken creates an access token carrying the resolved permissions of the role, and a refresh token
+func (m *manager) GenerateLoginToken(userId uuid.UUID, role string, permissions []string) (*LoginTokenDetail, error) {
 	if userId == uuid.Nil {
 		errMsg := "UserID is empty"
 		if m.logger != nil {
@@ -138,10 +140,11 @@ func (m *manager) GenerateLoginToken(userId uuid.UUID, role string) (*LoginToken
 	accessUUID := uuid.New().String()
 	authorized := true
 	loginAccessClaims := LoginAccessClaims{
-		AccessUuid: accessUUID,
-		Authorized: authorized,
-		UserId:     userId,
-		Role:       role,
+		AccessUuid:  accessUUID,
+		Authorized:  authorized,
+		UserId:      userId,
+		Role:        role,
+		Permissions: permissions,
 		RegisteredClaims: jwt.RegisteredClaims{
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			ExpiresAt: jwt.NewNumericDate(accessExp),
@@ -190,7 +193,7 @@ func (m *manager) GenerateLoginToken(userId uuid.UUID, role string) (*LoginToken
 }
 
 // GenerateAccessToken creates only an access token (no refresh token)
-func (m *manager) GenerateAccessToken(userId uuid.UUID, role string) (*LoginTokenDetail, error) {
+func (m *manager) GenerateAccessToken(userId uuid.UUID, role string, permissions []string) (*LoginTokenDetail, error) {
 	if userId == uuid.Nil {
 		errMsg := "UserID is empty"
 		if m.logger != nil {
@@ -210,10 +213,11 @@ func (m *manager) GenerateAccessToken(userId uuid.UUID, role string) (*LoginToke
 
 	accessUUID := uuid.New().String()
 	loginAccessClaims := LoginAccessClaims{
-		AccessUuid: accessUUID,
-		Authorized: true,
-		UserId:     userId,
-		Role:       role,
+		AccessUuid:  accessUUID,
+		Authorized:  true,
+		UserId:      userId,
+		Role:        role,
+		Permissions: permissions,
 		RegisteredClaims: jwt.RegisteredClaims{
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			ExpiresAt: jwt.NewNumericDate(accessExp),
@@ -0,0 +1,81 @@
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// prefix marks a value sealed by Box, so plaintext written before encryption existed can be told apart
+const prefix = "enc:v1:"
+
+var (
+	ErrEmptyKey = errors.New("secret key is empty")
+	// ErrNotSealed is returned by Open for a value that was not sealed by Box
+	ErrNotSealed = errors.New("value is not sealed")
+	// ErrInvalidSealed is returned by Open for a sealed value that is corrupt or sealed with another key
+	ErrInvalidSealed = errors.New("sealed value is invalid")
+)
+
+// Box encrypts values stored at rest (e.g. TOTP secrets) with AES-256-GCM.
+// The key is the SHA-256 of the configured passphrase.
+type Box struct {
+	aead cipher.AEAD
+}
+
+func New(key string) (*Box, error) {
+	if key == "" {
+		return nil, ErrEmptyKey
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Box{aead: aead}, nil
+}
+
+// IsSealed reports whether value was produced by Seal
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// Seal encrypts plaintext with a random nonce and returns it as prefix + base64(nonce || ciphertext)
+func (b *Box) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return prefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value returned by Seal
+func (b *Box) Open(value string) (string, error) {
+	if !IsSealed(value) {
+		return "", ErrNotSealed
+	}
+
+	raw, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(value, prefix))
+	if err != nil || len(raw) < b.aead.NonceSize() {
+		return "", ErrInvalidSealed
+	}
+
+	nonce, ciphertext := raw[:b.aead.NonceSize()], raw[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", ErrInvalidSealed
+	}
+	return string(plaintext), nil
+}
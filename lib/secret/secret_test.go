@@ -0,0 +1,67 @@
+package secret
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSealOpenRoundTrip(t *testing.T) {
+	box, err := New("passphrase")
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+
+	sealed, err := box.Seal("JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatalf("Seal error: %v", err)
+	}
+	if !IsSealed(sealed) || sealed == "JBSWY3DPEHPK3PXP" {
+		t.Fatalf("Seal = %q, want a sealed value", sealed)
+	}
+
+	again, _ := box.Seal("JBSWY3DPEHPK3PXP")
+	if again == sealed {
+		t.Fatalf("Seal returned the same value twice, want a random nonce")
+	}
+
+	plaintext, err := box.Open(sealed)
+	if err != nil || plaintext != "JBSWY3DPEHPK3PXP" {
+		t.Fatalf("Open = %q, %v, want the original value", plaintext, err)
+	}
+}
+
+func TestOpenRejects(t *testing.T) {
+	box, _ := New("passphrase")
+	other, _ := New("another passphrase")
+
+	sealed, err := box.Seal("JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatalf("Seal error: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		value string
+		want  error
+	}{
+		{"plaintext", "JBSWY3DPEHPK3PXP", ErrNotSealed},
+		{"tampered", sealed[:len(sealed)-2] + "AA", ErrInvalidSealed},
+		{"truncated", prefix + "AAAA", ErrInvalidSealed},
+		{"not base64", prefix + "!!!", ErrInvalidSealed},
+	}
+	for _, tt := range tests {
+		if _, err := box.Open(tt.value); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Open error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	if _, err := other.Open(sealed); !errors.Is(err, ErrInvalidSealed) {
+		t.Errorf("other key: Open error = %v, want %v", err, ErrInvalidSealed)
+	}
+}
+
+func TestNewRequiresKey(t *testing.T) {
+	if _, err := New(""); !errors.Is(err, ErrEmptyKey) {
+		t.Fatalf("New(\"\") error = %v, want %v", err, ErrEmptyKey)
+	}
+}
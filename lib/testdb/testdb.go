@@ -0,0 +1,126 @@
+// Package testdb opens a *gorm.DB on an in-memory database/sql driver for unit tests that run without Postgres.
+// Every statement succeeds, transactions begin, commit and roll back, and a query returns the rows of the first
+// Result whose Contains is part of its SQL, or no rows.
+package testdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Result is the canned answer to every query whose SQL contains Contains
+type Result struct {
+	Contains string
+	Columns  []string
+	Rows     [][]driver.Value
+}
+
+// Open returns a gorm DB with the postgres dialect on the fake driver, closed when the test ends
+func Open(t testing.TB, results ...Result) *gorm.DB {
+	t.Helper()
+
+	sqlDB := sql.OpenDB(connector{results: results})
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger:                 logger.Discard,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open error: %v", err)
+	}
+	return db
+}
+
+type connector struct {
+	results []Result
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{results: c.results}, nil
+}
+
+func (c connector) Driver() driver.Driver {
+	return fakeDriver{results: c.results}
+}
+
+type fakeDriver struct {
+	results []Result
+}
+
+func (d fakeDriver) Open(string) (driver.Conn, error) {
+	return &conn{results: d.results}, nil
+}
+
+type conn struct {
+	results []Result
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{conn: c, query: query}, nil
+}
+
+func (c *conn) Close() error { return nil }
+
+func (c *conn) Begin() (driver.Tx, error) { return tx{}, nil }
+
+// result returns the canned rows for query
+func (c *conn) result(query string) *rows {
+	for _, result := range c.results {
+		if strings.Contains(query, result.Contains) {
+			return &rows{columns: result.Columns, values: result.Rows}
+		}
+	}
+	return &rows{}
+}
+
+type tx struct{}
+
+func (tx) Commit() error   { return nil }
+func (tx) Rollback() error { return nil }
+
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+func (s *stmt) Close() error { return nil }
+
+// NumInput returns -1 so database/sql does not check the argument count
+func (s *stmt) NumInput() int { return -1 }
+
+func (s *stmt) Exec([]driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+func (s *stmt) Query([]driver.Value) (driver.Rows, error) {
+	return s.conn.result(s.query), nil
+}
+
+type rows struct {
+	columns []string
+	values  [][]driver.Value
+	next    int
+}
+
+func (r *rows) Columns() []string { return r.columns }
+
+func (r *rows) Close() error { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.next >= len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.next])
+	r.next++
+	return nil
+}
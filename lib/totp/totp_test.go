@@ -0,0 +1,85 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 seed of RFC 6238 Appendix B, base32 encoded
+var rfc6238Secret = encoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestGenerateRFC6238Vectors(t *testing.T) {
+	// Appendix B lists 8 digit codes, a 6 digit code is the same value mod 10^6 so it is the last 6 digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := Code(rfc6238Secret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("Code(%d) error: %v", tt.unix, err)
+		}
+		if want := tt.want[len(tt.want)-Digits:]; got != want {
+			t.Errorf("Code(%d) = %s, want %s", tt.unix, got, want)
+		}
+	}
+}
+
+func TestValidateAcceptsSkew(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+
+	tests := []struct {
+		steps int64
+		ok    bool
+	}{
+		{-2, false},
+		{-1, true},
+		{0, true},
+		{1, true},
+		{2, false},
+	}
+
+	for _, tt := range tests {
+		code, err := Code(rfc6238Secret, now.Add(time.Duration(tt.steps)*Period))
+		if err != nil {
+			t.Fatalf("Code error: %v", err)
+		}
+
+		counter, ok := Validate(rfc6238Secret, code, now)
+		if ok != tt.ok {
+			t.Errorf("step %+d: Validate ok = %v, want %v", tt.steps, ok, tt.ok)
+			continue
+		}
+		if ok && counter != Counter(now)+tt.steps {
+			t.Errorf("step %+d: counter = %d, want %d", tt.steps, counter, Counter(now)+tt.steps)
+		}
+	}
+}
+
+func TestValidateRejectsMalformedInput(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+	}{
+		{"short code", rfc6238Secret, "12345"},
+		{"long code", rfc6238Secret, "1234567"},
+		{"invalid secret", "not base32!", "123456"},
+	}
+
+	for _, tt := range tests {
+		if _, ok := Validate(tt.secret, tt.code, now); ok {
+			t.Errorf("%s: Validate accepted %q", tt.name, tt.code)
+		}
+	}
+}
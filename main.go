@@ -3,19 +3,33 @@ package main
 import (
 	"fmt"
 	"mini-erp-backend/api"
+	"mini-erp-backend/api/service/audit_log"
 	"mini-erp-backend/api/service/auth"
 	"mini-erp-backend/api/service/category"
+	"mini-erp-backend/api/service/customer"
 	"mini-erp-backend/api/service/product"
 	"mini-erp-backend/api/service/purchase_order"
 	"mini-erp-backend/api/service/register"
+	"mini-erp-backend/api/service/replenishment"
 	"mini-erp-backend/api/service/report"
+	"mini-erp-backend/api/service/role"
+	"mini-erp-backend/api/service/sales_order"
+	"mini-erp-backend/api/service/serial_number"
+	"mini-erp-backend/api/service/stock_count"
+	"mini-erp-backend/api/service/stock_period"
 	"mini-erp-backend/api/service/stock_transaction"
 	"mini-erp-backend/api/service/supplier"
+	"mini-erp-backend/api/service/user"
+	"mini-erp-backend/api/service/warehouse"
 	"mini-erp-backend/config/database"
 	"mini-erp-backend/config/environment"
+	"mini-erp-backend/lib/cache"
 	"mini-erp-backend/lib/jwt"
 	"mini-erp-backend/lib/logging"
+	"mini-erp-backend/lib/secret"
 	"mini-erp-backend/model"
+	"mini-erp-backend/utils"
+	"time"
 
 	"mini-erp-backend/api/repository"
 	_ "mini-erp-backend/docs"
@@ -24,6 +38,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/swagger"
+	"gorm.io/gorm"
 )
 
 // Main function
@@ -59,40 +74,258 @@ func main() {
 	// region Repository
 	categoryRepo := repository.NewCategory(log.Slogger)
 	productRepo := repository.NewProduct(log.Slogger)
-	stockTransactionRepo := repository.NewStockTransaction(log.Slogger)
+	unitRepo := repository.NewUnitOfMeasure(log.Slogger)
+	stockBalanceRepo := repository.NewStockBalance(log.Slogger)
+	warehouseRepo := repository.NewWarehouse(log.Slogger)
+	costLayerRepo := repository.NewCostLayer(log.Slogger, model.CostingMethod(environment.GetString(environment.CostingMethodKey)))
+	stockPeriodRepo := repository.NewStockPeriod(log.Slogger)
+	stockTransactionRepo := repository.NewStockTransaction(log.Slogger, stockBalanceRepo, costLayerRepo, stockPeriodRepo)
+	stockTransferRepo := repository.NewStockTransfer(log.Slogger)
+	stockCountRepo := repository.NewStockCount(log.Slogger)
+	lotRepo := repository.NewLot(log.Slogger)
+	serialRepo := repository.NewSerialNumber(log.Slogger)
 	supplierRepo := repository.NewSupplier(log.Slogger)
+	supplierProductRepo := repository.NewSupplierProduct(log.Slogger)
 	purchase_orderRepo := repository.NewPurchaseOrder(log.Slogger)
+	goodsReceiptRepo := repository.NewGoodsReceipt(log.Slogger)
+	salesOrderRepo := repository.NewSalesOrder(log.Slogger)
+	customerRepo := repository.NewCustomer(log.Slogger)
 	reportRepo := repository.NewReport(log.Slogger)
+	replenishmentRepo := repository.NewReplenishment(log.Slogger)
 	userRepo := repository.NewUser(log.Slogger)
 	sessionRepo := repository.NewUserSession(log.Slogger)
+	refreshTokenRepo := repository.NewRefreshToken(log.Slogger)
+	auditLogRepo := repository.NewAuditLog(log.Slogger)
+	resetTokenRepo := repository.NewPasswordResetToken(log.Slogger)
+	loginAttemptRepo := repository.NewLoginAttempt(log.Slogger)
+	mfaRepo := repository.NewMfa(log.Slogger)
+	roleRepo := repository.NewRole(log.Slogger)
+
+	// cache ของ session ที่ middleware ใช้ตรวจ access token ทุก request
+	sessionCache := cache.New[string, model.UserSession](
+		time.Duration(environment.GetInt(environment.SessionCacheTTLSecsKey)) * time.Second,
+	)
+
+	passwordPolicy := utils.PasswordPolicy{
+		MinLength:     environment.GetInt(environment.PasswordMinLengthKey),
+		RequireUpper:  environment.GetBool(environment.PasswordRequireUpperKey),
+		RequireLower:  environment.GetBool(environment.PasswordRequireLowerKey),
+		RequireDigit:  environment.GetBool(environment.PasswordRequireDigitKey),
+		RequireSymbol: environment.GetBool(environment.PasswordRequireSymbolKey),
+	}
+	resetTokenTTL := time.Duration(environment.GetInt(environment.PasswordResetTokenExpMinsKey)) * time.Minute
+	loginThrottle := utils.LoginThrottle{
+		Window:          time.Duration(environment.GetInt(environment.LoginAttemptWindowMinsKey)) * time.Minute,
+		BackoffAfter:    int64(environment.GetInt(environment.LoginBackoffAfterKey)),
+		BackoffBase:     time.Duration(environment.GetInt(environment.LoginBackoffBaseSecsKey)) * time.Second,
+		BackoffMax:      time.Duration(environment.GetInt(environment.LoginBackoffMaxSecsKey)) * time.Second,
+		LockoutAfter:    int64(environment.GetInt(environment.LoginLockoutAfterKey)),
+		IPLockoutAfter:  int64(environment.GetInt(environment.LoginIPLockoutAfterKey)),
+		LockoutDuration: time.Duration(environment.GetInt(environment.LoginLockoutDurationMinsKey)) * time.Minute,
+	}
+	// TOTP secret ถูกเข้ารหัสด้วย MFA_SECRET_KEY ถ้าไม่ได้ตั้งจะใช้ secret ของ access token แทน
+	mfaSecretKey := environment.GetString(environment.MfaSecretKey)
+	if mfaSecretKey == "" {
+		log.Slogger.Warn(environment.MfaSecretKey + " is not set, encrypting MFA secrets with " + environment.AccessTokenSecretKey)
+		mfaSecretKey = environment.GetString(environment.AccessTokenSecretKey)
+	}
+	mfaSecretBox, err := secret.New(mfaSecretKey)
+	if err != nil {
+		panic("invalid " + environment.MfaSecretKey + ": " + err.Error())
+	}
+	mfaPolicy := utils.MfaPolicy{
+		Issuer:               environment.GetString(environment.MfaIssuerKey),
+		RequireForAdmin:      environment.GetBool(environment.MfaRequireAdminKey),
+		ChallengeTTL:         time.Duration(environment.GetInt(environment.MfaChallengeExpMinsKey)) * time.Minute,
+		ChallengeMaxAttempts: int64(environment.GetInt(environment.MfaChallengeMaxAttemptsKey)),
+		SecretBox:            mfaSecretBox,
+	}
+	// เขตเวลาที่ใช้ตีความวันที่ทางธุรกิจ ทั้งการปิดงวดและ as_of ของรายงาน
+	businessLocation, err := time.LoadLocation(environment.GetString(environment.BusinessTimezoneKey))
+	if err != nil {
+		panic("invalid " + environment.BusinessTimezoneKey + ": " + err.Error())
+	}
 	// endregion
 
 	// region Service
 	category.NewService(log.Slogger, db, categoryRepo)
-	product.NewService(log.Slogger, db, productRepo, stockTransactionRepo)
-	stock_transaction.NewService(log.Slogger, db, stockTransactionRepo, productRepo)
-	purchase_order.NewService(db, log.Slogger, purchase_orderRepo)
-	supplier.NewService(log.Slogger, db, supplierRepo)
+	customer.NewService(log.Slogger, db, customerRepo)
+	product.NewService(log.Slogger, db, productRepo, stockBalanceRepo, lotRepo, supplierRepo, unitRepo)
+	stock_transaction.NewService(log.Slogger, db, stockTransactionRepo, stockBalanceRepo, productRepo, warehouseRepo, stockTransferRepo, lotRepo, serialRepo, unitRepo)
+	purchase_order.NewService(db, log.Slogger, purchase_orderRepo, goodsReceiptRepo, stockTransactionRepo, productRepo, warehouseRepo, lotRepo, serialRepo, supplierProductRepo, unitRepo)
+	sales_order.NewService(db, log.Slogger, salesOrderRepo, stockTransactionRepo, stockBalanceRepo, productRepo, customerRepo, warehouseRepo, lotRepo, serialRepo)
+	supplier.NewService(log.Slogger, db, supplierRepo, supplierProductRepo, productRepo)
+	warehouse.NewService(log.Slogger, db, warehouseRepo)
+	serial_number.NewService(log.Slogger, db, serialRepo, goodsReceiptRepo, salesOrderRepo)
+	stock_period.NewService(log.Slogger, db, stockPeriodRepo, businessLocation)
+	replenishment.NewService(log.Slogger, db, replenishmentRepo)
+	stock_count.NewService(log.Slogger, db, stockCountRepo, stockTransactionRepo, stockBalanceRepo, warehouseRepo, lotRepo, serialRepo)
 	report.NewService(log.Slogger, db, reportRepo)
-	auth.NewService(db, log.Slogger, jwtManager, userRepo)
-	register.NewService(db, log.Slogger, jwtManager, userRepo)
+	auth.NewService(db, log.Slogger, jwtManager, userRepo, sessionRepo, refreshTokenRepo, auditLogRepo, resetTokenRepo, loginAttemptRepo, mfaRepo, roleRepo, sessionCache, passwordPolicy, loginThrottle, mfaPolicy)
+	register.NewService(db, log.Slogger, jwtManager, userRepo, roleRepo, passwordPolicy)
+	user.NewService(log.Slogger, db, userRepo, roleRepo, sessionRepo, resetTokenRepo, sessionCache, resetTokenTTL)
+	audit_log.NewService(log.Slogger, db, auditLogRepo)
+	role.NewService(log.Slogger, db, roleRepo, userRepo, sessionRepo, sessionCache)
 
 	// endregion
 
+	// warehouses must exist before the ledger gets its NOT NULL warehouse_id, rows written before that go to the default warehouse
+	if err := db.AutoMigrate(&model.Warehouse{}, &model.Location{}); err != nil {
+		log.Slogger.Error("Warehouse migration failed", "error", err)
+	}
+	if defaultWarehouse, err := warehouseRepo.EnsureDefault(db); err != nil {
+		log.Slogger.Error("Failed to create default warehouse", "error", err)
+	} else {
+		for _, table := range []string{"stock_transactions", "goods_receipts"} {
+			if db.Migrator().HasTable(table) && !db.Migrator().HasColumn(table, "warehouse_id") {
+				if _, err := warehouseRepo.AssignDefaultWarehouse(db, table, defaultWarehouse.WarehouseId); err != nil {
+					log.Slogger.Error("Failed to assign default warehouse", "table", table, "error", err)
+				}
+			}
+		}
+	}
+
+	// money used to be stored as double precision, convert it to numeric before AutoMigrate
+	// rounding to the column's scale so float noise such as 12.2999999 is not carried over
+	for _, money := range []struct {
+		table, column string
+		precision     int
+		scale         int
+	}{
+		{"supplier_products", "unit_price", 15, 4},
+		{"sales_order_items", "price", 15, 4},
+		{"customers", "credit_limit", 15, 2},
+	} {
+		var dataType string
+		if err := db.Raw("SELECT data_type FROM information_schema.columns WHERE table_schema = CURRENT_SCHEMA() AND table_name = ? AND column_name = ?", money.table, money.column).
+			Scan(&dataType).Error; err != nil || dataType != "double precision" {
+			continue
+		}
+		if err := db.Exec(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE numeric(%d,%d) USING ROUND(%s::numeric, %d)",
+			money.table, money.column, money.precision, money.scale, money.column, money.scale)).Error; err != nil {
+			log.Slogger.Error("Failed to convert money column to numeric", "table", money.table, "column", money.column, "error", err)
+		}
+	}
+
+	// stock_balances used to be keyed by product only, drop it so it is rebuilt per warehouse below
+	if db.Migrator().HasTable(&model.StockBalance{}) && !db.Migrator().HasColumn(&model.StockBalance{}, "WarehouseId") {
+		if err := db.Migrator().DropTable(&model.StockBalance{}); err != nil {
+			log.Slogger.Error("Failed to drop stock balances", "error", err)
+		}
+	}
+
+	// stock_balances is a projection of the ledger, fill it once when the table is first created
+	// or when it gains the movement totals
+	needsStockBalanceRebuild := !db.Migrator().HasTable(&model.StockBalance{}) || !db.Migrator().HasColumn(&model.StockBalance{}, "TotalIn")
+	// orders received before goods receipts existed must show their items as fully received
+	needsReceivedBackfill := !db.Migrator().HasColumn(&model.PurchaseOrderItem{}, "ReceivedQuantity")
+	// totals were not stored before, compute them once from the items
+	needsTotalsBackfill := !db.Migrator().HasColumn(&model.PurchaseOrder{}, "TotalAmount")
+	// the ledger was not costed before cost layers existed, value it once at the product cost price
+	needsCostBackfill := db.Migrator().HasTable(&model.StockTransaction{}) && !db.Migrator().HasTable(&model.CostLayer{})
+	// serial numbers did not keep their lot before, take it from their latest movement once
+	needsSerialLotBackfill := db.Migrator().HasTable(&model.SerialNumber{}) && !db.Migrator().HasColumn(&model.SerialNumber{}, "LotId")
+
 	if err := db.AutoMigrate(
 		//&model.User{},
 		//&model.Category{},
 		//&model.Supplier{},
-		//&model.Product{},
-		//&model.PurchaseOrder{},
-		//&model.AuditLog{},
-		//&model.PurchaseOrderItem{},
+		&model.Product{},
+		&model.UnitOfMeasure{},
+		&model.PurchaseOrder{},
+		&model.AuditLog{},
+		&model.PurchaseOrderItem{},
+		&model.SupplierProduct{},
+		&model.Lot{},
 		&model.StockTransaction{},
-	//&model.UserSession{},
+		&model.StockBalance{},
+		&model.CostLayer{},
+		&model.StockTransfer{},
+		&model.StockCount{},
+		&model.StockCountLine{},
+		&model.StockPeriod{},
+		&model.StockPeriodSnapshot{},
+		&model.SerialNumber{},
+		&model.SerialMovement{},
+		&model.UserSession{},
+		&model.RefreshToken{},
+		&model.PasswordResetToken{},
+		&model.LoginAttempt{},
+		&model.MfaRecoveryCode{},
+		&model.MfaChallenge{},
+		&model.RoleDefinition{},
+		&model.RolePermission{},
+		&model.Customer{},
+		&model.SalesOrder{},
+		&model.SalesOrderItem{},
+		&model.GoodsReceipt{},
+		&model.GoodsReceiptLine{},
 	); err != nil {
 		log.Slogger.Error("Migration failed", "error", err)
 	}
 
+	// ตาราง users ไม่ได้ auto migrate จึงเพิ่มคอลัมน์ใหม่เอง
+	for _, field := range []string{"Disabled", "MustChangePassword", "MfaEnabled", "MfaSecret", "MfaLastCounter"} {
+		if !db.Migrator().HasColumn(&model.User{}, field) {
+			if err := db.Migrator().AddColumn(&model.User{}, field); err != nil {
+				log.Slogger.Error("Failed to add users column", "field", field, "error", err)
+			}
+		}
+	}
+
+	// TOTP secret ที่เก็บไว้ก่อนมีการเข้ารหัสต้องเข้ารหัสก่อนใช้งาน
+	if _, err := userRepo.SealMfaSecrets(db, secret.IsSealed, mfaSecretBox.Seal); err != nil {
+		log.Slogger.Error("Failed to encrypt MFA secrets", "error", err)
+	}
+
+	// role เริ่มต้น admin, staff, viewer ต้องมีก่อน login ครั้งแรก เพราะสิทธิ์ใน access token อ่านจากตารางนี้
+	if err := roleRepo.SeedDefaults(db); err != nil {
+		log.Slogger.Error("Failed to seed default roles", "error", err)
+	}
+
+	// stock_transactions.reference_id อ้างถึงได้ทั้ง purchase order และ sales order จึงต้องไม่มี FK ไปที่ purchase_orders
+	if db.Migrator().HasConstraint(&model.StockTransaction{}, "fk_purchase_orders_stock_transaction") {
+		if err := db.Migrator().DropConstraint(&model.StockTransaction{}, "fk_purchase_orders_stock_transaction"); err != nil {
+			log.Slogger.Error("Failed to drop purchase order reference constraint", "error", err)
+		}
+	}
+
+	if needsStockBalanceRebuild {
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			_, err := stockBalanceRepo.RebuildFromLedger(tx)
+			return err
+		}); err != nil {
+			log.Slogger.Error("Stock balance rebuild failed", "error", err)
+		}
+	}
+
+	if needsReceivedBackfill {
+		if _, err := purchase_orderRepo.BackfillReceivedQuantity(db); err != nil {
+			log.Slogger.Error("Received quantity backfill failed", "error", err)
+		}
+	}
+
+	if needsTotalsBackfill {
+		if _, err := purchase_orderRepo.BackfillTotals(db); err != nil {
+			log.Slogger.Error("Purchase order totals backfill failed", "error", err)
+		}
+	}
+
+	if needsCostBackfill {
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			_, err := costLayerRepo.BackfillFromProductCost(tx)
+			return err
+		}); err != nil {
+			log.Slogger.Error("Cost layer backfill failed", "error", err)
+		}
+	}
+
+	if needsSerialLotBackfill {
+		if _, err := serialRepo.BackfillLots(db); err != nil {
+			log.Slogger.Error("Serial number lot backfill failed", "error", err)
+		}
+	}
+
 	//middleware
 	mid := middleware.NewFiberMiddleware(
 		db,
@@ -100,6 +333,8 @@ func main() {
 		jwtManager,
 		userRepo,
 		sessionRepo,
+		auditLogRepo,
+		sessionCache,
 	)
 	app.Use(mid.CORS())
 
@@ -109,6 +344,7 @@ func main() {
 		log.Slogger,
 		jwtManager,
 		mid,
+		businessLocation,
 	)
 
 	// endregion
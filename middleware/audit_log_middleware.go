@@ -1 +1,251 @@
 package middleware
+
+import (
+	"encoding/json"
+	"mini-erp-backend/model"
+	"mini-erp-backend/utils"
+	"reflect"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type auditEntity struct {
+	Table      string
+	PrimaryKey string
+	// NamedKey is set when the primary key is not a UUID (roles by name), the segment after the resource is the key
+	NamedKey bool
+	// Self is set for routes that act on the authenticated user (/auth/*), the key is the user id
+	Self bool
+}
+
+// auditEntities maps the first path segment after /api/v1 to the table the route mutates
+var auditEntities = map[string]auditEntity{
+	"categories":      {Table: "categories", PrimaryKey: "category_id"},
+	"customers":       {Table: "customers", PrimaryKey: "customer_id"},
+	"products":        {Table: "products", PrimaryKey: "product_id"},
+	"suppliers":       {Table: "suppliers", PrimaryKey: "supplier_id"},
+	"purchase-orders": {Table: "purchase_orders", PrimaryKey: "purchase_order_id"},
+	"sales-orders":    {Table: "sales_orders", PrimaryKey: "sales_order_id"},
+	"stocks":          {Table: "stock_transactions", PrimaryKey: "stock_transaction_id"},
+	"stock-counts":    {Table: "stock_counts", PrimaryKey: "stock_count_id"},
+	"stock-periods":   {Table: "stock_periods", PrimaryKey: "stock_period_id"},
+	"warehouses":      {Table: "warehouses", PrimaryKey: "warehouse_id"},
+	"register":        {Table: "users", PrimaryKey: "user_id"},
+	"users":           {Table: "users", PrimaryKey: "user_id"},
+	"roles":           {Table: "role_definitions", PrimaryKey: "name", NamedKey: true},
+	"auth":            {Table: "users", PrimaryKey: "user_id", Self: true},
+}
+
+// fields that must never be copied into an audit log, either a column of any table or table.column
+var auditSensitiveFields = map[string]struct{}{
+	"password":                {},
+	"token":                   {},
+	"access_token":            {},
+	"refresh_token":           {},
+	"mfa_secret":              {},
+	"mfa_last_counter":        {},
+	"mfa_recovery_codes.code": {},
+}
+
+// auditSensitive reports whether a column of table must be left out of audit logs
+func auditSensitive(table, field string) bool {
+	if _, ok := auditSensitiveFields[field]; ok {
+		return true
+	}
+	_, ok := auditSensitiveFields[table+"."+field]
+	return ok
+}
+
+var auditMethodActions = map[string]string{
+	fiber.MethodPost:   model.AuditActionCreate,
+	fiber.MethodPut:    model.AuditActionUpdate,
+	fiber.MethodPatch:  model.AuditActionUpdate,
+	fiber.MethodDelete: model.AuditActionDelete,
+}
+
+// AuditLog records every mutating request of the authenticated user. It must run after Authenticated.
+func (f *FiberMiddleware) AuditLog() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		action, ok := auditMethodActions[c.Method()]
+		if !ok {
+			return c.Next()
+		}
+
+		ud, ok := c.Locals(utils.CONTEXT_USER_DATA_KEY).(utils.UserDataCtx)
+		if !ok || ud.UserId == uuid.Nil {
+			return c.Next()
+		}
+
+		entityName, rest := auditEntityFromPath(c.Path())
+		entity, known := auditEntities[entityName]
+		entityId := auditEntityId(entity, rest, ud.UserId)
+		action = auditAction(action, entityId)
+
+		// เก็บข้อมูลก่อนแก้ไขสำหรับ PUT/PATCH/DELETE
+		var before map[string]interface{}
+		if known && entityId != "" && action != model.AuditActionCreate {
+			before = f.auditSnapshot(entity, entityId)
+		}
+
+		err := c.Next()
+
+		if known && entityId == "" {
+			entityId = auditIdFromResponse(c.Response().Body(), entity.PrimaryKey)
+		}
+
+		detail := fiber.Map{
+			"method":      c.Method(),
+			"route":       c.Route().Path,
+			"path":        c.Path(),
+			"status_code": c.Response().StatusCode(),
+			"ip":          c.IP(),
+		}
+
+		if before != nil {
+			detail["before"] = before
+		}
+
+		if before != nil && action == model.AuditActionUpdate {
+			after := f.auditSnapshot(entity, entityId)
+			detail["after"] = after
+			detail["changes"] = auditDiff(before, after)
+		}
+
+		f.writeAuditLog(ud.UserId, action, entityName, entityId, detail)
+
+		return err
+	}
+}
+
+func (f *FiberMiddleware) auditSnapshot(entity auditEntity, entityId string) map[string]interface{} {
+	rows := []map[string]interface{}{}
+	if err := f.db.Table(entity.Table).Where(entity.PrimaryKey+" = ?", entityId).Limit(1).Find(&rows).Error; err != nil {
+		f.logger.Error("audit: failed to load entity snapshot", "table", entity.Table, "id", entityId, "error", err)
+		return nil
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	for field := range rows[0] {
+		if auditSensitive(entity.Table, field) {
+			delete(rows[0], field)
+		}
+	}
+
+	return rows[0]
+}
+
+func (f *FiberMiddleware) writeAuditLog(userId uuid.UUID, action, entity, entityId string, detail fiber.Map) {
+	raw, err := json.Marshal(detail)
+	if err != nil {
+		f.logger.Error("audit: failed to marshal detail", "error", err)
+		return
+	}
+
+	auditLog := &model.AuditLog{
+		UserId: userId,
+		Action: action,
+		Entity: entity,
+		Detail: raw,
+	}
+	if entityId != "" {
+		auditLog.EntityId = &entityId
+	}
+
+	// การบันทึก audit log ล้มเหลวต้องไม่ทำให้ request ล้มเหลว
+	if err := f.auditLogRepo.Create(f.db, auditLog); err != nil {
+		f.logger.Error("audit: failed to write audit log", "action", action, "entity", entity, "error", err)
+	}
+}
+
+// auditEntityFromPath returns the resource segment after /api/v1 and the segments after it
+func auditEntityFromPath(path string) (string, []string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i, segment := range segments {
+		if segment != "v1" || i+1 >= len(segments) {
+			continue
+		}
+		return segments[i+1], segments[i+2:]
+	}
+
+	return "", nil
+}
+
+// auditEntityId returns the key of the entity a request acts on: the authenticated user for Self entities,
+// the segment after the resource for NamedKey entities, otherwise the first UUID segment
+func auditEntityId(entity auditEntity, rest []string, userId uuid.UUID) string {
+	if entity.Self {
+		return userId.String()
+	}
+
+	if entity.NamedKey {
+		if len(rest) > 0 {
+			return rest[0]
+		}
+		return ""
+	}
+
+	for _, s := range rest {
+		if _, err := uuid.Parse(s); err == nil {
+			return s
+		}
+	}
+	return ""
+}
+
+// auditAction refines the action of the method by the route: a POST that names an existing entity,
+// e.g. /users/:id/deactivate or /purchase-orders/:id/receipts, changes that entity instead of creating one
+func auditAction(action string, entityId string) string {
+	if action == model.AuditActionCreate && entityId != "" {
+		return model.AuditActionUpdate
+	}
+	return action
+}
+
+// auditIdFromResponse looks for the primary key of a newly created entity in the JSON response,
+// either at the top level or one object deep (e.g. {"category": {"category_id": ...}})
+func auditIdFromResponse(body []byte, primaryKey string) string {
+	var payload map[string]interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return ""
+	}
+
+	if id, ok := payload[primaryKey].(string); ok {
+		return id
+	}
+
+	for _, v := range payload {
+		if nested, ok := v.(map[string]interface{}); ok {
+			if id, ok := nested[primaryKey].(string); ok {
+				return id
+			}
+		}
+	}
+
+	return ""
+}
+
+func auditDiff(before, after map[string]interface{}) map[string]interface{} {
+	changes := map[string]interface{}{}
+
+	for field, oldValue := range before {
+		newValue, ok := after[field]
+		if ok && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		changes[field] = fiber.Map{"before": oldValue, "after": newValue}
+	}
+
+	for field, newValue := range after {
+		if _, ok := before[field]; !ok {
+			changes[field] = fiber.Map{"before": nil, "after": newValue}
+		}
+	}
+
+	return changes
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"mini-erp-backend/model"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func TestAuditSensitive(t *testing.T) {
+	tests := []struct {
+		table string
+		field string
+		want  bool
+	}{
+		{"users", "password", true},
+		{"users", "mfa_secret", true},
+		{"users", "mfa_last_counter", true},
+		{"users", "username", false},
+		{"mfa_recovery_codes", "code", true},
+		{"warehouses", "code", false},
+	}
+	for _, tt := range tests {
+		if got := auditSensitive(tt.table, tt.field); got != tt.want {
+			t.Errorf("auditSensitive(%q, %q) = %v, want %v", tt.table, tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestAuditEntityAndAction(t *testing.T) {
+	userId := uuid.New()
+	orderId := uuid.New().String()
+	tests := []struct {
+		method     string
+		path       string
+		wantEntity string
+		wantId     string
+		wantAction string
+	}{
+		{fiber.MethodPost, "/api/v1/categories", "categories", "", model.AuditActionCreate},
+		{fiber.MethodPatch, "/api/v1/categories/" + orderId, "categories", orderId, model.AuditActionUpdate},
+		{fiber.MethodPost, "/api/v1/stocks/adjust", "stocks", "", model.AuditActionCreate},
+		{fiber.MethodPost, "/api/v1/purchase-orders/" + orderId + "/receipts", "purchase-orders", orderId, model.AuditActionUpdate},
+		{fiber.MethodPost, "/api/v1/users/" + orderId + "/deactivate", "users", orderId, model.AuditActionUpdate},
+		{fiber.MethodPost, "/api/v1/roles", "roles", "", model.AuditActionCreate},
+		{fiber.MethodPut, "/api/v1/roles/warehouse_staff", "roles", "warehouse_staff", model.AuditActionUpdate},
+		{fiber.MethodDelete, "/api/v1/roles/warehouse_staff", "roles", "warehouse_staff", model.AuditActionDelete},
+		{fiber.MethodPost, "/api/v1/auth/mfa/enroll", "auth", userId.String(), model.AuditActionUpdate},
+	}
+	for _, tt := range tests {
+		entityName, rest := auditEntityFromPath(tt.path)
+		entityId := auditEntityId(auditEntities[entityName], rest, userId)
+		action := auditAction(auditMethodActions[tt.method], entityId)
+		if entityName != tt.wantEntity || entityId != tt.wantId || action != tt.wantAction {
+			t.Errorf("%s %s = (%q, %q, %q), want (%q, %q, %q)", tt.method, tt.path, entityName, entityId, action, tt.wantEntity, tt.wantId, tt.wantAction)
+		}
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"log/slog"
 	"mini-erp-backend/api/repository"
 	"mini-erp-backend/config/environment"
+	"mini-erp-backend/lib/cache"
 	"mini-erp-backend/lib/jwt"
+	"mini-erp-backend/model"
 	"mini-erp-backend/utils"
 	"os"
 	"strconv"
@@ -20,12 +22,14 @@ import (
 )
 
 type FiberMiddleware struct {
-	db          *gorm.DB
-	corsSetUp   corsSetUp
-	logger      *slog.Logger
-	jwtManager  jwt.Manager
-	userRepo    repository.User
-	sessionRepo repository.UserSession
+	db           *gorm.DB
+	corsSetUp    corsSetUp
+	logger       *slog.Logger
+	jwtManager   jwt.Manager
+	userRepo     repository.User
+	sessionRepo  repository.UserSession
+	auditLogRepo repository.AuditLog
+	sessionCache *cache.Cache[string, model.UserSession]
 }
 
 type corsSetUp struct {
@@ -46,6 +50,8 @@ func getFiberMiddlewareInstance(
 	jwtManager jwt.Manager,
 	userAuthenRepo repository.User,
 	sessionRepo repository.UserSession,
+	auditLogRepo repository.AuditLog,
+	sessionCache *cache.Cache[string, model.UserSession],
 ) *FiberMiddleware {
 	if fiberMiddlewareInstance == nil {
 		fiberMiddlewareLock.Lock()
@@ -57,6 +63,8 @@ func getFiberMiddlewareInstance(
 				jwtManager,
 				userAuthenRepo,
 				sessionRepo,
+				auditLogRepo,
+				sessionCache,
 			)
 		}
 	}
@@ -71,8 +79,10 @@ func NewFiberMiddleware(
 	jwtManager jwt.Manager,
 	userAuthenRepo repository.User,
 	sessionRepo repository.UserSession,
+	auditLogRepo repository.AuditLog,
+	sessionCache *cache.Cache[string, model.UserSession],
 ) *FiberMiddleware {
-	return getFiberMiddlewareInstance(db, logger, jwtManager, userAuthenRepo, sessionRepo)
+	return getFiberMiddlewareInstance(db, logger, jwtManager, userAuthenRepo, sessionRepo, auditLogRepo, sessionCache)
 }
 
 // create the fiberMiddlewareInstance and set up it
@@ -82,6 +92,8 @@ func createFiberMiddlewareInstance(
 	jwtManager jwt.Manager,
 	userRepo repository.User,
 	sessionRepo repository.UserSession,
+	auditLogRepo repository.AuditLog,
+	sessionCache *cache.Cache[string, model.UserSession],
 ) *FiberMiddleware {
 	allowCredential, err := strconv.ParseBool(environment.GetString(environment.AllowCredentialKey))
 	if err != nil {
@@ -95,11 +107,13 @@ func createFiberMiddlewareInstance(
 			AllowOrigins:     environment.GetString(environment.AllowOriginKey),
 			AllowCredentials: allowCredential,
 		},
-		db:          db,
-		logger:      logger,
-		jwtManager:  jwtManager,
-		userRepo:    userRepo,
-		sessionRepo: sessionRepo,
+		db:           db,
+		logger:       logger,
+		jwtManager:   jwtManager,
+		userRepo:     userRepo,
+		sessionRepo:  sessionRepo,
+		auditLogRepo: auditLogRepo,
+		sessionCache: sessionCache,
 	}
 }
 
@@ -130,9 +144,35 @@ func (f *FiberMiddleware) Authenticated() fiber.Handler {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid access token"})
 		}
 
+		session, err := f.accessSession(claims.AccessUuid)
+		if err != nil {
+			f.logger.Error("authentication: session lookup failed", "error", err)
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid access token"})
+		}
+
+		if session.Revoked || session.UserId != claims.UserId {
+			f.logger.Error("authentication: session revoked", "session_id", session.SessionId)
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "session revoked"})
+		}
+
+		// session ที่ต้องเปลี่ยนรหัสผ่านหรือต้องเปิด MFA ก่อน ใช้ได้แค่ route ใน restrictedSessionRoutes
+		if session.MustChangePassword || session.MfaEnrollRequired {
+			if _, ok := restrictedSessionRoutes[strings.TrimSuffix(c.Path(), "/")]; !ok {
+				if session.MustChangePassword {
+					f.logger.Error("authentication: password change required", "session_id", session.SessionId)
+					return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "password change required"})
+				}
+				f.logger.Error("authentication: mfa enrollment required", "session_id", session.SessionId)
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "mfa enrollment required"})
+			}
+		}
+
 		userData := utils.UserDataCtx{
-			UserId: claims.UserId,
-			Role:   strings.ToLower(claims.Role),
+			UserId:      claims.UserId,
+			Role:        strings.ToLower(claims.Role),
+			Permissions: claims.Permissions,
+			SessionId:   session.SessionId,
+			AccessUuid:  claims.AccessUuid,
 		}
 		utils.SetUserDataLocal(c, userData)
 
@@ -140,55 +180,38 @@ func (f *FiberMiddleware) Authenticated() fiber.Handler {
 	}
 }
 
-var roleLevel = map[string]int{
-	"viewer": 1,
-	"staff":  2,
-	"admin":  3,
+// restrictedSessionRoutes are the only routes a session flagged with MustChangePassword or MfaEnrollRequired may call
+var restrictedSessionRoutes = map[string]struct{}{
+	"/api/v1/auth/password":   {},
+	"/api/v1/auth/mfa/enroll": {},
+	"/api/v1/auth/mfa/enable": {},
+	"/api/v1/auth/logout":     {},
+	"/api/v1/auth/logout-all": {},
 }
 
-func (f *FiberMiddleware) RequireMinRole(minRole string) fiber.Handler {
-	min := 999
-	if lvl, ok := roleLevel[strings.ToLower(minRole)]; ok {
-		min = lvl
+// accessSession returns the session that issued the access token, using the session cache before the database
+func (f *FiberMiddleware) accessSession(accessUuid string) (*model.UserSession, error) {
+	if accessUuid == "" {
+		return nil, repository.ErrSessionRevoked
 	}
 
-	return func(c *fiber.Ctx) error {
-		v := c.Locals(utils.CONTEXT_USER_DATA_KEY)
-		if v == nil {
-			f.logger.Error("authorization: user data missing in context")
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
-		}
-
-		ud, ok := v.(utils.UserDataCtx)
-		if !ok {
-			f.logger.Error("authorization: invalid user data type in context")
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
-		}
+	if session, ok := f.sessionCache.Get(accessUuid); ok {
+		return &session, nil
+	}
 
-		role := strings.ToLower(strings.TrimSpace(ud.Role))
-		if role == "" || ud.UserId == uuid.Nil {
-			f.logger.Error("authorization: role or user id missing in user data")
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "role missing or invalid"})
-		}
+	session, err := f.sessionRepo.SearchByAccessUuid(f.db, accessUuid)
+	if err != nil {
+		return nil, err
+	}
 
-		if lvl, ok := roleLevel[role]; ok && lvl >= min {
-			return c.Next()
-		}
+	f.sessionCache.Set(accessUuid, *session)
 
-		f.logger.Error("authorization: forbidden", "required_min_role", minRole, "user_role", role)
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "forbidden role for this action"})
-	}
+	return session, nil
 }
 
-func (f *FiberMiddleware) RequireRole(roles ...string) fiber.Handler {
-	allowed := make(map[string]struct{}, len(roles))
-	for _, r := range roles {
-		rn := strings.ToLower(strings.TrimSpace(r))
-		if rn != "" {
-			allowed[rn] = struct{}{}
-		}
-	}
-
+// RequirePermission allows the request only when the access token carries every given permission.
+// The permissions are resolved from the role of the user when the token is issued or refreshed.
+func (f *FiberMiddleware) RequirePermission(permissions ...model.Permission) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		v := c.Locals(utils.CONTEXT_USER_DATA_KEY)
 		if v == nil {
@@ -202,17 +225,18 @@ func (f *FiberMiddleware) RequireRole(roles ...string) fiber.Handler {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
 		}
 
-		role := strings.ToLower(strings.TrimSpace(ud.Role))
-		if role == "" || ud.UserId == uuid.Nil {
-			f.logger.Error("authorization: role or user id missing in user data")
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "role missing or invalid"})
+		if ud.UserId == uuid.Nil {
+			f.logger.Error("authorization: user id missing in user data")
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "permission missing or invalid"})
 		}
 
-		if _, ok := allowed[role]; ok {
-			return c.Next()
+		for _, p := range permissions {
+			if !ud.HasPermission(string(p)) {
+				f.logger.Error("authorization: forbidden", "required_permission", p, "user_role", ud.Role)
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "missing permission " + string(p)})
+			}
 		}
 
-		f.logger.Error("authorization: forbidden", "required_roles", roles, "user_role", role)
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "forbidden role for this action"})
+		return c.Next()
 	}
 }
@@ -9,10 +9,21 @@ import (
 
 type AuditLog struct {
 	AuditLogId uuid.UUID       `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"audit_log_id"`
-	UserId     uuid.UUID       `gorm:"type:uuid;not null" json:"user_id"`
-	Action     string          `gorm:"not null" json:"action"`
+	UserId     uuid.UUID       `gorm:"type:uuid;not null;index" json:"user_id"`
+	Action     string          `gorm:"not null;index" json:"action"`
+	Entity     string          `gorm:"not null;default:'';index" json:"entity"`
+	EntityId   *string         `gorm:"index" json:"entity_id"`
 	Detail     json.RawMessage `gorm:"type:json;not null" json:"detail"`
-	CreatedAt  time.Time       `gorm:"not null;autoCreateTime" json:"created_at"`
+	CreatedAt  time.Time       `gorm:"not null;autoCreateTime;index" json:"created_at"`
 
 	User User `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
 }
+
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+
+	// security events, e.g. a refresh token presented twice
+	AuditActionSecurity = "security"
+)
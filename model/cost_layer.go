@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type CostingMethod string
+
+const (
+	// CostingFIFO issues stock at the cost of the oldest receipts first
+	CostingFIFO CostingMethod = "FIFO"
+	// CostingAverage issues stock at the moving-average cost of what is on hand
+	CostingAverage CostingMethod = "AVERAGE"
+)
+
+func (m CostingMethod) IsValid() bool {
+	switch m {
+	case CostingFIFO, CostingAverage:
+		return true
+	}
+	return false
+}
+
+// CostLayer is a quantity of one product received into a warehouse at one unit cost.
+// Inbound movements add layers and outbound movements consume them, oldest first.
+// Stock issued beyond what is on hand is a negative layer, filled by the next receipts before they add a layer.
+type CostLayer struct {
+	CostLayerId uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"cost_layer_id"`
+	ProductId   uuid.UUID `gorm:"type:uuid;not null;index:idx_cost_layers_open" json:"product_id"`
+	WarehouseId uuid.UUID `gorm:"type:uuid;not null;index:idx_cost_layers_open" json:"warehouse_id"`
+	// StockTransactionId is the movement that created the layer (outbound for a negative layer), nil for opening balances
+	StockTransactionId *uuid.UUID      `gorm:"type:uuid;index" json:"stock_transaction_id"`
+	Quantity           int64           `gorm:"not null" json:"quantity"`
+	RemainingQuantity  int64           `gorm:"not null" json:"remaining_quantity"`
+	UnitCost           decimal.Decimal `gorm:"type:numeric(15,4);not null;default:0" json:"unit_cost"`
+	CreatedAt          time.Time       `gorm:"not null" json:"created_at"`
+
+	Product   Product    `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+	Warehouse *Warehouse `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+}
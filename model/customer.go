@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type Customer struct {
+	CustomerId  uuid.UUID       `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"customer_id"`
+	Name        string          `gorm:"not null" json:"name"`
+	ContactName *string         `json:"contact_name"`
+	Phone       *string         `json:"phone"`
+	Email       *string         `json:"email"`
+	Address     *string         `json:"address"`
+	TaxId       *string         `gorm:"index" json:"tax_id"`
+	CreditLimit decimal.Decimal `gorm:"type:numeric(15,2);not null;default:0" json:"credit_limit"`
+	// PaymentTermDays is the number of days the customer has to pay an invoice, 0 means cash
+	PaymentTermDays int       `gorm:"not null;default:0" json:"payment_term_days"`
+	CreatedAt       time.Time `gorm:"not null" json:"created_at"`
+	UpdatedAt       time.Time `gorm:"not null" json:"updated_at"`
+
+	SalesOrders []SalesOrder `gorm:"foreignKey:CustomerId;references:CustomerId" json:"-"`
+}
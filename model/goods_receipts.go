@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GoodsReceipt records one delivery received against a purchase order
+type GoodsReceipt struct {
+	GoodsReceiptId  uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"goods_receipt_id"`
+	PurchaseOrderId uuid.UUID `gorm:"type:uuid;not null;index" json:"purchase_order_id"`
+	// WarehouseId and LocationId are where the goods were put away
+	WarehouseId uuid.UUID  `gorm:"type:uuid;not null" json:"warehouse_id"`
+	LocationId  *uuid.UUID `gorm:"type:uuid" json:"location_id"`
+	Note        *string    `json:"note"`
+	ReceivedAt  time.Time  `gorm:"not null" json:"received_at"`
+	ReceivedBy  uuid.UUID  `gorm:"type:uuid;not null" json:"received_by"`
+
+	Lines         []GoodsReceiptLine `gorm:"foreignKey:GoodsReceiptId" json:"lines"`
+	PurchaseOrder PurchaseOrder      `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+}
+
+type GoodsReceiptLine struct {
+	GoodsReceiptLineId  uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"goods_receipt_line_id"`
+	GoodsReceiptId      uuid.UUID  `gorm:"type:uuid;not null;index" json:"goods_receipt_id"`
+	PurchaseOrderItemId uuid.UUID  `gorm:"type:uuid;not null;index" json:"purchase_order_item_id"`
+	ProductId           uuid.UUID  `gorm:"type:uuid;not null" json:"product_id"`
+	Quantity            uint64     `gorm:"not null" json:"quantity"`
+	StockTransactionId  uuid.UUID  `gorm:"type:uuid;not null" json:"stock_transaction_id"`
+	LotId               *uuid.UUID `gorm:"type:uuid" json:"lot_id"`
+
+	GoodsReceipt      GoodsReceipt      `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+	PurchaseOrderItem PurchaseOrderItem `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoginAttempt records every call to the login endpoint, failed ones drive the backoff and lockout
+type LoginAttempt struct {
+	LoginAttemptId uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"login_attempt_id"`
+	Username       string     `gorm:"not null;index" json:"username"`
+	UserId         *uuid.UUID `gorm:"type:uuid;index" json:"user_id"`
+	IP             string     `gorm:"column:ip;not null;default:'';index" json:"ip"`
+	Result         string     `gorm:"not null;index" json:"result"`
+	CreatedAt      time.Time  `gorm:"not null;autoCreateTime;index" json:"created_at"`
+}
+
+const (
+	LoginResultSuccess            = "success"
+	LoginResultInvalidCredentials = "invalid_credentials"
+	LoginResultDisabled           = "disabled"
+	LoginResultLocked             = "locked"
+	LoginResultThrottled          = "throttled"
+	LoginResultMfaRequired        = "mfa_required"
+)
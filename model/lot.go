@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Lot is a batch of one product received together, optionally with an expiry date.
+// Stock on hand per lot is derived from the stock_transactions that reference it.
+type Lot struct {
+	LotId      uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"lot_id"`
+	ProductId  uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_lots_product_number" json:"product_id"`
+	LotNumber  string     `gorm:"not null;uniqueIndex:idx_lots_product_number" json:"lot_number"`
+	ExpiryDate *time.Time `gorm:"type:date;index" json:"expiry_date"`
+	CreatedAt  time.Time  `gorm:"not null" json:"created_at"`
+
+	Product Product `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MfaRecoveryCode is a single-use code that replaces a TOTP code when the authenticator is lost.
+// Code keeps only the SHA-256 hash.
+type MfaRecoveryCode struct {
+	RecoveryCodeId uuid.UUID  `gorm:"column:recovery_code_id;type:uuid;default:uuid_generate_v4();primaryKey" json:"recovery_code_id"`
+	UserId         uuid.UUID  `gorm:"column:user_id;type:uuid;not null;index" json:"user_id"`
+	Code           string     `gorm:"column:code;not null" json:"-"`
+	UsedAt         *time.Time `gorm:"column:used_at" json:"used_at"`
+	CreatedAt      time.Time  `gorm:"column:created_at;not null;autoCreateTime" json:"created_at"`
+
+	User User `gorm:"foreignKey:UserId;references:UserId;constraint:OnDelete:CASCADE;" json:"-"`
+}
+
+// MfaChallenge is the short-lived second step of a login for a user with MFA enabled.
+// Token keeps only the SHA-256 hash of the value returned by the login.
+type MfaChallenge struct {
+	ChallengeId uuid.UUID  `gorm:"column:challenge_id;type:uuid;default:uuid_generate_v4();primaryKey" json:"challenge_id"`
+	UserId      uuid.UUID  `gorm:"column:user_id;type:uuid;not null;index" json:"user_id"`
+	Token       string     `gorm:"column:token;not null;uniqueIndex" json:"-"`
+	IP          string     `gorm:"column:ip;not null;default:''" json:"ip"`
+	Attempts    int64      `gorm:"column:attempts;not null;default:0" json:"attempts"`
+	ExpireAt    time.Time  `gorm:"column:expire_at;not null" json:"expire_at"`
+	UsedAt      *time.Time `gorm:"column:used_at" json:"used_at"`
+	CreatedAt   time.Time  `gorm:"column:created_at;not null;autoCreateTime" json:"created_at"`
+
+	User User `gorm:"foreignKey:UserId;references:UserId;constraint:OnDelete:CASCADE;" json:"-"`
+}
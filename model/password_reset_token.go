@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PasswordResetToken is a single-use token an admin issues so a user can set a new password.
+// Token keeps only the SHA-256 hash of the value handed to the user.
+type PasswordResetToken struct {
+	ResetId   uuid.UUID  `gorm:"column:reset_id;type:uuid;default:uuid_generate_v4();primaryKey" json:"reset_id"`
+	UserId    uuid.UUID  `gorm:"column:user_id;type:uuid;not null;index" json:"user_id"`
+	Token     string     `gorm:"column:token;not null;uniqueIndex" json:"-"`
+	ExpireAt  time.Time  `gorm:"column:expire_at;not null" json:"expire_at"`
+	UsedAt    *time.Time `gorm:"column:used_at" json:"used_at"`
+	CreatedBy uuid.UUID  `gorm:"column:created_by;type:uuid;not null" json:"created_by"`
+	CreatedAt time.Time  `gorm:"column:created_at;not null;autoCreateTime" json:"created_at"`
+
+	User User `gorm:"foreignKey:UserId;references:UserId;constraint:OnDelete:CASCADE;" json:"-"`
+}
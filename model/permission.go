@@ -0,0 +1,154 @@
+package model
+
+import "time"
+
+// Permission is a named action a role may perform, written as <resource>.<action>
+type Permission string
+
+const (
+	PermissionSupplierRead   Permission = "supplier.read"
+	PermissionSupplierManage Permission = "supplier.manage"
+
+	PermissionPurchaseOrderRead    Permission = "purchase_order.read"
+	PermissionPurchaseOrderWrite   Permission = "purchase_order.write"
+	PermissionPurchaseOrderApprove Permission = "purchase_order.approve"
+	PermissionPurchaseOrderReceive Permission = "purchase_order.receive"
+
+	PermissionReplenishmentRead     Permission = "replenishment.read"
+	PermissionReplenishmentGenerate Permission = "replenishment.generate"
+
+	PermissionSalesOrderRead  Permission = "sales_order.read"
+	PermissionSalesOrderWrite Permission = "sales_order.write"
+
+	PermissionReportView   Permission = "report.view"
+	PermissionReportExport Permission = "report.export"
+
+	PermissionCategoryRead   Permission = "category.read"
+	PermissionCategoryManage Permission = "category.manage"
+
+	PermissionCustomerRead   Permission = "customer.read"
+	PermissionCustomerWrite  Permission = "customer.write"
+	PermissionCustomerDelete Permission = "customer.delete"
+
+	PermissionWarehouseRead   Permission = "warehouse.read"
+	PermissionWarehouseManage Permission = "warehouse.manage"
+
+	PermissionProductRead  Permission = "product.read"
+	PermissionProductWrite Permission = "product.write"
+
+	PermissionStockRead    Permission = "stock.read"
+	PermissionStockMove    Permission = "stock.move"
+	PermissionStockAdjust  Permission = "stock.adjust"
+	PermissionStockRebuild Permission = "stock.rebuild"
+
+	PermissionStockCountRead    Permission = "stock_count.read"
+	PermissionStockCountWrite   Permission = "stock_count.write"
+	PermissionStockCountApprove Permission = "stock_count.approve"
+
+	PermissionStockPeriodRead  Permission = "stock_period.read"
+	PermissionStockPeriodClose Permission = "stock_period.close"
+
+	PermissionAuditLogRead     Permission = "audit_log.read"
+	PermissionLoginAttemptRead Permission = "login_attempt.read"
+	PermissionUserManage       Permission = "user.manage"
+	PermissionRoleManage       Permission = "role.manage"
+)
+
+// AllPermissions lists every permission the routes check, in the order the API shows them
+var AllPermissions = []Permission{
+	PermissionSupplierRead,
+	PermissionSupplierManage,
+	PermissionPurchaseOrderRead,
+	PermissionPurchaseOrderWrite,
+	PermissionPurchaseOrderApprove,
+	PermissionPurchaseOrderReceive,
+	PermissionReplenishmentRead,
+	PermissionReplenishmentGenerate,
+	PermissionSalesOrderRead,
+	PermissionSalesOrderWrite,
+	PermissionReportView,
+	PermissionReportExport,
+	PermissionCategoryRead,
+	PermissionCategoryManage,
+	PermissionCustomerRead,
+	PermissionCustomerWrite,
+	PermissionCustomerDelete,
+	PermissionWarehouseRead,
+	PermissionWarehouseManage,
+	PermissionProductRead,
+	PermissionProductWrite,
+	PermissionStockRead,
+	PermissionStockMove,
+	PermissionStockAdjust,
+	PermissionStockRebuild,
+	PermissionStockCountRead,
+	PermissionStockCountWrite,
+	PermissionStockCountApprove,
+	PermissionStockPeriodRead,
+	PermissionStockPeriodClose,
+	PermissionAuditLogRead,
+	PermissionLoginAttemptRead,
+	PermissionUserManage,
+	PermissionRoleManage,
+}
+
+// IsPermission reports whether p is one of AllPermissions
+func IsPermission(p Permission) bool {
+	for _, known := range AllPermissions {
+		if known == p {
+			return true
+		}
+	}
+	return false
+}
+
+// viewerPermissions สิทธิ์อ่านอย่างเดียวของ viewer เดิม
+var viewerPermissions = []Permission{
+	PermissionSupplierRead,
+	PermissionPurchaseOrderRead,
+	PermissionReplenishmentRead,
+	PermissionSalesOrderRead,
+	PermissionCategoryRead,
+	PermissionCustomerRead,
+	PermissionWarehouseRead,
+	PermissionProductRead,
+	PermissionStockRead,
+	PermissionStockCountRead,
+}
+
+// DefaultRolePermissions are the permission sets seeded for the three built-in roles. They follow the
+// viewer/staff/admin levels the routes required before, except that a role able to list suppliers or
+// purchase orders can now also open a single one.
+var DefaultRolePermissions = map[Role][]Permission{
+	RoleViewer: viewerPermissions,
+	RoleStaff: append(append([]Permission{}, viewerPermissions...),
+		PermissionPurchaseOrderWrite,
+		PermissionPurchaseOrderApprove,
+		PermissionPurchaseOrderReceive,
+		PermissionReplenishmentGenerate,
+		PermissionSalesOrderWrite,
+		PermissionCustomerWrite,
+		PermissionProductWrite,
+		PermissionStockMove,
+		PermissionStockAdjust,
+		PermissionStockCountWrite,
+	),
+	RoleAdmin: AllPermissions,
+}
+
+// RoleDefinition is a role stored in the database, the permissions it grants live in role_permissions
+type RoleDefinition struct {
+	Name        Role      `gorm:"column:name;primaryKey" json:"name"`
+	Description string    `gorm:"column:description;not null;default:''" json:"description"`
+	System      bool      `gorm:"column:system;not null;default:false" json:"system"` // role ที่ seed ไว้ ลบไม่ได้
+	CreatedAt   time.Time `gorm:"column:created_at;not null;autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;not null;autoUpdateTime" json:"updated_at"`
+
+	Grants      []RolePermission `gorm:"foreignKey:Role;references:Name;constraint:OnDelete:CASCADE" json:"-"`
+	Permissions []Permission     `gorm:"-" json:"permissions"` // เติมจาก Grants ตอนอ่านจาก repository
+}
+
+type RolePermission struct {
+	Role       Role       `gorm:"column:role;primaryKey" json:"role"`
+	Permission Permission `gorm:"column:permission;primaryKey" json:"permission"`
+}
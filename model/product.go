@@ -15,10 +15,23 @@ type Product struct {
 	SellingPrice float64   `gorm:"not null" json:"selling_price"`
 	Unit         string    `gorm:"not null" json:"unit"`
 	MinStock     int64     `gorm:"not null" json:"min_stock"`
+	// AllowBackorder lets stock OUT and negative ADJUST drive the balance below zero
+	AllowBackorder bool `gorm:"not null;default:false" json:"allow_backorder"`
+	// TrackSerial requires a serial number for every unit moved in or out
+	TrackSerial bool `gorm:"not null;default:false" json:"track_serial"`
+	// CostingMethod overrides the global COSTING_METHOD for this product, nil = use the global one
+	CostingMethod *CostingMethod `gorm:"type:varchar(16)" json:"costing_method"`
+	// PreferredSupplierId is the supplier replenishment orders are raised with, nil = not replenished automatically
+	PreferredSupplierId *uuid.UUID `gorm:"type:uuid" json:"preferred_supplier_id"`
+	// ReorderQuantity is the minimum quantity ordered when the product is replenished
+	ReorderQuantity int64 `gorm:"not null;default:0" json:"reorder_quantity"`
+	// LeadTimeDays is how long the preferred supplier takes to deliver
+	LeadTimeDays int64     `gorm:"not null;default:0" json:"lead_time_days"`
 	CreatedAt    time.Time `gorm:"not null" json:"created_at"`
 	UpdatedAt    time.Time `gorm:"not null" json:"updated_at"`
 
 	Category           *Category           `gorm:"constraint:OnDelete:CASCADE;" json:"category,omitempty"`
+	PreferredSupplier  *Supplier           `gorm:"foreignKey:PreferredSupplierId;references:SupplierId;constraint:OnDelete:SET NULL;" json:"preferred_supplier,omitempty"`
 	StockTransactions  []StockTransaction  `gorm:"foreignKey:ProductId;references:ProductId" json:"-"`
 	PurchaseOrderItems []PurchaseOrderItem `gorm:"foreignKey:ProductId;references:ProductId" json:"-"`
 }
@@ -2,6 +2,7 @@ package model
 
 import (
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 type PurchaseOrderItem struct {
@@ -9,8 +10,26 @@ type PurchaseOrderItem struct {
 	PurchaseOrderId     uuid.UUID `gorm:"type:uuid;not null;" json:"purchase_order_id"`
 	ProductId           uuid.UUID `gorm:"type:uuid;not null;" json:"product_id"`
 	Quantity            uint64    `gorm:"not null;" json:"quantity"`
-	Price               float64   `gorm:"not null;" json:"price"`
+	ReceivedQuantity    uint64    `gorm:"not null;default:0" json:"received_quantity"`
+	// Quantity and ReceivedQuantity are in the product's base unit. Unit is the unit the item was ordered in
+	// (nil = base unit) and UnitFactor the base units per ordered unit.
+	Unit       *string         `json:"unit"`
+	UnitFactor uint64          `gorm:"not null;default:1" json:"unit_factor"`
+	Price      decimal.Decimal `gorm:"type:numeric(15,4);not null;" json:"price"`
 
 	PurchaseOrder PurchaseOrder `gorm:"foconstraint:OnDelete:CASCADE;" json:"-"`
 	Product       Product       `gorm:"constraint:OnDelete:SET NULL;" json:"-"`
 }
+
+// OutstandingQuantity is the quantity ordered but not yet received
+func (i PurchaseOrderItem) OutstandingQuantity() uint64 {
+	if i.ReceivedQuantity >= i.Quantity {
+		return 0
+	}
+	return i.Quantity - i.ReceivedQuantity
+}
+
+// LineTotal is price x quantity of the item, rounded to 2 decimal places
+func (i PurchaseOrderItem) LineTotal() decimal.Decimal {
+	return i.Price.Mul(decimal.NewFromUint64(i.Quantity)).Round(2)
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 type PurchaseOrder struct {
@@ -13,8 +14,17 @@ type PurchaseOrder struct {
 	CreatedAt       time.Time           `gorm:"not null" json:"created_at"`
 	CreatedBy       uuid.UUID           `gorm:"type:uuid;not null" json:"created_by"`
 
+	// Amounts are recalculated from the items whenever the order is created or updated
+	Subtotal       decimal.Decimal `gorm:"type:numeric(15,2);not null;default:0" json:"subtotal"`
+	DiscountAmount decimal.Decimal `gorm:"type:numeric(15,2);not null;default:0" json:"discount_amount"`
+	// TaxRate is a percentage applied to the subtotal after discount, e.g. 7 for VAT 7%
+	TaxRate     decimal.Decimal `gorm:"type:numeric(5,2);not null;default:0" json:"tax_rate"`
+	TaxAmount   decimal.Decimal `gorm:"type:numeric(15,2);not null;default:0" json:"tax_amount"`
+	TotalAmount decimal.Decimal `gorm:"type:numeric(15,2);not null;default:0" json:"total_amount"`
+
 	PurchaseOrderItem []PurchaseOrderItem `gorm:"foreignKey:PurchaseOrderId" json:"purchase_order_items"`
-	StockTransaction  []StockTransaction  `gorm:"foreignKey:ReferenceId;constraint:OnDelete:SET NULL;" json:"stock_transactions"`
+	StockTransaction  []StockTransaction  `gorm:"foreignKey:ReferenceId;constraint:-" json:"stock_transactions"`
+	GoodsReceipts     []GoodsReceipt      `gorm:"foreignKey:PurchaseOrderId" json:"goods_receipts"`
 	Supplier          Supplier            `gorm:"constraint:OnDelete:SET NULL;" json:"-"`
 }
 
@@ -23,6 +33,47 @@ type PurchaseOrderStatus string
 const (
 	Draft     PurchaseOrderStatus = "DRAFT"
 	Confirmed PurchaseOrderStatus = "CONFIRMED"
-	Received  PurchaseOrderStatus = "RECEIVED"
-	Cancelled PurchaseOrderStatus = "CANCELLED"
+	// PartiallyReceived is set by goods receipts while some items are still outstanding
+	PartiallyReceived PurchaseOrderStatus = "PARTIALLY_RECEIVED"
+	Received          PurchaseOrderStatus = "RECEIVED"
+	Cancelled         PurchaseOrderStatus = "CANCELLED"
 )
+
+// purchaseOrderTransitions lists the statuses each status may move to.
+// RECEIVED and CANCELLED are final.
+var purchaseOrderTransitions = map[PurchaseOrderStatus][]PurchaseOrderStatus{
+	Draft:             {Confirmed, Cancelled},
+	Confirmed:         {PartiallyReceived, Received, Cancelled},
+	PartiallyReceived: {Received},
+}
+
+func (s PurchaseOrderStatus) IsValid() bool {
+	switch s {
+	case Draft, Confirmed, PartiallyReceived, Received, Cancelled:
+		return true
+	}
+	return false
+}
+
+func (s PurchaseOrderStatus) CanTransitionTo(next PurchaseOrderStatus) bool {
+	for _, allowed := range purchaseOrderTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// CalculateTotals recomputes Subtotal, TaxAmount and TotalAmount from items using the
+// order's DiscountAmount and TaxRate
+func (po *PurchaseOrder) CalculateTotals(items []PurchaseOrderItem) {
+	subtotal := decimal.Zero
+	for _, item := range items {
+		subtotal = subtotal.Add(item.LineTotal())
+	}
+
+	taxable := subtotal.Sub(po.DiscountAmount)
+	po.Subtotal = subtotal
+	po.TaxAmount = taxable.Mul(po.TaxRate).Div(decimal.NewFromInt(100)).Round(2)
+	po.TotalAmount = taxable.Add(po.TaxAmount)
+}
@@ -0,0 +1,51 @@
+package model
+
+import "testing"
+
+func TestPurchaseOrderStatusTransitions(t *testing.T) {
+	statuses := []PurchaseOrderStatus{Draft, Confirmed, PartiallyReceived, Received, Cancelled}
+	allowed := map[PurchaseOrderStatus][]PurchaseOrderStatus{
+		Draft:             {Confirmed, Cancelled},
+		Confirmed:         {PartiallyReceived, Received, Cancelled},
+		PartiallyReceived: {Received},
+		// RECEIVED และ CANCELLED เป็นสถานะสุดท้าย
+		Received:  nil,
+		Cancelled: nil,
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			want := false
+			for _, next := range allowed[from] {
+				if next == to {
+					want = true
+				}
+			}
+
+			if got := from.CanTransitionTo(to); got != want {
+				t.Errorf("%s.CanTransitionTo(%s) = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+}
+
+func TestPurchaseOrderStatusIsValid(t *testing.T) {
+	tests := []struct {
+		status PurchaseOrderStatus
+		want   bool
+	}{
+		{Draft, true},
+		{Confirmed, true},
+		{PartiallyReceived, true},
+		{Received, true},
+		{Cancelled, true},
+		{"", false},
+		{"received", false},
+		{"SHIPPED", false},
+	}
+	for _, tt := range tests {
+		if got := tt.status.IsValid(); got != tt.want {
+			t.Errorf("PurchaseOrderStatus(%q).IsValid() = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
@@ -6,15 +6,20 @@ import (
 	"github.com/google/uuid"
 )
 
+// RefreshToken is one link of a session's refresh token chain. Token keeps only the SHA-256 hash of the JWT.
 type RefreshToken struct {
-	RefreshId  uuid.UUID `gorm:"column:refresh_id;type:uuid;default:uuid_generate_v4();primaryKey" json:"refresh_id"`
-	UserNumber uuid.UUID `gorm:"column:user_id;type:uuid;not null;index" json:"user_id"`
-	Token      string    `gorm:"column:token;not null;uniqueIndex" json:"token"`
-	IssueAt    time.Time `gorm:"column:issue_at;not null" json:"issue_at"`
-	ExpireAt   time.Time `gorm:"column:expire_at;not null" json:"expire_at"`
-	Revoked    bool      `gorm:"column:revoked;not null" json:"revoked"`
-	CreatedAt  time.Time `gorm:"column:created_at;not null;autoCreateTime" json:"created_at"`
-	UpdatedAt  time.Time `gorm:"column:updated_at;not null;autoUpdateTime" json:"updated_at"`
+	RefreshId  uuid.UUID  `gorm:"column:refresh_id;type:uuid;default:uuid_generate_v4();primaryKey" json:"refresh_id"`
+	UserNumber uuid.UUID  `gorm:"column:user_id;type:uuid;not null;index" json:"user_id"`
+	SessionId  uuid.UUID  `gorm:"column:session_id;type:uuid;not null;index" json:"session_id"`
+	Token      string     `gorm:"column:token;not null;uniqueIndex" json:"-"`
+	IssueAt    time.Time  `gorm:"column:issue_at;not null" json:"issue_at"`
+	ExpireAt   time.Time  `gorm:"column:expire_at;not null" json:"expire_at"`
+	ConsumedAt *time.Time `gorm:"column:consumed_at" json:"consumed_at"`
+	ReplacedBy *uuid.UUID `gorm:"column:replaced_by;type:uuid" json:"replaced_by"`
+	Revoked    bool       `gorm:"column:revoked;not null;default:false" json:"revoked"`
+	CreatedAt  time.Time  `gorm:"column:created_at;not null;autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"column:updated_at;not null;autoUpdateTime" json:"updated_at"`
 
-	Users User `gorm:"foreignKey:UserId;references:UserNumber;constraint:OnDelete:CASCADE;" json:"-"`
+	Users   User        `gorm:"foreignKey:UserNumber;references:UserId;constraint:OnDelete:CASCADE;" json:"-"`
+	Session UserSession `gorm:"foreignKey:SessionId;references:SessionId;constraint:OnDelete:CASCADE;" json:"-"`
 }
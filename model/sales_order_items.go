@@ -0,0 +1,18 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type SalesOrderItem struct {
+	SalesOrderItemId uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"sales_order_item_id"`
+	SalesOrderId     uuid.UUID `gorm:"type:uuid;not null;index" json:"sales_order_id"`
+	ProductId        uuid.UUID `gorm:"type:uuid;not null;" json:"product_id"`
+	Quantity         uint64    `gorm:"not null;" json:"quantity"`
+	// Price is the product selling price at the time the item was added to the order
+	Price decimal.Decimal `gorm:"type:numeric(15,4);not null;" json:"price"`
+
+	SalesOrder SalesOrder `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+	Product    Product    `gorm:"constraint:OnDelete:RESTRICT;" json:"product"`
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SalesOrder struct {
+	SalesOrderId uuid.UUID        `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"sales_order_id"`
+	CustomerId   uuid.UUID        `gorm:"type:uuid;not null;index" json:"customer_id"`
+	Status       SalesOrderStatus `gorm:"not null;index" json:"status"`
+	CreatedAt    time.Time        `gorm:"not null" json:"created_at"`
+	CreatedBy    uuid.UUID        `gorm:"type:uuid;not null" json:"created_by"`
+	ShippedAt    *time.Time       `json:"shipped_at"`
+
+	SalesOrderItem   []SalesOrderItem   `gorm:"foreignKey:SalesOrderId" json:"sales_order_items"`
+	StockTransaction []StockTransaction `gorm:"foreignKey:ReferenceId;constraint:-" json:"stock_transactions"`
+	Customer         Customer           `gorm:"constraint:OnDelete:RESTRICT;" json:"customer"`
+}
+
+type SalesOrderStatus string
+
+const (
+	SalesOrderDraft     SalesOrderStatus = "DRAFT"
+	SalesOrderConfirmed SalesOrderStatus = "CONFIRMED"
+	SalesOrderShipped   SalesOrderStatus = "SHIPPED"
+	SalesOrderCancelled SalesOrderStatus = "CANCELLED"
+)
+
+// salesOrderTransitions lists the statuses each status may move to.
+// SHIPPED and CANCELLED are final.
+var salesOrderTransitions = map[SalesOrderStatus][]SalesOrderStatus{
+	SalesOrderDraft:     {SalesOrderConfirmed, SalesOrderCancelled},
+	SalesOrderConfirmed: {SalesOrderShipped, SalesOrderCancelled},
+}
+
+func (s SalesOrderStatus) CanTransitionTo(next SalesOrderStatus) bool {
+	for _, allowed := range salesOrderTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
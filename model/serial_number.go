@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SerialStatus string
+
+const (
+	SerialInStock   SerialStatus = "IN_STOCK"
+	SerialInTransit SerialStatus = "IN_TRANSIT" // อยู่ระหว่างโอนย้ายคลัง
+	SerialShipped   SerialStatus = "SHIPPED"
+	SerialScrapped  SerialStatus = "SCRAPPED"
+)
+
+// SerialNumber is one unit of a product with TrackSerial.
+// WarehouseId is where the unit is (or was last) held, LotId the lot it was received in,
+// so outgoing movements take the unit from its own lot.
+type SerialNumber struct {
+	SerialNumberId uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"serial_number_id"`
+	ProductId      uuid.UUID    `gorm:"type:uuid;not null;uniqueIndex:idx_serial_numbers_product_serial" json:"product_id"`
+	Serial         string       `gorm:"not null;uniqueIndex:idx_serial_numbers_product_serial;index" json:"serial"`
+	Status         SerialStatus `gorm:"not null;index" json:"status"`
+	WarehouseId    uuid.UUID    `gorm:"type:uuid;not null" json:"warehouse_id"`
+	LotId          *uuid.UUID   `gorm:"type:uuid;index" json:"lot_id"`
+	CreatedAt      time.Time    `gorm:"not null" json:"created_at"`
+	UpdatedAt      time.Time    `gorm:"not null" json:"updated_at"`
+
+	Product   *Product         `gorm:"constraint:OnDelete:CASCADE;" json:"product,omitempty"`
+	Warehouse *Warehouse       `gorm:"constraint:OnDelete:RESTRICT;" json:"warehouse,omitempty"`
+	Lot       *Lot             `gorm:"constraint:OnDelete:RESTRICT;" json:"lot,omitempty"`
+	Movements []SerialMovement `gorm:"foreignKey:SerialNumberId" json:"movements,omitempty"`
+}
+
+// SerialMovement is the history of a unit: one row per stock transaction that moved it
+type SerialMovement struct {
+	SerialMovementId   uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"serial_movement_id"`
+	SerialNumberId     uuid.UUID    `gorm:"type:uuid;not null;index" json:"serial_number_id"`
+	StockTransactionId uuid.UUID    `gorm:"type:uuid;not null;index" json:"stock_transaction_id"`
+	Status             SerialStatus `gorm:"not null" json:"status"` // สถานะหลังการเคลื่อนไหว
+	CreatedAt          time.Time    `gorm:"not null" json:"created_at"`
+
+	SerialNumber     SerialNumber      `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+	StockTransaction *StockTransaction `gorm:"constraint:OnDelete:CASCADE;" json:"stock_transaction,omitempty"`
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StockBalance is the current on-hand projection of the stock_transactions ledger per warehouse,
+// with the running totals per movement type so summaries never have to scan the ledger.
+// It is maintained in the same DB transaction as every ledger insert.
+type StockBalance struct {
+	WarehouseId uuid.UUID `gorm:"type:uuid;primaryKey" json:"warehouse_id"`
+	ProductId   uuid.UUID `gorm:"type:uuid;primaryKey" json:"product_id"`
+	Quantity    int64     `gorm:"not null;default:0" json:"quantity"`
+	TotalIn     int64     `gorm:"not null;default:0" json:"total_in"`
+	TotalOut    int64     `gorm:"not null;default:0" json:"total_out"`
+	TotalAdjust int64     `gorm:"not null;default:0" json:"total_adjust"` // ผลรวม ADJUST แบบมีเครื่องหมาย
+	UpdatedAt   time.Time `gorm:"not null" json:"updated_at"`
+
+	Product   Product    `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+	Warehouse *Warehouse `gorm:"constraint:OnDelete:CASCADE;" json:"warehouse,omitempty"`
+}
+
+// Apply adds a ledger row to the balance: its signed quantity to Quantity and its quantity to the total of its type.
+// Applying every row of the ledger in any order gives what a rebuild from the ledger sums up.
+func (b *StockBalance) Apply(transaction StockTransaction) {
+	b.Quantity += transaction.SignedQuantity()
+	switch transaction.Type {
+	case TransactionTypeIn:
+		b.TotalIn += transaction.Quantity
+	case TransactionTypeOut:
+		b.TotalOut += transaction.Quantity
+	case TransactionTypeAdjust:
+		b.TotalAdjust += transaction.Quantity
+	}
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStockBalanceApplyMatchesLedger(t *testing.T) {
+	ledger := []StockTransaction{
+		{Type: TransactionTypeIn, Quantity: 10},
+		{Type: TransactionTypeOut, Quantity: 3},
+		{Type: TransactionTypeAdjust, Quantity: -2},
+		{Type: TransactionTypeAdjust, Quantity: 1},
+		{Type: TransactionTypeIn, Quantity: 5},
+		// ขายเกินยอด (backorder) ยอดคงเหลือติดลบได้
+		{Type: TransactionTypeOut, Quantity: 12},
+	}
+	want := StockBalance{Quantity: -1, TotalIn: 15, TotalOut: 15, TotalAdjust: -1}
+
+	// rebuild รวม ledger โดยไม่สนลำดับ ผลต้องเท่ากับการ apply ทีละรายการไม่ว่าลำดับใด
+	reversed := slices.Clone(ledger)
+	slices.Reverse(reversed)
+	for name, rows := range map[string][]StockTransaction{"in order": ledger, "reversed": reversed} {
+		balance := StockBalance{}
+		for _, row := range rows {
+			balance.Apply(row)
+		}
+
+		if balance.Quantity != want.Quantity || balance.TotalIn != want.TotalIn || balance.TotalOut != want.TotalOut || balance.TotalAdjust != want.TotalAdjust {
+			t.Errorf("%s: balance = %+v, want %+v", name, balance, want)
+		}
+		if balance.Quantity != balance.TotalIn-balance.TotalOut+balance.TotalAdjust {
+			t.Errorf("%s: quantity %d does not equal in - out + adjust", name, balance.Quantity)
+		}
+	}
+}
+
+func TestSignedQuantity(t *testing.T) {
+	tests := []struct {
+		transaction StockTransaction
+		want        int64
+	}{
+		{StockTransaction{Type: TransactionTypeIn, Quantity: 4}, 4},
+		{StockTransaction{Type: TransactionTypeOut, Quantity: 4}, -4},
+		{StockTransaction{Type: TransactionTypeAdjust, Quantity: -4}, -4},
+		{StockTransaction{Type: TransactionTypeAdjust, Quantity: 4}, 4},
+	}
+	for _, tt := range tests {
+		if got := tt.transaction.SignedQuantity(); got != tt.want {
+			t.Errorf("SignedQuantity(%s %d) = %d, want %d", tt.transaction.Type, tt.transaction.Quantity, got, tt.want)
+		}
+	}
+}
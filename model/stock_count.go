@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type StockCountStatus string
+
+const (
+	// StockCountOpen accepts counted quantities until an admin approves it
+	StockCountOpen StockCountStatus = "OPEN"
+	// StockCountApproved has posted an ADJUST for every counted difference and is final
+	StockCountApproved StockCountStatus = "APPROVED"
+)
+
+// StockCount is a physical count of one warehouse, optionally limited to one category.
+// Expected quantities are frozen from stock_balances when the count is created.
+type StockCount struct {
+	StockCountId uuid.UUID        `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"stock_count_id"`
+	WarehouseId  uuid.UUID        `gorm:"type:uuid;not null;index" json:"warehouse_id"`
+	CategoryId   *uuid.UUID       `gorm:"type:uuid" json:"category_id"` // nil = ทุกหมวดหมู่
+	Status       StockCountStatus `gorm:"not null;index" json:"status"`
+	Note         *string          `json:"note"`
+	CreatedAt    time.Time        `gorm:"not null" json:"created_at"`
+	CreatedBy    uuid.UUID        `gorm:"type:uuid;not null" json:"created_by"`
+	ApprovedAt   *time.Time       `json:"approved_at"`
+	ApprovedBy   *uuid.UUID       `gorm:"type:uuid" json:"approved_by"`
+
+	Warehouse *Warehouse       `gorm:"constraint:OnDelete:RESTRICT;" json:"warehouse,omitempty"`
+	Category  *Category        `gorm:"constraint:OnDelete:SET NULL;" json:"category,omitempty"`
+	Lines     []StockCountLine `gorm:"foreignKey:StockCountId" json:"lines,omitempty"`
+}
+
+type StockCountLine struct {
+	StockCountLineId uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"stock_count_line_id"`
+	StockCountId     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_stock_count_lines_product" json:"stock_count_id"`
+	ProductId        uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_stock_count_lines_product" json:"product_id"`
+	ExpectedQuantity int64     `gorm:"not null" json:"expected_quantity"`
+	// CountedQuantity is the latest submitted count, nil = not counted yet
+	CountedQuantity *int64     `json:"counted_quantity"`
+	CountedAt       *time.Time `json:"counted_at"`
+	CountedBy       *uuid.UUID `gorm:"type:uuid" json:"counted_by"`
+	// CountedSerials are the units found, one per counted unit, for serial tracked products
+	CountedSerials []string `gorm:"type:jsonb;serializer:json" json:"counted_serials,omitempty"`
+	// StockTransactionId is the first ADJUST posted for the variance when the count was approved,
+	// a variance split over lots posts one ADJUST per lot, all referencing the count
+	StockTransactionId *uuid.UUID `gorm:"type:uuid" json:"stock_transaction_id"`
+
+	StockCount StockCount `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+	Product    *Product   `gorm:"constraint:OnDelete:CASCADE;" json:"product,omitempty"`
+}
+
+// Variance is counted minus expected quantity, nil while the product has not been counted
+func (l StockCountLine) Variance() *int64 {
+	if l.CountedQuantity == nil {
+		return nil
+	}
+	variance := *l.CountedQuantity - l.ExpectedQuantity
+	return &variance
+}
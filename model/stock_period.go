@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// StockPeriod is a closed month. Its snapshot is written once when the period is closed and never changed,
+// and no stock transaction may be dated on or before the end of the latest closed period.
+type StockPeriod struct {
+	StockPeriodId uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"stock_period_id"`
+	Year          int       `gorm:"not null;uniqueIndex:idx_stock_periods_year_month" json:"year"`
+	Month         int       `gorm:"not null;uniqueIndex:idx_stock_periods_year_month" json:"month"`
+	PeriodEnd     time.Time `gorm:"not null;index" json:"period_end"` // วินาทีสุดท้ายของเดือน
+	ClosedAt      time.Time `gorm:"not null" json:"closed_at"`
+	ClosedBy      uuid.UUID `gorm:"type:uuid;not null" json:"closed_by"`
+
+	Snapshots []StockPeriodSnapshot `gorm:"foreignKey:StockPeriodId" json:"snapshots,omitempty"`
+}
+
+// StockPeriodSnapshot is the quantity and value of one product in one warehouse at the end of a closed period
+type StockPeriodSnapshot struct {
+	StockPeriodSnapshotId uuid.UUID       `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"stock_period_snapshot_id"`
+	StockPeriodId         uuid.UUID       `gorm:"type:uuid;not null;index" json:"stock_period_id"`
+	WarehouseId           uuid.UUID       `gorm:"type:uuid;not null" json:"warehouse_id"`
+	ProductId             uuid.UUID       `gorm:"type:uuid;not null" json:"product_id"`
+	Quantity              int64           `gorm:"not null" json:"quantity"`
+	TotalValue            decimal.Decimal `gorm:"type:numeric(15,2);not null;default:0" json:"total_value"`
+
+	StockPeriod StockPeriod `gorm:"constraint:OnDelete:RESTRICT;" json:"-"`
+	Product     *Product    `gorm:"constraint:OnDelete:RESTRICT;" json:"product,omitempty"`
+	Warehouse   *Warehouse  `gorm:"constraint:OnDelete:RESTRICT;" json:"warehouse,omitempty"`
+}
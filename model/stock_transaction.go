@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 type TransactionType string
@@ -17,15 +18,37 @@ const (
 type StockTransaction struct {
 	StockTransactionId uuid.UUID       `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"stock_transaction_id"`
 	ProductId          uuid.UUID       `gorm:"type:uuid;not null" json:"product_id"`
+	WarehouseId        uuid.UUID       `gorm:"type:uuid;not null;index" json:"warehouse_id"`
+	LocationId         *uuid.UUID      `gorm:"type:uuid" json:"location_id"`
+	LotId              *uuid.UUID      `gorm:"type:uuid;index" json:"lot_id"`
 	Quantity           int64           `gorm:"not null" json:"quantity"`
 	Type               TransactionType `gorm:"not null" json:"type"` // e.g., "IN" or "OUT" or "ADJUST"
 	Reason             *string         `json:"reason"`
 	ReferenceId        *uuid.UUID      `gorm:"type:uuid" json:"reference_id"`
-	CreatedAt          time.Time       `gorm:"not null" json:"created_at"`
-	CreatedBy          string          `gorm:"not null" json:"created_by"`
+	// UnitCost and TotalCost are filled by the costing engine. TotalCost has the same sign as
+	// Quantity, so for OUT it is the cost of goods sold.
+	UnitCost  decimal.Decimal `gorm:"type:numeric(15,4);not null;default:0" json:"unit_cost"`
+	TotalCost decimal.Decimal `gorm:"type:numeric(15,2);not null;default:0" json:"total_cost"`
+	CreatedAt time.Time       `gorm:"not null" json:"created_at"`
+	CreatedBy string          `gorm:"not null" json:"created_by"`
+	// UnitCostSet marks UnitCost of an inbound row as supplied by the caller, so an explicit zero
+	// (free goods) is kept instead of replaced by the average cost. Not stored.
+	UnitCostSet bool `gorm:"-" json:"-"`
 
-	Product Product `gorm:"constraint:OnDelete:CASCADE;" json:"product"`
+	Product   Product    `gorm:"constraint:OnDelete:CASCADE;" json:"product"`
+	Warehouse *Warehouse `gorm:"constraint:OnDelete:RESTRICT;" json:"warehouse,omitempty"`
+	Location  *Location  `gorm:"constraint:OnDelete:SET NULL;" json:"location,omitempty"`
+	Lot       *Lot       `gorm:"constraint:OnDelete:RESTRICT;" json:"lot,omitempty"`
 
 	// PurchaseOrder *PurchaseOrder `gorm:"foreignKey:ReferenceId"`
 	// User User `gorm:"foreignKey:CreatedBy"`
 }
+
+// SignedQuantity returns the effect of the transaction on stock on hand:
+// IN adds, OUT removes and ADJUST is already signed.
+func (t StockTransaction) SignedQuantity() int64 {
+	if t.Type == TransactionTypeOut {
+		return -t.Quantity
+	}
+	return t.Quantity
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type StockTransferStatus string
+
+const (
+	// StockTransferInTransit means the stock has left the source warehouse but has not arrived yet
+	StockTransferInTransit StockTransferStatus = "IN_TRANSIT"
+	StockTransferReceived  StockTransferStatus = "RECEIVED"
+)
+
+// StockTransfer moves stock of one product between warehouses. Its OUT and IN stock transactions
+// share the transfer id as ReferenceId.
+type StockTransfer struct {
+	StockTransferId uuid.UUID           `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"stock_transfer_id"`
+	ProductId       uuid.UUID           `gorm:"type:uuid;not null;index" json:"product_id"`
+	FromWarehouseId uuid.UUID           `gorm:"type:uuid;not null;index" json:"from_warehouse_id"`
+	FromLocationId  *uuid.UUID          `gorm:"type:uuid" json:"from_location_id"`
+	ToWarehouseId   uuid.UUID           `gorm:"type:uuid;not null;index" json:"to_warehouse_id"`
+	ToLocationId    *uuid.UUID          `gorm:"type:uuid" json:"to_location_id"`
+	Quantity        int64               `gorm:"not null" json:"quantity"`
+	Status          StockTransferStatus `gorm:"not null;index" json:"status"`
+	Note            *string             `json:"note"`
+	ShippedAt       time.Time           `gorm:"not null" json:"shipped_at"`
+	ShippedBy       string              `gorm:"not null" json:"shipped_by"`
+	ReceivedAt      *time.Time          `json:"received_at"`
+	ReceivedBy      *string             `json:"received_by"`
+
+	Product       Product   `gorm:"constraint:OnDelete:CASCADE;" json:"product"`
+	FromWarehouse Warehouse `gorm:"foreignKey:FromWarehouseId;constraint:OnDelete:RESTRICT;" json:"from_warehouse"`
+	ToWarehouse   Warehouse `gorm:"foreignKey:ToWarehouseId;constraint:OnDelete:RESTRICT;" json:"to_warehouse"`
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// SupplierProduct is a product in a supplier's catalog with the terms that supplier sells it on
+type SupplierProduct struct {
+	SupplierProductId uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"supplier_product_id"`
+	SupplierId        uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_supplier_products_product" json:"supplier_id"`
+	ProductId         uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_supplier_products_product;index" json:"product_id"`
+	// SupplierSku is the supplier's own code for the product
+	SupplierSku *string `json:"supplier_sku"`
+	// UnitPrice replaces Product.CostPrice on purchase orders to this supplier
+	UnitPrice decimal.Decimal `gorm:"type:numeric(15,4);not null" json:"unit_price"`
+	// MinOrderQuantity is the smallest quantity the supplier accepts per order line
+	MinOrderQuantity uint64 `gorm:"not null;default:1" json:"min_order_quantity"`
+	// PackSize is the multiple an order quantity must be in, e.g. 12 for a box of 12
+	PackSize uint64 `gorm:"not null;default:1" json:"pack_size"`
+	// LeadTimeDays overrides Product.LeadTimeDays for this supplier, nil = use the product's
+	LeadTimeDays *int64    `json:"lead_time_days"`
+	CreatedAt    time.Time `gorm:"not null" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"not null" json:"updated_at"`
+
+	Supplier *Supplier `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+	Product  *Product  `gorm:"constraint:OnDelete:CASCADE;" json:"product,omitempty"`
+}
+
+// RoundUpQuantity returns the smallest quantity of at least quantity that meets the minimum order quantity
+// and is a multiple of the pack size
+func (s SupplierProduct) RoundUpQuantity(quantity uint64) uint64 {
+	quantity = max(quantity, s.MinOrderQuantity)
+	if s.PackSize > 1 && quantity%s.PackSize != 0 {
+		quantity += s.PackSize - quantity%s.PackSize
+	}
+	return quantity
+}
@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UnitOfMeasure is an alternative unit of a product, e.g. a box of 12 when Product.Unit is pcs.
+// Quantities are always stored in Product.Unit (the base unit), other units are converted on input.
+type UnitOfMeasure struct {
+	UnitOfMeasureId uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"unit_of_measure_id"`
+	ProductId       uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_unit_of_measures_product_name" json:"product_id"`
+	Name            string    `gorm:"not null;uniqueIndex:idx_unit_of_measures_product_name" json:"name"`
+	// Factor is how many base units one of this unit holds
+	Factor    int64     `gorm:"not null" json:"factor"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+
+	Product *Product `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+}
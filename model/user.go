@@ -17,15 +17,20 @@ const (
 )
 
 type User struct {
-	UserId    uuid.UUID `gorm:"column:user_id;type:uuid;default:uuid_generate_v4();primaryKey" json:"user_id"`
-	Username  string    `gorm:"column:username;not null;uniqueIndex" json:"username"`
-	FirstName string    `gorm:"column:first_name;not null" json:"first_name"`
-	LastName  string    `gorm:"column:last_name;not null" json:"last_name"`
-	Password  string    `gorm:"column:password;not null" json:"-"`
-	Role      Role      `gorm:"column:role; not null;" json:"role"`
-	CreatedAt time.Time `gorm:"column:created_at;not null;autoCreateTime" json:"created_at"`
-	UpdatedAt time.Time `gorm:"column:updated_at;not null;autoUpdateTime" json:"updated_at"`
-	Token     *string   `gorm:"column:token;" json:"-"`
+	UserId             uuid.UUID `gorm:"column:user_id;type:uuid;default:uuid_generate_v4();primaryKey" json:"user_id"`
+	Username           string    `gorm:"column:username;not null;uniqueIndex" json:"username"`
+	FirstName          string    `gorm:"column:first_name;not null" json:"first_name"`
+	LastName           string    `gorm:"column:last_name;not null" json:"last_name"`
+	Password           string    `gorm:"column:password;not null" json:"-"`
+	Role               Role      `gorm:"column:role; not null;" json:"role"`
+	Disabled           bool      `gorm:"column:disabled;not null;default:false" json:"disabled"`
+	MustChangePassword bool      `gorm:"column:must_change_password;not null;default:false" json:"must_change_password"`
+	MfaEnabled         bool      `gorm:"column:mfa_enabled;not null;default:false" json:"mfa_enabled"`
+	MfaSecret          *string   `gorm:"column:mfa_secret" json:"-"`
+	MfaLastCounter     int64     `gorm:"column:mfa_last_counter;not null;default:0" json:"-"`
+	CreatedAt          time.Time `gorm:"column:created_at;not null;autoCreateTime" json:"created_at"`
+	UpdatedAt          time.Time `gorm:"column:updated_at;not null;autoUpdateTime" json:"updated_at"`
+	Token              *string   `gorm:"column:token;" json:"-"`
 
 	AuditLogs []AuditLog `gorm:"foreignKey:UserId;references:UserId" json:"-"`
 }
@@ -7,11 +7,14 @@ import (
 )
 
 type UserSession struct {
-	SessionId    uuid.UUID `gorm:"type:uuid;primaryKey" json:"session_id"`
-	UserId       uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
-	AccessToken  string    `gorm:"not null;uniqueIndex" json:"access_token"`
-	RefreshToken string    `gorm:"not null;uniqueIndex" json:"refresh_token"`
-	Revoked      bool      `gorm:"not null;default:false" json:"revoked"`
-	CreatedAt    time.Time `gorm:"not null;autoCreateTime" json:"created_at"`
-	UpdatedAt    time.Time `gorm:"not null;autoUpdateTime" json:"updated_at"`
+	SessionId          uuid.UUID `gorm:"type:uuid;primaryKey" json:"session_id"`
+	UserId             uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	AccessToken        string    `gorm:"not null;uniqueIndex" json:"access_token"`
+	AccessUuid         string    `gorm:"not null;default:'';index" json:"access_uuid"`
+	RefreshToken       string    `gorm:"not null;uniqueIndex" json:"refresh_token"`
+	Revoked            bool      `gorm:"not null;default:false" json:"revoked"`
+	MustChangePassword bool      `gorm:"not null;default:false" json:"must_change_password"`
+	MfaEnrollRequired  bool      `gorm:"not null;default:false" json:"mfa_enroll_required"`
+	CreatedAt          time.Time `gorm:"not null;autoCreateTime" json:"created_at"`
+	UpdatedAt          time.Time `gorm:"not null;autoUpdateTime" json:"updated_at"`
 }
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Warehouse is a site that holds stock. Stock movements that do not name a warehouse
+// go to the default warehouse.
+type Warehouse struct {
+	WarehouseId uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"warehouse_id"`
+	Code        string    `gorm:"not null;uniqueIndex" json:"code"`
+	Name        string    `gorm:"not null" json:"name"`
+	Address     *string   `json:"address"`
+	IsDefault   bool      `gorm:"not null;default:false" json:"is_default"`
+	CreatedAt   time.Time `gorm:"not null" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"not null" json:"updated_at"`
+
+	Locations []Location `gorm:"foreignKey:WarehouseId;references:WarehouseId" json:"locations,omitempty"`
+}
+
+// Location is an optional bin or shelf inside a warehouse
+type Location struct {
+	LocationId  uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"location_id"`
+	WarehouseId uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_locations_warehouse_code" json:"warehouse_id"`
+	Code        string    `gorm:"not null;uniqueIndex:idx_locations_warehouse_code" json:"code"`
+	Description *string   `json:"description"`
+	CreatedAt   time.Time `gorm:"not null" json:"created_at"`
+
+	Warehouse Warehouse `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+}
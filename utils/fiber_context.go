@@ -6,8 +6,16 @@ import (
 )
 
 type UserDataCtx struct {
-	UserId uuid.UUID
-	Role   string
+	UserId      uuid.UUID
+	Role        string
+	Permissions []string
+	SessionId   uuid.UUID
+	AccessUuid  string
+}
+
+// HasPermission reports whether the access token of the user carries the permission
+func (u UserDataCtx) HasPermission(permission string) bool {
+	return MissingPermission(u.Permissions, []string{permission}) == ""
 }
 
 const (
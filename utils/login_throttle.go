@@ -0,0 +1,39 @@
+package utils
+
+import "time"
+
+// LoginThrottle กำหนดการหน่วงเวลาและล็อกบัญชีเมื่อ login ผิดติดกัน ค่ามาจาก environment
+type LoginThrottle struct {
+	// Window นับเฉพาะความล้มเหลวที่เกิดภายในช่วงเวลานี้
+	Window time.Duration
+	// BackoffAfter จำนวนครั้งที่ผิดได้ก่อนเริ่มหน่วงเวลา
+	BackoffAfter int64
+	BackoffBase  time.Duration
+	BackoffMax   time.Duration
+	// LockoutAfter จำนวนครั้งที่ผิดติดกันต่อ username ก่อนล็อกบัญชี ค่า 0 คือไม่ล็อกบัญชี
+	LockoutAfter int64
+	// IPLockoutAfter จำนวนครั้งที่ผิดต่อ IP ก่อนปฏิเสธทุก login จาก IP นั้น
+	IPLockoutAfter  int64
+	LockoutDuration time.Duration
+}
+
+// Backoff returns how long to wait after the last failure before the next attempt is accepted,
+// doubling with every failure beyond BackoffAfter up to BackoffMax
+func (t LoginThrottle) Backoff(failures int64) time.Duration {
+	if failures <= t.BackoffAfter {
+		return 0
+	}
+
+	delay := t.BackoffBase
+	for i := t.BackoffAfter + 1; i < failures; i++ {
+		delay *= 2
+		if delay >= t.BackoffMax {
+			return t.BackoffMax
+		}
+	}
+
+	if delay > t.BackoffMax {
+		return t.BackoffMax
+	}
+	return delay
+}
@@ -0,0 +1,18 @@
+package utils
+
+import (
+	"mini-erp-backend/lib/secret"
+	"time"
+)
+
+// MfaPolicy กำหนดการทำงานของ TOTP two-factor ค่ามาจาก environment
+type MfaPolicy struct {
+	// Issuer ชื่อที่แสดงในแอป authenticator
+	Issuer string
+	// RequireForAdmin บังคับให้ทุก role ที่มีสิทธิ์ user.manage หรือ role.manage ต้องเปิดใช้ MFA ก่อนใช้งานระบบ
+	RequireForAdmin      bool
+	ChallengeTTL         time.Duration
+	ChallengeMaxAttempts int64
+	// SecretBox เข้ารหัส TOTP secret ก่อนเก็บใน users.mfa_secret
+	SecretBox *secret.Box
+}
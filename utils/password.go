@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrWeakPassword is returned when a new password does not satisfy the password policy
+var ErrWeakPassword = errors.New("password does not meet the policy")
+
+// PasswordPolicy กำหนดเงื่อนไขของรหัสผ่านใหม่ ค่ามาจาก environment
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+// Validate returns ErrWeakPassword wrapped with every rule the password breaks
+func (p PasswordPolicy) Validate(password string) error {
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+
+	rules := []string{}
+	if len([]rune(password)) < p.MinLength {
+		rules = append(rules, fmt.Sprintf("at least %d characters", p.MinLength))
+	}
+	if p.RequireUpper && !upper {
+		rules = append(rules, "an uppercase letter")
+	}
+	if p.RequireLower && !lower {
+		rules = append(rules, "a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		rules = append(rules, "a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		rules = append(rules, "a symbol")
+	}
+
+	if len(rules) > 0 {
+		return fmt.Errorf("%w: requires %s", ErrWeakPassword, strings.Join(rules, ", "))
+	}
+	return nil
+}
+
+// HashPassword returns the bcrypt hash stored in users.password
+func HashPassword(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+// ComparePassword returns nil when the password matches the stored bcrypt hash
+func ComparePassword(hashed, password string) error {
+	return bcrypt.CompareHashAndPassword([]byte(hashed), []byte(password))
+}
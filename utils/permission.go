@@ -0,0 +1,17 @@
+package utils
+
+// MissingPermission returns the first permission of required that granted does not contain,
+// or "" when granted covers every one of them
+func MissingPermission(granted, required []string) string {
+	have := make(map[string]struct{}, len(granted))
+	for _, p := range granted {
+		have[p] = struct{}{}
+	}
+
+	for _, p := range required {
+		if _, ok := have[p]; !ok {
+			return p
+		}
+	}
+	return ""
+}